@@ -8,4 +8,14 @@ type Config struct {
 
 	MaxOpenNum int `json:"maxOpenNum"`
 	MaxIdleNum int `json:"maxIdleNum"`
+
+	// data source names of the read replicas, used in round-robin for select queries by InitDBWithReadReplicas
+	ReadReplicaDSN []string `json:"read_replica_dsn,omitempty"`
+
+	// AutoMigrate indicates if the services owning the schema of scroll-tech/database, i.e. the rollup services,
+	// apply its pending migrations on startup.
+	AutoMigrate bool `json:"auto_migrate,omitempty"`
+
+	// SlowQueryThresholdMs is the duration in milliseconds above which the queries are logged as slow, 0 disables it.
+	SlowQueryThresholdMs uint64 `json:"slow_query_threshold_ms,omitempty"`
 }
@@ -3,7 +3,9 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/log"
@@ -41,16 +43,80 @@ func (g *gormLogger) Error(_ context.Context, msg string, data ...interface{}) {
 func (g *gormLogger) Trace(_ context.Context, begin time.Time, fc func() (string, int64), err error) {
 	elapsed := time.Since(begin)
 	sql, rowsAffected := fc()
+	// all the orm statements are traced here, a pool exhaustion is not a query failure so it is only a warning.
+	if IsPoolExhaustedError(err) {
+		dbPoolExhaustedTotal.Inc()
+		g.gethLogger.Warn("gorm: db connection pool exhausted", "line", utils.FileWithLineNum(), "cost", elapsed, "sql", sql, "err", err)
+		return
+	}
 	g.gethLogger.Debug("gorm", "line", utils.FileWithLineNum(), "cost", elapsed, "sql", sql, "rowsAffected", rowsAffected, "err", err)
 }
 
 // InitDB init the db handler
 func InitDB(config *Config) (*gorm.DB, error) {
+	return openDB(config.DSN, config)
+}
+
+// DB is the primary db handler together with the read replica handlers.
+type DB struct {
+	primary  *gorm.DB
+	replicas []*gorm.DB
+	next     atomic.Uint64
+}
+
+// InitDBWithReadReplicas init the primary db handler and the handlers of config.ReadReplicaDSN.
+func InitDBWithReadReplicas(config *Config) (*DB, error) {
+	primary, err := openDB(config.DSN, config)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &DB{primary: primary}
+	for i, dsn := range config.ReadReplicaDSN {
+		replica, err := openDB(dsn, config)
+		if err != nil {
+			if closeErr := db.Close(); closeErr != nil {
+				log.Warn("failed to close db", "err", closeErr)
+			}
+			return nil, fmt.Errorf("failed to open read replica %d: %w", i, err)
+		}
+		db.replicas = append(db.replicas, replica)
+	}
+	return db, nil
+}
+
+// Primary returns the primary db handler, all the writes must go to it.
+func (d *DB) Primary() *gorm.DB {
+	return d.primary
+}
+
+// ReadDB returns the read replica handlers in round-robin, or the primary handler if there is no replica.
+// The replicas may lag behind the primary, so it must not be used to read back a recent write.
+func (d *DB) ReadDB() *gorm.DB {
+	if len(d.replicas) == 0 {
+		return d.primary
+	}
+	return d.replicas[(d.next.Add(1)-1)%uint64(len(d.replicas))]
+}
+
+// Close closes the primary and the read replica handlers.
+func (d *DB) Close() error {
+	var errs []error
+	for _, db := range append([]*gorm.DB{d.primary}, d.replicas...) {
+		if err := CloseDB(db); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// openDB opens the db handler of dsn with the connection pool settings of config.
+func openDB(dsn string, config *Config) (*gorm.DB, error) {
 	tmpGormLogger := gormLogger{
 		gethLogger: log.Root(),
 	}
 
-	db, err := gorm.Open(postgres.Open(config.DSN), &gorm.Config{
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: &tmpGormLogger,
 		NowFunc: func() time.Time {
 			// why set time to UTC.
@@ -65,6 +131,13 @@ func InitDB(config *Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if config.SlowQueryThresholdMs > 0 {
+		threshold := time.Duration(config.SlowQueryThresholdMs) * time.Millisecond
+		if err = db.Use(NewSlowQueryLogger(threshold, log.Root())); err != nil {
+			return nil, fmt.Errorf("failed to register the slow query logger: %w", err)
+		}
+	}
+
 	sqlDB, pingErr := Ping(db)
 	if pingErr != nil {
 		return nil, pingErr
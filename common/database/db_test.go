@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"testing"
@@ -10,8 +12,11 @@ import (
 
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 
 	"scroll-tech/common/docker"
 	"scroll-tech/common/version"
@@ -38,6 +43,24 @@ func TestGormLogger(t *testing.T) {
 	gl.Trace(context.Background(), time.Now(), func() (string, int64) { return "test trace", 1 }, nil)
 }
 
+func TestIsPoolExhaustedError(t *testing.T) {
+	assert.False(t, IsPoolExhaustedError(nil))
+	assert.False(t, IsPoolExhaustedError(errors.New("ERROR: relation \"batch\" does not exist (SQLSTATE 42P01)")))
+	assert.False(t, IsPoolExhaustedError(gorm.ErrRecordNotFound))
+
+	assert.True(t, IsPoolExhaustedError(driver.ErrBadConn))
+	assert.True(t, IsPoolExhaustedError(fmt.Errorf("Batch.GetBatches error: %w", driver.ErrBadConn)))
+	assert.True(t, IsPoolExhaustedError(errors.New("sql: database is closed")))
+	assert.True(t, IsPoolExhaustedError(errors.New("failed to connect to `host=localhost user=postgres database=scroll`: server error (FATAL: remaining connection slots are reserved for non-replication superuser connections (SQLSTATE 53300))")))
+
+	// the pool exhaustions of the traced statements are counted.
+	gl := gormLogger{gethLogger: log.Root()}
+	before := testutil.ToFloat64(dbPoolExhaustedTotal)
+	gl.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, driver.ErrBadConn)
+	gl.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, errors.New("query error"))
+	assert.Equal(t, before+1, testutil.ToFloat64(dbPoolExhaustedTotal))
+}
+
 func TestDB(t *testing.T) {
 	version.Version = "v4.1.98-aaa-bbb-ccc"
 	base := docker.NewDockerApp()
@@ -59,4 +82,58 @@ func TestDB(t *testing.T) {
 	assert.NotNil(t, sqlDB)
 
 	assert.NoError(t, CloseDB(db))
+
+	dbCfg.ReadReplicaDSN = []string{base.DBConfig.DSN}
+	replicatedDB, err := InitDBWithReadReplicas(dbCfg)
+	assert.NoError(t, err)
+	assert.NotSame(t, replicatedDB.Primary(), replicatedDB.ReadDB())
+	assert.NoError(t, replicatedDB.Close())
+}
+
+func TestReadDB(t *testing.T) {
+	primary, replica1, replica2 := &gorm.DB{}, &gorm.DB{}, &gorm.DB{}
+
+	db := &DB{primary: primary}
+	assert.Same(t, primary, db.ReadDB())
+
+	db.replicas = []*gorm.DB{replica1, replica2}
+	assert.Same(t, replica1, db.ReadDB())
+	assert.Same(t, replica2, db.ReadDB())
+	assert.Same(t, replica1, db.ReadDB())
+	assert.Same(t, primary, db.Primary())
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	var records []*log.Record
+	gethLogger := log.New()
+	gethLogger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+
+	// the statements are not executed in dry run mode, so no db is needed.
+	openDryRunDB := func(threshold time.Duration) *gorm.DB {
+		db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+		assert.NoError(t, err)
+		assert.NoError(t, db.Use(NewSlowQueryLogger(threshold, gethLogger)))
+		return db
+	}
+	type account struct {
+		Name string
+	}
+
+	before := testutil.ToFloat64(dbSlowQueryTotal)
+	db := openDryRunDB(time.Hour)
+	assert.NoError(t, db.Where("name = ?", "secret").Find(&[]account{}).Error)
+	assert.Empty(t, records)
+	assert.Equal(t, before, testutil.ToFloat64(dbSlowQueryTotal))
+
+	db = openDryRunDB(time.Nanosecond)
+	assert.NoError(t, db.Where("name = ?", "secret").Find(&[]account{}).Error)
+	assert.Len(t, records, 1)
+	assert.Equal(t, log.LvlWarn, records[0].Lvl)
+	assert.Equal(t, before+1, testutil.ToFloat64(dbSlowQueryTotal))
+	logged := fmt.Sprint(records[0].Ctx...)
+	assert.Contains(t, logged, "name = $1")
+	assert.NotContains(t, logged, "secret")
 }
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// poolExhaustedMessages are the messages of the errors returned when no connection can be used,
+// database/sql does not export its closed db error.
+var poolExhaustedMessages = []string{
+	"sql: database is closed",
+	"remaining connection slots are reserved",
+}
+
+var dbPoolExhaustedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "db_pool_exhausted_total",
+	Help: "The total number of db statements failed because no connection was available.",
+})
+
+// IsPoolExhaustedError returns true if err is caused by the connection pool or the server running out of
+// connections rather than by the query itself.
+func IsPoolExhaustedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	msg := err.Error()
+	for _, poolMsg := range poolExhaustedMessages {
+		if strings.Contains(msg, poolMsg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils"
+)
+
+// slowQueryStartKey is the key of the start time of a statement in its gorm instance.
+const slowQueryStartKey = "slow_query_logger:start"
+
+var dbSlowQueryTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "db_slow_query_total",
+	Help: "The total number of db statements slower than the slow query threshold.",
+})
+
+// SlowQueryLogger is a gorm plugin logging the statements slower than a threshold at warn level.
+// The statements are logged with their placeholders, the bind parameters are never logged.
+type SlowQueryLogger struct {
+	threshold  time.Duration
+	gethLogger log.Logger
+}
+
+// NewSlowQueryLogger returns a new instance of SlowQueryLogger.
+func NewSlowQueryLogger(threshold time.Duration, gethLogger log.Logger) *SlowQueryLogger {
+	return &SlowQueryLogger{threshold: threshold, gethLogger: gethLogger}
+}
+
+// Name returns the name of the plugin.
+func (*SlowQueryLogger) Name() string {
+	return "slow_query_logger"
+}
+
+// Initialize registers the callbacks timing the statements of db.
+func (l *SlowQueryLogger) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("slow_query_logger:before_create", l.before),
+		cb.Create().After("gorm:create").Register("slow_query_logger:after_create", l.after),
+		cb.Query().Before("gorm:query").Register("slow_query_logger:before_query", l.before),
+		cb.Query().After("gorm:query").Register("slow_query_logger:after_query", l.after),
+		cb.Update().Before("gorm:update").Register("slow_query_logger:before_update", l.before),
+		cb.Update().After("gorm:update").Register("slow_query_logger:after_update", l.after),
+		cb.Delete().Before("gorm:delete").Register("slow_query_logger:before_delete", l.before),
+		cb.Delete().After("gorm:delete").Register("slow_query_logger:after_delete", l.after),
+		cb.Row().Before("gorm:row").Register("slow_query_logger:before_row", l.before),
+		cb.Row().After("gorm:row").Register("slow_query_logger:after_row", l.after),
+		cb.Raw().Before("gorm:raw").Register("slow_query_logger:before_raw", l.before),
+		cb.Raw().After("gorm:raw").Register("slow_query_logger:after_raw", l.after),
+	)
+}
+
+func (l *SlowQueryLogger) before(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, time.Now())
+}
+
+func (l *SlowQueryLogger) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(slowQueryStartKey)
+	if !ok {
+		return
+	}
+	start, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed <= l.threshold {
+		return
+	}
+	dbSlowQueryTotal.Inc()
+	l.gethLogger.Warn("gorm: slow query", "line", utils.FileWithLineNum(), "cost", elapsed, "threshold", l.threshold, "sql", db.Statement.SQL.String())
+}
@@ -42,6 +42,149 @@ func (s GasOracleStatus) String() string {
 	}
 }
 
+// StateRootRelayStatus represents the status of relaying the state root of a batch to the l1 state root oracle
+type StateRootRelayStatus int
+
+const (
+	// StateRootRelayUndefined : undefined state root relay status
+	StateRootRelayUndefined StateRootRelayStatus = iota
+
+	// StateRootRelayPending represents the state root is not relayed yet
+	StateRootRelayPending
+
+	// StateRootRelaying represents the state root relay transaction is sent
+	StateRootRelaying
+
+	// StateRootRelayed represents the state root relay transaction is confirmed
+	StateRootRelayed
+
+	// StateRootRelayFailed represents the state root relay transaction failed
+	StateRootRelayFailed
+)
+
+func (s StateRootRelayStatus) String() string {
+	switch s {
+	case StateRootRelayUndefined:
+		return "StateRootRelayUndefined"
+	case StateRootRelayPending:
+		return "StateRootRelayPending"
+	case StateRootRelaying:
+		return "StateRootRelaying"
+	case StateRootRelayed:
+		return "StateRootRelayed"
+	case StateRootRelayFailed:
+		return "StateRootRelayFailed"
+	default:
+		return fmt.Sprintf("Undefined StateRootRelayStatus (%d)", int32(s))
+	}
+}
+
+// BatchFinalizationStatus represents the status of finalizing a batch with its proof in the l1 rollup contract
+type BatchFinalizationStatus int
+
+const (
+	// FinalizationUndefined : undefined batch finalization status
+	FinalizationUndefined BatchFinalizationStatus = iota
+
+	// FinalizationPending represents the finalize transaction is not submitted yet
+	FinalizationPending
+
+	// FinalizationSubmitted represents the finalize transaction is submitted
+	FinalizationSubmitted
+
+	// FinalizationConfirmed represents the finalize transaction is confirmed
+	FinalizationConfirmed
+
+	// FinalizationFailed represents the finalize transaction failed
+	FinalizationFailed
+)
+
+func (s BatchFinalizationStatus) String() string {
+	switch s {
+	case FinalizationUndefined:
+		return "FinalizationUndefined"
+	case FinalizationPending:
+		return "FinalizationPending"
+	case FinalizationSubmitted:
+		return "FinalizationSubmitted"
+	case FinalizationConfirmed:
+		return "FinalizationConfirmed"
+	case FinalizationFailed:
+		return "FinalizationFailed"
+	default:
+		return fmt.Sprintf("Undefined BatchFinalizationStatus (%d)", int32(s))
+	}
+}
+
+// ProofJobStatus represents the status of a job of the rollup relayer proof queue
+type ProofJobStatus int
+
+const (
+	// ProofJobUndefined : undefined proof job status
+	ProofJobUndefined ProofJobStatus = iota
+
+	// ProofJobPending represents the proof job is waiting for a worker
+	ProofJobPending
+
+	// ProofJobRunning represents the proof is being generated by the proof service
+	ProofJobRunning
+
+	// ProofJobCompleted represents the proof is generated and saved
+	ProofJobCompleted
+
+	// ProofJobFailed represents the proof generation failed
+	ProofJobFailed
+)
+
+func (s ProofJobStatus) String() string {
+	switch s {
+	case ProofJobUndefined:
+		return "ProofJobUndefined"
+	case ProofJobPending:
+		return "ProofJobPending"
+	case ProofJobRunning:
+		return "ProofJobRunning"
+	case ProofJobCompleted:
+		return "ProofJobCompleted"
+	case ProofJobFailed:
+		return "ProofJobFailed"
+	default:
+		return fmt.Sprintf("Undefined ProofJobStatus (%d)", int32(s))
+	}
+}
+
+// ProofAggregationStatus represents the status of aggregating the chunk proofs of a batch into one proof
+type ProofAggregationStatus int
+
+const (
+	// ProofAggregationUndefined : undefined proof aggregation status
+	ProofAggregationUndefined ProofAggregationStatus = iota
+
+	// ProofAggregationPending represents the chunk proofs of the batch are not aggregated yet
+	ProofAggregationPending
+
+	// ProofAggregationComplete represents the aggregated proof of the batch is stored
+	ProofAggregationComplete
+
+	// ProofAggregationFailed represents the aggregation service rejected the chunk proofs of the batch
+	ProofAggregationFailed
+)
+
+func (s ProofAggregationStatus) String() string {
+	switch s {
+	case ProofAggregationUndefined:
+		return "ProofAggregationUndefined"
+	case ProofAggregationPending:
+		return "ProofAggregationPending"
+	case ProofAggregationComplete:
+		return "ProofAggregationComplete"
+	case ProofAggregationFailed:
+		return "ProofAggregationFailed"
+	default:
+		return fmt.Sprintf("Undefined ProofAggregationStatus (%d)", int32(s))
+	}
+}
+
 // MsgStatus represents current layer1 transaction processing status
 type MsgStatus int
 
@@ -245,6 +388,10 @@ const (
 	SenderTypeL1GasOracle
 	// SenderTypeL2GasOracle indicates a sender from L1 responsible for updating L2 gas prices.
 	SenderTypeL2GasOracle
+	// SenderTypeStateRootOracle indicates a sender responsible for relaying L2 state roots to the L1 state root oracle.
+	SenderTypeStateRootOracle
+	// SenderTypeL1GasOracleShadow indicates a sender from L2 updating L1 gas prices on the shadow L2.
+	SenderTypeL1GasOracleShadow
 )
 
 // String returns a string representation of the SenderType.
@@ -258,6 +405,10 @@ func (t SenderType) String() string {
 		return "SenderTypeL1GasOracle"
 	case SenderTypeL2GasOracle:
 		return "SenderTypeL2GasOracle"
+	case SenderTypeStateRootOracle:
+		return "SenderTypeStateRootOracle"
+	case SenderTypeL1GasOracleShadow:
+		return "SenderTypeL1GasOracleShadow"
 	default:
 		return fmt.Sprintf("Unknown SenderType (%d)", int32(t))
 	}
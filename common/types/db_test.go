@@ -177,6 +177,16 @@ func TestSenderType(t *testing.T) {
 			SenderTypeL2GasOracle,
 			"SenderTypeL2GasOracle",
 		},
+		{
+			"SenderTypeStateRootOracle",
+			SenderTypeStateRootOracle,
+			"SenderTypeStateRootOracle",
+		},
+		{
+			"SenderTypeL1GasOracleShadow",
+			SenderTypeL1GasOracleShadow,
+			"SenderTypeL1GasOracleShadow",
+		},
 		{
 			"Invalid Value",
 			SenderType(999),
@@ -281,6 +291,181 @@ func TestGasOracleStatus(t *testing.T) {
 	}
 }
 
+func TestStateRootRelayStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		s    StateRootRelayStatus
+		want string
+	}{
+		{
+			"StateRootRelayUndefined",
+			StateRootRelayUndefined,
+			"StateRootRelayUndefined",
+		},
+		{
+			"StateRootRelayPending",
+			StateRootRelayPending,
+			"StateRootRelayPending",
+		},
+		{
+			"StateRootRelaying",
+			StateRootRelaying,
+			"StateRootRelaying",
+		},
+		{
+			"StateRootRelayed",
+			StateRootRelayed,
+			"StateRootRelayed",
+		},
+		{
+			"StateRootRelayFailed",
+			StateRootRelayFailed,
+			"StateRootRelayFailed",
+		},
+		{
+			"Invalid Value",
+			StateRootRelayStatus(999),
+			"Undefined StateRootRelayStatus (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.s.String())
+		})
+	}
+}
+
+func TestBatchFinalizationStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		s    BatchFinalizationStatus
+		want string
+	}{
+		{
+			"FinalizationUndefined",
+			FinalizationUndefined,
+			"FinalizationUndefined",
+		},
+		{
+			"FinalizationPending",
+			FinalizationPending,
+			"FinalizationPending",
+		},
+		{
+			"FinalizationSubmitted",
+			FinalizationSubmitted,
+			"FinalizationSubmitted",
+		},
+		{
+			"FinalizationConfirmed",
+			FinalizationConfirmed,
+			"FinalizationConfirmed",
+		},
+		{
+			"FinalizationFailed",
+			FinalizationFailed,
+			"FinalizationFailed",
+		},
+		{
+			"Invalid Value",
+			BatchFinalizationStatus(999),
+			"Undefined BatchFinalizationStatus (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.s.String())
+		})
+	}
+}
+
+func TestProofJobStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		s    ProofJobStatus
+		want string
+	}{
+		{
+			"ProofJobUndefined",
+			ProofJobUndefined,
+			"ProofJobUndefined",
+		},
+		{
+			"ProofJobPending",
+			ProofJobPending,
+			"ProofJobPending",
+		},
+		{
+			"ProofJobRunning",
+			ProofJobRunning,
+			"ProofJobRunning",
+		},
+		{
+			"ProofJobCompleted",
+			ProofJobCompleted,
+			"ProofJobCompleted",
+		},
+		{
+			"ProofJobFailed",
+			ProofJobFailed,
+			"ProofJobFailed",
+		},
+		{
+			"Invalid Value",
+			ProofJobStatus(999),
+			"Undefined ProofJobStatus (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.s.String())
+		})
+	}
+}
+
+func TestProofAggregationStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		s    ProofAggregationStatus
+		want string
+	}{
+		{
+			"ProofAggregationUndefined",
+			ProofAggregationUndefined,
+			"ProofAggregationUndefined",
+		},
+		{
+			"ProofAggregationPending",
+			ProofAggregationPending,
+			"ProofAggregationPending",
+		},
+		{
+			"ProofAggregationComplete",
+			ProofAggregationComplete,
+			"ProofAggregationComplete",
+		},
+		{
+			"ProofAggregationFailed",
+			ProofAggregationFailed,
+			"ProofAggregationFailed",
+		},
+		{
+			"Invalid Value",
+			ProofAggregationStatus(999),
+			"Undefined ProofAggregationStatus (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.s.String())
+		})
+	}
+}
+
 func TestProverTaskFailureType(t *testing.T) {
 	tests := []struct {
 		name string
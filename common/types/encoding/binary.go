@@ -0,0 +1,87 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+var (
+	// zstdEncoder and zstdDecoder are safe for concurrent use through EncodeAll and DecodeAll.
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// binaryBlock is the gob encoded form of Block. The header and the transactions are encoded beforehand
+// as their types are not supported by gob, and the row consumption as gob does not tell nil from empty.
+type binaryBlock struct {
+	Header         []byte // rlp encoded
+	Transactions   []byte // json encoded
+	WithdrawRoot   common.Hash
+	RowConsumption []byte // json encoded
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, it returns the zstd compressed gob encoding of the block.
+func (b *Block) MarshalBinary() ([]byte, error) {
+	header, err := rlp.EncodeToBytes(b.Header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rlp encode block header: %w", err)
+	}
+	transactions, err := json.Marshal(b.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal block transactions: %w", err)
+	}
+	rowConsumption, err := json.Marshal(b.RowConsumption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal block row consumption: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = gob.NewEncoder(&buf).Encode(&binaryBlock{
+		Header:         header,
+		Transactions:   transactions,
+		WithdrawRoot:   b.WithdrawRoot,
+		RowConsumption: rowConsumption,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to gob encode block: %w", err)
+	}
+	return zstdEncoder.EncodeAll(buf.Bytes(), nil), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it decodes the output of MarshalBinary.
+func (b *Block) UnmarshalBinary(data []byte) error {
+	decompressed, err := zstdDecoder.DecodeAll(data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decompress block: %w", err)
+	}
+	var bb binaryBlock
+	if err = gob.NewDecoder(bytes.NewReader(decompressed)).Decode(&bb); err != nil {
+		return fmt.Errorf("failed to gob decode block: %w", err)
+	}
+
+	var header types.Header
+	if err = rlp.DecodeBytes(bb.Header, &header); err != nil {
+		return fmt.Errorf("failed to rlp decode block header: %w", err)
+	}
+	var transactions []*types.TransactionData
+	if err = json.Unmarshal(bb.Transactions, &transactions); err != nil {
+		return fmt.Errorf("failed to unmarshal block transactions: %w", err)
+	}
+	var rowConsumption *types.RowConsumption
+	if err = json.Unmarshal(bb.RowConsumption, &rowConsumption); err != nil {
+		return fmt.Errorf("failed to unmarshal block row consumption: %w", err)
+	}
+
+	b.Header = &header
+	b.Transactions = transactions
+	b.WithdrawRoot = bb.WithdrawRoot
+	b.RowConsumption = rowConsumption
+	return nil
+}
@@ -120,3 +120,23 @@ func readBlockFromJSON(t *testing.T, filename string) *Block {
 	assert.NoError(t, json.Unmarshal(data, block))
 	return block
 }
+
+func TestBlockBinary(t *testing.T) {
+	for _, file := range []string{"blockTrace_02.json", "blockTrace_03.json", "blockTrace_04.json", "blockTrace_05.json", "blockTrace_06.json", "blockTrace_07.json"} {
+		block := readBlockFromJSON(t, "../../testdata/"+file)
+
+		data, err := block.MarshalBinary()
+		assert.NoError(t, err)
+		decoded := &Block{}
+		assert.NoError(t, decoded.UnmarshalBinary(data))
+
+		assert.Equal(t, block.Header.Hash(), decoded.Header.Hash())
+		expected, err := json.Marshal(block)
+		assert.NoError(t, err)
+		actual, err := json.Marshal(decoded)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(expected), string(actual))
+	}
+
+	assert.Error(t, (&Block{}).UnmarshalBinary([]byte("not a block")))
+}
@@ -26,4 +26,7 @@ const (
 	ErrCoordinatorHandleZkProofFailure = 20003
 	// ErrCoordinatorEmptyProofData get empty proof data
 	ErrCoordinatorEmptyProofData = 20004
+
+	// ErrRollupAdminParameterInvalidNo is invalid params
+	ErrRollupAdminParameterInvalidNo = 30001
 )
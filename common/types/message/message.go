@@ -282,6 +282,14 @@ type BatchProof struct {
 	GitVersion string `json:"git_version,omitempty"`
 }
 
+// AggregatedProof is the proof of a batch aggregated from the proofs of its chunks by an aggregation service.
+type AggregatedProof struct {
+	BatchIndex             uint64       `json:"batch_index"`
+	ChunkProofs            []ChunkProof `json:"chunk_proofs"`
+	AggregatedProofData    []byte       `json:"aggregated_proof_data"`
+	AggregatedPublicInputs []byte       `json:"aggregated_public_inputs"`
+}
+
 // SanityCheck checks whether an BatchProof is in a legal format
 // TODO: change to check Proof&Instance when upgrading to snark verifier v0.4
 func (ap *BatchProof) SanityCheck() error {
@@ -21,6 +21,7 @@ var (
 	// RollupRelayerFlags contains flags only used in rollup-relayer
 	RollupRelayerFlags = []cli.Flag{
 		&ImportGenesisFlag,
+		&DebugProposalsFlag,
 	}
 	// ConfigFileFlag load json type config file.
 	ConfigFileFlag = cli.StringFlag{
@@ -78,6 +79,18 @@ var (
 		Usage: "Import genesis batch into L1 contract during startup",
 		Value: false,
 	}
+	// DebugProposalsFlag logs the plan of each chunk proposal as json
+	DebugProposalsFlag = cli.BoolFlag{
+		Name:  "debug-proposals",
+		Usage: "Log the plan of each chunk proposal as JSON: the candidate blocks, the estimates and the reason it is sealed",
+		Value: false,
+	}
+	// AutoMigrateFlag applies the pending db migrations during startup
+	AutoMigrateFlag = cli.BoolFlag{
+		Name:  "auto-migrate",
+		Usage: "Apply the pending db migrations during startup, overriding auto_migrate of the db config",
+		Value: false,
+	}
 	// ServicePortFlag is the port the service will listen on
 	ServicePortFlag = cli.IntFlag{
 		Name:  "service.port",
@@ -49,6 +49,26 @@ func Loop(ctx context.Context, period time.Duration, f func()) {
 	}
 }
 
+// LoopWithDelay Run the f func repeatedly, waiting for the duration returned by delay after each run.
+func LoopWithDelay(ctx context.Context, delay func() time.Duration, f func()) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			f()
+		}
+
+		timer := time.NewTimer(delay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
 // IsNil Check if the interface is empty.
 func IsNil(i interface{}) bool {
 	return i == nil || reflect2.IsNil(i)
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE l1_block
+ADD COLUMN last_retry_at TIMESTAMP(0) DEFAULT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS l1_block
+DROP COLUMN IF EXISTS last_retry_at;
+
+-- +goose StatementEnd
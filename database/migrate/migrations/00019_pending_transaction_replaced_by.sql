@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE pending_transaction
+ADD COLUMN replaced_by VARCHAR DEFAULT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS pending_transaction
+DROP COLUMN IF EXISTS replaced_by;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create index if not exists l1_block_number_oracle_status_index
+on l1_block (number, oracle_status) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists l1_block_number_oracle_status_index;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table l1_message
+    add column log_index INTEGER DEFAULT NULL;
+
+create unique index if not exists l1_message_layer1_hash_log_index_uindex
+on l1_message (layer1_hash, log_index) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists l1_message_layer1_hash_log_index_uindex;
+
+alter table l1_message
+    drop column if exists log_index;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the legacy rows are backfilled by the rollup relayer, as the binary encoding is done in go.
+alter table l2_block
+    add column block_trace_binary BYTEA DEFAULT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table l2_block
+    drop column if exists block_trace_binary;
+
+-- +goose StatementEnd
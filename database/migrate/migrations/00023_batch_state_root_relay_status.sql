@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table batch
+    add column state_root_relay_status  SMALLINT    NOT NULL DEFAULT 1,
+    add column state_root_relay_tx_hash VARCHAR     DEFAULT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table batch
+    drop column if exists state_root_relay_tx_hash,
+    drop column if exists state_root_relay_status;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table batch
+    add column finalization_status SMALLINT NOT NULL DEFAULT 1;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table batch
+    drop column if exists finalization_status;
+
+-- +goose StatementEnd
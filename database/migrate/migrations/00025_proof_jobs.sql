@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE proof_jobs
+(
+    id           BIGSERIAL    PRIMARY KEY,
+
+    batch_index  BIGINT       NOT NULL,
+    batch_hash   VARCHAR      NOT NULL,
+    status       SMALLINT     NOT NULL DEFAULT 1,
+    started_at   TIMESTAMP(0) DEFAULT NULL,
+    completed_at TIMESTAMP(0) DEFAULT NULL,
+
+    created_at   TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at   TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX unique_idx_proof_jobs_on_batch_index ON proof_jobs(batch_index) WHERE deleted_at IS NULL;
+CREATE INDEX idx_proof_jobs_on_status_batch_index ON proof_jobs(status, batch_index) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN proof_jobs.status IS 'undefined, pending, running, completed, failed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS proof_jobs;
+-- +goose StatementEnd
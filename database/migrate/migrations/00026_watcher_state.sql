@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE watcher_state
+(
+    name                 VARCHAR      PRIMARY KEY,
+    start_block_override BIGINT       NOT NULL DEFAULT 0,
+
+    created_at           TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN watcher_state.start_block_override IS 'the block the watcher reprocesses the events from instead of the stored heights, 0 if disabled';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS watcher_state;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create index if not exists batch_finalization_status_index
+on batch (finalization_status, index) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists batch_finalization_status_index;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE quarantined_block
+(
+    number       BIGINT       PRIMARY KEY,
+    raw_block    BYTEA        DEFAULT NULL,
+    error        TEXT         NOT NULL,
+    discarded_at TIMESTAMP(0) DEFAULT NULL,
+
+    created_at   TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN quarantined_block.raw_block IS 'the raw json block trace returned by l2geth, NULL if it could not be fetched';
+COMMENT ON COLUMN quarantined_block.discarded_at IS 'the time an operator gave up on the block, it is not fetched again';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS quarantined_block;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE aggregated_proof
+(
+    batch_index   BIGINT       PRIMARY KEY,
+    batch_hash    VARCHAR      NOT NULL,
+    chunk_proofs  BYTEA        NOT NULL,
+    proof         BYTEA        NOT NULL,
+    public_inputs BYTEA        NOT NULL,
+
+    created_at    TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+alter table batch
+    add column proof_aggregation_status SMALLINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN batch.proof_aggregation_status IS 'undefined, pending, complete, failed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table batch
+    drop column if exists proof_aggregation_status;
+
+DROP TABLE IF EXISTS aggregated_proof;
+
+-- +goose StatementEnd
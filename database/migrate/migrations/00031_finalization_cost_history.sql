@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE finalization_cost_history
+(
+    batch_hash  VARCHAR      PRIMARY KEY,
+    tx_hash     VARCHAR      NOT NULL,
+    l1_base_fee BIGINT       NOT NULL,
+    gas_used    BIGINT       NOT NULL,
+
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_finalization_cost_history_created_at ON finalization_cost_history (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS finalization_cost_history;
+
+-- +goose StatementEnd
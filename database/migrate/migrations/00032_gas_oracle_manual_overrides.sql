@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE gas_oracle_manual_overrides
+(
+    id          BIGSERIAL    PRIMARY KEY,
+    operator_id VARCHAR      NOT NULL,
+    gas_price   BIGINT       NOT NULL,
+    tx_hash     VARCHAR      NOT NULL,
+
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_gas_oracle_manual_overrides_created_at ON gas_oracle_manual_overrides (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS gas_oracle_manual_overrides;
+
+-- +goose StatementEnd
@@ -109,3 +109,42 @@ func TestPackSetL2BaseFee(t *testing.T) {
 	_, err = l2GasOracleABI.Pack("setL2BaseFee", baseFee)
 	assert.NoError(err)
 }
+
+func TestPackMulticallSetL1BaseFee(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls []Multicall3Call3
+	for _, baseFee := range []int64{1, 2, 3} {
+		data, err := L1GasPriceOracleABI.Pack("setL1BaseFee", big.NewInt(baseFee))
+		assert.NoError(err)
+		calls = append(calls, Multicall3Call3{Target: common.Address{}, CallData: data})
+	}
+
+	data, err := Multicall3ABI.Pack("aggregate3", calls)
+	assert.NoError(err)
+	assert.Equal(Multicall3ABI.Methods["aggregate3"].ID, data[:4])
+}
+
+func TestPackMulticallValue(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := []Multicall3Call3Value{
+		{Target: common.HexToAddress("0x01"), Value: big.NewInt(1)},
+		{Target: common.HexToAddress("0x01"), Value: big.NewInt(0), CallData: []byte{1, 2, 3, 4}},
+	}
+	data, err := Multicall3ABI.Pack("aggregate3Value", calls)
+	assert.NoError(err)
+	assert.Equal(Multicall3ABI.Methods["aggregate3Value"].ID, data[:4])
+	// 0x174dea71 is the selector of aggregate3Value((address,bool,uint256,bytes)[])
+	assert.Equal([]byte{0x17, 0x4d, 0xea, 0x71}, data[:4])
+}
+
+func TestPackSetL2StateRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	stateRootOracleABI, err := L1StateRootOracleMetaData.GetAbi()
+	assert.NoError(err)
+
+	_, err = stateRootOracleABI.Pack("setL2StateRoot", common.Hash{}, big.NewInt(0))
+	assert.NoError(err)
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/version"
+)
+
+var app *cli.App
+
+var (
+	// addrFlag is the base url of the admin server.
+	addrFlag = cli.StringFlag{
+		Name:  "addr",
+		Usage: "Base url of the admin server",
+		Value: "http://localhost:8090",
+	}
+	// tlsCertFlag is the client certificate presented to the admin server.
+	tlsCertFlag = cli.StringFlag{
+		Name:  "tls.cert",
+		Usage: "PEM encoded client certificate file for mutual TLS",
+	}
+	// tlsKeyFlag is the private key of the client certificate.
+	tlsKeyFlag = cli.StringFlag{
+		Name:  "tls.key",
+		Usage: "PEM encoded client private key file for mutual TLS",
+	}
+	// tlsCAFlag is the CA certificates verifying the admin server certificate.
+	tlsCAFlag = cli.StringFlag{
+		Name:  "tls.ca",
+		Usage: "PEM encoded CA certificates file verifying the admin server, the system pool is used if empty",
+	}
+	// gasPriceFlag is the gas_price parameter of set-gas-price.
+	gasPriceFlag = cli.Uint64Flag{
+		Name:     "gas-price",
+		Usage:    "The l1 gas price set manually in the l2 gas price oracle",
+		Required: true,
+	}
+	// olderThanFlag is the older_than parameter of repropose-failed.
+	olderThanFlag = cli.StringFlag{
+		Name:  "older-than",
+		Usage: "Only repropose the batches whose commit failed longer ago than the duration, e.g. 10m",
+	}
+)
+
+func init() {
+	// Set up admin app info.
+	app = cli.NewApp()
+	app.Name = "rollup-admin"
+	app.Usage = "The Scroll Rollup Admin Client"
+	app.Version = version.Version
+	app.Flags = []cli.Flag{&addrFlag, &tlsCertFlag, &tlsKeyFlag, &tlsCAFlag}
+	app.Commands = []*cli.Command{
+		{
+			Name:   "gas-price-oracle",
+			Usage:  "Trigger the gas price oracle update of gas-oracle",
+			Action: post("/gas_price_oracle"),
+		},
+		{
+			Name:   "pause-gas-oracle",
+			Usage:  "Pause the l1 gas price oracle updates of gas-oracle",
+			Action: post("/gas_oracle/pause"),
+		},
+		{
+			Name:   "resume-gas-oracle",
+			Usage:  "Resume the l1 gas price oracle updates of gas-oracle",
+			Action: post("/gas_oracle/resume"),
+		},
+		{
+			Name:   "set-gas-price",
+			Usage:  "Set the l1 gas price of the l2 gas price oracle manually through gas-oracle, bypassing the oracle",
+			Flags:  []cli.Flag{&gasPriceFlag},
+			Action: setGasPrice,
+		},
+		{
+			Name:   "chunk-proposal",
+			Usage:  "Trigger a chunk proposal of rollup-relayer",
+			Action: post("/chunk_proposal"),
+		},
+		{
+			Name:   "batch-proposal",
+			Usage:  "Trigger a batch proposal of rollup-relayer",
+			Action: post("/batch_proposal"),
+		},
+		{
+			Name:   "repropose-failed",
+			Usage:  "Roll back the commit failed batches of rollup-relayer and repropose their chunks",
+			Flags:  []cli.Flag{&olderThanFlag},
+			Action: reproposeFailed,
+		},
+		{
+			Name:   "chunk-stats",
+			Usage:  "Query the statistics of the chunks proposed by rollup-relayer in the last 24 hours",
+			Action: get("/metrics/chunk_stats"),
+		},
+		{
+			Name:   "status",
+			Usage:  "Query the relayer status",
+			Action: status,
+		},
+	}
+}
+
+func newClient(ctx *cli.Context) (*resty.Client, error) {
+	client := resty.New().SetBaseURL(strings.TrimSuffix(ctx.String(addrFlag.Name), "/"))
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile := ctx.String(tlsCAFlag.Name); caFile != "" {
+		caPEM, err := os.ReadFile(filepath.Clean(caFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %w", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificate found in ca file %v", caFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if certFile, keyFile := ctx.String(tlsCertFlag.Name), ctx.String(tlsKeyFlag.Name); certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return client.SetTLSClientConfig(tlsConfig), nil
+}
+
+func post(path string) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		return request(ctx, http.MethodPost, path, nil)
+	}
+}
+
+func get(path string) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		return request(ctx, http.MethodGet, path, nil)
+	}
+}
+
+func reproposeFailed(ctx *cli.Context) error {
+	return request(ctx, http.MethodPost, "/repropose_failed", map[string]string{"older_than": ctx.String(olderThanFlag.Name)})
+}
+
+func setGasPrice(ctx *cli.Context) error {
+	return request(ctx, http.MethodPost, "/gas_oracle/set_gas_price", map[string]string{"gas_price": strconv.FormatUint(ctx.Uint64(gasPriceFlag.Name), 10)})
+}
+
+func status(ctx *cli.Context) error {
+	return request(ctx, http.MethodGet, "/status", nil)
+}
+
+// request calls the admin server and prints the data of a successful response.
+func request(ctx *cli.Context, method, path string, query map[string]string) error {
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var result types.Response
+	resp, err := client.R().SetContext(ctx.Context).SetQueryParams(query).SetResult(&result).SetError(&result).Execute(method, path)
+	if err != nil {
+		return fmt.Errorf("failed to request %v: %w", path, err)
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return fmt.Errorf("%v is not served by the admin server", path)
+	}
+	if result.ErrCode != types.Success {
+		return errors.New(result.ErrMsg)
+	}
+
+	if result.Data != nil {
+		data, err := json.MarshalIndent(result.Data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(ctx.App.Writer, string(data))
+	}
+	return nil
+}
+
+// Run rollup admin cmd instance.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/watcher"
+	"scroll-tech/rollup/internal/orm"
 )
 
 var app *cli.App
@@ -31,6 +32,7 @@ func init() {
 	app.Usage = "The Scroll Event Watcher"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.AutoMigrateFlag)
 	app.Commands = []*cli.Command{}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -47,6 +49,10 @@ func action(ctx *cli.Context) error {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
 
+	if ctx.Bool(utils.AutoMigrateFlag.Name) {
+		cfg.DBConfig.AutoMigrate = true
+	}
+
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
 	db, err := database.InitDB(cfg.DBConfig)
@@ -60,6 +66,13 @@ func action(ctx *cli.Context) error {
 		}
 	}()
 
+	if cfg.DBConfig.AutoMigrate {
+		if err = orm.MigrateSchema(db); err != nil {
+			log.Crit("failed to migrate db", "err", err)
+		}
+		log.Info("Applied the pending db migrations")
+	}
+
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
 	l1client, err := ethclient.Dial(cfg.L1Config.Endpoint)
@@ -69,12 +82,37 @@ func action(ctx *cli.Context) error {
 
 	l1watcher := watcher.NewL1WatcherClient(ctx.Context, l1client, cfg.L1Config.StartHeight, cfg.L1Config.Confirmations,
 		cfg.L1Config.L1MessageQueueAddress, cfg.L1Config.ScrollChainContractAddress, db, registry)
+	l1watcher.SetConfirmationDepth(cfg.L1Config.ConfirmationDepth)
+	l1watcher.SetMaxFilterLogSplitDepth(cfg.L1Config.MaxFilterLogSplitDepth)
+	l1watcher.SetAllowedSenders(cfg.L1Config.AllowedL1SenderAddresses)
+	l1watcher.SetMessageExpiryDuration(cfg.L1Config.MessageExpirySec)
+	l1watcher.SetAllowedSendersWatcher(config.NewFileAllowedL1SendersWatcher(cfgFile))
+	if cfg.L1Config.EventReplayFile != "" {
+		if err = l1watcher.SetEventReplayFile(cfg.L1Config.EventReplayFile); err != nil {
+			log.Crit("failed to load the event replay file", "file", cfg.L1Config.EventReplayFile, "error", err)
+		}
+	}
 
-	go utils.Loop(subCtx, 10*time.Second, func() {
-		if loopErr := l1watcher.FetchContractEvent(); loopErr != nil {
-			log.Error("Failed to fetch bridge contract", "err", loopErr)
+	// the events are fetched on each new head if subscribed, and polled in case the subscription drops.
+	var newHeads <-chan struct{}
+	if cfg.L1Config.UseWebSocketSubscription {
+		newHeads = l1watcher.SubscribeNewHeads(subCtx, cfg.L1Config.Endpoint)
+	}
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			if loopErr := l1watcher.FetchContractEvent(); loopErr != nil {
+				log.Error("Failed to fetch bridge contract", "err", loopErr)
+			}
+			select {
+			case <-subCtx.Done():
+				return
+			case <-ticker.C:
+			case <-newHeads:
+			}
 		}
-	})
+	}()
 
 	log.Info("Start event-watcher successfully")
 
@@ -19,8 +19,10 @@ import (
 	"scroll-tech/common/version"
 
 	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/admin"
 	"scroll-tech/rollup/internal/controller/relayer"
 	"scroll-tech/rollup/internal/controller/watcher"
+	"scroll-tech/rollup/internal/orm"
 	butils "scroll-tech/rollup/internal/utils"
 )
 
@@ -35,7 +37,22 @@ func init() {
 	app.Description = "Scroll Gas Oracle."
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
-	app.Commands = []*cli.Command{}
+	app.Flags = append(app.Flags, &utils.AutoMigrateFlag)
+	app.Commands = []*cli.Command{
+		{
+			Name:   "retry-gas-oracle",
+			Usage:  "Reset the failed l1 gas oracle blocks to pending so that they are retried.",
+			Action: retryGasOracle,
+			Flags: []cli.Flag{
+				&utils.ConfigFileFlag,
+				&cli.DurationFlag{
+					Name:  "older-than",
+					Usage: "Only retry the blocks that failed more than the given duration ago.",
+					Value: 10 * time.Minute,
+				},
+			},
+		},
+	}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
 	}
@@ -50,18 +67,31 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+
+	if ctx.Bool(utils.AutoMigrateFlag.Name) {
+		cfg.DBConfig.AutoMigrate = true
+	}
+
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
-	db, err := database.InitDB(cfg.DBConfig)
+	replicatedDB, err := database.InitDBWithReadReplicas(cfg.DBConfig)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
 	defer func() {
 		cancel()
-		if err = database.CloseDB(db); err != nil {
+		if err = replicatedDB.Close(); err != nil {
 			log.Crit("failed to close db connection", "error", err)
 		}
 	}()
+	db := replicatedDB.Primary()
+
+	if cfg.DBConfig.AutoMigrate {
+		if err = orm.MigrateSchema(db); err != nil {
+			log.Crit("failed to migrate db", "err", err)
+		}
+		log.Info("Applied the pending db migrations")
+	}
 
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
@@ -78,15 +108,41 @@ func action(ctx *cli.Context) error {
 	}
 
 	l1watcher := watcher.NewL1WatcherClient(ctx.Context, l1client, cfg.L1Config.StartHeight, cfg.L1Config.Confirmations, cfg.L1Config.L1MessageQueueAddress, cfg.L1Config.ScrollChainContractAddress, db, registry)
+	l1watcher.SetMaxExpectedReorgDepth(cfg.L1Config.MaxExpectedReorgDepth, cfg.L1Config.HaltOnDeepReorg)
+	if cfg.L1Config.PrefetchDepth > 0 {
+		l1watcher.StartPrefetch(cfg.L1Config.PrefetchDepth)
+	}
 
-	l1relayer, err := relayer.NewLayer1Relayer(ctx.Context, db, cfg.L1Config.RelayerConfig, relayer.ServiceTypeL1GasOracle, registry)
+	l1relayer, err := relayer.NewLayer1Relayer(ctx.Context, db, cfg.L1Config.RelayerConfig, relayer.ServiceTypeL1GasOracle, registry, relayer.WithL1Client(l1client), relayer.WithL1ReadReplica(replicatedDB))
 	if err != nil {
 		log.Crit("failed to create new l1 relayer", "config file", cfgFile, "error", err)
 	}
+	l1relayer.SetConfigWatcher(config.NewFileConfigWatcher(cfgFile, func(c *config.Config) *config.GasOracleConfig {
+		return c.L1Config.RelayerConfig.GasOracleConfig
+	}))
 	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, false /* initGenesis */, relayer.ServiceTypeL2GasOracle, registry)
 	if err != nil {
 		log.Crit("failed to create new l2 relayer", "config file", cfgFile, "error", err)
 	}
+	if cfg.AdminAddr != "" {
+		adminServer := admin.NewServer(cfg.AdminAddr, l1relayer, l2relayer)
+		adminServer.RegisterGasPriceOracles(l1relayer, l2relayer)
+		adminServer.RegisterGasOraclePauser(l1relayer)
+		adminServer.RegisterGasPriceOverrider(l1relayer)
+		adminServer.RegisterReorgAcknowledger(l1watcher)
+		if cfg.AdminTLSConfig != nil {
+			if err = adminServer.EnableTLS(cfg.AdminTLSConfig); err != nil {
+				log.Crit("failed to enable admin server tls", "config file", cfgFile, "error", err)
+			}
+		}
+		adminServer.Start()
+		defer func() {
+			if err = adminServer.Stop(context.Background()); err != nil {
+				log.Error("failed to stop admin server", "error", err)
+			}
+		}()
+	}
+
 	// Start l1 watcher process
 	go utils.LoopWithContext(subCtx, 10*time.Second, func(ctx context.Context) {
 		// Fetch the latest block number to decrease the delay when fetching gas prices
@@ -104,8 +160,18 @@ func action(ctx *cli.Context) error {
 	})
 
 	// Start l1relayer process
-	go utils.Loop(subCtx, 10*time.Second, l1relayer.ProcessGasPriceOracle)
-	go utils.Loop(subCtx, 2*time.Second, l2relayer.ProcessGasPriceOracle)
+	go utils.LoopWithDelay(subCtx, func() time.Duration {
+		return l1relayer.GasOraclePollDelay(10 * time.Second)
+	}, func() {
+		if loopErr := l1relayer.ProcessGasPriceOracle(); loopErr != nil {
+			relayer.LogError("Failed to process l1 gas price oracle", loopErr)
+		}
+	})
+	go utils.Loop(subCtx, 2*time.Second, func() {
+		if loopErr := l2relayer.ProcessGasPriceOracle(); loopErr != nil {
+			relayer.LogError("Failed to process l2 gas price oracle", loopErr)
+		}
+	})
 
 	// Finish start all message relayer functions
 	log.Info("Start gas-oracle successfully")
@@ -116,10 +182,36 @@ func action(ctx *cli.Context) error {
 
 	// Wait until the interrupt signal is received from an OS signal.
 	<-interrupt
+	l1relayer.Stop()
 
 	return nil
 }
 
+func retryGasOracle(ctx *cli.Context) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", cfgFile, err)
+	}
+	db, err := database.InitDB(cfg.DBConfig)
+	if err != nil {
+		return fmt.Errorf("failed to init db connection: %w", err)
+	}
+	defer func() {
+		if closeErr := database.CloseDB(db); closeErr != nil {
+			log.Error("failed to close db connection", "error", closeErr)
+		}
+	}()
+
+	olderThan := ctx.Duration("older-than")
+	count, err := orm.NewL1Block(db).RetryFailedL1GasOracleBlocks(ctx.Context, olderThan)
+	if err != nil {
+		return err
+	}
+	log.Info("Reset failed l1 gas oracle blocks to pending", "count", count, "older than", olderThan)
+	return nil
+}
+
 // Run message_relayer cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
@@ -2,14 +2,17 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
 
 	"scroll-tech/common/database"
@@ -18,13 +21,23 @@ import (
 	"scroll-tech/common/version"
 
 	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/admin"
 	"scroll-tech/rollup/internal/controller/relayer"
 	"scroll-tech/rollup/internal/controller/watcher"
+	"scroll-tech/rollup/internal/orm"
 	butils "scroll-tech/rollup/internal/utils"
 )
 
 var app *cli.App
 
+const (
+	// missingBlocksFetchLimit is the maximum number of blocks filled into the gaps of the stored l2 blocks per loop.
+	missingBlocksFetchLimit = 10
+
+	// defaultStateRootRelayInterval is the interval between two state root relay runs if it is not configured.
+	defaultStateRootRelayInterval = time.Minute
+)
+
 func init() {
 	// Set up rollup-relayer app info.
 	app = cli.NewApp()
@@ -33,8 +46,38 @@ func init() {
 	app.Usage = "The Scroll Rollup Relayer"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.AutoMigrateFlag)
 	app.Flags = append(app.Flags, utils.RollupRelayerFlags...)
-	app.Commands = []*cli.Command{}
+	app.Commands = []*cli.Command{
+		{
+			Name:   "print-config",
+			Usage:  "Print the loaded config as JSON with the secrets redacted, fail if it is invalid.",
+			Action: printConfig,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag},
+		},
+		{
+			Name:   "export-block-traces",
+			Usage:  "Export the stored l2 block traces as newline-delimited JSON.",
+			Action: exportBlockTraces,
+			Flags: []cli.Flag{
+				&utils.ConfigFileFlag,
+				&cli.Uint64Flag{Name: "from", Usage: "The first exported block number", Required: true},
+				&cli.Uint64Flag{Name: "to", Usage: "The last exported block number", Required: true},
+				&cli.Uint64Flag{Name: "after", Usage: "Resume the export after this block number, e.g. the last exported one"},
+				&cli.StringFlag{Name: "output", Usage: "The file the block traces are appended to, stdout if empty"},
+			},
+		},
+		{
+			Name:   "unquarantine-block",
+			Usage:  "Fetch a quarantined l2 block again and store it, or discard it so that it stays skipped.",
+			Action: unquarantineBlock,
+			Flags: []cli.Flag{
+				&utils.ConfigFileFlag,
+				&cli.Uint64Flag{Name: "number", Usage: "The number of the quarantined block", Required: true},
+				&cli.BoolFlag{Name: "discard", Usage: "Discard the block instead of reprocessing it"},
+			},
+		},
+	}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
 	}
@@ -50,30 +93,43 @@ func action(ctx *cli.Context) error {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
 
+	if ctx.Bool(utils.AutoMigrateFlag.Name) {
+		cfg.DBConfig.AutoMigrate = true
+	}
+
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
-	db, err := database.InitDB(cfg.DBConfig)
+	replicatedDB, err := database.InitDBWithReadReplicas(cfg.DBConfig)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
 	defer func() {
 		cancel()
-		if err = database.CloseDB(db); err != nil {
+		if err = replicatedDB.Close(); err != nil {
 			log.Crit("failed to close db connection", "error", err)
 		}
 	}()
+	db := replicatedDB.Primary()
+
+	if cfg.DBConfig.AutoMigrate {
+		if err = orm.MigrateSchema(db); err != nil {
+			log.Crit("failed to migrate db", "err", err)
+		}
+		log.Info("Applied the pending db migrations")
+	}
 
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
 
 	// Init l2geth connection
-	l2client, err := ethclient.Dial(cfg.L2Config.Endpoint)
+	l2rpc, err := rpc.Dial(cfg.L2Config.Endpoint)
 	if err != nil {
 		log.Crit("failed to connect l2 geth", "config file", cfgFile, "error", err)
 	}
+	l2client := ethclient.NewClient(l2rpc)
 
 	initGenesis := ctx.Bool(utils.ImportGenesisFlag.Name)
-	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, initGenesis, relayer.ServiceTypeL2RollupRelayer, registry)
+	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, initGenesis, relayer.ServiceTypeL2RollupRelayer, registry, relayer.WithL2ReadReplica(replicatedDB))
 	if err != nil {
 		log.Crit("failed to create l2 relayer", "config file", cfgFile, "error", err)
 	}
@@ -88,6 +144,16 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to create chunkProposer", "config file", cfgFile, "error", err)
 	}
+	if cfg.L2Config.ChunkProposerConfig.MaxChunkGasLimit > 0 {
+		l1client, dialErr := ethclient.Dial(cfg.L1Config.Endpoint)
+		if dialErr != nil {
+			log.Crit("failed to connect l1 geth", "config file", cfgFile, "error", dialErr)
+		}
+		commitSenderAddress := crypto.PubkeyToAddress(cfg.L2Config.RelayerConfig.CommitSenderPrivateKey.PublicKey)
+		chunkProposer.SetL1CommitGasEstimator(l1client, cfg.L2Config.RelayerConfig.RollupContractAddress, commitSenderAddress)
+	}
+	chunkProposer.SetDebugProposals(ctx.Bool(utils.DebugProposalsFlag.Name))
+	chunkProposer.SetReadReplica(replicatedDB)
 
 	batchProposer := watcher.NewBatchProposer(subCtx, cfg.L2Config.BatchProposerConfig, genesis.Config, db, registry)
 	if err != nil {
@@ -95,6 +161,17 @@ func action(ctx *cli.Context) error {
 	}
 
 	l2watcher := watcher.NewL2WatcherClient(subCtx, l2client, cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, registry)
+	l2watcher.SetFetchConcurrency(cfg.L2Config.FetchConcurrency)
+	l2watcher.SetMaxRPCRequestsPerSecond(cfg.L2Config.MaxRPCRequestsPerSecond)
+	l2watcher.SetRPCClient(l2rpc)
+	reorgDetector := watcher.NewReorgDetector(subCtx, l2client, cfg.L2Config.ReorgCheckDepth, db, registry)
+
+	// Encode the l2 blocks stored before the binary encoding, once.
+	go func() {
+		if backfillErr := l2watcher.BackfillBlockTraceBinary(subCtx); backfillErr != nil {
+			log.Error("failed to backfill block trace binary", "err", backfillErr)
+		}
+	}()
 
 	// Watcher loop to fetch missing blocks
 	go utils.LoopWithContext(subCtx, 2*time.Second, func(ctx context.Context) {
@@ -103,16 +180,95 @@ func action(ctx *cli.Context) error {
 			log.Error("failed to get block number", "err", loopErr)
 			return
 		}
+		if loopErr = reorgDetector.TryDetectReorg(); loopErr != nil {
+			log.Error("failed to detect l2 reorg", "err", loopErr)
+			return
+		}
 		l2watcher.TryFetchRunningMissingBlocks(number)
+		if _, loopErr = l2watcher.FetchMissingBlocks(ctx, missingBlocksFetchLimit); loopErr != nil {
+			log.Error("failed to fetch missing l2 blocks", "err", loopErr)
+		}
 	})
 
+	if cfg.AdminAddr != "" {
+		adminServer := admin.NewServer(cfg.AdminAddr, l2relayer)
+		adminServer.RegisterChunkProposer(chunkProposer)
+		adminServer.RegisterBatchProposer(batchProposer)
+		adminServer.RegisterFinalizationCostPredictor(l2relayer.CostPredictor())
+		if cfg.AdminTLSConfig != nil {
+			if err = adminServer.EnableTLS(cfg.AdminTLSConfig); err != nil {
+				log.Crit("failed to enable admin server tls", "config file", cfgFile, "error", err)
+			}
+		}
+		adminServer.Start()
+		defer func() {
+			if err = adminServer.Stop(context.Background()); err != nil {
+				log.Error("failed to stop admin server", "error", err)
+			}
+		}()
+	}
+
 	go utils.Loop(subCtx, 2*time.Second, chunkProposer.TryProposeChunk)
 
 	go utils.Loop(subCtx, 10*time.Second, batchProposer.TryProposeBatch)
 
 	go utils.Loop(subCtx, 2*time.Second, l2relayer.ProcessPendingBatches)
 
-	go utils.Loop(subCtx, 15*time.Second, l2relayer.ProcessCommittedBatches)
+	// The committed batches are finalized periodically, and as soon as a proof is generated by the proof queue.
+	var proofReady <-chan uint64
+	if proofQueueCfg := cfg.L2Config.RelayerConfig.ProofQueueConfig; proofQueueCfg != nil {
+		proofQueue := relayer.NewProofQueue(subCtx, proofQueueCfg, db, registry)
+		if err = proofQueue.Start(); err != nil {
+			log.Crit("failed to start proof queue", "config file", cfgFile, "error", err)
+		}
+		l2relayer.SetProofQueue(proofQueue)
+		proofReady = proofQueue.ProofReady()
+	}
+	if proofPollingCfg := cfg.L2Config.RelayerConfig.ProofPollingConfig; proofPollingCfg != nil {
+		proofPollingRelayer := relayer.NewProofPollingRelayer(proofPollingCfg, db, registry)
+		go utils.LoopWithContext(subCtx, proofPollingRelayer.PollInterval(), func(ctx context.Context) {
+			if loopErr := proofPollingRelayer.PollBatchProofStatus(ctx); loopErr != nil {
+				log.Error("failed to poll batch proof status", "err", loopErr)
+			}
+		})
+	}
+	if proofAggregationCfg := cfg.L2Config.RelayerConfig.ProofAggregationConfig; proofAggregationCfg != nil {
+		proofAggregator := relayer.NewProofAggregator(proofAggregationCfg, db, registry)
+		go utils.LoopWithContext(subCtx, relayer.ProofAggregationInterval, func(ctx context.Context) {
+			if loopErr := proofAggregator.AggregateReadyBatches(ctx); loopErr != nil {
+				log.Error("failed to aggregate chunk proofs", "err", loopErr)
+			}
+		})
+	}
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case <-ticker.C:
+			case <-proofReady:
+			}
+			l2relayer.ProcessCommittedBatches()
+		}
+	}()
+
+	if relayerCfg := cfg.L2Config.RelayerConfig; relayerCfg.StateRootSenderPrivateKey != nil {
+		stateRootRelayer, stateRootErr := relayer.NewStateRootRelayer(subCtx, db, relayerCfg, registry)
+		if stateRootErr != nil {
+			log.Crit("failed to create state root relayer", "config file", cfgFile, "error", stateRootErr)
+		}
+		interval := defaultStateRootRelayInterval
+		if relayerCfg.StateRootRelayIntervalSec > 0 {
+			interval = time.Duration(relayerCfg.StateRootRelayIntervalSec) * time.Second
+		}
+		go utils.Loop(subCtx, interval, func() {
+			if loopErr := stateRootRelayer.ProcessStateRoot(); loopErr != nil {
+				relayer.LogError("Failed to relay l2 state root", loopErr)
+			}
+		})
+	}
 
 	// Finish start all rollup relayer functions.
 	log.Info("Start rollup-relayer successfully")
@@ -127,6 +283,94 @@ func action(ctx *cli.Context) error {
 	return nil
 }
 
+func printConfig(ctx *cli.Context) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", cfgFile, err)
+	}
+	if cfg.L2Config.RelayerConfig == nil {
+		return errors.New("relayer_config of l2_config is required")
+	}
+	if err = cfg.L2Config.RelayerConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid l2 relayer config: %w", err)
+	}
+	return config.WriteRedactedJSON(ctx.App.Writer, cfg)
+}
+
+func exportBlockTraces(ctx *cli.Context) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", cfgFile, err)
+	}
+
+	db, err := database.InitDB(cfg.DBConfig)
+	if err != nil {
+		return fmt.Errorf("failed to init db connection: %w", err)
+	}
+	defer func() {
+		if closeErr := database.CloseDB(db); closeErr != nil {
+			log.Error("failed to close db connection", "error", closeErr)
+		}
+	}()
+
+	writer := ctx.App.Writer
+	if output := ctx.String("output"); output != "" {
+		file, openErr := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if openErr != nil {
+			return fmt.Errorf("failed to open output file %s: %w", output, openErr)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	var afterBlockNumber *uint64
+	if ctx.IsSet("after") {
+		after := ctx.Uint64("after")
+		afterBlockNumber = &after
+	}
+
+	// the block traces are read from the db only.
+	l2watcher := watcher.NewL2WatcherClient(ctx.Context, nil, cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, nil)
+	l2watcher.SetExportRateLimit(cfg.L2Config.ExportRateLimitBlocksPerSec)
+	return l2watcher.ExportBlockTraces(ctx.Context, ctx.Uint64("from"), ctx.Uint64("to"), afterBlockNumber, writer)
+}
+
+func unquarantineBlock(ctx *cli.Context) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", cfgFile, err)
+	}
+
+	db, err := database.InitDB(cfg.DBConfig)
+	if err != nil {
+		return fmt.Errorf("failed to init db connection: %w", err)
+	}
+	defer func() {
+		if closeErr := database.CloseDB(db); closeErr != nil {
+			log.Error("failed to close db connection", "error", closeErr)
+		}
+	}()
+
+	number := ctx.Uint64("number")
+	if ctx.Bool("discard") {
+		// the block is not fetched again.
+		l2watcher := watcher.NewL2WatcherClient(ctx.Context, nil, cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, nil)
+		return l2watcher.DiscardQuarantinedBlock(ctx.Context, number)
+	}
+
+	l2rpc, err := rpc.Dial(cfg.L2Config.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect l2 geth: %w", err)
+	}
+	defer l2rpc.Close()
+	l2watcher := watcher.NewL2WatcherClient(ctx.Context, ethclient.NewClient(l2rpc), cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, nil)
+	l2watcher.SetRPCClient(l2rpc)
+	return l2watcher.ReprocessQuarantinedBlock(ctx.Context, number)
+}
+
 // Run rollup relayer cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,253 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/utils"
+	"scroll-tech/common/version"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// stuckTransactionsLimit is the maximum number of stuck transactions listed.
+const stuckTransactionsLimit = 20
+
+var app *cli.App
+
+var (
+	// stuckAfterFlag is the age of a pending transaction from which it is reported as stuck.
+	stuckAfterFlag = cli.DurationFlag{
+		Name:  "stuck-after",
+		Usage: "The age from which a pending transaction is reported as stuck",
+		Value: 10 * time.Minute,
+	}
+	// gasOracleStaleAfterFlag is the age of the last l1 gas oracle update from which it is reported as stale.
+	gasOracleStaleAfterFlag = cli.DurationFlag{
+		Name:  "gas-oracle-stale-after",
+		Usage: "The age from which the last l1 gas oracle update is reported as stale",
+		Value: 30 * time.Minute,
+	}
+	// noColorFlag disables the colors of the summary.
+	noColorFlag = cli.BoolFlag{
+		Name:  "no-color",
+		Usage: "Print the summary without colors",
+	}
+)
+
+func init() {
+	// Set up status app info.
+	app = cli.NewApp()
+	app.Action = action
+	app.Name = "rollup-status"
+	app.Usage = "Print a summary of the rollup status stored in the database"
+	app.Version = version.Version
+	app.Flags = []cli.Flag{&utils.ConfigFileFlag, &stuckAfterFlag, &gasOracleStaleAfterFlag, &noColorFlag}
+}
+
+// level is the health of a status row.
+type level int
+
+const (
+	levelOK level = iota
+	levelWarning
+	levelCritical
+)
+
+func (l level) String() string {
+	switch l {
+	case levelOK:
+		return "OK"
+	case levelWarning:
+		return "WARN"
+	default:
+		return "CRIT"
+	}
+}
+
+// color returns the ANSI color code of the level.
+func (l level) color() string {
+	switch l {
+	case levelOK:
+		return "\033[32m"
+	case levelWarning:
+		return "\033[33m"
+	default:
+		return "\033[31m"
+	}
+}
+
+// row is a line of the status summary.
+type row struct {
+	name  string
+	value string
+	level level
+}
+
+func action(ctx *cli.Context) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", cfgFile, err)
+	}
+
+	db, err := database.InitDBWithReadReplicas(cfg.DBConfig)
+	if err != nil {
+		return fmt.Errorf("failed to init db connection: %w", err)
+	}
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Error("failed to close db connection", "error", closeErr)
+		}
+	}()
+
+	rows, stuckTxs, err := collectStatus(ctx.Context, db.Primary(), time.Now(), ctx.Duration(stuckAfterFlag.Name), ctx.Duration(gasOracleStaleAfterFlag.Name), orm.WithReadReplica(db))
+	if err != nil {
+		return err
+	}
+	return printStatus(ctx.App.Writer, rows, stuckTxs, !ctx.Bool(noColorFlag.Name))
+}
+
+// collectStatus queries the status rows and the pending transactions older than stuckAfter.
+// The opts route the counts and the listings to the read replicas, the latest heights are read from the primary.
+func collectStatus(ctx context.Context, db *gorm.DB, now time.Time, stuckAfter, gasOracleStaleAfter time.Duration, opts ...orm.Option) ([]row, []orm.PendingTransaction, error) {
+	var rows []row
+
+	l1Height, err := orm.NewL1Block(db).GetLatestL1BlockHeight(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the latest l1 block: %w", err)
+	}
+	rows = append(rows, row{name: "latest l1 block", value: fmt.Sprint(l1Height), level: levelIfZero(l1Height, levelWarning)})
+
+	l2Height, err := orm.NewL2Block(db).GetL2BlocksLatestHeight(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the latest l2 block: %w", err)
+	}
+	rows = append(rows, row{name: "latest l2 block", value: fmt.Sprint(l2Height), level: levelIfZero(l2Height, levelWarning)})
+
+	batchOrm := orm.NewBatch(db, opts...)
+	for _, status := range []struct {
+		name   string
+		status types.RollupStatus
+		// the level of a non zero count
+		level level
+	}{
+		{"pending batches", types.RollupPending, levelOK},
+		{"committed batches", types.RollupCommitted, levelOK},
+		{"finalized batches", types.RollupFinalized, levelOK},
+		{"commit failed batches", types.RollupCommitFailed, levelCritical},
+		{"finalize failed batches", types.RollupFinalizeFailed, levelCritical},
+	} {
+		count, countErr := batchOrm.GetBatchCountByRollupStatus(ctx, status.status)
+		if countErr != nil {
+			return nil, nil, fmt.Errorf("failed to count the %s: %w", status.name, countErr)
+		}
+		rowLevel := levelOK
+		if count > 0 {
+			rowLevel = status.level
+		}
+		rows = append(rows, row{name: status.name, value: fmt.Sprint(count), level: rowLevel})
+	}
+
+	// the latest imported l1 block was updated by the last gas oracle transaction.
+	imported, err := orm.NewL1Block(db).GetL1BlocksByGasOracleStatus(ctx, types.GasOracleImported, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the last gas oracle update: %w", err)
+	}
+	if len(imported) == 0 {
+		rows = append(rows, row{name: "last gas oracle update", value: "never", level: levelWarning})
+	} else {
+		age := now.Sub(imported[0].UpdatedAt)
+		rowLevel := levelOK
+		if age > gasOracleStaleAfter {
+			rowLevel = levelWarning
+		}
+		value := fmt.Sprintf("%s (%s ago, l1 block %d)", imported[0].UpdatedAt.UTC().Format(time.RFC3339), age.Truncate(time.Second), imported[0].Number)
+		rows = append(rows, row{name: "last gas oracle update", value: value, level: rowLevel})
+	}
+
+	// a manual override more recent than the last gas oracle update is still in effect.
+	overrides, err := orm.NewGasOracleManualOverride(db, opts...).GetLatestManualOverrides(ctx, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the last manual gas price override: %w", err)
+	}
+	if len(overrides) == 0 {
+		rows = append(rows, row{name: "last manual gas price override", value: "never", level: levelOK})
+	} else {
+		override := overrides[0]
+		rowLevel := levelOK
+		if len(imported) == 0 || override.CreatedAt.After(imported[0].UpdatedAt) {
+			rowLevel = levelWarning
+		}
+		value := fmt.Sprintf("%d by %s at %s (%s ago, tx %s)", override.GasPrice, override.OperatorID, override.CreatedAt.UTC().Format(time.RFC3339), now.Sub(override.CreatedAt).Truncate(time.Second), override.TxHash)
+		rows = append(rows, row{name: "last manual gas price override", value: value, level: rowLevel})
+	}
+
+	stuckTxs, err := orm.NewPendingTransaction(db, opts...).GetPendingTransactionsCreatedBefore(ctx, now.Add(-stuckAfter), stuckTransactionsLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the stuck transactions: %w", err)
+	}
+	rowLevel := levelOK
+	value := fmt.Sprint(len(stuckTxs))
+	if len(stuckTxs) > 0 {
+		rowLevel = levelCritical
+		if len(stuckTxs) == stuckTransactionsLimit {
+			value += "+"
+		}
+	}
+	rows = append(rows, row{name: fmt.Sprintf("transactions pending for more than %s", stuckAfter), value: value, level: rowLevel})
+	return rows, stuckTxs, nil
+}
+
+func levelIfZero(value uint64, l level) level {
+	if value == 0 {
+		return l
+	}
+	return levelOK
+}
+
+// printStatus writes the status rows as a table, followed by the stuck transactions if any.
+func printStatus(w io.Writer, rows []row, stuckTxs []orm.PendingTransaction, color bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CHECK\tVALUE\tSTATUS")
+	for _, r := range rows {
+		status := r.level.String()
+		if color {
+			status = r.level.color() + status + "\033[0m"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", r.name, r.value, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(stuckTxs) == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SENDER\tSERVICE\tCONTEXT ID\tNONCE\tHASH\tCREATED AT")
+	for _, tx := range stuckTxs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", tx.SenderName, tx.SenderService, tx.ContextID, tx.Nonce, tx.Hash, tx.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+// Run rollup status cmd instance.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
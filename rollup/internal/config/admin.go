@@ -0,0 +1,11 @@
+package config
+
+// AdminTLSConfig loads the TLS configuration items of the admin http server.
+type AdminTLSConfig struct {
+	// The PEM encoded certificate file of the server.
+	CertFile string `json:"cert_file"`
+	// The PEM encoded private key file of the server.
+	KeyFile string `json:"key_file"`
+	// The PEM encoded CA certificates verifying the client certificates, mutual TLS is disabled if empty.
+	ClientCAFile string `json:"client_ca_file,omitempty"`
+}
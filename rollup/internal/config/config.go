@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"scroll-tech/common/database"
 
@@ -17,12 +18,41 @@ type Config struct {
 	L1Config *L1Config        `json:"l1_config"`
 	L2Config *L2Config        `json:"l2_config"`
 	DBConfig *database.Config `json:"db_config"`
+
+	// The listen address of the admin http server, disabled if empty.
+	AdminAddr string `json:"admin_addr,omitempty"`
+	// The TLS config of the admin http server, served in plain http if empty.
+	AdminTLSConfig *AdminTLSConfig `json:"admin_tls_config,omitempty"`
 }
 
 func (c *Config) validate() error {
+	if c.L1Config == nil || c.L2Config == nil || c.DBConfig == nil {
+		return errors.New("Invalid configuration: l1_config, l2_config and db_config are required")
+	}
+	if c.L1Config.UseWebSocketSubscription && !strings.HasPrefix(c.L1Config.Endpoint, "ws://") && !strings.HasPrefix(c.L1Config.Endpoint, "wss://") {
+		return fmt.Errorf("Invalid endpoint configuration of l1_config: %v is not a websocket url, required by use_websocket_subscription", c.L1Config.Endpoint)
+	}
+	if c.L2Config.BatchProposerConfig == nil {
+		return errors.New("Invalid configuration: batch_proposer_config of l2_config is required")
+	}
 	if maxChunkPerBatch := c.L2Config.BatchProposerConfig.MaxChunkNumPerBatch; maxChunkPerBatch <= 0 {
 		return fmt.Errorf("Invalid max_chunk_num_per_batch configuration: %v", maxChunkPerBatch)
 	}
+	switch batchProposerCfg := c.L2Config.BatchProposerConfig; batchProposerCfg.BatchStrategy {
+	case "", BatchStrategyCountSize:
+	case BatchStrategyTimeWindow:
+		if batchProposerCfg.BatchTimeWindowSec == 0 {
+			return errors.New("Invalid batch_time_window_sec configuration: must be positive for the time_window batch strategy")
+		}
+	default:
+		return fmt.Errorf("Invalid batch_strategy configuration: %v", batchProposerCfg.BatchStrategy)
+	}
+	if maxBlobsPerBatch := c.L2Config.BatchProposerConfig.MaxBlobsPerBatch; maxBlobsPerBatch < 0 {
+		return fmt.Errorf("Invalid max_blobs_per_batch configuration: %v", maxBlobsPerBatch)
+	}
+	if tlsCfg := c.AdminTLSConfig; tlsCfg != nil && (tlsCfg.CertFile == "" || tlsCfg.KeyFile == "") {
+		return errors.New("Invalid admin_tls_config configuration: cert_file and key_file are required")
+	}
 	return nil
 }
 
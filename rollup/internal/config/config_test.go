@@ -3,10 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,4 +58,236 @@ func TestConfig(t *testing.T) {
 		_, err = NewConfig(tmpFile.Name())
 		assert.Error(t, err)
 	})
+
+	t.Run("Missing Sections", func(t *testing.T) {
+		tmpJSON := filepath.Join(t.TempDir(), "empty_config.json")
+		assert.NoError(t, os.WriteFile(tmpJSON, []byte("{}"), 0644))
+
+		_, err := NewConfig(tmpJSON)
+		assert.ErrorContains(t, err, "l1_config, l2_config and db_config are required")
+	})
+}
+
+func TestRelayerConfigGasOracleSenderPrivateKeys(t *testing.T) {
+	t.Run("Multiple Keys", func(t *testing.T) {
+		input := `{
+			"gas_oracle_sender_private_key": "1313131313131313131313131313131313131313131313131313131313131313",
+			"gas_oracle_sender_private_keys": [
+				"1616161616161616161616161616161616161616161616161616161616161616",
+				"1717171717171717171717171717171717171717171717171717171717171717"
+			]
+		}`
+		cfg := &RelayerConfig{}
+		assert.NoError(t, json.Unmarshal([]byte(input), cfg))
+		assert.NotNil(t, cfg.GasOracleSenderPrivateKey)
+		assert.Len(t, cfg.GasOracleSenderPrivateKeys, 2)
+
+		data, err := json.Marshal(cfg)
+		assert.NoError(t, err)
+		cfg2 := &RelayerConfig{}
+		assert.NoError(t, json.Unmarshal(data, cfg2))
+		assert.Equal(t, cfg, cfg2)
+	})
+
+	t.Run("Duplicated Keys", func(t *testing.T) {
+		input := `{
+			"gas_oracle_sender_private_key": "1313131313131313131313131313131313131313131313131313131313131313",
+			"gas_oracle_sender_private_keys": ["1313131313131313131313131313131313131313131313131313131313131313"]
+		}`
+		cfg := &RelayerConfig{}
+		assert.Error(t, json.Unmarshal([]byte(input), cfg))
+	})
+}
+
+func TestRelayerConfigValidate(t *testing.T) {
+	cfg, err := NewConfig("../../conf/config.json")
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.L1Config.RelayerConfig.Validate())
+	assert.NoError(t, cfg.L2Config.RelayerConfig.Validate())
+
+	relayerCfg := *cfg.L2Config.RelayerConfig
+	relayerCfg.RollupContractAddress = common.Address{}
+	assert.ErrorContains(t, relayerCfg.Validate(), "rollup_contract_address")
+
+	relayerCfg = *cfg.L2Config.RelayerConfig
+	relayerCfg.GasPriceOracleContractAddress = common.Address{}
+	assert.ErrorContains(t, relayerCfg.Validate(), "gas_price_oracle_contract_address")
+
+	relayerCfg = *cfg.L2Config.RelayerConfig
+	relayerCfg.FinalizeSenderPrivateKey = nil
+	assert.Error(t, relayerCfg.Validate())
+
+	relayerCfg = *cfg.L1Config.RelayerConfig
+	relayerCfg.GasOracleSenderPrivateKey = nil
+	assert.ErrorContains(t, relayerCfg.Validate(), "no sender private key")
+
+	relayerCfg.GasOracleSenderKeySecret = "rollup/gas-oracle-sender-key"
+	assert.NoError(t, relayerCfg.Validate())
+	relayerCfg.GasOracleSenderPrivateKey = cfg.L1Config.RelayerConfig.GasOracleSenderPrivateKey
+	assert.ErrorContains(t, relayerCfg.Validate(), "gas_oracle_sender_key_secret")
+
+	relayerCfg = *cfg.L1Config.RelayerConfig
+	relayerCfg.SenderConfig = nil
+	assert.Error(t, relayerCfg.Validate())
+
+	relayerCfg = *cfg.L1Config.RelayerConfig
+	relayerCfg.GasOracleConfig = &GasOracleConfig{GasPriceDiff: gasPriceDiffPrecision}
+	assert.ErrorContains(t, relayerCfg.Validate(), "gas_price_diff")
+
+	relayerCfg.GasOracleConfig = &GasOracleConfig{GasPriceDiff: 50000, MinGasPrice: math.MaxUint64 / 2}
+	assert.ErrorContains(t, relayerCfg.Validate(), "min_gas_price")
+
+	relayerCfg.GasOracleConfig = &GasOracleConfig{GasPriceDiff: 50000, UsePercentileGasPrice: true, PercentileValue: 75}
+	assert.ErrorContains(t, relayerCfg.Validate(), "percentile_window")
+	relayerCfg.GasOracleConfig = &GasOracleConfig{GasPriceDiff: 50000, UsePercentileGasPrice: true, PercentileWindow: 20, PercentileValue: 101}
+	assert.ErrorContains(t, relayerCfg.Validate(), "percentile_value")
+	relayerCfg.GasOracleConfig = &GasOracleConfig{GasPriceDiff: 50000, UsePercentileGasPrice: true, PercentileWindow: 20, PercentileValue: 75}
+	assert.NoError(t, relayerCfg.Validate())
+
+	relayerCfg = *cfg.L1Config.RelayerConfig
+	relayerCfg.AlertWebhookSecret = "secret"
+	assert.ErrorContains(t, relayerCfg.Validate(), "alert_webhook_url")
+	relayerCfg.AlertWebhookURL = "alertmanager:9093"
+	assert.ErrorContains(t, relayerCfg.Validate(), "alert_webhook_url")
+	relayerCfg.AlertWebhookURL = "http://alertmanager:9093/api/v1/alerts"
+	assert.NoError(t, relayerCfg.Validate())
+
+	relayerCfg = *cfg.L2Config.RelayerConfig
+	relayerCfg.ProofQueueConfig = &ProofQueueConfig{ProofServiceURL: "prover:8080", ProofWorkers: 2}
+	assert.ErrorContains(t, relayerCfg.Validate(), "proof_service_url")
+	relayerCfg.ProofQueueConfig = &ProofQueueConfig{ProofServiceURL: "http://prover:8080/prove"}
+	assert.ErrorContains(t, relayerCfg.Validate(), "proof_workers")
+	relayerCfg.ProofQueueConfig = &ProofQueueConfig{ProofServiceURL: "http://prover:8080/prove", ProofWorkers: 2, TimeoutSec: 600}
+	assert.NoError(t, relayerCfg.Validate())
+	relayerCfg.ProofPollingConfig = &ProofPollingConfig{ProvingServiceURL: "http://prover:8080"}
+	assert.ErrorContains(t, relayerCfg.Validate(), "must not be set together")
+	relayerCfg.ProofQueueConfig = nil
+	assert.NoError(t, relayerCfg.Validate())
+	relayerCfg.ProofPollingConfig = &ProofPollingConfig{ProvingServiceURL: "prover:8080"}
+	assert.ErrorContains(t, relayerCfg.Validate(), "proving_service_url")
+	relayerCfg.ProofPollingConfig = nil
+	relayerCfg.ProofAggregationConfig = &ProofAggregationConfig{AggregationServiceURL: "http://aggregator:8080/aggregate"}
+	assert.NoError(t, relayerCfg.Validate())
+	relayerCfg.ProofAggregationConfig.AggregationServiceURL = "aggregator:8080"
+	assert.ErrorContains(t, relayerCfg.Validate(), "aggregation_service_url")
+	relayerCfg.ProofAggregationConfig = &ProofAggregationConfig{AggregationServiceURL: "http://aggregator:8080/aggregate", TimeoutSec: -1}
+	assert.ErrorContains(t, relayerCfg.Validate(), "timeout_sec of proof_aggregation_config")
+	relayerCfg.ProofAggregationConfig = nil
+
+	relayerCfg.LocalProofVerifierConfig = &LocalProofVerifierConfig{}
+	assert.ErrorContains(t, relayerCfg.Validate(), "exactly one of binary_path and plugin_path")
+	relayerCfg.LocalProofVerifierConfig = &LocalProofVerifierConfig{BinaryPath: "/usr/local/bin/verifier", PluginPath: "verifier.so"}
+	assert.ErrorContains(t, relayerCfg.Validate(), "exactly one of binary_path and plugin_path")
+	relayerCfg.LocalProofVerifierConfig = &LocalProofVerifierConfig{BinaryPath: "/usr/local/bin/verifier", TimeoutSec: -1}
+	assert.ErrorContains(t, relayerCfg.Validate(), "timeout_sec of local_proof_verifier_config")
+	relayerCfg.LocalProofVerifierConfig = &LocalProofVerifierConfig{PluginPath: "verifier.so"}
+	assert.NoError(t, relayerCfg.Validate())
+
+	relayerCfg.ShadowEnabled = true
+	assert.ErrorContains(t, relayerCfg.Validate(), "shadow_l2_endpoint")
+	relayerCfg.ShadowL2Endpoint = "http://localhost:8545"
+	assert.NoError(t, relayerCfg.Validate())
+
+	relayerCfg = *cfg.L2Config.RelayerConfig
+	senderCfg := *relayerCfg.SenderConfig
+	relayerCfg.SenderConfig = &senderCfg
+	senderCfg.FlashbotsRelayURL = "relay.flashbots.net"
+	assert.ErrorContains(t, relayerCfg.Validate(), "flashbots_relay_url")
+	senderCfg.FlashbotsRelayURL = "https://relay.flashbots.net"
+	assert.ErrorContains(t, relayerCfg.Validate(), "flashbots_signing_key")
+	senderCfg.FlashbotsSigningKey = relayerCfg.CommitSenderPrivateKey
+	assert.NoError(t, relayerCfg.Validate())
+	senderCfg.EnableBatchingOptimizer = true
+	assert.ErrorContains(t, relayerCfg.Validate(), "batching_multicall_address")
+	senderCfg.BatchingMulticallAddress = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	assert.NoError(t, relayerCfg.Validate())
+
+	senderCfg.FeeBumpPolicy = &FeeBumpPolicyConfig{MinBumpPercent: -1}
+	assert.ErrorContains(t, relayerCfg.Validate(), "min_bump_percent")
+	senderCfg.FeeBumpPolicy = &FeeBumpPolicyConfig{MinBumpPercent: 20, MaxBumpPercent: 10}
+	assert.ErrorContains(t, relayerCfg.Validate(), "max_bump_percent")
+	senderCfg.FeeBumpPolicy = &FeeBumpPolicyConfig{MaxBumps: -1}
+	assert.ErrorContains(t, relayerCfg.Validate(), "max_bumps")
+	senderCfg.FeeBumpPolicy = &FeeBumpPolicyConfig{MinBumpPercent: 10, MaxBumpPercent: 50, MaxBumps: 5, BumpIntervalSec: 60}
+	assert.NoError(t, relayerCfg.Validate())
+}
+
+func TestSenderConfigFlashbotsSigningKey(t *testing.T) {
+	input := `{
+		"endpoint": "http://localhost:8545",
+		"flashbots_relay_url": "https://relay.flashbots.net",
+		"flashbots_signing_key": "1414141414141414141414141414141414141414141414141414141414141414"
+	}`
+	cfg := &SenderConfig{}
+	assert.NoError(t, json.Unmarshal([]byte(input), cfg))
+	assert.Equal(t, "https://relay.flashbots.net", cfg.FlashbotsRelayURL)
+	assert.NotNil(t, cfg.FlashbotsSigningKey)
+
+	data, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	cfg2 := &SenderConfig{}
+	assert.NoError(t, json.Unmarshal(data, cfg2))
+	assert.Equal(t, cfg, cfg2)
+
+	// the signing key is omitted if flashbots is disabled.
+	data, err = json.Marshal(&SenderConfig{Endpoint: "http://localhost:8545"})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "flashbots_signing_key")
+
+	assert.Error(t, json.Unmarshal([]byte(`{"flashbots_signing_key": "0x01"}`), &SenderConfig{}))
+}
+
+func TestConfigBatchStrategy(t *testing.T) {
+	cfg, err := NewConfig("../../conf/config.json")
+	assert.NoError(t, err)
+
+	batchProposerCfg := cfg.L2Config.BatchProposerConfig
+	batchProposerCfg.BatchStrategy = BatchStrategyCountSize
+	assert.NoError(t, cfg.validate())
+
+	batchProposerCfg.BatchStrategy = BatchStrategyTimeWindow
+	assert.Error(t, cfg.validate())
+
+	batchProposerCfg.BatchTimeWindowSec = 12
+	assert.NoError(t, cfg.validate())
+
+	batchProposerCfg.BatchStrategy = "unknown"
+	assert.Error(t, cfg.validate())
+}
+
+func TestConfigMaxBlobsPerBatch(t *testing.T) {
+	cfg, err := NewConfig("../../conf/config.json")
+	assert.NoError(t, err)
+
+	cfg.L2Config.BatchProposerConfig.MaxBlobsPerBatch = -1
+	assert.Error(t, cfg.validate())
+
+	cfg.L2Config.BatchProposerConfig.MaxBlobsPerBatch = 6
+	assert.NoError(t, cfg.validate())
+}
+
+func TestConfigL1WebSocketSubscription(t *testing.T) {
+	cfg, err := NewConfig("../../conf/config.json")
+	assert.NoError(t, err)
+
+	cfg.L1Config.UseWebSocketSubscription = true
+	cfg.L1Config.Endpoint = "http://localhost:8545"
+	assert.Error(t, cfg.validate())
+
+	cfg.L1Config.Endpoint = "ws://localhost:8546"
+	assert.NoError(t, cfg.validate())
+
+	cfg.L1Config.Endpoint = "wss://l1.example.com"
+	assert.NoError(t, cfg.validate())
+}
+
+func TestConfigAdminTLS(t *testing.T) {
+	cfg, err := NewConfig("../../conf/config.json")
+	assert.NoError(t, err)
+
+	cfg.AdminTLSConfig = &AdminTLSConfig{CertFile: "server.crt"}
+	assert.Error(t, cfg.validate())
+
+	cfg.AdminTLSConfig.KeyFile = "server.key"
+	assert.NoError(t, cfg.validate())
 }
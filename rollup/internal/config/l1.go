@@ -9,6 +9,8 @@ import (
 type L1Config struct {
 	// Confirmations block height confirmations number.
 	Confirmations rpc.BlockNumber `json:"confirmations"`
+	// The number of blocks on top of an event log block before the event is saved, on top of confirmations.
+	ConfirmationDepth uint64 `json:"confirmation_depth,omitempty"`
 	// l1 eth node url.
 	Endpoint string `json:"endpoint"`
 	// The start height to sync event from layer 1
@@ -17,6 +19,27 @@ type L1Config struct {
 	L1MessageQueueAddress common.Address `json:"l1_message_queue_address"`
 	// The ScrollChain contract address deployed on layer 1 chain.
 	ScrollChainContractAddress common.Address `json:"scroll_chain_address"`
+	// The senders allowed to queue L1 messages, the QueueTransaction events of other senders are dropped.
+	// All senders are allowed if empty. It is reloaded on SIGHUP.
+	AllowedL1SenderAddresses []common.Address `json:"allowed_l1_sender_addresses,omitempty"`
+	// The max number of times the block range of an event logs query is bisected when the node rejects it for returning
+	// too many results, the query is not split if 0.
+	MaxFilterLogSplitDepth int `json:"max_filter_log_split_depth,omitempty"`
+	// The number of confirmed block headers fetched in parallel ahead of the watcher, prefetching is disabled if 0.
+	PrefetchDepth uint64 `json:"prefetch_depth,omitempty"`
+	// The JSON array file of event logs, in the format of the eth_getLogs responses, the watcher reads the events from
+	// instead of the l1 node. Used to replay an incident offline, disabled if empty.
+	EventReplayFile string `json:"event_replay_file,omitempty"`
+	// Whether the watcher fetches the events as soon as the l1 node announces a new head on a websocket subscription,
+	// on top of polling. The endpoint must be a ws or wss url.
+	UseWebSocketSubscription bool `json:"use_websocket_subscription,omitempty"`
+	// The max number of stored blocks replaced by a reorg, a deeper reorg is alerted. Disabled if 0.
+	MaxExpectedReorgDepth uint64 `json:"max_expected_reorg_depth,omitempty"`
+	// Whether the watcher halts on a reorg deeper than MaxExpectedReorgDepth until it is acknowledged on the admin server.
+	HaltOnDeepReorg bool `json:"halt_on_deep_reorg,omitempty"`
+	// The time in seconds after which the l1 messages still pending since they were saved are marked as expired,
+	// they do not expire if 0.
+	MessageExpirySec uint64 `json:"message_expiry_sec,omitempty"`
 	// The relayer config
 	RelayerConfig *RelayerConfig `json:"relayer_config"`
 }
@@ -16,6 +16,14 @@ type L2Config struct {
 	L2MessageQueueAddress common.Address `json:"l2_message_queue_address"`
 	// The WithdrawTrieRootSlot in L2MessageQueue contract.
 	WithdrawTrieRootSlot common.Hash `json:"withdraw_trie_root_slot,omitempty"`
+	// The number of latest stored blocks checked against the canonical chain for reorgs, 0 disables reorg detection.
+	ReorgCheckDepth uint64 `json:"reorg_check_depth,omitempty"`
+	// The maximum number of parallel block requests when filling the gaps of the stored blocks, defaults to 1.
+	FetchConcurrency int `json:"fetch_concurrency,omitempty"`
+	// The maximum number of blocks read per second by the block trace exports, unlimited if 0.
+	ExportRateLimitBlocksPerSec int `json:"export_rate_limit_blocks_per_sec,omitempty"`
+	// The maximum number of rpc requests per second of the l2 watcher fetching the blocks, unlimited if 0.
+	MaxRPCRequestsPerSecond float64 `json:"max_rpc_requests_per_second,omitempty"`
 	// The relayer config
 	RelayerConfig *RelayerConfig `json:"relayer_config"`
 	// The chunk_proposer config
@@ -33,6 +41,10 @@ type ChunkProposerConfig struct {
 	ChunkTimeoutSec                 uint64  `json:"chunk_timeout_sec"`
 	MaxRowConsumptionPerChunk       uint64  `json:"max_row_consumption_per_chunk"`
 	GasCostIncreaseMultiplier       float64 `json:"gas_cost_increase_multiplier"`
+	// MaxChunkGasLimit is the limit of the commit gas of a chunk estimated against the L1 rollup contract, zero disables the estimation.
+	MaxChunkGasLimit uint64 `json:"max_chunk_gas_limit,omitempty"`
+	// LagAlertThresholdBlocks is the number of unchunked L2 blocks above which a warning is logged, zero disables it.
+	LagAlertThresholdBlocks uint64 `json:"lag_alert_threshold_blocks,omitempty"`
 }
 
 // BatchProposerConfig loads batch_proposer configuration items.
@@ -42,4 +54,24 @@ type BatchProposerConfig struct {
 	MaxL1CommitCalldataSizePerBatch uint64  `json:"max_l1_commit_calldata_size_per_batch"`
 	BatchTimeoutSec                 uint64  `json:"batch_timeout_sec"`
 	GasCostIncreaseMultiplier       float64 `json:"gas_cost_increase_multiplier"`
+	// BatchStrategy selects how chunks are grouped into batches, one of "count_size" (default) and "time_window".
+	// The commit gas, calldata size and chunk number limits apply to both strategies.
+	BatchStrategy string `json:"batch_strategy,omitempty"`
+	// BatchTimeWindowSec is the time window of the "time_window" strategy, chunks whose last block
+	// timestamps fall within the window of the first chunk of a batch are put into that batch.
+	BatchTimeWindowSec uint64 `json:"batch_time_window_sec,omitempty"`
+	// LagAlertThresholdChunks is the number of unbatched chunks above which a warning is logged, zero disables it.
+	LagAlertThresholdChunks uint64 `json:"lag_alert_threshold_chunks,omitempty"`
+	// MaxBlobsPerBatch is the limit of the EIP-4844 blobs estimated for the chunks of a batch, zero disables it.
+	MaxBlobsPerBatch int `json:"max_blobs_per_batch,omitempty"`
+	// MaxBatchAgeSeconds is the time since the first pending chunk was proposed after which the pending chunks
+	// are sealed in a batch even if no limit is reached, zero disables it.
+	MaxBatchAgeSeconds uint64 `json:"max_batch_age_seconds,omitempty"`
 }
+
+const (
+	// BatchStrategyCountSize groups chunks by their number and size.
+	BatchStrategyCountSize = "count_size"
+	// BatchStrategyTimeWindow groups chunks by the timestamp of their last block.
+	BatchStrategyTimeWindow = "time_window"
+)
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces the secrets in the redacted config.
+const redactedValue = "<redacted>"
+
+// redactedKeySuffixes are the suffixes of the json keys holding secrets.
+var redactedKeySuffixes = []string{"private_key", "private_keys", "signing_key", "secret", "dsn"}
+
+// WriteRedactedJSON writes cfg as indented JSON to w, the private keys, secrets and database dsns are redacted.
+func WriteRedactedJSON(w io.Writer, cfg *Config) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var fields interface{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(redact("", fields))
+}
+
+// redact returns value with the secrets replaced by redactedValue, key is the json key of value.
+func redact(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			v[k] = redact(k, field)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = redact(key, elem)
+		}
+		return v
+	case string:
+		if v == "" {
+			return v
+		}
+		for _, suffix := range redactedKeySuffixes {
+			if strings.HasSuffix(key, suffix) {
+				return redactedValue
+			}
+		}
+		// the endpoints may hold credentials, e.g. an amqp url.
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				return u.Redacted()
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// ConfigWatcher delivers gas oracle config updates without restarting the service.
+type ConfigWatcher interface {
+	// Watch returns a channel of new gas oracle configs, which is closed when ctx is done.
+	Watch(ctx context.Context) <-chan *GasOracleConfig
+}
+
+// FileConfigWatcher re-reads the config file on SIGHUP.
+type FileConfigWatcher struct {
+	file     string
+	selector func(*Config) *GasOracleConfig
+}
+
+// NewFileConfigWatcher returns a new instance of FileConfigWatcher, selector picks the gas oracle config to watch from the reloaded config.
+func NewFileConfigWatcher(file string, selector func(*Config) *GasOracleConfig) *FileConfigWatcher {
+	return &FileConfigWatcher{
+		file:     file,
+		selector: selector,
+	}
+}
+
+// Watch implements ConfigWatcher.
+func (w *FileConfigWatcher) Watch(ctx context.Context) <-chan *GasOracleConfig {
+	return w.watch(ctx, notifySIGHUP(ctx))
+}
+
+func (w *FileConfigWatcher) watch(ctx context.Context, trigger <-chan os.Signal) <-chan *GasOracleConfig {
+	return watchFile(ctx, w.file, trigger, func(cfg *Config) *GasOracleConfig {
+		gasOracleConfig := w.selector(cfg)
+		log.Info("reloaded gas oracle config", "config file", w.file, "gas oracle config", gasOracleConfig)
+		return gasOracleConfig
+	})
+}
+
+// AllowedL1SendersWatcher delivers updates of the allowed L1 sender addresses without restarting the service.
+type AllowedL1SendersWatcher interface {
+	// Watch returns a channel of new allowed L1 sender addresses, which is closed when ctx is done.
+	Watch(ctx context.Context) <-chan []common.Address
+}
+
+// FileAllowedL1SendersWatcher re-reads the allowed L1 sender addresses from the config file on SIGHUP.
+type FileAllowedL1SendersWatcher struct {
+	file string
+}
+
+// NewFileAllowedL1SendersWatcher returns a new instance of FileAllowedL1SendersWatcher.
+func NewFileAllowedL1SendersWatcher(file string) *FileAllowedL1SendersWatcher {
+	return &FileAllowedL1SendersWatcher{file: file}
+}
+
+// Watch implements AllowedL1SendersWatcher.
+func (w *FileAllowedL1SendersWatcher) Watch(ctx context.Context) <-chan []common.Address {
+	return w.watch(ctx, notifySIGHUP(ctx))
+}
+
+func (w *FileAllowedL1SendersWatcher) watch(ctx context.Context, trigger <-chan os.Signal) <-chan []common.Address {
+	return watchFile(ctx, w.file, trigger, func(cfg *Config) []common.Address {
+		log.Info("reloaded allowed l1 sender addresses", "config file", w.file, "addresses", cfg.L1Config.AllowedL1SenderAddresses)
+		return cfg.L1Config.AllowedL1SenderAddresses
+	})
+}
+
+// notifySIGHUP returns a channel receiving SIGHUP until ctx is done.
+func notifySIGHUP(ctx context.Context) <-chan os.Signal {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		<-ctx.Done()
+		signal.Stop(sigCh)
+	}()
+	return sigCh
+}
+
+// watchFile re-reads the config file on each trigger and delivers the part picked by selector.
+func watchFile[T any](ctx context.Context, file string, trigger <-chan os.Signal, selector func(*Config) T) <-chan T {
+	updateCh := make(chan T, 1)
+	go func() {
+		defer close(updateCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-trigger:
+				cfg, err := NewConfig(file)
+				if err != nil {
+					log.Error("failed to reload config file", "config file", file, "err", err)
+					continue
+				}
+				update := selector(cfg)
+
+				// Only keep the latest update if the previous one has not been consumed yet.
+				select {
+				case <-updateCh:
+				default:
+				}
+				updateCh <- update
+			}
+		}
+	}()
+	return updateCh
+}
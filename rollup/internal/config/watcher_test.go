@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileConfigWatcher(t *testing.T) {
+	buf, err := os.ReadFile("../../conf/config.json")
+	assert.NoError(t, err)
+
+	tmpJSON := fmt.Sprintf("/tmp/%d_rollup_config_watcher.json", time.Now().Nanosecond())
+	defer func() {
+		assert.NoError(t, os.Remove(tmpJSON))
+	}()
+	assert.NoError(t, os.WriteFile(tmpJSON, buf, 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	trigger := make(chan os.Signal, 1)
+	w := NewFileConfigWatcher(tmpJSON, func(c *Config) *GasOracleConfig {
+		return c.L1Config.RelayerConfig.GasOracleConfig
+	})
+	updateCh := w.watch(ctx, trigger)
+
+	updated := strings.Replace(string(buf), `"min_gas_price": 0`, `"min_gas_price": 7`, 1)
+	assert.NotEqual(t, string(buf), updated)
+	assert.NoError(t, os.WriteFile(tmpJSON, []byte(updated), 0644))
+
+	trigger <- syscall.SIGHUP
+	select {
+	case gasOracleConfig := <-updateCh:
+		assert.Equal(t, uint64(7), gasOracleConfig.MinGasPrice)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	cancel()
+	_, ok := <-updateCh
+	assert.False(t, ok)
+}
+
+func TestFileAllowedL1SendersWatcher(t *testing.T) {
+	buf, err := os.ReadFile("../../conf/config.json")
+	assert.NoError(t, err)
+
+	tmpJSON := fmt.Sprintf("/tmp/%d_rollup_config_allowed_senders.json", time.Now().Nanosecond())
+	defer func() {
+		assert.NoError(t, os.Remove(tmpJSON))
+	}()
+	assert.NoError(t, os.WriteFile(tmpJSON, buf, 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	trigger := make(chan os.Signal, 1)
+	w := NewFileAllowedL1SendersWatcher(tmpJSON)
+	updateCh := w.watch(ctx, trigger)
+
+	updated := strings.Replace(string(buf), `"l1_config": {`, `"l1_config": {
+    "allowed_l1_sender_addresses": ["0x0000000000000000000000000000000000000001"],`, 1)
+	assert.NotEqual(t, string(buf), updated)
+	assert.NoError(t, os.WriteFile(tmpJSON, []byte(updated), 0644))
+
+	trigger <- syscall.SIGHUP
+	select {
+	case addresses := <-updateCh:
+		assert.Equal(t, []common.Address{common.HexToAddress("0x1")}, addresses)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for allowed senders update")
+	}
+
+	cancel()
+	_, ok := <-updateCh
+	assert.False(t, ok)
+}
@@ -0,0 +1,343 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+const (
+	healthCheckTimeout = 10 * time.Second
+
+	// chunkStatsWindow is the time window of the chunks summarized by the chunk_stats endpoint.
+	chunkStatsWindow = 24 * time.Hour
+)
+
+// HealthChecker is implemented by the components whose health is reported by the admin server.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// GasPriceOracle is implemented by the relayers whose gas price oracle update can be triggered by the admin server.
+type GasPriceOracle interface {
+	ProcessGasPriceOracle() error
+}
+
+// GasOraclePauser is implemented by the relayers whose gas price oracle can be paused by the admin server.
+type GasOraclePauser interface {
+	PauseGasOracle()
+	ResumeGasOracle()
+}
+
+// GasPriceOverrider is implemented by the relayers whose l1 gas price can be set manually by the admin server.
+type GasPriceOverrider interface {
+	SetGasPriceManually(ctx context.Context, gasPrice uint64) error
+}
+
+// ChunkProposer is implemented by the chunk proposer whose proposal can be triggered by the admin server.
+type ChunkProposer interface {
+	TryProposeChunk()
+	ReproposeFailed(ctx context.Context, olderThan time.Duration) (int, error)
+	ChunkStats(ctx context.Context, since time.Time) (*watcher.ChunkStatsReport, error)
+}
+
+// BatchProposer is implemented by the batch proposer whose proposal can be triggered by the admin server.
+type BatchProposer interface {
+	TryProposeBatch()
+}
+
+// ReorgAcknowledger is implemented by the l1 watcher halted on a deep reorg until an operator acknowledges it.
+type ReorgAcknowledger interface {
+	AcknowledgeDeepReorg()
+}
+
+// FinalizationCostPredictor is implemented by the predictor of the l1 cost of the batch finalizations.
+type FinalizationCostPredictor interface {
+	PredictFinalizationCostGwei(estimatedGasUsed uint64) float64
+}
+
+// CheckerStatus is the health of a registered checker reported by the status endpoint.
+type CheckerStatus struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// FinalizationCostResult is the result of the finalization_cost endpoint.
+type FinalizationCostResult struct {
+	GasUsed  uint64  `json:"gas_used"`
+	CostGwei float64 `json:"cost_gwei"`
+}
+
+// ReproposeFailedResult is the result of the repropose_failed endpoint.
+type ReproposeFailedResult struct {
+	Chunks int `json:"chunks"`
+}
+
+// Server is the admin http server of the rollup services.
+type Server struct {
+	server   *http.Server
+	router   *gin.Engine
+	tlsFiles *config.AdminTLSConfig
+	checkers []HealthChecker
+
+	gasPriceOracles []GasPriceOracle
+	gasOraclePauser GasOraclePauser
+	gasPriceSetter  GasPriceOverrider
+	chunkProposer   ChunkProposer
+	batchProposer   BatchProposer
+
+	reorgAcknowledger ReorgAcknowledger
+	costPredictor     FinalizationCostPredictor
+}
+
+// NewServer returns a new instance of Server listening on addr.
+func NewServer(addr string, checkers ...HealthChecker) *Server {
+	s := &Server{checkers: checkers}
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.GET("/healthz", s.healthz)
+	r.GET("/status", s.status)
+	s.router = r
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           r,
+		ReadHeaderTimeout: time.Minute,
+	}
+	return s
+}
+
+// EnableTLS serves the admin server over TLS, and requires client certificates signed by
+// the configured client CAs if any.
+func (s *Server) EnableTLS(cfg *config.AdminTLSConfig) error {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(filepath.Clean(cfg.ClientCAFile))
+		if err != nil {
+			return fmt.Errorf("failed to read client ca file: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("no valid certificate found in client ca file %v", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	s.server.TLSConfig = tlsConfig
+	s.tlsFiles = cfg
+	return nil
+}
+
+// RegisterGasPriceOracles serves the gas_price_oracle endpoint triggering the given gas price oracles in order.
+func (s *Server) RegisterGasPriceOracles(oracles ...GasPriceOracle) {
+	s.gasPriceOracles = oracles
+	s.router.POST("/gas_price_oracle", s.triggerGasPriceOracle)
+}
+
+// RegisterGasOraclePauser serves the gas_oracle/pause and gas_oracle/resume endpoints of the given relayer.
+func (s *Server) RegisterGasOraclePauser(p GasOraclePauser) {
+	s.gasOraclePauser = p
+	s.router.POST("/gas_oracle/pause", s.pauseGasOracle)
+	s.router.POST("/gas_oracle/resume", s.resumeGasOracle)
+}
+
+// RegisterGasPriceOverrider serves the gas_oracle/set_gas_price endpoint of the given relayer.
+func (s *Server) RegisterGasPriceOverrider(o GasPriceOverrider) {
+	s.gasPriceSetter = o
+	s.router.POST("/gas_oracle/set_gas_price", s.setGasPrice)
+}
+
+// RegisterChunkProposer serves the chunk_proposal, repropose_failed and chunk_stats endpoints of the given chunk proposer.
+func (s *Server) RegisterChunkProposer(p ChunkProposer) {
+	s.chunkProposer = p
+	s.router.POST("/chunk_proposal", s.triggerChunkProposal)
+	s.router.POST("/repropose_failed", s.reproposeFailed)
+	s.router.GET("/metrics/chunk_stats", s.chunkStats)
+}
+
+// RegisterBatchProposer serves the batch_proposal endpoint of the given batch proposer.
+func (s *Server) RegisterBatchProposer(p BatchProposer) {
+	s.batchProposer = p
+	s.router.POST("/batch_proposal", s.triggerBatchProposal)
+}
+
+// RegisterReorgAcknowledger serves the l1_watcher/acknowledge_reorg endpoint of the given l1 watcher.
+func (s *Server) RegisterReorgAcknowledger(a ReorgAcknowledger) {
+	s.reorgAcknowledger = a
+	s.router.POST("/l1_watcher/acknowledge_reorg", s.acknowledgeReorg)
+}
+
+// RegisterFinalizationCostPredictor serves the finalization_cost endpoint of the given predictor.
+func (s *Server) RegisterFinalizationCostPredictor(p FinalizationCostPredictor) {
+	s.costPredictor = p
+	s.router.GET("/finalization_cost", s.finalizationCost)
+}
+
+// Start starts serving the admin http server in the background.
+func (s *Server) Start() {
+	log.Info("Starting admin server", "address", s.server.Addr, "tls", s.tlsFiles != nil)
+	go func() {
+		var err error
+		if s.tlsFiles != nil {
+			err = s.server.ListenAndServeTLS(s.tlsFiles.CertFile, s.tlsFiles.KeyFile)
+		} else {
+			err = s.server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Crit("run admin http server failure", "error", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts down the admin http server.
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// healthz returns 200 only if all the registered checkers are healthy.
+func (s *Server) healthz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	for _, checker := range s.checkers {
+		if err := checker.HealthCheck(ctx); err != nil {
+			log.Warn("health check failed", "err", err)
+			types.RenderFatal(c, err)
+			return
+		}
+	}
+	types.RenderSuccess(c, nil)
+}
+
+// status returns the health of every registered checker, in the order of registration.
+func (s *Server) status(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	statuses := make([]CheckerStatus, len(s.checkers))
+	for i, checker := range s.checkers {
+		statuses[i].Healthy = true
+		if err := checker.HealthCheck(ctx); err != nil {
+			statuses[i] = CheckerStatus{Healthy: false, Error: err.Error()}
+		}
+	}
+	types.RenderSuccess(c, statuses)
+}
+
+func (s *Server) triggerGasPriceOracle(c *gin.Context) {
+	log.Info("admin triggered gas price oracle")
+	for _, oracle := range s.gasPriceOracles {
+		if err := oracle.ProcessGasPriceOracle(); err != nil {
+			log.Warn("admin triggered gas price oracle failed", "err", err)
+			types.RenderFatal(c, err)
+			return
+		}
+	}
+	types.RenderSuccess(c, nil)
+}
+
+func (s *Server) pauseGasOracle(c *gin.Context) {
+	log.Info("admin paused gas oracle")
+	s.gasOraclePauser.PauseGasOracle()
+	types.RenderSuccess(c, nil)
+}
+
+func (s *Server) resumeGasOracle(c *gin.Context) {
+	log.Info("admin resumed gas oracle")
+	s.gasOraclePauser.ResumeGasOracle()
+	types.RenderSuccess(c, nil)
+}
+
+// setGasPrice imports the gas_price query gas price to layer2, bypassing the gas oracle.
+func (s *Server) setGasPrice(c *gin.Context) {
+	gasPrice, err := strconv.ParseUint(c.Query("gas_price"), 10, 64)
+	if err != nil {
+		types.RenderFailure(c, types.ErrRollupAdminParameterInvalidNo, fmt.Errorf("invalid gas_price: %w", err))
+		return
+	}
+
+	log.Warn("admin set gas price manually", "gas price", gasPrice)
+	if err = s.gasPriceSetter.SetGasPriceManually(c.Request.Context(), gasPrice); err != nil {
+		log.Warn("admin set gas price manually failed", "err", err)
+		types.RenderFatal(c, err)
+		return
+	}
+	types.RenderSuccess(c, nil)
+}
+
+func (s *Server) triggerChunkProposal(c *gin.Context) {
+	log.Info("admin triggered chunk proposal")
+	s.chunkProposer.TryProposeChunk()
+	types.RenderSuccess(c, nil)
+}
+
+func (s *Server) triggerBatchProposal(c *gin.Context) {
+	log.Info("admin triggered batch proposal")
+	s.batchProposer.TryProposeBatch()
+	types.RenderSuccess(c, nil)
+}
+
+func (s *Server) acknowledgeReorg(c *gin.Context) {
+	log.Info("admin acknowledged deep l1 reorg")
+	s.reorgAcknowledger.AcknowledgeDeepReorg()
+	types.RenderSuccess(c, nil)
+}
+
+// reproposeFailed re-proposes the chunks of the batches whose commit failed more than the
+// older_than query duration ago, all the commit failed batches are handled if it is empty.
+func (s *Server) reproposeFailed(c *gin.Context) {
+	var olderThan time.Duration
+	if param := c.Query("older_than"); param != "" {
+		var err error
+		if olderThan, err = time.ParseDuration(param); err != nil {
+			types.RenderFailure(c, types.ErrRollupAdminParameterInvalidNo, fmt.Errorf("invalid older_than: %w", err))
+			return
+		}
+	}
+
+	log.Info("admin triggered repropose failed", "older than", olderThan)
+	chunks, err := s.chunkProposer.ReproposeFailed(c.Request.Context(), olderThan)
+	if err != nil {
+		log.Warn("admin triggered repropose failed failed", "err", err)
+		types.RenderFatal(c, err)
+		return
+	}
+	types.RenderSuccess(c, ReproposeFailedResult{Chunks: chunks})
+}
+
+// finalizationCost returns the predicted cost of a finalization using the gas_used query gas.
+func (s *Server) finalizationCost(c *gin.Context) {
+	gasUsed, err := strconv.ParseUint(c.Query("gas_used"), 10, 64)
+	if err != nil {
+		types.RenderFailure(c, types.ErrRollupAdminParameterInvalidNo, fmt.Errorf("invalid gas_used: %w", err))
+		return
+	}
+	types.RenderSuccess(c, FinalizationCostResult{GasUsed: gasUsed, CostGwei: s.costPredictor.PredictFinalizationCostGwei(gasUsed)})
+}
+
+// chunkStats returns the statistics of the chunks proposed in the last chunkStatsWindow.
+func (s *Server) chunkStats(c *gin.Context) {
+	report, err := s.chunkProposer.ChunkStats(c.Request.Context(), time.Now().Add(-chunkStatsWindow))
+	if err != nil {
+		log.Warn("failed to get chunk stats", "err", err)
+		types.RenderFatal(c, err)
+		return
+	}
+	types.RenderSuccess(c, report)
+}
@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+type mockChecker struct {
+	err error
+}
+
+func (m *mockChecker) HealthCheck(context.Context) error {
+	return m.err
+}
+
+func TestHealthz(t *testing.T) {
+	healthy := &mockChecker{}
+	unhealthy := &mockChecker{err: errors.New("zero balance")}
+
+	tests := []struct {
+		name       string
+		checkers   []HealthChecker
+		statusCode int
+	}{
+		{"no checkers", nil, http.StatusOK},
+		{"all healthy", []HealthChecker{healthy, healthy}, http.StatusOK},
+		{"one unhealthy", []HealthChecker{healthy, unhealthy}, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("", tt.checkers...)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			s.server.Handler.ServeHTTP(w, req)
+			assert.Equal(t, tt.statusCode, w.Code)
+		})
+	}
+}
+
+type mockGasPriceOracle struct {
+	calls int
+	err   error
+}
+
+func (m *mockGasPriceOracle) ProcessGasPriceOracle() error {
+	m.calls++
+	return m.err
+}
+
+type mockGasOraclePauser struct {
+	paused bool
+}
+
+func (m *mockGasOraclePauser) PauseGasOracle() {
+	m.paused = true
+}
+
+func (m *mockGasOraclePauser) ResumeGasOracle() {
+	m.paused = false
+}
+
+type mockGasPriceOverrider struct {
+	gasPrice uint64
+	err      error
+}
+
+func (m *mockGasPriceOverrider) SetGasPriceManually(_ context.Context, gasPrice uint64) error {
+	m.gasPrice = gasPrice
+	return m.err
+}
+
+type mockReorgAcknowledger struct {
+	acknowledgements int
+}
+
+func (m *mockReorgAcknowledger) AcknowledgeDeepReorg() {
+	m.acknowledgements++
+}
+
+type mockCostPredictor struct{}
+
+func (mockCostPredictor) PredictFinalizationCostGwei(estimatedGasUsed uint64) float64 {
+	return float64(estimatedGasUsed) * 2
+}
+
+type mockProposer struct {
+	chunkProposals int
+	batchProposals int
+	olderThan      time.Duration
+}
+
+func (m *mockProposer) TryProposeChunk() {
+	m.chunkProposals++
+}
+
+func (m *mockProposer) ReproposeFailed(_ context.Context, olderThan time.Duration) (int, error) {
+	m.olderThan = olderThan
+	return 2, nil
+}
+
+func (m *mockProposer) ChunkStats(_ context.Context, since time.Time) (*watcher.ChunkStatsReport, error) {
+	return &watcher.ChunkStatsReport{Since: since, ChunkCount: 3}, nil
+}
+
+func (m *mockProposer) TryProposeBatch() {
+	m.batchProposals++
+}
+
+func serve(s *Server, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestTriggers(t *testing.T) {
+	oracle := &mockGasPriceOracle{}
+	proposer := &mockProposer{}
+
+	s := NewServer("")
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodPost, "/chunk_proposal").Code)
+
+	s.RegisterGasPriceOracles(oracle, oracle)
+	s.RegisterChunkProposer(proposer)
+	s.RegisterBatchProposer(proposer)
+
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/gas_price_oracle").Code)
+	assert.Equal(t, 2, oracle.calls)
+	oracle.err = errors.New("oracle failure")
+	assert.Equal(t, http.StatusInternalServerError, serve(s, http.MethodPost, "/gas_price_oracle").Code)
+
+	pauser := &mockGasOraclePauser{}
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodPost, "/gas_oracle/pause").Code)
+	s.RegisterGasOraclePauser(pauser)
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/gas_oracle/pause").Code)
+	assert.True(t, pauser.paused)
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/gas_oracle/resume").Code)
+	assert.False(t, pauser.paused)
+
+	overrider := &mockGasPriceOverrider{}
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodPost, "/gas_oracle/set_gas_price?gas_price=100").Code)
+	s.RegisterGasPriceOverrider(overrider)
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/gas_oracle/set_gas_price?gas_price=100").Code)
+	assert.Equal(t, uint64(100), overrider.gasPrice)
+	var overrideResp types.Response
+	assert.NoError(t, json.Unmarshal(serve(s, http.MethodPost, "/gas_oracle/set_gas_price").Body.Bytes(), &overrideResp))
+	assert.Equal(t, types.ErrRollupAdminParameterInvalidNo, overrideResp.ErrCode)
+	overrider.err = errors.New("override failure")
+	assert.Equal(t, http.StatusInternalServerError, serve(s, http.MethodPost, "/gas_oracle/set_gas_price?gas_price=100").Code)
+
+	acknowledger := &mockReorgAcknowledger{}
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodPost, "/l1_watcher/acknowledge_reorg").Code)
+	s.RegisterReorgAcknowledger(acknowledger)
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/l1_watcher/acknowledge_reorg").Code)
+	assert.Equal(t, 1, acknowledger.acknowledgements)
+
+	s.RegisterFinalizationCostPredictor(mockCostPredictor{})
+	w := serve(s, http.MethodGet, "/finalization_cost?gas_used=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var costResp types.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &costResp))
+	assert.Equal(t, map[string]interface{}{"gas_used": float64(100), "cost_gwei": float64(200)}, costResp.Data)
+	w = serve(s, http.MethodGet, "/finalization_cost")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &costResp))
+	assert.Equal(t, types.ErrRollupAdminParameterInvalidNo, costResp.ErrCode)
+
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/chunk_proposal").Code)
+	assert.Equal(t, 1, proposer.chunkProposals)
+	assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/batch_proposal").Code)
+	assert.Equal(t, 1, proposer.batchProposals)
+
+	w = serve(s, http.MethodPost, "/repropose_failed?older_than=10m")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10*time.Minute, proposer.olderThan)
+	var resp types.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]interface{}{"chunks": float64(2)}, resp.Data)
+
+	w = serve(s, http.MethodPost, "/repropose_failed?older_than=soon")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, types.ErrRollupAdminParameterInvalidNo, resp.ErrCode)
+
+	w = serve(s, http.MethodGet, "/metrics/chunk_stats")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var statsResp struct {
+		Data watcher.ChunkStatsReport `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &statsResp))
+	assert.Equal(t, 3, statsResp.Data.ChunkCount)
+	assert.WithinDuration(t, time.Now().Add(-chunkStatsWindow), statsResp.Data.Since, time.Minute)
+}
+
+func TestStatus(t *testing.T) {
+	s := NewServer("", &mockChecker{}, &mockChecker{err: errors.New("zero balance")})
+	w := serve(s, http.MethodGet, "/status")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data []CheckerStatus `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []CheckerStatus{{Healthy: true}, {Healthy: false, Error: "zero balance"}}, resp.Data)
+}
+
+func TestEnableTLS(t *testing.T) {
+	s := NewServer("")
+	assert.NoError(t, s.EnableTLS(&config.AdminTLSConfig{CertFile: "server.crt", KeyFile: "server.key"}))
+	assert.Equal(t, tls.NoClientCert, s.server.TLSConfig.ClientAuth)
+
+	assert.Error(t, s.EnableTLS(&config.AdminTLSConfig{CertFile: "server.crt", KeyFile: "server.key", ClientCAFile: "not_exist.crt"}))
+}
@@ -0,0 +1,114 @@
+package relayer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	alertWebhookTimeout = 5 * time.Second
+
+	// alertSignatureHeader carries the hex encoded HMAC-SHA256 of the request body keyed by the webhook secret.
+	alertSignatureHeader = "X-Signature-256"
+
+	// maxDBErrorCycles is the number of consecutive cycles failing on db errors tolerated before alerting.
+	maxDBErrorCycles = 3
+)
+
+// webhookAlert is an alert of the Alertmanager webhook payload.
+type webhookAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+// webhookMessage is the Alertmanager webhook payload, see https://prometheus.io/docs/alerting/latest/configuration/#webhook_config.
+type webhookMessage struct {
+	Version           string            `json:"version"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	Alerts            []webhookAlert    `json:"alerts"`
+}
+
+// alertWebhook posts critical relayer events to an Alertmanager compatible webhook.
+// A nil alertWebhook is a no-op, it is returned when no webhook url is configured.
+type alertWebhook struct {
+	service string
+	url     string
+	secret  []byte
+	client  *resty.Client
+	now     func() time.Time
+}
+
+func newAlertWebhook(service, url, secret string) *alertWebhook {
+	if url == "" {
+		return nil
+	}
+	return &alertWebhook{
+		service: service,
+		url:     url,
+		secret:  []byte(secret),
+		client:  resty.New().SetTimeout(alertWebhookTimeout),
+		now:     time.Now,
+	}
+}
+
+// send posts a firing critical alert, the body is signed if a secret is configured.
+func (w *alertWebhook) send(ctx context.Context, title, body string) error {
+	if w == nil {
+		return nil
+	}
+
+	labels := map[string]string{
+		"alertname": title,
+		"service":   w.service,
+		"severity":  "critical",
+	}
+	annotations := map[string]string{
+		"summary":     title,
+		"description": body,
+	}
+	payload, err := json.Marshal(&webhookMessage{
+		Version:           "4",
+		Status:            "firing",
+		Receiver:          w.service,
+		GroupLabels:       map[string]string{"alertname": title},
+		CommonLabels:      labels,
+		CommonAnnotations: annotations,
+		Alerts: []webhookAlert{{
+			Status:      "firing",
+			Labels:      labels,
+			Annotations: annotations,
+			StartsAt:    w.now().UTC(),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req := w.client.R().SetContext(ctx).SetHeader("Content-Type", "application/json").SetBody(payload)
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(payload)
+		req.SetHeader(alertSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := req.Post(w.url)
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("failed to post alert, status: %s", resp.Status())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package relayer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/controller/sender"
+)
+
+type webhookRequest struct {
+	signature string
+	message   webhookMessage
+}
+
+func newWebhookServer(t *testing.T, statusCode int) (*httptest.Server, *[]webhookRequest) {
+	var requests []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := webhookRequest{signature: r.Header.Get(alertSignatureHeader)}
+		assert.NoError(t, json.Unmarshal(body, &req.message))
+		if req.signature != "" {
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write(body)
+			assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.signature)
+		}
+		requests = append(requests, req)
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestAlertWebhook(t *testing.T) {
+	// unconfigured
+	var w *alertWebhook
+	assert.Nil(t, newAlertWebhook("l1_relayer", "", "secret"))
+	assert.NoError(t, w.send(context.Background(), "title", "body"))
+
+	server, requests := newWebhookServer(t, http.StatusOK)
+	w = newAlertWebhook("l1_relayer", server.URL, "secret")
+	assert.NoError(t, w.send(context.Background(), "L1GasOracleImportFailed", "tx failed"))
+	w = newAlertWebhook("l1_relayer", server.URL, "")
+	assert.NoError(t, w.send(context.Background(), "L1GasOracleImportFailed", "tx failed"))
+
+	assert.Len(t, *requests, 2)
+	assert.NotEmpty(t, (*requests)[0].signature)
+	assert.Empty(t, (*requests)[1].signature)
+	msg := (*requests)[0].message
+	assert.Equal(t, "firing", msg.Status)
+	assert.Len(t, msg.Alerts, 1)
+	assert.Equal(t, map[string]string{"alertname": "L1GasOracleImportFailed", "service": "l1_relayer", "severity": "critical"}, msg.Alerts[0].Labels)
+	assert.Equal(t, "tx failed", msg.Alerts[0].Annotations["description"])
+
+	server, _ = newWebhookServer(t, http.StatusInternalServerError)
+	w = newAlertWebhook("l1_relayer", server.URL, "secret")
+	assert.Error(t, w.send(context.Background(), "L1GasOracleImportFailed", "tx failed"))
+}
+
+func TestLayer1RelayerAlerts(t *testing.T) {
+	server, requests := newWebhookServer(t, http.StatusOK)
+	r := &Layer1Relayer{
+		ctx:          context.Background(),
+		alertWebhook: newAlertWebhook("l1_relayer", server.URL, "secret"),
+		metrics:      initL1RelayerMetrics(nil),
+	}
+
+	// db errors are alerted once they persist for more than maxDBErrorCycles cycles
+	dbErr := errors.New("connection refused")
+	failedCycle := func() {
+		r.observeDBError(dbErr)
+		r.endDBErrorCycle()
+	}
+	for i := 0; i < maxDBErrorCycles; i++ {
+		failedCycle()
+	}
+	r.observeDBError(nil)
+	r.endDBErrorCycle()
+	for i := 0; i < maxDBErrorCycles; i++ {
+		failedCycle()
+	}
+	assert.Empty(t, *requests)
+	failedCycle()
+	failedCycle()
+	assert.Len(t, *requests, 1)
+	assert.Equal(t, "L1GasOracleDBError", (*requests)[0].message.Alerts[0].Labels["alertname"])
+
+	// the cycles whose reads succeed but whose update fails are failed cycles, the db errors are counted once per cycle.
+	*requests = nil
+	r.endDBErrorCycle()
+	partiallyFailedCycle := func() {
+		r.observeDBError(nil)
+		r.observeDBError(nil)
+		r.observeDBError(dbErr)
+		r.endDBErrorCycle()
+	}
+	r.observeDBError(dbErr)
+	r.observeDBError(dbErr)
+	r.observeDBError(dbErr)
+	r.observeDBError(dbErr)
+	r.endDBErrorCycle()
+	for i := 0; i < maxDBErrorCycles-1; i++ {
+		partiallyFailedCycle()
+	}
+	assert.Empty(t, *requests)
+	partiallyFailedCycle()
+	assert.Len(t, *requests, 1)
+
+	// an open circuit breaker is alerted once until a transaction is sent again
+	*requests = nil
+	circuitErr := fmt.Errorf("send failed: %w", sender.ErrCircuitOpen)
+	r.observeSendError(errors.New("nonce too low"))
+	r.observeSendError(circuitErr)
+	r.observeSendError(circuitErr)
+	assert.Len(t, *requests, 1)
+	r.observeSendError(nil)
+	r.observeSendError(circuitErr)
+	assert.Len(t, *requests, 2)
+	assert.Equal(t, "L1GasOracleCircuitOpen", (*requests)[1].message.Alerts[0].Labels["alertname"])
+}
@@ -0,0 +1,46 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// blockHeightReconciler is implemented by orm.L1Block and orm.L2Block.
+type blockHeightReconciler interface {
+	ReconcileBlockHeight(ctx context.Context, chainHead uint64) (*orm.ReconciliationReport, error)
+}
+
+// chainHeadReader is implemented by ethclient.Client.
+type chainHeadReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// checkBlockGaps reconciles the stored blocks against the chain head read from client, and returns an error
+// if a gap is larger than maxAllowedGapBlocks. The gaps are logged and the failure is alerted to webhook.
+func checkBlockGaps(ctx context.Context, name string, reconciler blockHeightReconciler, client chainHeadReader, maxAllowedGapBlocks uint64, webhook *alertWebhook) error {
+	chainHead, err := client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get %s chain head: %w", name, err)
+	}
+	report, err := reconciler.ReconcileBlockHeight(ctx, chainHead)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile %s block height: %w", name, err)
+	}
+
+	for _, gap := range report.Gaps {
+		log.Warn("Found a gap in the stored blocks", "chain", name, "from", gap.From, "to", gap.To, "size", gap.Size(), "chain head", chainHead)
+	}
+	if largest := report.LargestGap(); largest > maxAllowedGapBlocks {
+		err = fmt.Errorf("%s block gap of %d blocks exceeds max_allowed_gap_blocks %d, latest stored height: %d, chain head: %d, gaps: %v",
+			name, largest, maxAllowedGapBlocks, report.LatestStoredHeight, chainHead, report.Gaps)
+		if alertErr := webhook.send(ctx, "BlockGapDetected", err.Error()); alertErr != nil {
+			log.Warn("Failed to send alert", "title", "BlockGapDetected", "err", alertErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+type mockChainHeadReader struct {
+	head uint64
+	err  error
+}
+
+func (m *mockChainHeadReader) BlockNumber(context.Context) (uint64, error) {
+	return m.head, m.err
+}
+
+type mockBlockHeightReconciler struct {
+	gaps []orm.BlockGap
+}
+
+func (m *mockBlockHeightReconciler) ReconcileBlockHeight(_ context.Context, chainHead uint64) (*orm.ReconciliationReport, error) {
+	return &orm.ReconciliationReport{ChainHead: chainHead, Gaps: m.gaps}, nil
+}
+
+func TestCheckBlockGaps(t *testing.T) {
+	server, requests := newWebhookServer(t, http.StatusOK)
+	webhook := newAlertWebhook("l1_relayer", server.URL, "")
+	ctx := context.Background()
+
+	// no gap
+	err := checkBlockGaps(ctx, "l1", &mockBlockHeightReconciler{}, &mockChainHeadReader{head: 100}, 10, webhook)
+	assert.NoError(t, err)
+
+	// gaps within the limit
+	reconciler := &mockBlockHeightReconciler{gaps: []orm.BlockGap{{From: 5, To: 14}, {From: 91, To: 100}}}
+	err = checkBlockGaps(ctx, "l1", reconciler, &mockChainHeadReader{head: 100}, 10, webhook)
+	assert.NoError(t, err)
+	assert.Empty(t, *requests)
+
+	// a gap over the limit
+	reconciler = &mockBlockHeightReconciler{gaps: []orm.BlockGap{{From: 5, To: 15}}}
+	err = checkBlockGaps(ctx, "l1", reconciler, &mockChainHeadReader{head: 100}, 10, webhook)
+	assert.ErrorContains(t, err, "l1 block gap of 11 blocks exceeds max_allowed_gap_blocks 10")
+	assert.Len(t, *requests, 1)
+	assert.Equal(t, "BlockGapDetected", (*requests)[0].message.GroupLabels["alertname"])
+
+	// chain head unavailable
+	targetErr := errors.New("BlockNumber error")
+	err = checkBlockGaps(ctx, "l1", reconciler, &mockChainHeadReader{err: targetErr}, 10, webhook)
+	assert.ErrorIs(t, err, targetErr)
+}
@@ -1,11 +1,26 @@
 package relayer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"scroll-tech/rollup/internal/config"
+)
 
 const (
 	gasPriceDiffPrecision = 1000000
 
 	defaultGasPriceDiff = 50000 // 5%
+
+	// multicallContextIDSeparator separates the block hashes in the context ID of a multicall gas oracle transaction.
+	multicallContextIDSeparator = ","
+	// manualOverrideContextIDPrefix prefixes the context ID of a gas oracle transaction sent by SetGasPriceManually.
+	manualOverrideContextIDPrefix = "manual-gas-price-override-"
+
+	// confirmationDrainTimeout bounds the time spent by Stop on handling the remaining confirmations.
+	confirmationDrainTimeout = 5 * time.Second
 )
 
 var (
@@ -28,3 +43,84 @@ const (
 	// ServiceTypeL2GasOracle indicates the service is a Layer 2 gas oracle.
 	ServiceTypeL2GasOracle
 )
+
+// withoutFlashbots returns a copy of cfg sending the transactions to the endpoint, only the batch commit
+// transactions are privately sent to the Flashbots relay.
+func withoutFlashbots(cfg *config.SenderConfig) *config.SenderConfig {
+	senderCfg := *cfg
+	senderCfg.FlashbotsRelayURL = ""
+	return &senderCfg
+}
+
+// gasOracleParams returns the minimum gas price and the gas price diff of cfg, with defaults if cfg is nil.
+func gasOracleParams(cfg *config.GasOracleConfig) (minGasPrice uint64, gasPriceDiff uint64) {
+	if cfg == nil {
+		return 0, defaultGasPriceDiff
+	}
+	return cfg.MinGasPrice, cfg.GasPriceDiff
+}
+
+// oraclePollJitter returns the max random delay added to each gas oracle polling interval.
+func oraclePollJitter(cfg *config.GasOracleConfig) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	return time.Duration(cfg.OraclePollJitterMs) * time.Millisecond
+}
+
+// gasPriceSpikeParams returns the gas price above which l1 gas oracle updates are frozen and the soak period after which the freeze lifts.
+func gasPriceSpikeParams(cfg *config.GasOracleConfig) (maxGasPrice uint64, spikeExemptionDuration time.Duration, err error) {
+	if cfg == nil || cfg.MaxGasPrice == 0 {
+		return 0, 0, nil
+	}
+	if cfg.SpikeExemptionDuration == 0 {
+		return 0, 0, fmt.Errorf("spike exemption duration is required when max gas price is set, max gas price: %d", cfg.MaxGasPrice)
+	}
+	return cfg.MaxGasPrice, time.Duration(cfg.SpikeExemptionDuration) * time.Second, nil
+}
+
+// emaParams returns the weight of the latest base fee in the gas price moving average, 0 if EMA smoothing is disabled.
+func emaParams(cfg *config.GasOracleConfig) (alpha float64, err error) {
+	if cfg == nil || !cfg.UseEMASmoothing {
+		return 0, nil
+	}
+	if cfg.EMAAlpha <= 0 || cfg.EMAAlpha > 1 {
+		return 0, fmt.Errorf("ema alpha must be in (0, 1] when ema smoothing is enabled, ema alpha: %v", cfg.EMAAlpha)
+	}
+	return cfg.EMAAlpha, nil
+}
+
+// percentileParams returns the number of latest blocks and the percentile of their base fees relayed by the l1 gas oracle,
+// 0 window if the percentile gas price is disabled.
+func percentileParams(cfg *config.GasOracleConfig) (window uint64, value uint64, err error) {
+	if cfg == nil || !cfg.UsePercentileGasPrice {
+		return 0, 0, nil
+	}
+	if cfg.PercentileWindow == 0 {
+		return 0, 0, errors.New("percentile window is required when percentile gas price is enabled")
+	}
+	if cfg.PercentileValue == 0 || cfg.PercentileValue > 100 {
+		return 0, 0, fmt.Errorf("percentile value must be in [1, 100] when percentile gas price is enabled, percentile value: %d", cfg.PercentileValue)
+	}
+	return cfg.PercentileWindow, cfg.PercentileValue, nil
+}
+
+// percentile returns the p-th percentile of values with the nearest-rank method, 0 if values is empty.
+func percentile(values []uint64, p uint64) uint64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]uint64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	// the rank is ceil(p / 100 * n), in [1, n].
+	rank := (p*uint64(len(sorted)) + 99) / 100
+	if rank == 0 {
+		rank = 1
+	}
+	if rank > uint64(len(sorted)) {
+		rank = uint64(len(sorted))
+	}
+	return sorted[rank-1]
+}
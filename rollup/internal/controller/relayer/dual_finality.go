@@ -0,0 +1,129 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// defaultFinalityPollInterval is the interval between two polls of the chains if it is not configured.
+const defaultFinalityPollInterval = 12 * time.Second
+
+// finalityChainReader is implemented by ethclient.Client.
+type finalityChainReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error)
+}
+
+// BothFinalizedEvent is sent by DualFinalityChecker once a l1 transaction and a l2 block are both deep enough.
+type BothFinalizedEvent struct {
+	ContextID     string
+	L1TxHash      common.Hash
+	L1BlockNumber uint64
+	L2BlockNumber uint64
+	// The confirmations of the l1 transaction and the l2 block when both thresholds were met.
+	L1Confirmations uint64
+	L2Confirmations uint64
+}
+
+// DualFinalityChecker waits for a l1 transaction and a l2 block to reach their confirmation depths,
+// e.g. a finalizeBatch transaction and the last block of the finalized batch.
+type DualFinalityChecker struct {
+	l1Client        finalityChainReader
+	l2Client        finalityChainReader
+	l1Confirmations uint64
+	l2Confirmations uint64
+	pollInterval    time.Duration
+}
+
+// NewDualFinalityChecker returns a DualFinalityChecker polling both chains every pollInterval, 12 seconds if 0.
+func NewDualFinalityChecker(l1Client, l2Client finalityChainReader, l1Confirmations, l2Confirmations uint64, pollInterval time.Duration) *DualFinalityChecker {
+	if pollInterval == 0 {
+		pollInterval = defaultFinalityPollInterval
+	}
+	return &DualFinalityChecker{
+		l1Client:        l1Client,
+		l2Client:        l2Client,
+		l1Confirmations: l1Confirmations,
+		l2Confirmations: l2Confirmations,
+		pollInterval:    pollInterval,
+	}
+}
+
+// Wait polls both chains until l1TxHash has l1Confirmations and l2BlockNumber has l2Confirmations, then sends
+// a BothFinalizedEvent on the returned channel. The channel is closed without an event if ctx is done first.
+// A l1 transaction whose receipt disappears in a reorg is waited for again.
+func (c *DualFinalityChecker) Wait(ctx context.Context, contextID string, l1TxHash common.Hash, l2BlockNumber uint64) <-chan *BothFinalizedEvent {
+	eventCh := make(chan *BothFinalizedEvent, 1)
+	go func() {
+		defer close(eventCh)
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+		for {
+			event, err := c.check(ctx, contextID, l1TxHash, l2BlockNumber)
+			if err != nil {
+				log.Warn("failed to check the finality", "context ID", contextID, "l1 tx hash", l1TxHash.String(), "l2 block number", l2BlockNumber, "err", err)
+			} else if event != nil {
+				eventCh <- event
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return eventCh
+}
+
+// check returns a BothFinalizedEvent if both confirmation thresholds are met, nil otherwise.
+func (c *DualFinalityChecker) check(ctx context.Context, contextID string, l1TxHash common.Hash, l2BlockNumber uint64) (*BothFinalizedEvent, error) {
+	l1Head, err := c.l1Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.l1Client.TransactionReceipt(ctx, l1TxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l1BlockNumber := receipt.BlockNumber.Uint64()
+	l1Confirmations := confirmationDepth(l1Head, l1BlockNumber)
+	if l1Confirmations == 0 || l1Confirmations < c.l1Confirmations {
+		return nil, nil
+	}
+
+	l2Head, err := c.l2Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l2Confirmations := confirmationDepth(l2Head, l2BlockNumber)
+	if l2Confirmations == 0 || l2Confirmations < c.l2Confirmations {
+		return nil, nil
+	}
+
+	return &BothFinalizedEvent{
+		ContextID:       contextID,
+		L1TxHash:        l1TxHash,
+		L1BlockNumber:   l1BlockNumber,
+		L2BlockNumber:   l2BlockNumber,
+		L1Confirmations: l1Confirmations,
+		L2Confirmations: l2Confirmations,
+	}, nil
+}
+
+// confirmationDepth returns the number of blocks from number to head, both included, 0 if number is above head.
+func confirmationDepth(head, number uint64) uint64 {
+	if number > head {
+		return 0
+	}
+	return head - number + 1
+}
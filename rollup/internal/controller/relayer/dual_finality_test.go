@@ -0,0 +1,111 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// simulatedChain mines a block each time its head is read, unless it is stalled.
+// The transactions are included in their block once it is mined.
+type simulatedChain struct {
+	mu      sync.Mutex
+	head    uint64
+	stalled bool
+	txs     map[common.Hash]uint64
+}
+
+func (c *simulatedChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.stalled {
+		c.head++
+	}
+	return c.head, nil
+}
+
+func (c *simulatedChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	number, ok := c.txs[txHash]
+	if !ok || number > c.head {
+		return nil, ethereum.NotFound
+	}
+	return &gethTypes.Receipt{TxHash: txHash, BlockNumber: new(big.Int).SetUint64(number), Status: gethTypes.ReceiptStatusSuccessful}, nil
+}
+
+func TestDualFinalityChecker(t *testing.T) {
+	txHash := common.HexToHash("0x1")
+	tests := []struct {
+		name            string
+		l1Confirmations uint64
+		l2Confirmations uint64
+		l1TxBlock       uint64
+		l2Block         uint64
+		// the minimum confirmations of the event, the chains may advance past the thresholds.
+		expectedL1Confirmations uint64
+		expectedL2Confirmations uint64
+	}{
+		{"zero confirmations", 0, 0, 10, 10, 1, 1},
+		{"one confirmation", 1, 1, 10, 10, 1, 1},
+		{"deeper l1", 12, 2, 10, 10, 12, 2},
+		{"deeper l2", 2, 12, 10, 10, 2, 12},
+		{"l2 block ahead of the l1 tx", 3, 3, 5, 40, 3, 3},
+		{"tx not yet included", 4, 4, 20, 10, 4, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l1 := &simulatedChain{head: 9, txs: map[common.Hash]uint64{txHash: tt.l1TxBlock}}
+			l2 := &simulatedChain{head: 9}
+			checker := NewDualFinalityChecker(l1, l2, tt.l1Confirmations, tt.l2Confirmations, time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			event, ok := <-checker.Wait(ctx, "batch", txHash, tt.l2Block)
+			assert.True(t, ok)
+			assert.Equal(t, "batch", event.ContextID)
+			assert.Equal(t, txHash, event.L1TxHash)
+			assert.Equal(t, tt.l1TxBlock, event.L1BlockNumber)
+			assert.Equal(t, tt.l2Block, event.L2BlockNumber)
+			assert.GreaterOrEqual(t, event.L1Confirmations, tt.expectedL1Confirmations)
+			// the l2 head only advances once the l1 threshold is met, so it stops right at its threshold.
+			assert.Equal(t, tt.expectedL2Confirmations, event.L2Confirmations)
+
+			l1.mu.Lock()
+			assert.Equal(t, confirmationDepth(l1.head, tt.l1TxBlock), event.L1Confirmations)
+			l1.mu.Unlock()
+		})
+	}
+}
+
+func TestDualFinalityCheckerNotFinalized(t *testing.T) {
+	txHash := common.HexToHash("0x1")
+	// the l2 chain is stalled below the required confirmations.
+	l1 := &simulatedChain{head: 100, txs: map[common.Hash]uint64{txHash: 10}}
+	l2 := &simulatedChain{head: 10, stalled: true}
+	checker := NewDualFinalityChecker(l1, l2, 2, 2, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	event, ok := <-checker.Wait(ctx, "batch", txHash, 10)
+	assert.False(t, ok)
+	assert.Nil(t, event)
+
+	// the l1 transaction is never included.
+	l1 = &simulatedChain{head: 100, txs: map[common.Hash]uint64{}}
+	l2 = &simulatedChain{head: 100}
+	checker = NewDualFinalityChecker(l1, l2, 1, 1, time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, ok = <-checker.Wait(ctx, "batch", txHash, 10)
+	assert.False(t, ok)
+
+	assert.Equal(t, defaultFinalityPollInterval, NewDualFinalityChecker(l1, l2, 1, 1, 0).pollInterval)
+}
@@ -0,0 +1,52 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+var (
+	// ErrTransient indicates a failure that may succeed on retry, e.g. a db or rpc error.
+	ErrTransient = errors.New("transient error")
+	// ErrPermanent indicates a failure that will not succeed on retry without intervention, e.g. invalid data.
+	ErrPermanent = errors.New("permanent error")
+	// ErrProofInvalid indicates a batch proof rejected by the local proof verifier.
+	ErrProofInvalid = errors.New("proof invalid")
+)
+
+// Error is an error of the relayer classified by Kind, which is either ErrTransient or ErrPermanent.
+// Both Kind and Err can be matched by errors.Is and errors.As.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+// Unwrap returns both the kind and the underlying error.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func newTransientError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrTransient, Err: fmt.Errorf(format, args...)}
+}
+
+func newPermanentError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPermanent, Err: fmt.Errorf(format, args...)}
+}
+
+// LogError logs an error returned by the relayer, transient errors are logged as warnings and all the others as errors.
+func LogError(msg string, err error, ctx ...interface{}) {
+	ctx = append(ctx, "err", err)
+	if errors.Is(err, ErrTransient) {
+		log.Warn(msg, ctx...)
+		return
+	}
+	log.Error(msg, ctx...)
+}
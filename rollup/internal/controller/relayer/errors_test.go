@@ -0,0 +1,27 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	transientErr := newTransientError("failed to get latest L1 block height: %w", cause)
+	assert.ErrorIs(t, transientErr, ErrTransient)
+	assert.ErrorIs(t, transientErr, cause)
+	assert.NotErrorIs(t, transientErr, ErrPermanent)
+	assert.Equal(t, "transient error: failed to get latest L1 block height: connection refused", transientErr.Error())
+
+	permanentErr := fmt.Errorf("process gas price oracle: %w", newPermanentError("block not exist, height: %d", 1))
+	assert.ErrorIs(t, permanentErr, ErrPermanent)
+	assert.NotErrorIs(t, permanentErr, ErrTransient)
+
+	var relayerErr *Error
+	assert.True(t, errors.As(permanentErr, &relayerErr))
+	assert.Equal(t, ErrPermanent, relayerErr.Kind)
+}
@@ -0,0 +1,122 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/controller/sender"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const (
+	// finalizationCostHistorySize is the number of the latest finalizations the cost of a finalization is predicted from.
+	finalizationCostHistorySize = 100
+	// weiPerGwei converts the costs in wei to gwei.
+	weiPerGwei = 1e9
+)
+
+// GasCostPredictor predicts the l1 cost of a batch finalization from the gas it uses, with a linear regression of
+// the costs of the latest confirmed finalizations on their gas used.
+type GasCostPredictor struct {
+	costHistoryOrm *orm.FinalizationCostHistory
+	l1BlockOrm     *orm.L1Block
+
+	mu        sync.RWMutex
+	intercept float64
+	slope     float64
+
+	metrics *gasCostPredictorMetrics
+}
+
+// NewGasCostPredictor returns a new instance of GasCostPredictor, Fit must be called before the first prediction.
+func NewGasCostPredictor(db *gorm.DB, reg prometheus.Registerer) *GasCostPredictor {
+	return &GasCostPredictor{
+		costHistoryOrm: orm.NewFinalizationCostHistory(db),
+		l1BlockOrm:     orm.NewL1Block(db),
+		metrics:        initGasCostPredictorMetrics(reg),
+	}
+}
+
+// RecordFinalization stores the cost of a confirmed finalization and fits the prediction again.
+// The l1 base fee is the one of the block stored by the l1 watcher, or the gas price paid if the block is not stored.
+func (p *GasCostPredictor) RecordFinalization(ctx context.Context, cfm *sender.Confirmation) error {
+	blocks, err := p.l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{"number = ?": cfm.BlockNumber})
+	if err != nil {
+		return err
+	}
+	var l1BaseFee uint64
+	if len(blocks) > 0 {
+		l1BaseFee = blocks[0].BaseFee
+	} else if cfm.EffectiveGasPrice != nil {
+		l1BaseFee = cfm.EffectiveGasPrice.Uint64()
+	}
+
+	if err = p.costHistoryOrm.InsertFinalizationCost(ctx, cfm.ContextID, cfm.TxHash.String(), l1BaseFee, cfm.GasUsed); err != nil {
+		return err
+	}
+	return p.Fit(ctx)
+}
+
+// Fit fits the prediction to the latest finalizationCostHistorySize confirmed finalizations.
+func (p *GasCostPredictor) Fit(ctx context.Context) error {
+	costs, err := p.costHistoryOrm.GetLatestFinalizationCosts(ctx, finalizationCostHistorySize)
+	if err != nil {
+		return fmt.Errorf("failed to get the finalization cost history: %w", err)
+	}
+	intercept, slope := fitFinalizationCost(costs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.intercept, p.slope = intercept, slope
+	log.Debug("Fitted finalization cost prediction", "finalizations", len(costs), "intercept", intercept, "slope", slope)
+	return nil
+}
+
+// PredictFinalizationCostGwei returns the predicted cost in gwei of a finalization using estimatedGasUsed gas,
+// 0 if there is no confirmed finalization yet.
+func (p *GasCostPredictor) PredictFinalizationCostGwei(estimatedGasUsed uint64) float64 {
+	p.mu.RLock()
+	cost := p.intercept + p.slope*float64(estimatedGasUsed)
+	p.mu.RUnlock()
+
+	if cost < 0 {
+		cost = 0
+	}
+	p.metrics.rollupPredictedFinalizationCostGwei.Set(cost)
+	return cost
+}
+
+// fitFinalizationCost returns the intercept and the slope of the least squares regression of the costs in gwei of the
+// finalizations on their gas used. If all the finalizations used the same gas, the cost is proportional to the gas.
+func fitFinalizationCost(costs []orm.FinalizationCostHistory) (float64, float64) {
+	if len(costs) == 0 {
+		return 0, 0
+	}
+
+	n := float64(len(costs))
+	var meanGas, meanCost float64
+	for _, c := range costs {
+		meanGas += float64(c.GasUsed) / n
+		meanCost += float64(c.GasUsed) * float64(c.L1BaseFee) / weiPerGwei / n
+	}
+
+	var sxx, sxy float64
+	for _, c := range costs {
+		dx := float64(c.GasUsed) - meanGas
+		sxx += dx * dx
+		sxy += dx * (float64(c.GasUsed)*float64(c.L1BaseFee)/weiPerGwei - meanCost)
+	}
+	if sxx == 0 {
+		if meanGas == 0 {
+			return 0, 0
+		}
+		return 0, meanCost / meanGas
+	}
+	slope := sxy / sxx
+	return meanCost - slope*meanGas, slope
+}
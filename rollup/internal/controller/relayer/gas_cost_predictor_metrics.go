@@ -0,0 +1,29 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type gasCostPredictorMetrics struct {
+	rollupPredictedFinalizationCostGwei prometheus.Gauge
+}
+
+var (
+	initGasCostPredictorMetricOnce sync.Once
+	gasCostPredictorMetric         *gasCostPredictorMetrics
+)
+
+func initGasCostPredictorMetrics(reg prometheus.Registerer) *gasCostPredictorMetrics {
+	initGasCostPredictorMetricOnce.Do(func() {
+		gasCostPredictorMetric = &gasCostPredictorMetrics{
+			rollupPredictedFinalizationCostGwei: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_predicted_finalization_cost_gwei",
+				Help: "The last predicted l1 cost in gwei of a batch finalization",
+			}),
+		}
+	})
+	return gasCostPredictorMetric
+}
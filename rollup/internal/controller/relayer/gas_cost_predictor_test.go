@@ -0,0 +1,48 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+func TestFitFinalizationCost(t *testing.T) {
+	intercept, slope := fitFinalizationCost(nil)
+	assert.Zero(t, intercept)
+	assert.Zero(t, slope)
+
+	// the same gas used: the cost is proportional to the gas at the mean price.
+	intercept, slope = fitFinalizationCost([]orm.FinalizationCostHistory{
+		{GasUsed: 100000, L1BaseFee: 10e9},
+		{GasUsed: 100000, L1BaseFee: 30e9},
+	})
+	assert.Zero(t, intercept)
+	assert.InDelta(t, 20, slope, 1e-9)
+
+	// cost = 1000 + 30 * gas in gwei.
+	var costs []orm.FinalizationCostHistory
+	for _, gasUsed := range []uint64{100000, 200000, 400000} {
+		costGwei := 1000 + 30*float64(gasUsed)
+		costs = append(costs, orm.FinalizationCostHistory{GasUsed: gasUsed, L1BaseFee: uint64(costGwei * 1e9 / float64(gasUsed))})
+	}
+	intercept, slope = fitFinalizationCost(costs)
+	assert.InDelta(t, 1000, intercept, 1e-3)
+	assert.InDelta(t, 30, slope, 1e-6)
+}
+
+func TestGasCostPredictorPredictFinalizationCostGwei(t *testing.T) {
+	p := &GasCostPredictor{metrics: initGasCostPredictorMetrics(prometheus.NewRegistry())}
+	assert.Zero(t, p.PredictFinalizationCostGwei(100000))
+
+	p.intercept, p.slope = 1000, 30
+	assert.InDelta(t, 3001000, p.PredictFinalizationCostGwei(100000), 1e-6)
+	assert.InDelta(t, 3001000, testutil.ToFloat64(p.metrics.rollupPredictedFinalizationCostGwei), 1e-6)
+
+	// a negative cost is never predicted.
+	p.intercept, p.slope = -1000, 0
+	assert.Zero(t, p.PredictFinalizationCostGwei(100000))
+}
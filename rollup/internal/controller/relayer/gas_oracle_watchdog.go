@@ -0,0 +1,51 @@
+package relayer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// startWatchdog starts the goroutine reporting the gas oracle as stalled when no block is processed within timeout,
+// it runs until the relayer is stopped.
+func (r *Layer1Relayer) startWatchdog(timeout time.Duration) {
+	r.watchdogKick = make(chan struct{}, 1)
+	go r.runWatchdog(timeout)
+}
+
+// kickWatchdog resets the watchdog timer, it does nothing if the watchdog is disabled.
+func (r *Layer1Relayer) kickWatchdog() {
+	if r.watchdogKick == nil {
+		return
+	}
+	select {
+	case r.watchdogKick <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Layer1Relayer) runWatchdog(timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-r.watchdogKick:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(timeout)
+		case <-timer.C:
+			r.metrics.rollupL1RelayerWatchdogTimeoutsTotal.Inc()
+			log.Error("l1 gas price oracle stalled, no block processed within the watchdog timeout", "timeout", timeout)
+			r.sendAlert("L1GasOracleStalled", fmt.Sprintf("l1 gas oracle processed no block for %v", timeout))
+			if r.cfg.WatchdogExitOnTimeout {
+				log.Error("exiting on l1 gas price oracle watchdog timeout")
+				r.exit(1)
+			}
+			timer.Reset(timeout)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestLayer1RelayerWatchdog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exited := make(chan int, 1)
+	r := &Layer1Relayer{
+		ctx:     ctx,
+		cfg:     &config.RelayerConfig{WatchdogExitOnTimeout: true},
+		metrics: initL1RelayerMetrics(nil),
+		exit:    func(code int) { exited <- code },
+	}
+	timeouts := testutil.ToFloat64(r.metrics.rollupL1RelayerWatchdogTimeoutsTotal)
+	r.startWatchdog(200 * time.Millisecond)
+
+	// the watchdog does not fire while blocks are processed.
+	blocked := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			r.kickWatchdog()
+			time.Sleep(50 * time.Millisecond)
+		}
+		// the oracle loop is blocked.
+		<-blocked
+	}()
+	defer close(blocked)
+
+	select {
+	case <-exited:
+		t.Fatal("watchdog fired while the gas oracle was processing blocks")
+	case <-time.After(400 * time.Millisecond):
+	}
+	assert.Equal(t, timeouts, testutil.ToFloat64(r.metrics.rollupL1RelayerWatchdogTimeoutsTotal))
+
+	select {
+	case code := <-exited:
+		assert.Equal(t, 1, code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchdog did not fire while the gas oracle was blocked")
+	}
+	assert.Equal(t, timeouts+1, testutil.ToFloat64(r.metrics.rollupL1RelayerWatchdogTimeoutsTotal))
+}
+
+func TestLayer1RelayerWatchdogDisabled(t *testing.T) {
+	r := &Layer1Relayer{}
+	// kicking a disabled watchdog does not block.
+	r.kickWatchdog()
+	assert.Nil(t, r.watchdogKick)
+}
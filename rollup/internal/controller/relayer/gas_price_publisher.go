@@ -0,0 +1,117 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+const defaultAMQPDialTimeout = 10 * time.Second
+
+// gasPriceEvent is the message published for each l1 base fee update sent to layer2.
+type gasPriceEvent struct {
+	BlockHash string `json:"block_hash"`
+	BaseFee   uint64 `json:"base_fee"`
+	TxHash    string `json:"tx_hash"`
+}
+
+// gasPricePublisher publishes gas price events to an AMQP exchange.
+// The connection is opened on the first publish and reopened on the next publish after a failure.
+// A nil gasPricePublisher is a no-op, it is returned when AMQP is not configured.
+type gasPricePublisher struct {
+	cfg *config.AMQPConfig
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func newGasPricePublisher(cfg *config.AMQPConfig) *gasPricePublisher {
+	if cfg == nil || cfg.URL == "" {
+		return nil
+	}
+	return &gasPricePublisher{cfg: cfg}
+}
+
+// publish sends event to the configured exchange.
+func (p *gasPricePublisher) publish(ctx context.Context, event *gasPriceEvent) error {
+	if p == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal gas price event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err = p.connect(); err != nil {
+		return err
+	}
+	err = p.channel.PublishWithContext(ctx, p.cfg.Exchange, p.cfg.RoutingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+	if err != nil {
+		// Reconnect on the next publish.
+		p.reset()
+		return fmt.Errorf("failed to publish gas price event to exchange %s: %w", p.cfg.Exchange, err)
+	}
+	return nil
+}
+
+// connect opens the connection and the channel if they are not open, p.mu must be held.
+func (p *gasPricePublisher) connect() error {
+	if p.channel != nil && !p.channel.IsClosed() && !p.conn.IsClosed() {
+		return nil
+	}
+	p.reset()
+
+	dialTimeout := defaultAMQPDialTimeout
+	if p.cfg.DialTimeoutSec > 0 {
+		dialTimeout = time.Duration(p.cfg.DialTimeoutSec) * time.Second
+	}
+	conn, err := amqp.DialConfig(p.cfg.URL, amqp.Config{Dial: amqp.DefaultDial(dialTimeout)})
+	if err != nil {
+		return fmt.Errorf("failed to dial amqp broker: %w", err)
+	}
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to open amqp channel: %w", err)
+	}
+	p.conn, p.channel = conn, channel
+	log.Info("Connected to amqp broker", "exchange", p.cfg.Exchange)
+	return nil
+}
+
+// reset closes the connection if it is open, p.mu must be held.
+func (p *gasPricePublisher) reset() {
+	if p.conn != nil {
+		if err := p.conn.Close(); err != nil && err != amqp.ErrClosed {
+			log.Warn("Failed to close amqp connection", "err", err)
+		}
+	}
+	p.conn, p.channel = nil, nil
+}
+
+// close closes the connection.
+func (p *gasPricePublisher) close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
+}
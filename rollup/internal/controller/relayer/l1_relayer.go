@@ -2,21 +2,35 @@ package relayer
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/database"
 	"scroll-tech/common/types"
 
 	bridgeAbi "scroll-tech/rollup/abi"
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/sender"
 	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/utils"
 )
 
 // Layer1Relayer is responsible for
@@ -26,162 +40,908 @@ import (
 // Actions are triggered by new head from layer 1 geth node.
 // @todo It's better to be triggered by watcher.
 type Layer1Relayer struct {
-	ctx context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	// confirmCtx is used to update the confirmed blocks, it is not canceled by Stop so that in-flight updates complete.
+	confirmCtx    context.Context
+	confirmLoopWg sync.WaitGroup
+
+	// confirmWaiters are the channels of the WaitForConfirmation calls, by the transaction hash returned by the sender.
+	confirmWaitersMu sync.Mutex
+	confirmWaiters   map[common.Hash][]chan *sender.Confirmation
 
 	cfg *config.RelayerConfig
 
-	gasOracleSender *sender.Sender
-	l1GasOracleABI  *abi.ABI
+	// gasOracleMu serializes the gas oracle updates, they are triggered by the admin server too, and guards their state below.
+	gasOracleMu sync.Mutex
+
+	// gasOracleSenders are used in round-robin, each of them has an independent nonce sequence.
+	gasOracleSenders    []*sender.Sender
+	nextGasOracleSender int
+	l1GasOracleABI      *abi.ABI
 
 	lastGasPrice uint64
 	minGasPrice  uint64
 	gasPriceDiff uint64
 
-	l1BlockOrm *orm.L1Block
-	metrics    *l1RelayerMetrics
+	// Updates above maxGasPrice are frozen until spikeExemptionDuration has elapsed since spikeStartedAt.
+	maxGasPrice            uint64
+	spikeExemptionDuration time.Duration
+	spikeStartedAt         time.Time
+	now                    func() time.Time
+
+	// emaAlpha is the weight of the latest base fee in smoothedGasPrice, EMA smoothing is disabled if 0.
+	emaAlpha float64
+	// smoothedGasPrice is the moving average of the base fees up to smoothedBlockNumber, 0 if not started.
+	smoothedGasPrice    float64
+	smoothedBlockNumber uint64
+
+	// percentileWindow is the number of latest blocks whose percentileValue-th percentile base fee is relayed,
+	// the base fee of the latest block is relayed if 0.
+	percentileWindow uint64
+	percentileValue  uint64
+
+	// paused is set to 1 by PauseGasOracle to skip the gas price oracle updates until ResumeGasOracle.
+	paused int32
+
+	// pollJitter is the max random delay added to each gas oracle polling interval.
+	pollJitter time.Duration
+
+	// gasOracleConfigCh delivers hot-reloaded gas oracle configs, only consumed by ProcessGasPriceOracle.
+	gasOracleConfigCh <-chan *config.GasOracleConfig
+
+	// gasPricePublisher publishes the sent base fee updates, nil if AMQP is not configured.
+	gasPricePublisher *gasPricePublisher
+
+	// alertWebhook receives the critical events, nil if no webhook is configured.
+	alertWebhook *alertWebhook
+	// circuitOpenAlerted is set once the open circuit breaker of a sender is alerted, until a transaction is sent again.
+	circuitOpenAlerted bool
+	// dbErrorCycles is the number of consecutive gas oracle cycles failing on db errors.
+	dbErrorCycles int
+	// cycleDBError is the first db error of the running gas oracle cycle, nil if there is none.
+	cycleDBError error
+
+	// watchdogKick resets the watchdog timer after each processed block, nil if the watchdog is disabled.
+	watchdogKick chan struct{}
+	// exit terminates the process when the watchdog fires and watchdog_exit_on_timeout is set.
+	exit func(code int)
+
+	// shadowSender sends the gas oracle transactions to the shadow l2 too, nil if shadow mode is disabled.
+	// shadowMu is held while a shadow transaction is being sent.
+	shadowSender *sender.Sender
+	shadowMu     sync.Mutex
+
+	l1BlockOrm        *orm.L1Block
+	manualOverrideOrm *orm.GasOracleManualOverride
+	// pendingTransactionOrm resolves the transactions replaced by a confirmed one for WaitForConfirmation.
+	pendingTransactionOrm *orm.PendingTransaction
+	metrics               *l1RelayerMetrics
+}
+
+// Layer1RelayerOption configures the optional dependencies of a Layer1Relayer.
+type Layer1RelayerOption func(*layer1RelayerOptions)
+
+type layer1RelayerOptions struct {
+	l1Client      chainHeadReader
+	secretsClient secretValueGetter
+	readReplica   *database.DB
+}
+
+// WithL1Client provides the l1 chain head the stored l1 blocks are reconciled against at startup,
+// it is required if max_allowed_gap_blocks is set.
+func WithL1Client(client *ethclient.Client) Layer1RelayerOption {
+	return func(o *layer1RelayerOptions) {
+		o.l1Client = client
+	}
+}
+
+// WithSecretsManagerClient provides the client gas_oracle_sender_key_secret is read with,
+// a client configured by the standard AWS SDK environment is used by default.
+func WithSecretsManagerClient(client *secretsmanager.Client) Layer1RelayerOption {
+	return func(o *layer1RelayerOptions) {
+		o.secretsClient = client
+	}
+}
+
+// WithL1ReadReplica reconciles the stored l1 blocks at startup against the read replicas of db.
+func WithL1ReadReplica(db *database.DB) Layer1RelayerOption {
+	return func(o *layer1RelayerOptions) {
+		o.readReplica = db
+	}
 }
 
 // NewLayer1Relayer will return a new instance of Layer1RelayerClient
-func NewLayer1Relayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfig, serviceType ServiceType, reg prometheus.Registerer) (*Layer1Relayer, error) {
-	var gasOracleSender *sender.Sender
-	var err error
+func NewLayer1Relayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfig, serviceType ServiceType, reg prometheus.Registerer, opts ...Layer1RelayerOption) (*Layer1Relayer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid l1_relayer config: %w", err)
+	}
+	if err := orm.CheckSchemaVersion(db, orm.ExpectedSchemaVersion); err != nil {
+		return nil, fmt.Errorf("l1_relayer refuses to run: %w", err)
+	}
+
+	var options layer1RelayerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if cfg.MaxAllowedGapBlocks > 0 {
+		if options.l1Client == nil {
+			return nil, fmt.Errorf("an l1 client is required to check the l1 block gaps")
+		}
+		webhook := newAlertWebhook("l1_relayer", cfg.AlertWebhookURL, cfg.AlertWebhookSecret)
+		if err := checkBlockGaps(ctx, "l1", orm.NewL1Block(db, orm.WithReadReplica(options.readReplica)), options.l1Client, cfg.MaxAllowedGapBlocks, webhook); err != nil {
+			return nil, fmt.Errorf("l1_relayer refuses to run: %w", err)
+		}
+	}
+
+	var (
+		gasOracleSenders []*sender.Sender
+		shadowSender     *sender.Sender
+	)
 
 	switch serviceType {
 	case ServiceTypeL1GasOracle:
-		gasOracleSender, err = sender.NewSender(ctx, cfg.SenderConfig, cfg.GasOracleSenderPrivateKey, "l1_relayer", "gas_oracle_sender", types.SenderTypeL1GasOracle, db, reg)
-		if err != nil {
-			addr := crypto.PubkeyToAddress(cfg.GasOracleSenderPrivateKey.PublicKey)
-			return nil, fmt.Errorf("new gas oracle sender failed for address %s, err: %v", addr.Hex(), err)
+		var privateKeys []*ecdsa.PrivateKey
+		if cfg.GasOracleSenderPrivateKey != nil {
+			privateKeys = append(privateKeys, cfg.GasOracleSenderPrivateKey)
+		}
+		if cfg.GasOracleSenderKeySecret != "" {
+			if options.secretsClient == nil {
+				client, err := newSecretsManagerClient(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create the secrets manager client: %w", err)
+				}
+				options.secretsClient = client
+			}
+			privateKey, err := fetchSecretPrivateKey(ctx, options.secretsClient, cfg.GasOracleSenderKeySecret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the gas oracle sender private key: %w", err)
+			}
+			privateKeys = append(privateKeys, privateKey)
 		}
+		privateKeys = append(privateKeys, cfg.GasOracleSenderPrivateKeys...)
+		if len(privateKeys) == 0 {
+			return nil, fmt.Errorf("no gas oracle sender private key configured")
+		}
+
+		for i, privateKey := range privateKeys {
+			// Keep the name of the first sender unchanged for the metrics and pending transactions of existing deployments.
+			name := "gas_oracle_sender"
+			if i > 0 {
+				name = fmt.Sprintf("gas_oracle_sender_%d", i)
+			}
+			gasOracleSender, err := sender.NewSender(ctx, withoutFlashbots(cfg.SenderConfig), privateKey, "l1_relayer", name, types.SenderTypeL1GasOracle, db, reg)
+			if err != nil {
+				addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+				return nil, fmt.Errorf("new gas oracle sender failed for address %s, err: %v", addr.Hex(), err)
+			}
 
-		// Ensure test features aren't enabled on the scroll mainnet.
-		if gasOracleSender.GetChainID().Cmp(big.NewInt(534352)) == 0 && cfg.EnableTestEnvBypassFeatures {
-			return nil, fmt.Errorf("cannot enable test env features in mainnet")
+			// Ensure test features aren't enabled on the scroll mainnet.
+			if gasOracleSender.GetChainID().Cmp(big.NewInt(534352)) == 0 && cfg.EnableTestEnvBypassFeatures {
+				return nil, fmt.Errorf("cannot enable test env features in mainnet")
+			}
+			gasOracleSenders = append(gasOracleSenders, gasOracleSender)
+		}
+		if cfg.ShadowEnabled {
+			shadowSender = newShadowSender(ctx, cfg, privateKeys[0], db, reg)
 		}
 	default:
 		return nil, fmt.Errorf("invalid service type for l1_relayer: %v", serviceType)
 	}
 
-	var minGasPrice uint64
-	var gasPriceDiff uint64
-	if cfg.GasOracleConfig != nil {
-		minGasPrice = cfg.GasOracleConfig.MinGasPrice
-		gasPriceDiff = cfg.GasOracleConfig.GasPriceDiff
-	} else {
-		minGasPrice = 0
-		gasPriceDiff = defaultGasPriceDiff
+	minGasPrice, gasPriceDiff := gasOracleParams(cfg.GasOracleConfig)
+	maxGasPrice, spikeExemptionDuration, err := gasPriceSpikeParams(cfg.GasOracleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas oracle config: %w", err)
+	}
+	emaAlpha, err := emaParams(cfg.GasOracleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas oracle config: %w", err)
+	}
+	percentileWindow, percentileValue, err := percentileParams(cfg.GasOracleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas oracle config: %w", err)
 	}
 
+	relayerCtx, cancel := context.WithCancel(ctx)
 	l1Relayer := &Layer1Relayer{
-		cfg:        cfg,
-		ctx:        ctx,
-		l1BlockOrm: orm.NewL1Block(db),
+		cfg:                   cfg,
+		ctx:                   relayerCtx,
+		cancel:                cancel,
+		confirmCtx:            ctx,
+		l1BlockOrm:            orm.NewL1Block(db),
+		manualOverrideOrm:     orm.NewGasOracleManualOverride(db),
+		pendingTransactionOrm: orm.NewPendingTransaction(db),
 
-		gasOracleSender: gasOracleSender,
-		l1GasOracleABI:  bridgeAbi.L1GasPriceOracleABI,
+		gasOracleSenders: gasOracleSenders,
+		l1GasOracleABI:   bridgeAbi.L1GasPriceOracleABI,
+		shadowSender:     shadowSender,
 
 		minGasPrice:  minGasPrice,
 		gasPriceDiff: gasPriceDiff,
+
+		maxGasPrice:            maxGasPrice,
+		spikeExemptionDuration: spikeExemptionDuration,
+		now:                    time.Now,
+
+		emaAlpha: emaAlpha,
+
+		percentileWindow: percentileWindow,
+		percentileValue:  percentileValue,
+
+		pollJitter: oraclePollJitter(cfg.GasOracleConfig),
+
+		gasPricePublisher: newGasPricePublisher(cfg.AMQPConfig),
+		alertWebhook:      newAlertWebhook("l1_relayer", cfg.AlertWebhookURL, cfg.AlertWebhookSecret),
+
+		exit: os.Exit,
 	}
 
 	l1Relayer.metrics = initL1RelayerMetrics(reg)
+	if cfg.WatchdogTimeoutMinutes > 0 {
+		l1Relayer.startWatchdog(time.Duration(cfg.WatchdogTimeoutMinutes) * time.Minute)
+	}
 
 	switch serviceType {
 	case ServiceTypeL1GasOracle:
-		go l1Relayer.handleL1GasOracleConfirmLoop(ctx)
+		for _, gasOracleSender := range gasOracleSenders {
+			l1Relayer.confirmLoopWg.Add(1)
+			go l1Relayer.handleL1GasOracleConfirmLoop(gasOracleSender)
+		}
+		if shadowSender != nil {
+			l1Relayer.confirmLoopWg.Add(1)
+			go l1Relayer.handleShadowConfirmLoop()
+		}
 	default:
+		cancel()
 		return nil, fmt.Errorf("invalid service type for l1_relayer: %v", serviceType)
 	}
 
 	return l1Relayer, nil
 }
 
-// ProcessGasPriceOracle imports gas price to layer2
-func (r *Layer1Relayer) ProcessGasPriceOracle() {
+// SetConfigWatcher makes the relayer pick up the gas oracle configs delivered by w.
+// It must be called before ProcessGasPriceOracle is started.
+func (r *Layer1Relayer) SetConfigWatcher(w config.ConfigWatcher) {
+	r.gasOracleConfigCh = w.Watch(r.ctx)
+}
+
+// applyGasOracleConfigUpdates applies the latest pending gas oracle config update, if any.
+func (r *Layer1Relayer) applyGasOracleConfigUpdates(ctx context.Context) {
+	logger := utils.Logger(ctx)
+	for {
+		select {
+		case gasOracleConfig, ok := <-r.gasOracleConfigCh:
+			if !ok {
+				r.gasOracleConfigCh = nil
+				return
+			}
+			r.minGasPrice, r.gasPriceDiff = gasOracleParams(gasOracleConfig)
+			maxGasPrice, spikeExemptionDuration, err := gasPriceSpikeParams(gasOracleConfig)
+			if err != nil {
+				logger.Error("Invalid l1 gas oracle spike config, keep the current one", "maxGasPrice", r.maxGasPrice, "spikeExemptionDuration", r.spikeExemptionDuration, "err", err)
+			} else {
+				r.maxGasPrice, r.spikeExemptionDuration = maxGasPrice, spikeExemptionDuration
+			}
+			emaAlpha, err := emaParams(gasOracleConfig)
+			if err != nil {
+				logger.Error("Invalid l1 gas oracle ema config, keep the current one", "emaAlpha", r.emaAlpha, "err", err)
+			} else {
+				if emaAlpha == 0 {
+					r.smoothedGasPrice = 0
+				}
+				r.emaAlpha = emaAlpha
+			}
+			percentileWindow, percentileValue, err := percentileParams(gasOracleConfig)
+			if err != nil {
+				logger.Error("Invalid l1 gas oracle percentile config, keep the current one", "percentileWindow", r.percentileWindow, "percentileValue", r.percentileValue, "err", err)
+			} else {
+				r.percentileWindow, r.percentileValue = percentileWindow, percentileValue
+			}
+			r.pollJitter = oraclePollJitter(gasOracleConfig)
+			logger.Info("Update l1 gas oracle config", "minGasPrice", r.minGasPrice, "gasPriceDiff", r.gasPriceDiff, "maxGasPrice", r.maxGasPrice, "spikeExemptionDuration", r.spikeExemptionDuration, "emaAlpha", r.emaAlpha, "percentileWindow", r.percentileWindow, "percentileValue", r.percentileValue, "pollJitter", r.pollJitter)
+		default:
+			return
+		}
+	}
+}
+
+// GasOraclePollDelay returns the delay before the next ProcessGasPriceOracle run, interval plus a random jitter.
+// The jitter is drawn from crypto/rand so that relayer instances started together do not poll L1 in lockstep.
+func (r *Layer1Relayer) GasOraclePollDelay(interval time.Duration) time.Duration {
+	r.gasOracleMu.Lock()
+	pollJitter := r.pollJitter
+	r.gasOracleMu.Unlock()
+
+	delay := interval
+	if pollJitter > 0 {
+		jitter, err := rand.Int(rand.Reader, big.NewInt(int64(pollJitter)+1))
+		if err != nil {
+			log.Warn("Failed to draw gas oracle poll jitter", "err", err)
+		} else {
+			delay += time.Duration(jitter.Int64())
+		}
+	}
+	r.metrics.rollupL1RelayerOraclePollDelayMs.Observe(float64(delay.Milliseconds()))
+	return delay
+}
+
+// PauseGasOracle stops importing gas prices to layer2 until ResumeGasOracle is called.
+func (r *Layer1Relayer) PauseGasOracle() {
+	if atomic.SwapInt32(&r.paused, 1) == 0 {
+		log.Warn("l1 gas price oracle paused")
+	}
+}
+
+// ResumeGasOracle resumes importing gas prices to layer2 after PauseGasOracle.
+func (r *Layer1Relayer) ResumeGasOracle() {
+	if atomic.SwapInt32(&r.paused, 0) == 1 {
+		log.Info("l1 gas price oracle resumed")
+	}
+}
+
+// isGasOraclePaused returns true if the gas price oracle is paused, counting the skipped update.
+func (r *Layer1Relayer) isGasOraclePaused() bool {
+	if atomic.LoadInt32(&r.paused) == 0 {
+		return false
+	}
+	r.metrics.rollupL1RelayerGasOraclePausedSkipsTotal.Inc()
+	return true
+}
+
+// ProcessGasPriceOracle imports gas price to layer2, each successful run resets the watchdog.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *Layer1Relayer) ProcessGasPriceOracle() error {
+	r.gasOracleMu.Lock()
+	defer r.gasOracleMu.Unlock()
+
+	if err := r.processGasPriceOracle(); err != nil {
+		return err
+	}
+	r.kickWatchdog()
+	return nil
+}
+
+func (r *Layer1Relayer) processGasPriceOracle() error {
+	if r.isGasOraclePaused() {
+		return nil
+	}
+	defer r.endDBErrorCycle()
 	r.metrics.rollupL1RelayerGasPriceOraclerRunTotal.Inc()
-	latestBlockHeight, err := r.l1BlockOrm.GetLatestL1BlockHeight(r.ctx)
+	ctx := utils.WithTrace(r.ctx, utils.NewTraceID())
+	logger := utils.Logger(ctx)
+	r.applyGasOracleConfigUpdates(ctx)
+
+	var latestBlockHeight uint64
+	err := retryWithBackoff(ctx, r.cfg.RetryConfig, "GetLatestL1BlockHeight", func() error {
+		var fetchErr error
+		latestBlockHeight, fetchErr = r.l1BlockOrm.GetLatestL1BlockHeight(ctx)
+		return fetchErr
+	})
+	r.observeDBError(err)
 	if err != nil {
-		log.Warn("Failed to fetch latest L1 block height from db", "err", err)
-		return
+		return newTransientError("failed to fetch latest L1 block height from db: %w", err)
 	}
 
-	blocks, err := r.l1BlockOrm.GetL1Blocks(r.ctx, map[string]interface{}{
-		"number": latestBlockHeight,
+	var (
+		blocks []*orm.L1Block
+		total  int64
+	)
+	err = retryWithBackoff(ctx, r.cfg.RetryConfig, "GetL1BlocksInRange", func() error {
+		var fetchErr error
+		blocks, total, fetchErr = r.l1BlockOrm.GetL1BlocksInRange(ctx, latestBlockHeight, latestBlockHeight, 1, 1)
+		return fetchErr
 	})
+	r.observeDBError(err)
 	if err != nil {
-		log.Error("Failed to GetL1Blocks from db", "height", latestBlockHeight, "err", err)
-		return
+		return newTransientError("failed to GetL1BlocksInRange from db, height: %d: %w", latestBlockHeight, err)
 	}
-	if len(blocks) != 1 {
-		log.Error("Block not exist", "height", latestBlockHeight)
-		return
+	if total != 1 || len(blocks) != 1 {
+		return newPermanentError("block not exist, height: %d", latestBlockHeight)
 	}
 	block := blocks[0]
 
 	if types.GasOracleStatus(block.GasOracleStatus) == types.GasOraclePending {
-		expectedDelta := r.lastGasPrice * r.gasPriceDiff / gasPriceDiffPrecision
-		if r.lastGasPrice > 0 && expectedDelta == 0 {
-			expectedDelta = 1
+		baseFee := block.BaseFee
+		if r.percentileWindow > 0 {
+			if baseFee, err = r.percentileBaseFee(ctx, latestBlockHeight); err != nil {
+				return err
+			}
 		}
-		// last is undefine or (block.BaseFee >= minGasPrice && exceed diff)
-		if r.lastGasPrice == 0 || (block.BaseFee >= r.minGasPrice && (block.BaseFee >= r.lastGasPrice+expectedDelta || block.BaseFee <= r.lastGasPrice-expectedDelta)) {
-			baseFee := big.NewInt(int64(block.BaseFee))
+		if r.isGasPriceSpikeFrozen(baseFee) {
+			logger.Warn("Skip l1 base fee update during gas price spike", "block.Hash", block.Hash, "block.Height", block.Number, "baseFee", baseFee, "maxGasPrice", r.maxGasPrice, "spikeStartedAt", r.spikeStartedAt)
+			return nil
+		}
+		gasPrice := r.smoothGasPrice(block.Number, baseFee)
+		if r.shouldUpdateGasPrice(r.lastGasPrice, gasPrice) {
+			baseFee := big.NewInt(int64(gasPrice))
 			data, err := r.l1GasOracleABI.Pack("setL1BaseFee", baseFee)
 			if err != nil {
-				log.Error("Failed to pack setL1BaseFee", "block.Hash", block.Hash, "block.Height", block.Number, "block.BaseFee", block.BaseFee, "err", err)
-				return
+				return newPermanentError("failed to pack setL1BaseFee, block hash: %s, height: %d, base fee: %d: %w", block.Hash, block.Number, gasPrice, err)
 			}
 
-			hash, err := r.gasOracleSender.SendTransaction(block.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+			if r.cfg.DryRun {
+				r.metrics.rollupL1RelayerGasPriceOracleDryRunTotal.Inc()
+				r.lastGasPrice = gasPrice
+				logger.Info("Dry run, skip sending setL1BaseFee tx to layer2", "block.Hash", block.Hash, "block.Height", block.Number, "baseFee", baseFee, "to", r.cfg.GasPriceOracleContractAddress, "calldata", common.Bytes2Hex(data))
+				return nil
+			}
+
+			r.sendShadowTransaction(block.Hash, data)
+			gasOracleSender := r.gasOracleSenders[r.nextGasOracleSender]
+			r.nextGasOracleSender = (r.nextGasOracleSender + 1) % len(r.gasOracleSenders)
+			hash, err := gasOracleSender.SendTransaction(block.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+			r.observeSendError(err)
 			if err != nil {
-				log.Error("Failed to send setL1BaseFee tx to layer2 ", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
-				return
+				return newTransientError("failed to send setL1BaseFee tx to layer2, block hash: %s, height: %d: %w", block.Hash, block.Number, err)
 			}
 
-			err = r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(r.ctx, block.Hash, types.GasOracleImporting, hash.String())
+			err = r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(ctx, block.Hash, types.GasOracleImporting, hash.String())
+			r.observeDBError(err)
 			if err != nil {
-				log.Error("UpdateGasOracleStatusAndOracleTxHash failed", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
-				return
+				return newTransientError("failed to UpdateL1GasOracleStatusAndOracleTxHash, block hash: %s, height: %d: %w", block.Hash, block.Number, err)
 			}
-			r.lastGasPrice = block.BaseFee
+			r.lastGasPrice = gasPrice
 			r.metrics.rollupL1RelayerLastGasPrice.Set(float64(r.lastGasPrice))
-			log.Info("Update l1 base fee", "txHash", hash.String(), "baseFee", baseFee)
+			logger.Info("Update l1 base fee", "txHash", hash.String(), "baseFee", baseFee)
+			r.publishGasPrice(ctx, block.Hash, gasPrice, hash.String())
 		}
 	}
+	return nil
 }
 
-func (r *Layer1Relayer) handleConfirmation(cfm *sender.Confirmation) {
+// ProcessGasPriceOracleBatch imports the gas prices of at most maxBlocks pending blocks to layer2 in a single multicall transaction.
+// Blocks not exceeding the gas price diff threshold are skipped, the same as in ProcessGasPriceOracle.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *Layer1Relayer) ProcessGasPriceOracleBatch(maxBlocks int) error {
+	r.gasOracleMu.Lock()
+	defer r.gasOracleMu.Unlock()
+
+	if r.isGasOraclePaused() {
+		return nil
+	}
+	defer r.endDBErrorCycle()
+	r.metrics.rollupL1RelayerGasPriceOraclerRunTotal.Inc()
+	ctx := utils.WithTrace(r.ctx, utils.NewTraceID())
+	logger := utils.Logger(ctx)
+	r.applyGasOracleConfigUpdates(ctx)
+
+	if r.cfg.MulticallContractAddress == (common.Address{}) {
+		return newPermanentError("multicall contract address is not configured")
+	}
+
+	blocks, err := r.l1BlockOrm.GetL1BlocksByGasOracleStatus(ctx, types.GasOraclePending, maxBlocks)
+	r.observeDBError(err)
+	if err != nil {
+		return newTransientError("failed to GetL1BlocksByGasOracleStatus from db, limit: %d: %w", maxBlocks, err)
+	}
+
+	lastGasPrice := r.lastGasPrice
+	var calls []bridgeAbi.Multicall3Call3
+	var blockHashes []string
+	var baseFees []uint64
+	for _, block := range blocks {
+		if r.isGasPriceSpikeFrozen(block.BaseFee) {
+			logger.Warn("Skip l1 base fee update during gas price spike", "block.Hash", block.Hash, "block.Height", block.Number, "baseFee", block.BaseFee, "maxGasPrice", r.maxGasPrice, "spikeStartedAt", r.spikeStartedAt)
+			continue
+		}
+		if !r.shouldUpdateGasPrice(lastGasPrice, block.BaseFee) {
+			continue
+		}
+		data, err := r.l1GasOracleABI.Pack("setL1BaseFee", big.NewInt(int64(block.BaseFee)))
+		if err != nil {
+			return newPermanentError("failed to pack setL1BaseFee, block hash: %s, height: %d, base fee: %d: %w", block.Hash, block.Number, block.BaseFee, err)
+		}
+		calls = append(calls, bridgeAbi.Multicall3Call3{
+			Target:   r.cfg.GasPriceOracleContractAddress,
+			CallData: data,
+		})
+		blockHashes = append(blockHashes, block.Hash)
+		baseFees = append(baseFees, block.BaseFee)
+		lastGasPrice = block.BaseFee
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+
+	data, err := bridgeAbi.Multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		return newPermanentError("failed to pack aggregate3, block hashes: %v: %w", blockHashes, err)
+	}
+
+	// The confirmation of the multicall transaction updates all the block hashes in its context ID.
+	contextID := strings.Join(blockHashes, multicallContextIDSeparator)
+	gasOracleSender := r.gasOracleSenders[r.nextGasOracleSender]
+	r.nextGasOracleSender = (r.nextGasOracleSender + 1) % len(r.gasOracleSenders)
+	hash, err := gasOracleSender.SendTransaction(contextID, &r.cfg.MulticallContractAddress, big.NewInt(0), data, 0)
+	r.observeSendError(err)
+	if err != nil {
+		return newTransientError("failed to send multicall setL1BaseFee tx to layer2, block hashes: %v: %w", blockHashes, err)
+	}
+
+	err = r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHashByHashes(ctx, blockHashes, types.GasOracleImporting, hash.String())
+	r.observeDBError(err)
+	if err != nil {
+		return newTransientError("failed to UpdateL1GasOracleStatusAndOracleTxHashByHashes, block hashes: %v: %w", blockHashes, err)
+	}
+	r.lastGasPrice = lastGasPrice
+	r.metrics.rollupL1RelayerLastGasPrice.Set(float64(r.lastGasPrice))
+	logger.Info("Update l1 base fee in batch", "txHash", hash.String(), "blocks", len(blockHashes), "baseFee", r.lastGasPrice)
+	for i, blockHash := range blockHashes {
+		r.publishGasPrice(ctx, blockHash, baseFees[i], hash.String())
+	}
+	return nil
+}
+
+// SetGasPriceManually imports gasPrice to layer2 regardless of the l1 blocks and the diff threshold, for the
+// operators overriding the oracle during l1 emergencies, it is imported even if the gas oracle is paused.
+// The override is recorded with the operator_id of the config.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *Layer1Relayer) SetGasPriceManually(ctx context.Context, gasPrice uint64) error {
+	r.gasOracleMu.Lock()
+	defer r.gasOracleMu.Unlock()
+
+	logger := utils.Logger(ctx)
+	if r.cfg.OperatorID == "" {
+		return newPermanentError("operator_id is not configured, gas price: %d", gasPrice)
+	}
+	if gasPrice > math.MaxInt64 {
+		return newPermanentError("gas price overflows, gas price: %d", gasPrice)
+	}
+
+	data, err := r.l1GasOracleABI.Pack("setL1BaseFee", new(big.Int).SetUint64(gasPrice))
+	if err != nil {
+		return newPermanentError("failed to pack setL1BaseFee, gas price: %d: %w", gasPrice, err)
+	}
+
+	if r.cfg.DryRun {
+		r.metrics.rollupL1RelayerGasPriceOracleDryRunTotal.Inc()
+		r.lastGasPrice = gasPrice
+		logger.Info("Dry run, skip sending manual setL1BaseFee tx to layer2", "operator", r.cfg.OperatorID, "gasPrice", gasPrice, "to", r.cfg.GasPriceOracleContractAddress, "calldata", common.Bytes2Hex(data))
+		return nil
+	}
+
+	contextID := fmt.Sprintf("%s%d", manualOverrideContextIDPrefix, r.now().UnixNano())
+	gasOracleSender := r.gasOracleSenders[r.nextGasOracleSender]
+	r.nextGasOracleSender = (r.nextGasOracleSender + 1) % len(r.gasOracleSenders)
+	hash, err := gasOracleSender.SendTransaction(contextID, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+	r.observeSendError(err)
+	if err != nil {
+		return newTransientError("failed to send manual setL1BaseFee tx to layer2, gas price: %d: %w", gasPrice, err)
+	}
+	r.lastGasPrice = gasPrice
+	r.metrics.rollupL1RelayerLastGasPrice.Set(float64(r.lastGasPrice))
+	logger.Warn("Manually set l1 base fee", "operator", r.cfg.OperatorID, "txHash", hash.String(), "gasPrice", gasPrice)
+
+	// the manual overrides are not gas oracle cycles, their db errors are returned to the operator.
+	err = r.manualOverrideOrm.InsertManualOverride(ctx, r.cfg.OperatorID, gasPrice, hash.String())
+	if err != nil {
+		return newTransientError("failed to record manual gas price override, tx hash: %s: %w", hash.String(), err)
+	}
+	return nil
+}
+
+// publishGasPrice publishes a sent base fee update, failures are only logged as the update is already sent to layer2.
+func (r *Layer1Relayer) publishGasPrice(ctx context.Context, blockHash string, baseFee uint64, txHash string) {
+	event := &gasPriceEvent{BlockHash: blockHash, BaseFee: baseFee, TxHash: txHash}
+	if err := r.gasPricePublisher.publish(ctx, event); err != nil {
+		utils.Logger(ctx).Warn("Failed to publish l1 gas price update", "block hash", blockHash, "tx hash", txHash, "err", err)
+	}
+}
+
+// sendAlert posts a critical event to the alert webhook, failures are only logged.
+func (r *Layer1Relayer) sendAlert(title, body string) {
+	if err := r.alertWebhook.send(r.ctx, title, body); err != nil {
+		log.Warn("Failed to send alert", "title", title, "err", err)
+	}
+}
+
+// observeDBError records the first db error of the running gas oracle cycle.
+func (r *Layer1Relayer) observeDBError(err error) {
+	if err != nil && r.cycleDBError == nil {
+		r.cycleDBError = err
+	}
+}
+
+// endDBErrorCycle ends a gas oracle cycle, it alerts once the cycles keep failing on db errors for more than
+// maxDBErrorCycles cycles. Only a cycle without any db error resets the count.
+func (r *Layer1Relayer) endDBErrorCycle() {
+	err := r.cycleDBError
+	r.cycleDBError = nil
+	if err == nil {
+		r.dbErrorCycles = 0
+		return
+	}
+	r.dbErrorCycles++
+	if r.dbErrorCycles == maxDBErrorCycles+1 {
+		r.sendAlert("L1GasOracleDBError", fmt.Sprintf("l1 gas oracle failed on db errors for %d consecutive cycles: %v", r.dbErrorCycles, err))
+	}
+}
+
+// observeSendError alerts once when a gas oracle sender rejects the transactions because its circuit breaker is open.
+func (r *Layer1Relayer) observeSendError(err error) {
+	if err == nil {
+		r.circuitOpenAlerted = false
+		return
+	}
+	if errors.Is(err, sender.ErrCircuitOpen) && !r.circuitOpenAlerted {
+		r.circuitOpenAlerted = true
+		r.sendAlert("L1GasOracleCircuitOpen", fmt.Sprintf("l1 gas oracle sender circuit breaker is open: %v", err))
+	}
+}
+
+// shouldUpdateGasPrice returns true if lastGasPrice is undefined, or baseFee is no less than minGasPrice and exceeds the diff threshold.
+func (r *Layer1Relayer) shouldUpdateGasPrice(lastGasPrice, baseFee uint64) bool {
+	if lastGasPrice == 0 {
+		return true
+	}
+	expectedDelta := lastGasPrice * r.gasPriceDiff / gasPriceDiffPrecision
+	if expectedDelta == 0 {
+		expectedDelta = 1
+	}
+	return baseFee >= r.minGasPrice && (baseFee >= lastGasPrice+expectedDelta || baseFee <= lastGasPrice-expectedDelta)
+}
+
+// smoothGasPrice returns the exponential moving average of the base fees if EMA smoothing is enabled, baseFee otherwise.
+// The average is updated once per block, as the latest block is polled until its gas oracle is imported.
+func (r *Layer1Relayer) smoothGasPrice(blockNumber, baseFee uint64) uint64 {
+	if r.emaAlpha == 0 {
+		return baseFee
+	}
+	if r.smoothedGasPrice == 0 {
+		r.smoothedGasPrice = float64(baseFee)
+	} else if blockNumber != r.smoothedBlockNumber {
+		r.smoothedGasPrice = r.emaAlpha*float64(baseFee) + (1-r.emaAlpha)*r.smoothedGasPrice
+	}
+	r.smoothedBlockNumber = blockNumber
+	r.metrics.rollupL1RelayerGasPriceEMA.Set(r.smoothedGasPrice)
+	return uint64(math.Round(r.smoothedGasPrice))
+}
+
+// percentileBaseFee returns the percentileValue-th percentile of the base fees of the last percentileWindow blocks up to latestBlockHeight.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *Layer1Relayer) percentileBaseFee(ctx context.Context, latestBlockHeight uint64) (uint64, error) {
+	var startHeight uint64
+	if latestBlockHeight+1 > r.percentileWindow {
+		startHeight = latestBlockHeight + 1 - r.percentileWindow
+	}
+
+	var blocks []*orm.L1Block
+	err := retryWithBackoff(ctx, r.cfg.RetryConfig, "GetL1BlocksInRange", func() error {
+		var fetchErr error
+		blocks, _, fetchErr = r.l1BlockOrm.GetL1BlocksInRange(ctx, startHeight, latestBlockHeight, 1, int(r.percentileWindow))
+		return fetchErr
+	})
+	r.observeDBError(err)
+	if err != nil {
+		return 0, newTransientError("failed to GetL1BlocksInRange from db, start height: %d, end height: %d: %w", startHeight, latestBlockHeight, err)
+	}
+	if len(blocks) == 0 {
+		return 0, newPermanentError("no block in range, start height: %d, end height: %d", startHeight, latestBlockHeight)
+	}
+
+	baseFees := make([]uint64, 0, len(blocks))
+	for _, block := range blocks {
+		baseFees = append(baseFees, block.BaseFee)
+	}
+	baseFee := percentile(baseFees, r.percentileValue)
+	r.metrics.rollupL1RelayerGasPricePercentile.Set(float64(baseFee))
+	return baseFee, nil
+}
+
+// isGasPriceSpikeFrozen returns true if the update of baseFee is frozen as a gas price spike.
+// A spike starts when baseFee exceeds maxGasPrice, the freeze lifts after spikeExemptionDuration even if the spike lasts.
+// lastGasPrice is left untouched while frozen, so that the diff threshold is checked against the last accepted price once the spike recedes.
+func (r *Layer1Relayer) isGasPriceSpikeFrozen(baseFee uint64) bool {
+	if r.maxGasPrice == 0 || baseFee <= r.maxGasPrice {
+		r.spikeStartedAt = time.Time{}
+		return false
+	}
+
+	now := r.now()
+	if r.spikeStartedAt.IsZero() {
+		r.spikeStartedAt = now
+	}
+	if now.Sub(r.spikeStartedAt) >= r.spikeExemptionDuration {
+		return false
+	}
+	r.metrics.rollupL1RelayerGasPriceSpikeSkippedTotal.Inc()
+	return true
+}
+
+// HealthCheck checks the health of all the gas oracle senders.
+func (r *Layer1Relayer) HealthCheck(ctx context.Context) error {
+	for _, gasOracleSender := range r.gasOracleSenders {
+		if err := gasOracleSender.HealthCheck(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop cancels the relayer context, then handles the confirmations left in the gas oracle senders' confirmation channels,
+// so that the blocks of the in-flight gas oracle transactions are updated before the process exits.
+// It gives up after confirmationDrainTimeout.
+func (r *Layer1Relayer) Stop() {
+	r.cancel()
+	defer r.gasPricePublisher.close()
+
+	ctx, cancel := context.WithTimeout(r.confirmCtx, confirmationDrainTimeout)
+	defer cancel()
+
+	// Wait for the confirmations being handled by the confirm loops.
+	loopsDone := make(chan struct{})
+	go func() {
+		r.confirmLoopWg.Wait()
+		close(loopsDone)
+	}()
+	select {
+	case <-loopsDone:
+	case <-ctx.Done():
+		log.Warn("Timed out waiting for the l1 gas oracle confirm loops to stop")
+		return
+	}
+
+	drained := 0
+	for _, gasOracleSender := range r.gasOracleSenders {
+	drain:
+		for {
+			select {
+			case <-ctx.Done():
+				log.Warn("Timed out draining l1 gas oracle confirmations", "drained", drained)
+				return
+			case cfm := <-gasOracleSender.ConfirmChan():
+				if err := r.handleConfirmation(ctx, cfm); err != nil {
+					LogError("Failed to handle l1 gas oracle confirmation", err, "confirmation", cfm)
+				}
+				r.notifyConfirmWaiters(ctx, cfm)
+				drained++
+			default:
+				break drain
+			}
+		}
+	}
+	log.Info("Layer1Relayer stopped", "drained confirmations", drained)
+}
+
+func (r *Layer1Relayer) handleConfirmation(ctx context.Context, cfm *sender.Confirmation) error {
+	logger := utils.Logger(ctx)
 	switch cfm.SenderType {
 	case types.SenderTypeL1GasOracle:
+		if strings.HasPrefix(cfm.ContextID, manualOverrideContextIDPrefix) {
+			if !cfm.IsSuccessful {
+				r.metrics.rollupL1UpdateGasOracleConfirmedFailedTotal.Inc()
+				logger.Warn("Manual setL1BaseFee transaction confirmed but failed in layer2", "confirmation", cfm)
+				r.sendAlert("L1GasOracleImportFailed", fmt.Sprintf("manual setL1BaseFee transaction %s failed in layer2, context ID: %s", cfm.TxHash.String(), cfm.ContextID))
+				return nil
+			}
+			r.metrics.rollupL1UpdateGasOracleConfirmedTotal.Inc()
+			break
+		}
+
 		var status types.GasOracleStatus
 		if cfm.IsSuccessful {
 			status = types.GasOracleImported
 			r.metrics.rollupL1UpdateGasOracleConfirmedTotal.Inc()
-			log.Info("UpdateGasOracleTxType transaction confirmed in layer2", "confirmation", cfm)
+			logger.Info("UpdateGasOracleTxType transaction confirmed in layer2", "confirmation", cfm)
 		} else {
 			status = types.GasOracleImportedFailed
 			r.metrics.rollupL1UpdateGasOracleConfirmedFailedTotal.Inc()
-			log.Warn("UpdateGasOracleTxType transaction confirmed but failed in layer2", "confirmation", cfm)
+			logger.Warn("UpdateGasOracleTxType transaction confirmed but failed in layer2", "confirmation", cfm)
+			r.sendAlert("L1GasOracleImportFailed", fmt.Sprintf("setL1BaseFee transaction %s failed in layer2, context ID: %s", cfm.TxHash.String(), cfm.ContextID))
 		}
 
-		err := r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(r.ctx, cfm.ContextID, status, cfm.TxHash.String())
+		blockHashes := strings.Split(cfm.ContextID, multicallContextIDSeparator)
+		err := r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHashByHashes(ctx, blockHashes, status, cfm.TxHash.String())
 		if err != nil {
-			log.Warn("UpdateL1GasOracleStatusAndOracleTxHash failed", "confirmation", cfm, "err", err)
+			return newTransientError("failed to UpdateL1GasOracleStatusAndOracleTxHashByHashes, context ID: %s: %w", cfm.ContextID, err)
+		}
+		if cfm.IsSuccessful {
+			r.observePropagationLatency(ctx, blockHashes)
 		}
 	default:
-		log.Warn("Unknown transaction type", "confirmation", cfm)
+		return newPermanentError("unknown transaction type: %v, context ID: %s", cfm.SenderType, cfm.ContextID)
 	}
 
-	log.Info("Transaction confirmed in layer2", "confirmation", cfm)
+	logger.Info("Transaction confirmed in layer2", "confirmation", cfm)
+	return nil
+}
+
+// observePropagationLatency records the time elapsed since the given blocks were stored, once their gas oracle is imported.
+func (r *Layer1Relayer) observePropagationLatency(ctx context.Context, blockHashes []string) {
+	blocks, err := r.l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{"hash": blockHashes})
+	if err != nil {
+		utils.Logger(ctx).Warn("Failed to get l1 blocks for the gas oracle propagation latency", "block hashes", blockHashes, "err", err)
+		return
+	}
+	for _, block := range blocks {
+		latency := r.now().Sub(block.CreatedAt).Seconds()
+		r.metrics.rollupL1GasOraclePropagationLatencySeconds.Observe(latency)
+		r.metrics.rollupL1GasOracleLastPropagationLatency.Set(latency)
+	}
 }
 
-func (r *Layer1Relayer) handleL1GasOracleConfirmLoop(ctx context.Context) {
+func (r *Layer1Relayer) handleL1GasOracleConfirmLoop(gasOracleSender *sender.Sender) {
+	defer r.confirmLoopWg.Done()
 	for {
 		select {
-		case <-ctx.Done():
+		case <-r.ctx.Done():
 			return
-		case cfm := <-r.gasOracleSender.ConfirmChan():
-			r.handleConfirmation(cfm)
+		case cfm := <-gasOracleSender.ConfirmChan():
+			if err := r.handleConfirmation(r.confirmCtx, cfm); err != nil {
+				LogError("Failed to handle l1 gas oracle confirmation", err, "confirmation", cfm)
+			}
+			r.notifyConfirmWaiters(r.confirmCtx, cfm)
+		}
+	}
+}
+
+// WaitForConfirmation blocks until the gas oracle transaction of txHash, or a transaction replacing it with a higher fee,
+// is confirmed and handled, or ctx expires.
+// The confirmations handled before the call are not seen, so it must be called before the transaction confirms.
+func (r *Layer1Relayer) WaitForConfirmation(ctx context.Context, txHash common.Hash) (*sender.Confirmation, error) {
+	ch := make(chan *sender.Confirmation, 1)
+	r.confirmWaitersMu.Lock()
+	if r.confirmWaiters == nil {
+		r.confirmWaiters = make(map[common.Hash][]chan *sender.Confirmation)
+	}
+	r.confirmWaiters[txHash] = append(r.confirmWaiters[txHash], ch)
+	r.confirmWaitersMu.Unlock()
+
+	select {
+	case cfm := <-ch:
+		return cfm, nil
+	case <-ctx.Done():
+		r.removeConfirmWaiter(txHash, ch)
+		return nil, fmt.Errorf("failed to wait for confirmation, tx hash: %s: %w", txHash.String(), ctx.Err())
+	}
+}
+
+func (r *Layer1Relayer) removeConfirmWaiter(txHash common.Hash, ch chan *sender.Confirmation) {
+	r.confirmWaitersMu.Lock()
+	defer r.confirmWaitersMu.Unlock()
+	waiters := r.confirmWaiters[txHash]
+	for i, waiter := range waiters {
+		if waiter == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
 		}
 	}
+	if len(waiters) == 0 {
+		delete(r.confirmWaiters, txHash)
+	} else {
+		r.confirmWaiters[txHash] = waiters
+	}
+}
+
+// notifyConfirmWaiters delivers the confirmation to the WaitForConfirmation calls of its transaction and of the
+// transactions it replaced.
+func (r *Layer1Relayer) notifyConfirmWaiters(ctx context.Context, cfm *sender.Confirmation) {
+	r.confirmWaitersMu.Lock()
+	hasWaiters := len(r.confirmWaiters) > 0
+	r.confirmWaitersMu.Unlock()
+	if !hasWaiters {
+		return
+	}
+
+	hashes := []common.Hash{cfm.TxHash}
+	replaced, err := r.pendingTransactionOrm.GetReplacedTransactionHashes(ctx, cfm.TxHash)
+	if err != nil {
+		// the waiters of the replaced transactions time out.
+		log.Warn("Failed to get the transactions replaced by the confirmed one", "confirmation", cfm, "err", err)
+	}
+	hashes = append(hashes, replaced...)
+
+	var waiters []chan *sender.Confirmation
+	r.confirmWaitersMu.Lock()
+	for _, hash := range hashes {
+		waiters = append(waiters, r.confirmWaiters[hash]...)
+		delete(r.confirmWaiters, hash)
+	}
+	r.confirmWaitersMu.Unlock()
+
+	// the channels are buffered and each of them receives a single confirmation.
+	for _, ch := range waiters {
+		ch <- cfm
+	}
 }
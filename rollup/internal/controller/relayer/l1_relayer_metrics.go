@@ -12,6 +12,15 @@ type l1RelayerMetrics struct {
 	rollupL1RelayerLastGasPrice                 prometheus.Gauge
 	rollupL1UpdateGasOracleConfirmedTotal       prometheus.Counter
 	rollupL1UpdateGasOracleConfirmedFailedTotal prometheus.Counter
+	rollupL1RelayerGasPriceOracleDryRunTotal    prometheus.Counter
+	rollupL1RelayerGasPriceSpikeSkippedTotal    prometheus.Counter
+	rollupL1RelayerOraclePollDelayMs            prometheus.Histogram
+	rollupL1GasOraclePropagationLatencySeconds  prometheus.Histogram
+	rollupL1GasOracleLastPropagationLatency     prometheus.Gauge
+	rollupL1RelayerGasPriceEMA                  prometheus.Gauge
+	rollupL1RelayerGasPricePercentile           prometheus.Gauge
+	rollupL1RelayerGasOraclePausedSkipsTotal    prometheus.Counter
+	rollupL1RelayerWatchdogTimeoutsTotal        prometheus.Counter
 }
 
 var (
@@ -38,6 +47,44 @@ func initL1RelayerMetrics(reg prometheus.Registerer) *l1RelayerMetrics {
 				Name: "rollup_layer1_update_gas_oracle_confirmed_failed_total",
 				Help: "The total number of updating layer1 gas oracle confirmed failed",
 			}),
+			rollupL1RelayerGasPriceOracleDryRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer1_gas_price_oracle_dry_run_total",
+				Help: "The total number of layer1 gas price oracle updates skipped in dry run mode",
+			}),
+			rollupL1RelayerGasPriceSpikeSkippedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer1_gas_price_spike_skipped_total",
+				Help: "The total number of layer1 gas price oracle updates skipped during a gas price spike",
+			}),
+			rollupL1RelayerOraclePollDelayMs: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+				Name:    "rollup_layer1_gas_oracle_poll_delay_ms",
+				Help:    "The delay in milliseconds between layer1 gas price oracle runs, including the jitter",
+				Buckets: prometheus.ExponentialBuckets(1000, 1.5, 10),
+			}),
+			rollupL1GasOraclePropagationLatencySeconds: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+				Name:    "rollup_layer1_gas_oracle_propagation_latency_seconds",
+				Help:    "The latency in seconds between storing a layer1 block and its gas oracle being imported to layer2",
+				Buckets: prometheus.ExponentialBucketsRange(1, 600, 10),
+			}),
+			rollupL1GasOracleLastPropagationLatency: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_layer1_gas_oracle_last_propagation_latency_seconds",
+				Help: "The latency in seconds between storing a layer1 block and its gas oracle being imported to layer2, of the latest imported block",
+			}),
+			rollupL1RelayerGasPriceEMA: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_layer1_gas_price_ema",
+				Help: "The exponential moving average of the layer1 base fee used by the gas price oracle",
+			}),
+			rollupL1RelayerGasPricePercentile: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_layer1_gas_price_percentile",
+				Help: "The percentile of the base fees of the latest layer1 blocks used by the gas price oracle",
+			}),
+			rollupL1RelayerGasOraclePausedSkipsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer1_gas_oracle_paused_skips_total",
+				Help: "The total number of layer1 gas price oracle runs skipped while the gas oracle is paused",
+			}),
+			rollupL1RelayerWatchdogTimeoutsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer1_gas_oracle_watchdog_timeouts_total",
+				Help: "The total number of times the layer1 gas price oracle processed no block within the watchdog timeout",
+			}),
 		}
 	})
 	return l1RelayerMetric
@@ -0,0 +1,73 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/sender"
+)
+
+// newShadowSender returns the sender of the gas oracle transactions to the shadow l2, nil if it cannot be created,
+// so that the gas oracle runs without the shadow l2 rather than not at all.
+// It has the same key as the primary senders, its pending transactions are stored under its own sender type so that
+// the senders do not resubmit nor fail the transactions of the other chain.
+func newShadowSender(ctx context.Context, cfg *config.RelayerConfig, privateKey *ecdsa.PrivateKey, db *gorm.DB, reg prometheus.Registerer) *sender.Sender {
+	shadowCfg := *cfg.SenderConfig
+	shadowCfg.Endpoint = cfg.ShadowL2Endpoint
+	// the standby endpoint and the flashbots relay are of the primary chain.
+	shadowCfg.StandbyEndpoint = ""
+	shadowCfg.FlashbotsRelayURL = ""
+	shadowSender, err := sender.NewSender(ctx, &shadowCfg, privateKey, "l1_relayer_shadow", "gas_oracle_shadow_sender", types.SenderTypeL1GasOracleShadow, db, reg)
+	if err != nil {
+		log.Error("failed to create the shadow gas oracle sender, shadow mode is disabled", "endpoint", cfg.ShadowL2Endpoint, "err", err)
+		return nil
+	}
+	return shadowSender
+}
+
+// sendShadowTransaction sends the calldata of a gas oracle transaction to the shadow l2 in the background, the result
+// is only logged. A transaction is skipped while the previous one is still being sent, so that a slow shadow l2
+// does not pile up goroutines.
+func (r *Layer1Relayer) sendShadowTransaction(contextID string, data []byte) {
+	if r.shadowSender == nil {
+		return
+	}
+	if !r.shadowMu.TryLock() {
+		log.Warn("Skip shadow setL1BaseFee tx, the previous one is still being sent", "contextID", contextID)
+		return
+	}
+	go func() {
+		defer r.shadowMu.Unlock()
+		hash, err := r.shadowSender.SendTransaction(contextID, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+		if err != nil {
+			log.Warn("Failed to send shadow setL1BaseFee tx", "contextID", contextID, "err", err)
+			return
+		}
+		log.Info("Sent shadow setL1BaseFee tx", "contextID", contextID, "txHash", hash.String())
+	}()
+}
+
+// handleShadowConfirmLoop logs the confirmations of the shadow transactions, they do not update the l1 blocks.
+func (r *Layer1Relayer) handleShadowConfirmLoop() {
+	defer r.confirmLoopWg.Done()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case cfm := <-r.shadowSender.ConfirmChan():
+			if cfm.IsSuccessful {
+				log.Info("Shadow setL1BaseFee tx confirmed", "confirmation", cfm)
+			} else {
+				log.Warn("Shadow setL1BaseFee tx confirmed but failed", "confirmation", cfm)
+			}
+		}
+	}
+}
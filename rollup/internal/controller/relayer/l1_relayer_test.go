@@ -3,10 +3,19 @@ package relayer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
@@ -18,8 +27,13 @@ import (
 
 	"scroll-tech/database/migrate"
 
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/admin"
 	"scroll-tech/rollup/internal/controller/sender"
+	"scroll-tech/rollup/internal/controller/watcher"
 	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/mock_bridge"
 )
 
 func setupL1RelayerDB(t *testing.T) *gorm.DB {
@@ -60,12 +74,12 @@ func testL1RelayerGasOracleConfirm(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Simulate message confirmations.
-	l1Relayer.gasOracleSender.SendConfirmation(&sender.Confirmation{
+	l1Relayer.gasOracleSenders[0].SendConfirmation(&sender.Confirmation{
 		ContextID:    "gas-oracle-1",
 		IsSuccessful: true,
 		SenderType:   types.SenderTypeL1GasOracle,
 	})
-	l1Relayer.gasOracleSender.SendConfirmation(&sender.Confirmation{
+	l1Relayer.gasOracleSenders[0].SendConfirmation(&sender.Confirmation{
 		ContextID:    "gas-oracle-2",
 		IsSuccessful: false,
 		SenderType:   types.SenderTypeL1GasOracle,
@@ -81,6 +95,97 @@ func testL1RelayerGasOracleConfirm(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func testL1RelayerStop(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+	l1BlockOrm := orm.NewL1Block(db)
+
+	const numBlocks = 10
+	var l1Blocks []orm.L1Block
+	for i := 0; i < numBlocks; i++ {
+		l1Blocks = append(l1Blocks, orm.L1Block{Hash: fmt.Sprintf("gas-oracle-stop-%d", i), Number: uint64(i), GasOracleStatus: int16(types.GasOracleImporting)})
+	}
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), l1Blocks))
+
+	l1Relayer, err := NewLayer1Relayer(context.Background(), db, cfg.L1Config.RelayerConfig, ServiceTypeL1GasOracle, nil)
+	assert.NoError(t, err)
+	// Pretend the blocks were stored a minute ago.
+	l1Relayer.now = func() time.Time { return time.Now().Add(time.Minute) }
+
+	for _, block := range l1Blocks {
+		l1Relayer.gasOracleSenders[0].SendConfirmation(&sender.Confirmation{
+			ContextID:    block.Hash,
+			IsSuccessful: true,
+			SenderType:   types.SenderTypeL1GasOracle,
+		})
+	}
+	// All the confirmations are handled once Stop returns.
+	l1Relayer.Stop()
+
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"oracle_status": types.GasOracleImported})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, numBlocks)
+	assert.Empty(t, l1Relayer.gasOracleSenders[0].ConfirmChan())
+
+	latency := testutil.ToFloat64(l1Relayer.metrics.rollupL1GasOracleLastPropagationLatency)
+	assert.GreaterOrEqual(t, latency, time.Minute.Seconds())
+	assert.Less(t, latency, 2*time.Minute.Seconds())
+}
+
+func testL1RelayerConcurrentConfirm(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+	l1BlockOrm := orm.NewL1Block(db)
+
+	const numBlocks = 20
+	var l1Blocks []orm.L1Block
+	for i := 0; i < numBlocks; i++ {
+		l1Blocks = append(l1Blocks, orm.L1Block{Hash: fmt.Sprintf("gas-oracle-concurrent-%d", i), Number: uint64(i), GasOracleStatus: int16(types.GasOracleImporting)})
+	}
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), l1Blocks))
+
+	// Two relayers sharing the db, as after a restart, confirm the same blocks with conflicting results.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relayer1, err := NewLayer1Relayer(ctx, db, cfg.L1Config.RelayerConfig, ServiceTypeL1GasOracle, nil)
+	assert.NoError(t, err)
+	relayer2, err := NewLayer1Relayer(ctx, db, cfg.L1Config.RelayerConfig, ServiceTypeL1GasOracle, nil)
+	assert.NoError(t, err)
+
+	txHash1, txHash2 := common.HexToHash("0x01"), common.HexToHash("0x02")
+	for _, block := range l1Blocks {
+		relayer1.gasOracleSenders[0].SendConfirmation(&sender.Confirmation{
+			ContextID:    block.Hash,
+			IsSuccessful: true,
+			TxHash:       txHash1,
+			SenderType:   types.SenderTypeL1GasOracle,
+		})
+		relayer2.gasOracleSenders[0].SendConfirmation(&sender.Confirmation{
+			ContextID:    block.Hash,
+			IsSuccessful: false,
+			TxHash:       txHash2,
+			SenderType:   types.SenderTypeL1GasOracle,
+		})
+	}
+	relayer1.Stop()
+	relayer2.Stop()
+
+	// Each block holds the status and the tx hash of the same confirmation.
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, numBlocks)
+	for _, block := range blocks {
+		switch types.GasOracleStatus(block.GasOracleStatus) {
+		case types.GasOracleImported:
+			assert.Equal(t, txHash1.String(), block.OracleTxHash, "block hash: %s", block.Hash)
+		case types.GasOracleImportedFailed:
+			assert.Equal(t, txHash2.String(), block.OracleTxHash, "block hash: %s", block.Hash)
+		default:
+			t.Errorf("unexpected gas oracle status %d of block %s", block.GasOracleStatus, block.Hash)
+		}
+	}
+}
+
 func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 	db := setupL1RelayerDB(t)
 	defer database.CloseDB(db)
@@ -99,7 +204,7 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 			return 0, targetErr
 		})
 		defer patchGuard.Reset()
-		l1Relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, l1Relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	patchGuard := gomonkey.ApplyMethodFunc(l1BlockOrm, "GetLatestL1BlockHeight", func(ctx context.Context) (uint64, error) {
@@ -107,34 +212,30 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 	})
 	defer patchGuard.Reset()
 
-	convey.Convey("GetL1Blocks failure", t, func() {
-		targetErr := errors.New("GetL1Blocks error")
-		patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1Blocks", func(ctx context.Context, fields map[string]interface{}) ([]orm.L1Block, error) {
-			return nil, targetErr
+	convey.Convey("GetL1BlocksInRange failure", t, func() {
+		targetErr := errors.New("GetL1BlocksInRange error")
+		patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, startHeight, endHeight uint64, page, pageSize int) ([]*orm.L1Block, int64, error) {
+			return nil, 0, targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, l1Relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	convey.Convey("Block not exist", t, func() {
-		patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1Blocks", func(ctx context.Context, fields map[string]interface{}) ([]orm.L1Block, error) {
-			tmpInfo := []orm.L1Block{
-				{Hash: "gas-oracle-1", Number: 0},
-				{Hash: "gas-oracle-2", Number: 1},
-			}
-			return tmpInfo, nil
+		patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, startHeight, endHeight uint64, page, pageSize int) ([]*orm.L1Block, int64, error) {
+			return nil, 0, nil
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, l1Relayer.ProcessGasPriceOracle(), ErrPermanent)
 	})
 
-	patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1Blocks", func(ctx context.Context, fields map[string]interface{}) ([]orm.L1Block, error) {
-		tmpInfo := []orm.L1Block{
+	patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, startHeight, endHeight uint64, page, pageSize int) ([]*orm.L1Block, int64, error) {
+		tmpInfo := []*orm.L1Block{
 			{
 				Hash:            "gas-oracle-1",
 				Number:          0,
 				GasOracleStatus: int16(types.GasOraclePending),
 			},
 		}
-		return tmpInfo, nil
+		return tmpInfo, 1, nil
 	})
 
 	convey.Convey("setL1BaseFee failure", t, func() {
@@ -142,7 +243,7 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(l1Relayer.l1GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
 			return nil, targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, l1Relayer.ProcessGasPriceOracle(), ErrPermanent)
 	})
 
 	patchGuard.ApplyMethodFunc(l1Relayer.l1GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
@@ -151,13 +252,13 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 
 	convey.Convey("send transaction failure", t, func() {
 		targetErr := errors.New("send transaction failure")
-		patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
+		patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSenders[0], "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
 			return common.Hash{}, targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, l1Relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
-	patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
+	patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSenders[0], "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
 		return common.Hash{}, nil
 	})
 
@@ -166,12 +267,851 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(context.Context, string, types.GasOracleStatus, string) error {
 			return targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, l1Relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	patchGuard.ApplyMethodFunc(l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(context.Context, string, types.GasOracleStatus, string) error {
 		return nil
 	})
 
-	l1Relayer.ProcessGasPriceOracle()
+	assert.NoError(t, l1Relayer.ProcessGasPriceOracle())
+
+	convey.Convey("dry run skips sending transaction", t, func() {
+		l1Relayer.cfg.DryRun = true
+		defer func() { l1Relayer.cfg.DryRun = false }()
+		l1Relayer.lastGasPrice = 0
+
+		var sendCalled, updateCalled bool
+		patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSenders[0], "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
+			sendCalled = true
+			return common.Hash{}, nil
+		})
+		patchGuard.ApplyMethodFunc(l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(context.Context, string, types.GasOracleStatus, string) error {
+			updateCalled = true
+			return nil
+		})
+		assert.NoError(t, l1Relayer.ProcessGasPriceOracle())
+		assert.False(t, sendCalled)
+		assert.False(t, updateCalled)
+	})
+}
+
+func testL1RelayerProcessGasPriceOracleBatch(t *testing.T) {
+	blocks := []orm.L1Block{
+		{Number: 1, Hash: "gas-oracle-1", BaseFee: 100},
+		{Number: 2, Hash: "gas-oracle-2", BaseFee: 101}, // within the default 5% diff threshold, skipped
+		{Number: 3, Hash: "gas-oracle-3", BaseFee: 200},
+	}
+	multicallAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	txHash := common.HexToHash("0x56789abcdef1234")
+
+	// runRelayer processes the blocks, confirms all the sent transactions and returns the final state.
+	runRelayer := func(process func(*Layer1Relayer, *orm.L1Block)) (uint64, []orm.L1Block, []*common.Address) {
+		db := setupL1RelayerDB(t)
+		defer database.CloseDB(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		relayerCfg := *cfg.L1Config.RelayerConfig
+		relayerCfg.MulticallContractAddress = multicallAddress
+		relayerCfg.GasOracleConfig = nil
+		l1Relayer, err := NewLayer1Relayer(ctx, db, &relayerCfg, ServiceTypeL1GasOracle, nil)
+		assert.NoError(t, err)
+
+		var contextIDs []string
+		var targets []*common.Address
+		patchGuard := gomonkey.ApplyMethodFunc(l1Relayer.gasOracleSenders[0], "SendTransaction", func(contextID string, target *common.Address, _ *big.Int, _ []byte, _ uint64) (common.Hash, error) {
+			contextIDs = append(contextIDs, contextID)
+			targets = append(targets, target)
+			return txHash, nil
+		})
+		defer patchGuard.Reset()
+
+		l1BlockOrm := orm.NewL1Block(db)
+		process(l1Relayer, l1BlockOrm)
+
+		for _, contextID := range contextIDs {
+			l1Relayer.gasOracleSenders[0].SendConfirmation(&sender.Confirmation{
+				ContextID:    contextID,
+				IsSuccessful: true,
+				TxHash:       txHash,
+				SenderType:   types.SenderTypeL1GasOracle,
+			})
+		}
+		ok := utils.TryTimes(5, func() bool {
+			importing, err := l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{"oracle_status": int(types.GasOracleImporting)})
+			return err == nil && len(importing) == 0
+		})
+		assert.True(t, ok)
+
+		dbBlocks, err := l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{})
+		assert.NoError(t, err)
+		return l1Relayer.lastGasPrice, dbBlocks, targets
+	}
+
+	// individual-call path: every block is processed when it is the latest one.
+	individualGasPrice, individualBlocks, individualTargets := runRelayer(func(l1Relayer *Layer1Relayer, l1BlockOrm *orm.L1Block) {
+		for _, block := range blocks {
+			assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []orm.L1Block{block}))
+			assert.NoError(t, l1Relayer.ProcessGasPriceOracle())
+		}
+	})
+	assert.Len(t, individualTargets, 2)
+
+	// multicall path: all the pending blocks are processed in one transaction.
+	batchGasPrice, batchBlocks, batchTargets := runRelayer(func(l1Relayer *Layer1Relayer, l1BlockOrm *orm.L1Block) {
+		assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), blocks))
+		assert.NoError(t, l1Relayer.ProcessGasPriceOracleBatch(10))
+	})
+	assert.Len(t, batchTargets, 1)
+	assert.Equal(t, multicallAddress, *batchTargets[0])
+
+	assert.Equal(t, uint64(200), batchGasPrice)
+	assert.Equal(t, individualGasPrice, batchGasPrice)
+	assert.Len(t, batchBlocks, len(blocks))
+	assert.Len(t, individualBlocks, len(blocks))
+	for i := range individualBlocks {
+		assert.Equal(t, individualBlocks[i].Hash, batchBlocks[i].Hash)
+		assert.Equal(t, individualBlocks[i].GasOracleStatus, batchBlocks[i].GasOracleStatus)
+		assert.Equal(t, individualBlocks[i].OracleTxHash, batchBlocks[i].OracleTxHash)
+	}
+	assert.Equal(t, types.GasOracleImported, types.GasOracleStatus(batchBlocks[0].GasOracleStatus))
+	assert.Equal(t, types.GasOraclePending, types.GasOracleStatus(batchBlocks[1].GasOracleStatus))
+	assert.Equal(t, types.GasOracleImported, types.GasOracleStatus(batchBlocks[2].GasOracleStatus))
+}
+
+func testL1RelayerSetGasPriceManually(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relayerCfg := *cfg.L1Config.RelayerConfig
+	l1Relayer, err := NewLayer1Relayer(ctx, db, &relayerCfg, ServiceTypeL1GasOracle, nil)
+	assert.NoError(t, err)
+
+	// the override is refused without an operator identity.
+	assert.ErrorIs(t, l1Relayer.SetGasPriceManually(ctx, 100), ErrPermanent)
+	relayerCfg.OperatorID = "operator-1"
+
+	txHash := common.HexToHash("0x56789abcdef1234")
+	var contextIDs []string
+	patchGuard := gomonkey.ApplyMethodFunc(l1Relayer.gasOracleSenders[0], "SendTransaction", func(contextID string, target *common.Address, _ *big.Int, _ []byte, _ uint64) (common.Hash, error) {
+		contextIDs = append(contextIDs, contextID)
+		assert.Equal(t, relayerCfg.GasPriceOracleContractAddress, *target)
+		return txHash, nil
+	})
+	defer patchGuard.Reset()
+
+	// the diff threshold is bypassed, and the override is imported even while the oracle is paused.
+	l1Relayer.PauseGasOracle()
+	l1Relayer.lastGasPrice = 100
+	assert.NoError(t, l1Relayer.SetGasPriceManually(ctx, 101))
+	assert.Equal(t, uint64(101), l1Relayer.lastGasPrice)
+	assert.Len(t, contextIDs, 1)
+
+	overrides, err := orm.NewGasOracleManualOverride(db).GetLatestManualOverrides(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, overrides, 1)
+	assert.Equal(t, "operator-1", overrides[0].OperatorID)
+	assert.Equal(t, uint64(101), overrides[0].GasPrice)
+	assert.Equal(t, txHash.String(), overrides[0].TxHash)
+
+	// the confirmation of an override does not touch the l1 blocks.
+	assert.NoError(t, l1Relayer.handleConfirmation(ctx, &sender.Confirmation{
+		ContextID:    contextIDs[0],
+		IsSuccessful: true,
+		TxHash:       txHash,
+		SenderType:   types.SenderTypeL1GasOracle,
+	}))
+}
+
+type mockConfigWatcher struct {
+	ch chan *config.GasOracleConfig
+}
+
+func (w *mockConfigWatcher) Watch(context.Context) <-chan *config.GasOracleConfig {
+	return w.ch
+}
+
+func TestLayer1RelayerConfigWatcher(t *testing.T) {
+	r := &Layer1Relayer{ctx: context.Background()}
+	r.minGasPrice, r.gasPriceDiff = gasOracleParams(nil)
+
+	watcher := &mockConfigWatcher{ch: make(chan *config.GasOracleConfig, 2)}
+	r.SetConfigWatcher(watcher)
+
+	// no update
+	r.applyGasOracleConfigUpdates(context.Background())
+	assert.Equal(t, uint64(0), r.minGasPrice)
+	assert.Equal(t, uint64(defaultGasPriceDiff), r.gasPriceDiff)
+
+	// the latest update wins
+	watcher.ch <- &config.GasOracleConfig{MinGasPrice: 1, GasPriceDiff: 10}
+	watcher.ch <- &config.GasOracleConfig{MinGasPrice: 2, GasPriceDiff: 20}
+	r.applyGasOracleConfigUpdates(context.Background())
+	assert.Equal(t, uint64(2), r.minGasPrice)
+	assert.Equal(t, uint64(20), r.gasPriceDiff)
+
+	// a nil config falls back to defaults
+	watcher.ch <- nil
+	r.applyGasOracleConfigUpdates(context.Background())
+	assert.Equal(t, uint64(0), r.minGasPrice)
+	assert.Equal(t, uint64(defaultGasPriceDiff), r.gasPriceDiff)
+
+	// a closed watcher keeps the current config
+	close(watcher.ch)
+	r.applyGasOracleConfigUpdates(context.Background())
+	assert.Nil(t, r.gasOracleConfigCh)
+	assert.Equal(t, uint64(defaultGasPriceDiff), r.gasPriceDiff)
+}
+
+func TestLayer1RelayerGasPriceSpike(t *testing.T) {
+	now := time.Now()
+	r := &Layer1Relayer{
+		metrics:      initL1RelayerMetrics(nil),
+		lastGasPrice: 100,
+		now:          func() time.Time { return now },
+	}
+	r.minGasPrice, r.gasPriceDiff = gasOracleParams(nil)
+
+	var err error
+	r.maxGasPrice, r.spikeExemptionDuration, err = gasPriceSpikeParams(&config.GasOracleConfig{MaxGasPrice: 1000, SpikeExemptionDuration: 60})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, r.spikeExemptionDuration)
+
+	_, _, err = gasPriceSpikeParams(&config.GasOracleConfig{MaxGasPrice: 1000})
+	assert.Error(t, err)
+
+	// below the max gas price
+	assert.False(t, r.isGasPriceSpikeFrozen(1000))
+
+	// a spike freezes updates until the soak period has elapsed
+	skipped := testutil.ToFloat64(r.metrics.rollupL1RelayerGasPriceSpikeSkippedTotal)
+	assert.True(t, r.isGasPriceSpikeFrozen(5000))
+	now = now.Add(59 * time.Second)
+	assert.True(t, r.isGasPriceSpikeFrozen(5000))
+	assert.Equal(t, skipped+2, testutil.ToFloat64(r.metrics.rollupL1RelayerGasPriceSpikeSkippedTotal))
+	assert.Equal(t, uint64(100), r.lastGasPrice)
+
+	now = now.Add(time.Second)
+	assert.False(t, r.isGasPriceSpikeFrozen(5000))
+
+	// once the spike recedes, the next spike starts a new soak period
+	assert.False(t, r.isGasPriceSpikeFrozen(200))
+	assert.True(t, r.shouldUpdateGasPrice(r.lastGasPrice, 200))
+	assert.True(t, r.isGasPriceSpikeFrozen(5000))
+
+	// disabled
+	r.maxGasPrice = 0
+	assert.False(t, r.isGasPriceSpikeFrozen(5000))
+}
+
+func TestLayer1RelayerGasPriceEMA(t *testing.T) {
+	r := &Layer1Relayer{metrics: initL1RelayerMetrics(nil)}
+
+	_, err := emaParams(&config.GasOracleConfig{UseEMASmoothing: true})
+	assert.Error(t, err)
+	_, err = emaParams(&config.GasOracleConfig{UseEMASmoothing: true, EMAAlpha: 1.5})
+	assert.Error(t, err)
+
+	// disabled
+	assert.Equal(t, uint64(1000), r.smoothGasPrice(1, 1000))
+
+	r.emaAlpha, err = emaParams(&config.GasOracleConfig{UseEMASmoothing: true, EMAAlpha: 0.25})
+	assert.NoError(t, err)
+
+	// the first base fee starts the average
+	assert.Equal(t, uint64(1000), r.smoothGasPrice(1, 1000))
+	assert.Equal(t, uint64(1500), r.smoothGasPrice(2, 3000))
+	assert.Equal(t, float64(1500), testutil.ToFloat64(r.metrics.rollupL1RelayerGasPriceEMA))
+
+	// polling the same block again does not move the average
+	assert.Equal(t, uint64(1500), r.smoothGasPrice(2, 3000))
+
+	// an oscillating base fee is damped below the diff threshold
+	r.minGasPrice, r.gasPriceDiff = gasOracleParams(&config.GasOracleConfig{GasPriceDiff: 100000})
+	r.lastGasPrice = 1500
+	assert.True(t, r.shouldUpdateGasPrice(r.lastGasPrice, 1300))
+	assert.False(t, r.shouldUpdateGasPrice(r.lastGasPrice, r.smoothGasPrice(3, 1300)))
+}
+
+func TestLayer1RelayerPauseGasOracle(t *testing.T) {
+	// no db and senders are needed as long as the gas oracle is paused
+	r := &Layer1Relayer{metrics: initL1RelayerMetrics(nil)}
+
+	r.PauseGasOracle()
+	r.PauseGasOracle()
+	skipped := testutil.ToFloat64(r.metrics.rollupL1RelayerGasOraclePausedSkipsTotal)
+	assert.NoError(t, r.ProcessGasPriceOracle())
+	assert.NoError(t, r.ProcessGasPriceOracleBatch(10))
+	assert.Equal(t, skipped+2, testutil.ToFloat64(r.metrics.rollupL1RelayerGasOraclePausedSkipsTotal))
+
+	r.ResumeGasOracle()
+	assert.False(t, r.isGasOraclePaused())
+	assert.Equal(t, skipped+2, testutil.ToFloat64(r.metrics.rollupL1RelayerGasOraclePausedSkipsTotal))
+}
+
+func TestLayer1RelayerGasOraclePollDelay(t *testing.T) {
+	r := &Layer1Relayer{metrics: initL1RelayerMetrics(nil)}
+
+	// disabled
+	assert.Equal(t, 10*time.Second, r.GasOraclePollDelay(10*time.Second))
+
+	r.pollJitter = oraclePollJitter(&config.GasOracleConfig{OraclePollJitterMs: 500})
+	assert.Equal(t, 500*time.Millisecond, r.pollJitter)
+	for i := 0; i < 100; i++ {
+		delay := r.GasOraclePollDelay(10 * time.Second)
+		assert.GreaterOrEqual(t, delay, 10*time.Second)
+		assert.LessOrEqual(t, delay, 10*time.Second+500*time.Millisecond)
+	}
+}
+
+func testGasOracleRelayEndToEnd(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+
+	l1Client, err := base.L1Client()
+	assert.NoError(t, err)
+
+	// Deploy the gas price oracle on L2.
+	relayerCfg := *cfg.L1Config.RelayerConfig
+	l2Auth, err := bind.NewKeyedTransactorWithChainID(relayerCfg.GasOracleSenderPrivateKey, base.L2gethImg.ChainID())
+	assert.NoError(t, err)
+	_, tx, _, err := mock_bridge.DeployMockBridgeL2(l2Auth, l2Cli)
+	assert.NoError(t, err)
+	relayerCfg.GasPriceOracleContractAddress, err = bind.WaitDeployed(context.Background(), l2Cli, tx)
+	assert.NoError(t, err)
+
+	// Wait for the L1 geth image to mine a few blocks.
+	startHeight, err := l1Client.BlockNumber(context.Background())
+	assert.NoError(t, err)
+	var number uint64
+	assert.True(t, utils.TryTimes(60, func() bool {
+		number, err = l1Client.BlockNumber(context.Background())
+		return err == nil && number >= startHeight+3
+	}))
+
+	// Store the latest L1 block.
+	l1Watcher := watcher.NewL1WatcherClient(context.Background(), l1Client, startHeight, 0, cfg.L1Config.L1MessageQueueAddress, cfg.L1Config.ScrollChainContractAddress, db, nil)
+	assert.NoError(t, l1Watcher.FetchBlockHeader(number))
+
+	l1BlockOrm := orm.NewL1Block(db)
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"number": number})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, types.GasOraclePending, types.GasOracleStatus(blocks[0].GasOracleStatus))
+
+	// Relay the L1 base fee.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l1Relayer, err := NewLayer1Relayer(ctx, db, &relayerCfg, ServiceTypeL1GasOracle, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, l1Relayer.ProcessGasPriceOracle())
+
+	assert.True(t, utils.TryTimes(60, func() bool {
+		blocks, err = l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"number": number})
+		return err == nil && len(blocks) == 1 && types.GasOracleStatus(blocks[0].GasOracleStatus) == types.GasOracleImported
+	}))
+	assert.NotEmpty(t, blocks[0].OracleTxHash)
+
+	// The gas price oracle on L2 holds the L1 base fee.
+	calldata, err := bridgeAbi.L1GasPriceOracleABI.Pack("l1BaseFee")
+	assert.NoError(t, err)
+	result, err := l2Cli.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &relayerCfg.GasPriceOracleContractAddress,
+		Data: calldata,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).SetUint64(blocks[0].BaseFee), new(big.Int).SetBytes(result))
+}
+
+func testL1RelayerWaitForConfirmation(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+
+	l2Cli, err := base.L2Client()
+	assert.NoError(t, err)
+
+	// Deploy the gas price oracle on L2.
+	relayerCfg := *cfg.L1Config.RelayerConfig
+	l2Auth, err := bind.NewKeyedTransactorWithChainID(relayerCfg.GasOracleSenderPrivateKey, base.L2gethImg.ChainID())
+	assert.NoError(t, err)
+	_, tx, _, err := mock_bridge.DeployMockBridgeL2(l2Auth, l2Cli)
+	assert.NoError(t, err)
+	relayerCfg.GasPriceOracleContractAddress, err = bind.WaitDeployed(context.Background(), l2Cli, tx)
+	assert.NoError(t, err)
+
+	l1BlockOrm := orm.NewL1Block(db)
+	block := orm.L1Block{Hash: "gas-oracle-wait", Number: 0, BaseFee: 1000, GasOracleStatus: int16(types.GasOraclePending)}
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []orm.L1Block{block}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l1Relayer, err := NewLayer1Relayer(ctx, db, &relayerCfg, ServiceTypeL1GasOracle, nil)
+	assert.NoError(t, err)
+
+	data, err := l1Relayer.l1GasOracleABI.Pack("setL1BaseFee", new(big.Int).SetUint64(block.BaseFee))
+	assert.NoError(t, err)
+	hash, err := l1Relayer.gasOracleSenders[0].SendTransaction(block.Hash, &relayerCfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+	assert.NoError(t, err)
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer waitCancel()
+	cfm, err := l1Relayer.WaitForConfirmation(waitCtx, hash)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, cfm.TxHash)
+	assert.Equal(t, block.Hash, cfm.ContextID)
+	assert.True(t, cfm.IsSuccessful)
+
+	// The confirmation is handled once WaitForConfirmation returns.
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"hash": block.Hash})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, types.GasOracleImported, types.GasOracleStatus(blocks[0].GasOracleStatus))
+}
+
+func TestLayer1RelayerWaitForConfirmationConcurrent(t *testing.T) {
+	r := &Layer1Relayer{pendingTransactionOrm: &orm.PendingTransaction{}}
+	hash1, hash2 := common.HexToHash("0x01"), common.HexToHash("0x02")
+	patches := gomonkey.ApplyMethodFunc(r.pendingTransactionOrm, "GetReplacedTransactionHashes", func(ctx context.Context, hash common.Hash) ([]common.Hash, error) {
+		return nil, nil
+	})
+	defer patches.Reset()
+
+	var wg sync.WaitGroup
+	results := make([]*sender.Confirmation, 4)
+	for i := range results {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hash := hash1
+			if i%2 == 1 {
+				hash = hash2
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			cfm, err := r.WaitForConfirmation(ctx, hash)
+			assert.NoError(t, err)
+			results[i] = cfm
+		}()
+	}
+
+	// Wait for all the waiters to be registered.
+	assert.True(t, utils.TryTimes(50, func() bool {
+		r.confirmWaitersMu.Lock()
+		defer r.confirmWaitersMu.Unlock()
+		return len(r.confirmWaiters[hash1]) == 2 && len(r.confirmWaiters[hash2]) == 2
+	}))
+
+	r.notifyConfirmWaiters(context.Background(), &sender.Confirmation{TxHash: common.HexToHash("0x03"), ContextID: "other"})
+	r.notifyConfirmWaiters(context.Background(), &sender.Confirmation{TxHash: hash1, ContextID: "first", IsSuccessful: true})
+	r.notifyConfirmWaiters(context.Background(), &sender.Confirmation{TxHash: hash2, ContextID: "second"})
+	wg.Wait()
+
+	for i, cfm := range results {
+		if i%2 == 0 {
+			assert.Equal(t, "first", cfm.ContextID)
+			assert.True(t, cfm.IsSuccessful)
+		} else {
+			assert.Equal(t, "second", cfm.ContextID)
+			assert.False(t, cfm.IsSuccessful)
+		}
+	}
+	assert.Empty(t, r.confirmWaiters)
+}
+
+func TestLayer1RelayerWaitForConfirmationReplaced(t *testing.T) {
+	r := &Layer1Relayer{pendingTransactionOrm: &orm.PendingTransaction{}}
+	original, bumped, replacement := common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")
+	// the original transaction is replaced twice as the base fee rises, the last replacement is confirmed.
+	patches := gomonkey.ApplyMethodFunc(r.pendingTransactionOrm, "GetReplacedTransactionHashes", func(ctx context.Context, hash common.Hash) ([]common.Hash, error) {
+		if hash == replacement {
+			return []common.Hash{bumped, original}, nil
+		}
+		return nil, nil
+	})
+	defer patches.Reset()
+
+	done := make(chan *sender.Confirmation)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		cfm, err := r.WaitForConfirmation(ctx, original)
+		assert.NoError(t, err)
+		done <- cfm
+	}()
+	assert.True(t, utils.TryTimes(50, func() bool {
+		r.confirmWaitersMu.Lock()
+		defer r.confirmWaitersMu.Unlock()
+		return len(r.confirmWaiters[original]) == 1
+	}))
+
+	r.notifyConfirmWaiters(context.Background(), &sender.Confirmation{TxHash: replacement, ContextID: "block", IsSuccessful: true})
+	cfm := <-done
+	assert.Equal(t, replacement, cfm.TxHash)
+	assert.True(t, cfm.IsSuccessful)
+	assert.Empty(t, r.confirmWaiters)
+}
+
+func TestLayer1RelayerWaitForConfirmationTimeout(t *testing.T) {
+	r := &Layer1Relayer{}
+	hash := common.HexToHash("0x01")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := r.WaitForConfirmation(ctx, hash)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, r.confirmWaiters)
+
+	// A confirmation without waiters is ignored.
+	r.notifyConfirmWaiters(context.Background(), &sender.Confirmation{TxHash: hash})
+}
+
+func TestLayer1RelayerGasPricePercentile(t *testing.T) {
+	_, _, err := percentileParams(&config.GasOracleConfig{UsePercentileGasPrice: true, PercentileValue: 75})
+	assert.Error(t, err)
+	_, _, err = percentileParams(&config.GasOracleConfig{UsePercentileGasPrice: true, PercentileWindow: 10, PercentileValue: 0})
+	assert.Error(t, err)
+	_, _, err = percentileParams(&config.GasOracleConfig{UsePercentileGasPrice: true, PercentileWindow: 10, PercentileValue: 101})
+	assert.Error(t, err)
+	window, value, err := percentileParams(&config.GasOracleConfig{PercentileWindow: 10, PercentileValue: 75})
+	assert.NoError(t, err)
+	assert.Zero(t, window)
+	assert.Zero(t, value)
+
+	fees := []uint64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	assert.Equal(t, uint64(80), percentile(fees, 75))
+	assert.Equal(t, uint64(50), percentile(fees, 50))
+	assert.Equal(t, uint64(10), percentile(fees, 1))
+	assert.Equal(t, uint64(100), percentile(fees, 100))
+
+	// the order of the fees does not matter and they are left untouched.
+	shuffled := []uint64{300, 100, 400, 200}
+	assert.Equal(t, uint64(300), percentile(shuffled, 75))
+	assert.Equal(t, []uint64{300, 100, 400, 200}, shuffled)
+
+	// a single spike is ignored below the top percentiles.
+	spiky := []uint64{1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 50000}
+	assert.Equal(t, uint64(1000), percentile(spiky, 75))
+	assert.Equal(t, uint64(50000), percentile(spiky, 95))
+
+	assert.Equal(t, uint64(7), percentile([]uint64{7}, 75))
+	assert.Zero(t, percentile(nil, 75))
+}
+
+func TestLayer1RelayerPercentileBaseFee(t *testing.T) {
+	r := &Layer1Relayer{
+		cfg:              &config.RelayerConfig{},
+		l1BlockOrm:       &orm.L1Block{},
+		percentileWindow: 5,
+		percentileValue:  75,
+		metrics:          initL1RelayerMetrics(nil),
+	}
+
+	var startHeight, endHeight uint64
+	var pageSize int
+	fees := []uint64{500, 100, 400, 200, 300}
+	patches := gomonkey.ApplyMethodFunc(r.l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, start, end uint64, page, size int) ([]*orm.L1Block, int64, error) {
+		startHeight, endHeight, pageSize = start, end, size
+		var blocks []*orm.L1Block
+		for i, fee := range fees {
+			blocks = append(blocks, &orm.L1Block{Number: start + uint64(i), BaseFee: fee})
+		}
+		return blocks, int64(len(blocks)), nil
+	})
+	defer patches.Reset()
+
+	baseFee, err := r.percentileBaseFee(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(400), baseFee)
+	assert.Equal(t, uint64(96), startHeight)
+	assert.Equal(t, uint64(100), endHeight)
+	assert.Equal(t, 5, pageSize)
+	assert.Equal(t, float64(400), testutil.ToFloat64(r.metrics.rollupL1RelayerGasPricePercentile))
+
+	// the window is truncated at the genesis block.
+	fees = []uint64{100, 200}
+	baseFee, err = r.percentileBaseFee(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(200), baseFee)
+	assert.Equal(t, uint64(0), startHeight)
+
+	fees = nil
+	_, err = r.percentileBaseFee(context.Background(), 100)
+	assert.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestProcessGasPriceOracleDiffThreshold(t *testing.T) {
+	tests := []struct {
+		name         string
+		lastGasPrice uint64
+		baseFee      uint64
+		minGasPrice  uint64
+		expectSend   bool
+	}{
+		{"first run", 0, 1000, 0, true},
+		{"first run below min gas price", 0, 1000, 2000, true},
+		{"within diff", 1000, 1020, 0, false},
+		{"unchanged", 1000, 1000, 0, false},
+		{"just above diff", 1000, 1050, 0, true},
+		{"just below diff", 1000, 1049, 0, false},
+		{"decrease just above diff", 1000, 950, 0, true},
+		{"decrease just below diff", 1000, 951, 0, false},
+		{"below min gas price", 1000, 1500, 1600, false},
+		{"at min gas price", 1000, 1600, 1600, true},
+		{"zero expected delta", 10, 11, 0, true},
+		{"zero expected delta unchanged", 10, 10, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasOracleSender := &sender.Sender{}
+			r := &Layer1Relayer{
+				ctx:              context.Background(),
+				cfg:              &config.RelayerConfig{GasPriceOracleContractAddress: common.HexToAddress("0x5300000000000000000000000000000000000002")},
+				l1BlockOrm:       &orm.L1Block{},
+				l1GasOracleABI:   bridgeAbi.L1GasPriceOracleABI,
+				gasOracleSenders: []*sender.Sender{gasOracleSender},
+				lastGasPrice:     tt.lastGasPrice,
+				metrics:          initL1RelayerMetrics(nil),
+			}
+			// a 5% diff threshold.
+			r.minGasPrice, r.gasPriceDiff = gasOracleParams(&config.GasOracleConfig{MinGasPrice: tt.minGasPrice, GasPriceDiff: 50000})
+
+			block := &orm.L1Block{Hash: "gas-oracle-diff", Number: 100, BaseFee: tt.baseFee, GasOracleStatus: int16(types.GasOraclePending)}
+			patches := gomonkey.ApplyMethodFunc(r.l1BlockOrm, "GetLatestL1BlockHeight", func(ctx context.Context) (uint64, error) {
+				return block.Number, nil
+			})
+			defer patches.Reset()
+			patches.ApplyMethodFunc(r.l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, start, end uint64, page, size int) ([]*orm.L1Block, int64, error) {
+				return []*orm.L1Block{block}, 1, nil
+			})
+			var updated bool
+			patches.ApplyMethodFunc(r.l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(ctx context.Context, blockHash string, status types.GasOracleStatus, txHash string) error {
+				updated = true
+				assert.Equal(t, block.Hash, blockHash)
+				assert.Equal(t, types.GasOracleImporting, status)
+				return nil
+			})
+			var sent int
+			patches.ApplyMethodFunc(gasOracleSender, "SendTransaction", func(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+				sent++
+				assert.Equal(t, block.Hash, contextID)
+				assert.Equal(t, r.cfg.GasPriceOracleContractAddress, *target)
+				expected, err := r.l1GasOracleABI.Pack("setL1BaseFee", new(big.Int).SetUint64(tt.baseFee))
+				assert.NoError(t, err)
+				assert.Equal(t, expected, data)
+				return common.HexToHash("0x1"), nil
+			})
+
+			assert.NoError(t, r.ProcessGasPriceOracle())
+			if tt.expectSend {
+				assert.Equal(t, 1, sent)
+				assert.True(t, updated)
+				assert.Equal(t, tt.baseFee, r.lastGasPrice)
+			} else {
+				assert.Zero(t, sent)
+				assert.False(t, updated)
+				assert.Equal(t, tt.lastGasPrice, r.lastGasPrice)
+			}
+		})
+	}
+}
+
+func TestProcessGasPriceOracleShadow(t *testing.T) {
+	gasOracleSender := &sender.Sender{}
+	shadowSender := &sender.Sender{}
+	r := &Layer1Relayer{
+		ctx:              context.Background(),
+		cfg:              &config.RelayerConfig{GasPriceOracleContractAddress: common.HexToAddress("0x5300000000000000000000000000000000000002")},
+		l1BlockOrm:       &orm.L1Block{},
+		l1GasOracleABI:   bridgeAbi.L1GasPriceOracleABI,
+		gasOracleSenders: []*sender.Sender{gasOracleSender},
+		shadowSender:     shadowSender,
+		metrics:          initL1RelayerMetrics(nil),
+	}
+	r.minGasPrice, r.gasPriceDiff = gasOracleParams(&config.GasOracleConfig{GasPriceDiff: 50000})
+
+	block := &orm.L1Block{Hash: "gas-oracle-shadow", Number: 100, BaseFee: 1000, GasOracleStatus: int16(types.GasOraclePending)}
+	patches := gomonkey.ApplyMethodFunc(r.l1BlockOrm, "GetLatestL1BlockHeight", func(ctx context.Context) (uint64, error) {
+		return block.Number, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(r.l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, start, end uint64, page, size int) ([]*orm.L1Block, int64, error) {
+		return []*orm.L1Block{block}, 1, nil
+	})
+	var updatedTxHash string
+	patches.ApplyMethodFunc(r.l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(ctx context.Context, blockHash string, status types.GasOracleStatus, txHash string) error {
+		updatedTxHash = txHash
+		return nil
+	})
+	shadowData := make(chan []byte, 1)
+	var primaryData []byte
+	patches.ApplyMethod(reflect.TypeOf(gasOracleSender), "SendTransaction", func(s *sender.Sender, contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+		if s == shadowSender {
+			shadowData <- data
+			// the failures of the shadow l2 do not affect the primary flow.
+			return common.Hash{}, errors.New("shadow l2 unavailable")
+		}
+		primaryData = data
+		return common.HexToHash("0x1"), nil
+	})
+
+	assert.NoError(t, r.ProcessGasPriceOracle())
+	assert.Equal(t, common.HexToHash("0x1").String(), updatedTxHash)
+	assert.Equal(t, uint64(1000), r.lastGasPrice)
+	select {
+	case data := <-shadowData:
+		assert.Equal(t, primaryData, data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shadow setL1BaseFee tx not sent")
+	}
+}
+
+func TestLayer1RelayerAdminTriggerConcurrency(t *testing.T) {
+	gasOracleSender := &sender.Sender{}
+	r := &Layer1Relayer{
+		ctx:              context.Background(),
+		cfg:              &config.RelayerConfig{GasPriceOracleContractAddress: common.HexToAddress("0x5300000000000000000000000000000000000002")},
+		l1BlockOrm:       &orm.L1Block{},
+		l1GasOracleABI:   bridgeAbi.L1GasPriceOracleABI,
+		gasOracleSenders: []*sender.Sender{gasOracleSender},
+		pollJitter:       time.Millisecond,
+		metrics:          initL1RelayerMetrics(nil),
+	}
+	r.minGasPrice, r.gasPriceDiff = gasOracleParams(&config.GasOracleConfig{GasPriceDiff: 50000})
+
+	// the block stays pending, only the first update of its base fee is sent.
+	block := &orm.L1Block{Hash: "gas-oracle-admin", Number: 100, BaseFee: 1000, GasOracleStatus: int16(types.GasOraclePending)}
+	patches := gomonkey.ApplyMethodFunc(r.l1BlockOrm, "GetLatestL1BlockHeight", func(ctx context.Context) (uint64, error) {
+		return block.Number, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(r.l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, start, end uint64, page, size int) ([]*orm.L1Block, int64, error) {
+		return []*orm.L1Block{block}, 1, nil
+	})
+	patches.ApplyMethodFunc(r.l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(ctx context.Context, blockHash string, status types.GasOracleStatus, txHash string) error {
+		return nil
+	})
+	var sent int32
+	patches.ApplyMethodFunc(gasOracleSender, "SendTransaction", func(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+		atomic.AddInt32(&sent, 1)
+		// widen the window of a concurrent run.
+		time.Sleep(time.Millisecond)
+		return common.HexToHash("0x1"), nil
+	})
+
+	addr := utils.RandomURL()
+	s := admin.NewServer(addr)
+	s.RegisterGasPriceOracles(r)
+	s.Start()
+	defer func() {
+		assert.NoError(t, s.Stop(context.Background()))
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		utils.LoopWithDelay(ctx, func() time.Duration { return r.GasOraclePollDelay(0) }, func() {
+			assert.NoError(t, r.ProcessGasPriceOracle())
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				var resp *http.Response
+				// the admin server may not be listening yet.
+				ok := utils.TryTimes(10, func() bool {
+					var postErr error
+					resp, postErr = http.Post("http://"+addr+"/gas_price_oracle", "application/json", nil)
+					return postErr == nil
+				})
+				if !assert.True(t, ok) {
+					return
+				}
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.NoError(t, resp.Body.Close())
+			}
+		}()
+	}
+	wg.Wait()
+	cancel()
+	<-loopDone
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&sent))
+	assert.Equal(t, block.BaseFee, r.lastGasPrice)
+}
+
+func TestLayer1RelayerSetGasPriceManuallyConcurrency(t *testing.T) {
+	gasOracleSenders := []*sender.Sender{{}, {}}
+	now := time.Unix(1700000000, 0)
+	r := &Layer1Relayer{
+		ctx: context.Background(),
+		cfg: &config.RelayerConfig{
+			GasPriceOracleContractAddress: common.HexToAddress("0x5300000000000000000000000000000000000002"),
+			OperatorID:                    "operator",
+		},
+		l1BlockOrm:        &orm.L1Block{},
+		manualOverrideOrm: &orm.GasOracleManualOverride{},
+		l1GasOracleABI:    bridgeAbi.L1GasPriceOracleABI,
+		gasOracleSenders:  gasOracleSenders,
+		now:               func() time.Time { return now },
+		metrics:           initL1RelayerMetrics(nil),
+	}
+	r.minGasPrice, r.gasPriceDiff = gasOracleParams(&config.GasOracleConfig{GasPriceDiff: 50000})
+
+	var blockNumber uint64
+	patches := gomonkey.ApplyMethodFunc(r.l1BlockOrm, "GetLatestL1BlockHeight", func(ctx context.Context) (uint64, error) {
+		return atomic.LoadUint64(&blockNumber), nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(r.l1BlockOrm, "GetL1BlocksInRange", func(ctx context.Context, start, end uint64, page, size int) ([]*orm.L1Block, int64, error) {
+		number := atomic.LoadUint64(&blockNumber)
+		// the base fee of each block differs enough from the manual gas prices to be updated.
+		return []*orm.L1Block{{Hash: fmt.Sprintf("block-%d", number), Number: number, BaseFee: 1000000 * number, GasOracleStatus: int16(types.GasOraclePending)}}, 1, nil
+	})
+	patches.ApplyMethodFunc(r.l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(ctx context.Context, blockHash string, status types.GasOracleStatus, txHash string) error {
+		return nil
+	})
+	patches.ApplyMethodFunc(r.manualOverrideOrm, "InsertManualOverride", func(ctx context.Context, operatorID string, gasPrice uint64, txHash string) error {
+		return nil
+	})
+	var contextIDs sync.Map
+	var sent int32
+	patches.ApplyMethod(reflect.TypeOf(gasOracleSenders[0]), "SendTransaction", func(s *sender.Sender, contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+		atomic.AddInt32(&sent, 1)
+		contextIDs.Store(contextID, struct{}{})
+		return common.HexToHash("0x1"), nil
+	})
+
+	const updates = 20
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= updates; i++ {
+			atomic.StoreUint64(&blockNumber, uint64(i))
+			assert.NoError(t, r.ProcessGasPriceOracle())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= updates; i++ {
+			assert.NoError(t, r.SetGasPriceManually(context.Background(), uint64(i)))
+		}
+	}()
+	wg.Wait()
+
+	// every update is sent, the senders are used in turn.
+	assert.Equal(t, int32(2*updates), atomic.LoadInt32(&sent))
+	assert.Equal(t, 0, r.nextGasOracleSender)
+	_, ok := contextIDs.Load(fmt.Sprintf("%s%d", manualOverrideContextIDPrefix, now.UnixNano()))
+	assert.True(t, ok)
 }
@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -17,6 +18,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/database"
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/encoding"
 	"scroll-tech/common/types/encoding/codecv0"
@@ -26,6 +28,7 @@ import (
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/sender"
 	"scroll-tech/rollup/internal/orm"
+	butils "scroll-tech/rollup/internal/utils"
 )
 
 // Layer2Relayer is responsible for
@@ -60,17 +63,69 @@ type Layer2Relayer struct {
 	// Used to get batch status from chain_monitor api.
 	chainMonitorClient *resty.Client
 
+	// alertWebhook receives the critical events, nil if no webhook is configured.
+	alertWebhook *alertWebhook
+
+	// proofQueue generates the proofs of the committed batches, nil if the batches are proven by the coordinator.
+	proofQueue *ProofQueue
+
+	// finalityChecker confirms the finalizations once deep enough on both chains, nil if they are confirmed with their l1 transaction.
+	finalityChecker *DualFinalityChecker
+
+	// proofVerifier verifies the batch proofs before their finalization is submitted, nil if disabled.
+	proofVerifier *LocalProofVerifier
+
+	// costPredictor predicts the cost of the finalizations from the confirmed ones, nil for the gas oracle.
+	costPredictor *GasCostPredictor
+
 	metrics *l2RelayerMetrics
 }
 
+// Layer2RelayerOption configures the optional dependencies of a Layer2Relayer.
+type Layer2RelayerOption func(*layer2RelayerOptions)
+
+type layer2RelayerOptions struct {
+	readReplica *database.DB
+}
+
+// WithL2ReadReplica reconciles the stored l2 blocks at startup against the read replicas of db.
+func WithL2ReadReplica(db *database.DB) Layer2RelayerOption {
+	return func(o *layer2RelayerOptions) {
+		o.readReplica = db
+	}
+}
+
 // NewLayer2Relayer will return a new instance of Layer2RelayerClient
-func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.DB, cfg *config.RelayerConfig, initGenesis bool, serviceType ServiceType, reg prometheus.Registerer) (*Layer2Relayer, error) {
+func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.DB, cfg *config.RelayerConfig, initGenesis bool, serviceType ServiceType, reg prometheus.Registerer, opts ...Layer2RelayerOption) (*Layer2Relayer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid l2_relayer config: %w", err)
+	}
+	if err := orm.CheckSchemaVersion(db, orm.ExpectedSchemaVersion); err != nil {
+		return nil, fmt.Errorf("l2_relayer refuses to run: %w", err)
+	}
+	var options layer2RelayerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if serviceType == ServiceTypeL2RollupRelayer && cfg.MaxAllowedGapBlocks > 0 {
+		if l2Client == nil {
+			return nil, fmt.Errorf("an l2 client is required to check the l2 block gaps")
+		}
+		webhook := newAlertWebhook("l2_relayer", cfg.AlertWebhookURL, cfg.AlertWebhookSecret)
+		if err := checkBlockGaps(ctx, "l2", orm.NewL2Block(db, orm.WithReadReplica(options.readReplica)), l2Client, cfg.MaxAllowedGapBlocks, webhook); err != nil {
+			return nil, fmt.Errorf("l2_relayer refuses to run: %w", err)
+		}
+	}
+
 	var gasOracleSender, commitSender, finalizeSender *sender.Sender
 	var err error
 
 	switch serviceType {
 	case ServiceTypeL2GasOracle:
-		gasOracleSender, err = sender.NewSender(ctx, cfg.SenderConfig, cfg.GasOracleSenderPrivateKey, "l2_relayer", "gas_oracle_sender", types.SenderTypeL2GasOracle, db, reg)
+		if cfg.GasOracleSenderPrivateKey == nil {
+			return nil, fmt.Errorf("no gas oracle sender private key configured")
+		}
+		gasOracleSender, err = sender.NewSender(ctx, withoutFlashbots(cfg.SenderConfig), cfg.GasOracleSenderPrivateKey, "l2_relayer", "gas_oracle_sender", types.SenderTypeL2GasOracle, db, reg)
 		if err != nil {
 			addr := crypto.PubkeyToAddress(cfg.GasOracleSenderPrivateKey.PublicKey)
 			return nil, fmt.Errorf("new gas oracle sender failed for address %s, err: %w", addr.Hex(), err)
@@ -82,13 +137,16 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		}
 
 	case ServiceTypeL2RollupRelayer:
+		if cfg.CommitSenderPrivateKey == nil {
+			return nil, fmt.Errorf("no commit sender private key configured")
+		}
 		commitSender, err = sender.NewSender(ctx, cfg.SenderConfig, cfg.CommitSenderPrivateKey, "l2_relayer", "commit_sender", types.SenderTypeCommitBatch, db, reg)
 		if err != nil {
 			addr := crypto.PubkeyToAddress(cfg.CommitSenderPrivateKey.PublicKey)
 			return nil, fmt.Errorf("new commit sender failed for address %s, err: %w", addr.Hex(), err)
 		}
 
-		finalizeSender, err = sender.NewSender(ctx, cfg.SenderConfig, cfg.FinalizeSenderPrivateKey, "l2_relayer", "finalize_sender", types.SenderTypeFinalizeBatch, db, reg)
+		finalizeSender, err = sender.NewSender(ctx, withoutFlashbots(cfg.SenderConfig), cfg.FinalizeSenderPrivateKey, "l2_relayer", "finalize_sender", types.SenderTypeFinalizeBatch, db, reg)
 		if err != nil {
 			addr := crypto.PubkeyToAddress(cfg.FinalizeSenderPrivateKey.PublicKey)
 			return nil, fmt.Errorf("new finalize sender failed for address %s, err: %w", addr.Hex(), err)
@@ -103,15 +161,7 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		return nil, fmt.Errorf("invalid service type for l2_relayer: %v", serviceType)
 	}
 
-	var minGasPrice uint64
-	var gasPriceDiff uint64
-	if cfg.GasOracleConfig != nil {
-		minGasPrice = cfg.GasOracleConfig.MinGasPrice
-		gasPriceDiff = cfg.GasOracleConfig.GasPriceDiff
-	} else {
-		minGasPrice = 0
-		gasPriceDiff = defaultGasPriceDiff
-	}
+	minGasPrice, gasPriceDiff := gasOracleParams(cfg.GasOracleConfig)
 
 	layer2Relayer := &Layer2Relayer{
 		ctx: ctx,
@@ -133,9 +183,37 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		minGasPrice:  minGasPrice,
 		gasPriceDiff: gasPriceDiff,
 
+		alertWebhook: newAlertWebhook("l2_relayer", cfg.AlertWebhookURL, cfg.AlertWebhookSecret),
+
 		cfg: cfg,
 	}
 
+	if serviceType == ServiceTypeL2RollupRelayer && cfg.FinalityConfig != nil {
+		if l2Client == nil {
+			return nil, fmt.Errorf("an l2 client is required to check the finality of the batches")
+		}
+		l1Client, dialErr := ethclient.Dial(cfg.SenderConfig.Endpoint)
+		if dialErr != nil {
+			return nil, fmt.Errorf("failed to dial l1 client to check the finality of the batches: %w", dialErr)
+		}
+		layer2Relayer.finalityChecker = NewDualFinalityChecker(l1Client, l2Client, cfg.FinalityConfig.L1Confirmations, cfg.FinalityConfig.L2Confirmations,
+			time.Duration(cfg.FinalityConfig.PollIntervalSec)*time.Second)
+	}
+
+	if serviceType == ServiceTypeL2RollupRelayer && cfg.LocalProofVerifierConfig != nil {
+		layer2Relayer.proofVerifier, err = NewLocalProofVerifier(cfg.LocalProofVerifierConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if serviceType == ServiceTypeL2RollupRelayer {
+		layer2Relayer.costPredictor = NewGasCostPredictor(db, reg)
+		if err = layer2Relayer.costPredictor.Fit(ctx); err != nil {
+			log.Warn("Failed to fit the finalization cost prediction", "err", err)
+		}
+	}
+
 	// chain_monitor client
 	if cfg.ChainMonitor.Enabled {
 		layer2Relayer.chainMonitorClient = resty.New()
@@ -156,6 +234,9 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		go layer2Relayer.handleL2GasOracleConfirmLoop(ctx)
 	case ServiceTypeL2RollupRelayer:
 		go layer2Relayer.handleL2RollupRelayerConfirmLoop(ctx)
+		if layer2Relayer.finalityChecker != nil {
+			go layer2Relayer.resumeFinalityChecks(ctx)
+		}
 	default:
 		return nil, fmt.Errorf("invalid service type for l2_relayer: %v", serviceType)
 	}
@@ -163,6 +244,11 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 	return layer2Relayer, nil
 }
 
+// SetProofQueue makes the relayer enqueue the proof job of each batch once its commitBatch tx is sent.
+func (r *Layer2Relayer) SetProofQueue(q *ProofQueue) {
+	r.proofQueue = q
+}
+
 func (r *Layer2Relayer) initializeGenesis() error {
 	if count, err := r.batchOrm.GetBatchCount(r.ctx); err != nil {
 		return fmt.Errorf("failed to get batch count: %v", err)
@@ -284,20 +370,25 @@ func (r *Layer2Relayer) commitGenesisBatch(batchHash string, batchHeader []byte,
 	}
 }
 
-// ProcessGasPriceOracle imports gas price to layer1
-func (r *Layer2Relayer) ProcessGasPriceOracle() {
+// ProcessGasPriceOracle imports gas price to layer1.
+// It is the L2 -> L1 counterpart of Layer1Relayer.ProcessGasPriceOracle: the l2geth suggested gas price is
+// relayed through setL2BaseFee of L2GasPriceOracle, and the progress is tracked by the oracle status of the latest batch.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *Layer2Relayer) ProcessGasPriceOracle() error {
 	r.metrics.rollupL2RelayerGasPriceOraclerRunTotal.Inc()
-	batch, err := r.batchOrm.GetLatestBatch(r.ctx)
-	if batch == nil || err != nil {
-		log.Error("Failed to GetLatestBatch", "batch", batch, "err", err)
-		return
+	ctx := butils.WithTrace(r.ctx, butils.NewTraceID())
+	batch, err := r.batchOrm.GetLatestBatch(ctx)
+	if err != nil {
+		return newTransientError("failed to GetLatestBatch: %w", err)
+	}
+	if batch == nil {
+		return newPermanentError("latest batch not found")
 	}
 
 	if types.GasOracleStatus(batch.OracleStatus) == types.GasOraclePending {
-		suggestGasPrice, err := r.l2Client.SuggestGasPrice(r.ctx)
+		suggestGasPrice, err := r.l2Client.SuggestGasPrice(ctx)
 		if err != nil {
-			log.Error("Failed to fetch SuggestGasPrice from l2geth", "err", err)
-			return
+			return newTransientError("failed to fetch SuggestGasPrice from l2geth: %w", err)
 		}
 		suggestGasPriceUint64 := uint64(suggestGasPrice.Int64())
 		expectedDelta := r.lastGasPrice * r.gasPriceDiff / gasPriceDiffPrecision
@@ -309,63 +400,86 @@ func (r *Layer2Relayer) ProcessGasPriceOracle() {
 		if r.lastGasPrice == 0 || (suggestGasPriceUint64 >= r.minGasPrice && (suggestGasPriceUint64 >= r.lastGasPrice+expectedDelta || suggestGasPriceUint64 <= r.lastGasPrice-expectedDelta)) {
 			data, err := r.l2GasOracleABI.Pack("setL2BaseFee", suggestGasPrice)
 			if err != nil {
-				log.Error("Failed to pack setL2BaseFee", "batch.Hash", batch.Hash, "GasPrice", suggestGasPrice.Uint64(), "err", err)
-				return
+				return newPermanentError("failed to pack setL2BaseFee, batch hash: %s, gas price: %d: %w", batch.Hash, suggestGasPrice.Uint64(), err)
 			}
 
 			hash, err := r.gasOracleSender.SendTransaction(batch.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
 			if err != nil {
-				log.Error("Failed to send setL2BaseFee tx to layer2 ", "batch.Hash", batch.Hash, "err", err)
-				return
+				return newTransientError("failed to send setL2BaseFee tx to layer1, batch hash: %s: %w", batch.Hash, err)
 			}
 
-			err = r.batchOrm.UpdateL2GasOracleStatusAndOracleTxHash(r.ctx, batch.Hash, types.GasOracleImporting, hash.String())
+			err = r.batchOrm.UpdateL2GasOracleStatusAndOracleTxHash(ctx, batch.Hash, types.GasOracleImporting, hash.String())
 			if err != nil {
-				log.Error("UpdateGasOracleStatusAndOracleTxHash failed", "batch.Hash", batch.Hash, "err", err)
-				return
+				return newTransientError("failed to UpdateL2GasOracleStatusAndOracleTxHash, batch hash: %s: %w", batch.Hash, err)
 			}
 			r.lastGasPrice = suggestGasPriceUint64
 			r.metrics.rollupL2RelayerLastGasPrice.Set(float64(r.lastGasPrice))
-			log.Info("Update l2 gas price", "txHash", hash.String(), "GasPrice", suggestGasPrice)
+			butils.Logger(ctx, "batchID", batch.Hash).Info("Update l2 gas price", "txHash", hash.String(), "GasPrice", suggestGasPrice)
 		}
 	}
+	return nil
 }
 
 // ProcessPendingBatches processes the pending batches by sending commitBatch transactions to layer 1.
+// At most MaxConcurrentBatchCommits commitBatch txs are waiting for confirmation at any time if it is set.
 func (r *Layer2Relayer) ProcessPendingBatches() {
+	ctx := butils.WithTrace(r.ctx, butils.NewTraceID())
+	logger := butils.Logger(ctx)
+	limit := 5
+	inFlight, err := r.batchOrm.GetBatchCountByRollupStatus(ctx, types.RollupCommitting)
+	if err != nil {
+		logger.Error("Failed to count in-flight batch commits", "err", err)
+		return
+	}
+	r.metrics.rollupL2RelayerInFlightBatchCommits.Set(float64(inFlight))
+	if maxCommits := r.cfg.MaxConcurrentBatchCommits; maxCommits > 0 {
+		if inFlight >= uint64(maxCommits) {
+			logger.Debug("Too many in-flight batch commits, skip committing", "in flight", inFlight, "max", maxCommits)
+			return
+		}
+		if available := maxCommits - int(inFlight); available < limit {
+			limit = available
+		}
+	}
+
 	// get pending batches from database in ascending order by their index.
-	batches, err := r.batchOrm.GetFailedAndPendingBatches(r.ctx, 5)
+	batches, err := r.batchOrm.GetFailedAndPendingBatches(ctx, limit)
 	if err != nil {
-		log.Error("Failed to fetch pending L2 batches", "err", err)
+		logger.Error("Failed to fetch pending L2 batches", "err", err)
 		return
 	}
 	for _, batch := range batches {
+		if ctx.Err() != nil {
+			logger.Info("Stop committing pending batches", "err", ctx.Err())
+			return
+		}
+		batchLogger := logger.New("batchID", batch.Hash)
 		r.metrics.rollupL2RelayerProcessPendingBatchTotal.Inc()
 		// get current header and parent header.
 		daBatch, err := codecv0.NewDABatchFromBytes(batch.BatchHeader)
 		if err != nil {
-			log.Error("Failed to initialize new DA batch from bytes", "index", batch.Index, "hash", batch.Hash, "err", err)
+			batchLogger.Error("Failed to initialize new DA batch from bytes", "index", batch.Index, "hash", batch.Hash, "err", err)
 			return
 		}
 		parentBatch := &orm.Batch{}
 		if batch.Index > 0 {
-			parentBatch, err = r.batchOrm.GetBatchByIndex(r.ctx, batch.Index-1)
+			parentBatch, err = r.batchOrm.GetBatchByIndex(ctx, batch.Index-1)
 			if err != nil {
-				log.Error("Failed to get parent batch header", "index", batch.Index-1, "error", err)
+				batchLogger.Error("Failed to get parent batch header", "index", batch.Index-1, "error", err)
 				return
 			}
 
 			if types.RollupStatus(parentBatch.RollupStatus) == types.RollupCommitFailed {
-				log.Error("Previous batch commit failed, halting further committing",
+				batchLogger.Error("Previous batch commit failed, halting further committing",
 					"index", parentBatch.Index, "tx hash", parentBatch.CommitTxHash)
 				return
 			}
 		}
 
 		// get the metadata of chunks for the batch
-		dbChunks, err := r.chunkOrm.GetChunksInRange(r.ctx, batch.StartChunkIndex, batch.EndChunkIndex)
+		dbChunks, err := r.chunkOrm.GetChunksInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex)
 		if err != nil {
-			log.Error("Failed to fetch chunks",
+			batchLogger.Error("Failed to fetch chunks",
 				"start index", batch.StartChunkIndex,
 				"end index", batch.EndChunkIndex, "error", err)
 			return
@@ -373,33 +487,16 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 
 		encodedChunks := make([][]byte, len(dbChunks))
 		for i, c := range dbChunks {
-			var blocks []*encoding.Block
-			blocks, err = r.l2BlockOrm.GetL2BlocksInRange(r.ctx, c.StartBlockNumber, c.EndBlockNumber)
+			encodedChunks[i], err = r.encodeChunk(ctx, c)
 			if err != nil {
-				log.Error("Failed to fetch blocks", "start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
+				batchLogger.Error("Failed to encode chunk", "chunkID", c.Hash, "start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
 				return
 			}
-			chunk := &encoding.Chunk{
-				Blocks: blocks,
-			}
-			var daChunk *codecv0.DAChunk
-			daChunk, err = codecv0.NewDAChunk(chunk, c.TotalL1MessagesPoppedBefore)
-			if err != nil {
-				log.Error("Failed to initialize new DA chunk", "start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
-				return
-			}
-			var daChunkBytes []byte
-			daChunkBytes, err = daChunk.Encode()
-			if err != nil {
-				log.Error("Failed to encode DA chunk", "start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
-				return
-			}
-			encodedChunks[i] = daChunkBytes
 		}
 
 		calldata, err := r.l1RollupABI.Pack("commitBatch", daBatch.Version, parentBatch.BatchHeader, encodedChunks, daBatch.SkippedL1MessageBitmap)
 		if err != nil {
-			log.Error("Failed to pack commitBatch", "index", batch.Index, "error", err)
+			batchLogger.Error("Failed to pack commitBatch", "index", batch.Index, "error", err)
 			return
 		}
 
@@ -408,18 +505,18 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 		if types.RollupStatus(batch.RollupStatus) == types.RollupCommitFailed {
 			// use eth_estimateGas if this batch has been committed failed.
 			fallbackGasLimit = 0
-			log.Warn("Batch commit previously failed, using eth_estimateGas for the re-submission", "hash", batch.Hash)
+			batchLogger.Warn("Batch commit previously failed, using eth_estimateGas for the re-submission", "hash", batch.Hash)
 		}
 		txHash, err := r.commitSender.SendTransaction(batch.Hash, &r.cfg.RollupContractAddress, big.NewInt(0), calldata, fallbackGasLimit)
 		if err != nil {
-			log.Error(
+			batchLogger.Error(
 				"Failed to send commitBatch tx to layer1",
 				"index", batch.Index,
 				"hash", batch.Hash,
 				"RollupContractAddress", r.cfg.RollupContractAddress,
 				"err", err,
 			)
-			log.Debug(
+			batchLogger.Debug(
 				"Failed to send commitBatch tx to layer1",
 				"index", batch.Index,
 				"hash", batch.Hash,
@@ -430,56 +527,67 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 			return
 		}
 
-		err = r.batchOrm.UpdateCommitTxHashAndRollupStatus(r.ctx, batch.Hash, txHash.String(), types.RollupCommitting)
+		err = r.batchOrm.UpdateCommitTxHashAndRollupStatus(ctx, batch.Hash, txHash.String(), types.RollupCommitting)
 		if err != nil {
-			log.Error("UpdateCommitTxHashAndRollupStatus failed", "hash", batch.Hash, "index", batch.Index, "err", err)
+			batchLogger.Error("UpdateCommitTxHashAndRollupStatus failed", "hash", batch.Hash, "index", batch.Index, "err", err)
 			return
 		}
 		r.metrics.rollupL2RelayerProcessPendingBatchSuccessTotal.Inc()
-		log.Info("Sent the commitBatch tx to layer1", "batch index", batch.Index, "batch hash", batch.Hash, "tx hash", txHash.Hex())
+		r.metrics.rollupL2RelayerInFlightBatchCommits.Inc()
+		batchLogger.Info("Sent the commitBatch tx to layer1", "batch index", batch.Index, "batch hash", batch.Hash, "tx hash", txHash.Hex())
+
+		if r.proofQueue != nil {
+			if err = r.proofQueue.Enqueue(batch.Index); err != nil {
+				batchLogger.Error("Failed to enqueue proof job", "index", batch.Index, "hash", batch.Hash, "err", err)
+			}
+		}
 	}
 }
 
 // ProcessCommittedBatches submit proof to layer 1 rollup contract
 func (r *Layer2Relayer) ProcessCommittedBatches() {
+	ctx := butils.WithTrace(r.ctx, butils.NewTraceID())
+	logger := butils.Logger(ctx)
+
 	// retrieves the earliest batch whose rollup status is 'committed'
 	fields := map[string]interface{}{
 		"rollup_status": types.RollupCommitted,
 	}
 	orderByList := []string{"index ASC"}
 	limit := 1
-	batches, err := r.batchOrm.GetBatches(r.ctx, fields, orderByList, limit)
+	batches, err := r.batchOrm.GetBatches(ctx, fields, orderByList, limit)
 	if err != nil {
-		log.Error("Failed to fetch committed L2 batches", "err", err)
+		logger.Error("Failed to fetch committed L2 batches", "err", err)
 		return
 	}
 	if len(batches) != 1 {
-		log.Warn("Unexpected result for GetBlockBatches", "number of batches", len(batches))
+		logger.Warn("Unexpected result for GetBlockBatches", "number of batches", len(batches))
 		return
 	}
 
 	r.metrics.rollupL2RelayerProcessCommittedBatchesTotal.Inc()
 
 	batch := batches[0]
+	logger = logger.New("batchID", batch.Hash)
 	status := types.ProvingStatus(batch.ProvingStatus)
 	switch status {
 	case types.ProvingTaskUnassigned, types.ProvingTaskAssigned:
 		if batch.CommittedAt == nil {
-			log.Error("batch.CommittedAt is nil", "index", batch.Index, "hash", batch.Hash)
+			logger.Error("batch.CommittedAt is nil", "index", batch.Index, "hash", batch.Hash)
 			return
 		}
 
 		if r.cfg.EnableTestEnvBypassFeatures && utils.NowUTC().Sub(*batch.CommittedAt) > time.Duration(r.cfg.FinalizeBatchWithoutProofTimeoutSec)*time.Second {
-			if err := r.finalizeBatch(batch, false); err != nil {
-				log.Error("Failed to finalize timeout batch without proof", "index", batch.Index, "hash", batch.Hash, "err", err)
+			if err := r.finalizeBatch(ctx, batch, false); err != nil {
+				logger.Error("Failed to finalize timeout batch without proof", "index", batch.Index, "hash", batch.Hash, "err", err)
 			}
 		}
 
 	case types.ProvingTaskVerified:
-		log.Info("Start to roll up zk proof", "hash", batch.Hash)
+		logger.Info("Start to roll up zk proof", "hash", batch.Hash)
 		r.metrics.rollupL2RelayerProcessCommittedBatchesFinalizedTotal.Inc()
-		if err := r.finalizeBatch(batch, true); err != nil {
-			log.Error("Failed to finalize batch with proof", "index", batch.Index, "hash", batch.Hash, "err", err)
+		if err := r.finalizeBatch(ctx, batch, true); err != nil {
+			logger.Error("Failed to finalize batch with proof", "index", batch.Index, "hash", batch.Hash, "err", err)
 		}
 
 	case types.ProvingTaskFailed:
@@ -491,7 +599,7 @@ func (r *Layer2Relayer) ProcessCommittedBatches() {
 		//     stop the ledger, fix the limit, revert all the violating blocks,
 		//     chunks and batches and all subsequent ones, and resume, i.e. this
 		//     case requires manual resolution.
-		log.Error(
+		logger.Error(
 			"batch proving failed",
 			"Index", batch.Index,
 			"Hash", batch.Hash,
@@ -501,84 +609,125 @@ func (r *Layer2Relayer) ProcessCommittedBatches() {
 		)
 
 	default:
-		log.Error("encounter unreachable case in ProcessCommittedBatches", "proving status", status)
+		logger.Error("encounter unreachable case in ProcessCommittedBatches", "proving status", status)
 	}
 }
 
-func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
+func (r *Layer2Relayer) finalizeBatch(ctx context.Context, batch *orm.Batch, withProof bool) error {
+	logger := butils.Logger(ctx, "batchID", batch.Hash)
+
 	// Check batch status before send `finalizeBatch` tx.
 	if r.cfg.ChainMonitor.Enabled {
 		var batchStatus bool
-		batchStatus, err := r.getBatchStatusByIndex(batch)
+		batchStatus, err := r.getBatchStatusByIndex(ctx, batch)
 		if err != nil {
 			r.metrics.rollupL2ChainMonitorLatestFailedCall.Inc()
-			log.Warn("failed to get batch status, please check chain_monitor api server", "batch_index", batch.Index, "err", err)
+			logger.Warn("failed to get batch status, please check chain_monitor api server", "batch_index", batch.Index, "err", err)
 			return err
 		}
 		if !batchStatus {
 			r.metrics.rollupL2ChainMonitorLatestFailedBatchStatus.Inc()
-			log.Error("the batch status is not right, stop finalize batch and check the reason", "batch_index", batch.Index)
+			logger.Error("the batch status is not right, stop finalize batch and check the reason", "batch_index", batch.Index)
 			return err
 		}
 	}
 
-	var parentBatchStateRoot string
-	if batch.Index > 0 {
-		var parentBatch *orm.Batch
-		parentBatch, err := r.batchOrm.GetBatchByIndex(r.ctx, batch.Index-1)
-		// handle unexpected db error
-		if err != nil {
-			log.Error("Failed to get batch", "index", batch.Index-1, "err", err)
-			return err
-		}
-		parentBatchStateRoot = parentBatch.StateRoot
+	if withProof {
+		return r.SubmitFinalizeProof(ctx, batch)
 	}
 
-	var txCalldata []byte
-	if withProof {
-		aggProof, err := r.batchOrm.GetVerifiedProofByHash(r.ctx, batch.Hash)
-		if err != nil {
-			log.Error("get verified proof by hash failed", "hash", batch.Hash, "err", err)
-			return err
-		}
+	parentBatchStateRoot, err := r.getParentBatchStateRoot(ctx, batch)
+	if err != nil {
+		logger.Error("Failed to get batch", "index", batch.Index-1, "err", err)
+		return err
+	}
 
-		if err = aggProof.SanityCheck(); err != nil {
-			log.Error("agg_proof sanity check fails", "hash", batch.Hash, "error", err)
-			return err
-		}
+	txCalldata, err := r.l1RollupABI.Pack(
+		"finalizeBatch",
+		batch.BatchHeader,
+		common.HexToHash(parentBatchStateRoot),
+		common.HexToHash(batch.StateRoot),
+		common.HexToHash(batch.WithdrawRoot),
+	)
+	if err != nil {
+		logger.Error("Pack finalizeBatch failed", "err", err)
+		return err
+	}
+	return r.sendFinalizeTx(ctx, batch, txCalldata, false)
+}
 
-		txCalldata, err = r.l1RollupABI.Pack(
-			"finalizeBatchWithProof",
-			batch.BatchHeader,
+// SubmitFinalizeProof submits the verified proof of a batch to the l1 rollup contract through finalizeBatchWithProof,
+// the public inputs of the proof being the batch header, the parent batch state root, the state root and the withdraw root.
+// If a local proof verifier is configured, a proof it rejects is not submitted and the error also wraps ErrProofInvalid.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *Layer2Relayer) SubmitFinalizeProof(ctx context.Context, batch *orm.Batch) error {
+	aggProof, err := r.batchOrm.GetVerifiedProofByHash(ctx, batch.Hash)
+	if err != nil {
+		return newTransientError("failed to GetVerifiedProofByHash, batch hash: %s: %w", batch.Hash, err)
+	}
+	if err = aggProof.SanityCheck(); err != nil {
+		return newPermanentError("agg_proof sanity check fails, batch hash: %s: %w", batch.Hash, err)
+	}
+
+	parentBatchStateRoot, err := r.getParentBatchStateRoot(ctx, batch)
+	if err != nil {
+		return newTransientError("failed to get parent batch, batch hash: %s: %w", batch.Hash, err)
+	}
+
+	if r.proofVerifier != nil {
+		publicInputs := []common.Hash{
 			common.HexToHash(parentBatchStateRoot),
 			common.HexToHash(batch.StateRoot),
 			common.HexToHash(batch.WithdrawRoot),
-			aggProof.Proof,
-		)
-		if err != nil {
-			log.Error("Pack finalizeBatchWithProof failed", "err", err)
-			return err
+			common.HexToHash(batch.Hash),
 		}
-	} else {
-		var err error
-		txCalldata, err = r.l1RollupABI.Pack(
-			"finalizeBatch",
-			batch.BatchHeader,
-			common.HexToHash(parentBatchStateRoot),
-			common.HexToHash(batch.StateRoot),
-			common.HexToHash(batch.WithdrawRoot),
-		)
-		if err != nil {
-			log.Error("Pack finalizeBatch failed", "err", err)
-			return err
+		if err = r.proofVerifier.VerifyProofLocally(aggProof.Proof, publicInputs); err != nil {
+			if errors.Is(err, ErrProofInvalid) {
+				r.metrics.rollupFinalizationProofInvalidTotal.Inc()
+				return newPermanentError("local proof verification fails, batch hash: %s: %w", batch.Hash, err)
+			}
+			return newTransientError("failed to verify proof locally, batch hash: %s: %w", batch.Hash, err)
 		}
 	}
 
+	txCalldata, err := r.l1RollupABI.Pack(
+		"finalizeBatchWithProof",
+		batch.BatchHeader,
+		common.HexToHash(parentBatchStateRoot),
+		common.HexToHash(batch.StateRoot),
+		common.HexToHash(batch.WithdrawRoot),
+		aggProof.Proof,
+	)
+	if err != nil {
+		return newPermanentError("failed to pack finalizeBatchWithProof, batch hash: %s: %w", batch.Hash, err)
+	}
+
+	if err := r.sendFinalizeTx(ctx, batch, txCalldata, true); err != nil {
+		return newTransientError("failed to submit finalizeBatchWithProof, batch hash: %s: %w", batch.Hash, err)
+	}
+	return nil
+}
+
+// getParentBatchStateRoot returns the state root of the parent batch, which is empty for the genesis batch.
+func (r *Layer2Relayer) getParentBatchStateRoot(ctx context.Context, batch *orm.Batch) (string, error) {
+	if batch.Index == 0 {
+		return "", nil
+	}
+	parentBatch, err := r.batchOrm.GetBatchByIndex(ctx, batch.Index-1)
+	if err != nil {
+		return "", err
+	}
+	return parentBatch.StateRoot, nil
+}
+
+func (r *Layer2Relayer) sendFinalizeTx(ctx context.Context, batch *orm.Batch, txCalldata []byte, withProof bool) error {
+	logger := butils.Logger(ctx, "batchID", batch.Hash)
+
 	// add suffix `-finalize` to avoid duplication with commit tx in unit tests
 	txHash, err := r.finalizeSender.SendTransaction(batch.Hash, &r.cfg.RollupContractAddress, big.NewInt(0), txCalldata, 0)
 	finalizeTxHash := &txHash
 	if err != nil {
-		log.Error(
+		logger.Error(
 			"finalizeBatch in layer1 failed",
 			"with proof", withProof,
 			"index", batch.Index,
@@ -586,7 +735,7 @@ func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
 			"RollupContractAddress", r.cfg.RollupContractAddress,
 			"err", err,
 		)
-		log.Debug(
+		logger.Debug(
 			"finalizeBatch in layer1 failed",
 			"with proof", withProof,
 			"index", batch.Index,
@@ -597,11 +746,15 @@ func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
 		)
 		return err
 	}
-	log.Info("finalizeBatch in layer1", "with proof", withProof, "index", batch.Index, "batch hash", batch.Hash, "tx hash", batch.Hash)
+	logger.Info("finalizeBatch in layer1", "with proof", withProof, "index", batch.Index, "batch hash", batch.Hash, "tx hash", batch.Hash)
 
 	// record and sync with db, @todo handle db error
-	if err := r.batchOrm.UpdateFinalizeTxHashAndRollupStatus(r.ctx, batch.Hash, finalizeTxHash.String(), types.RollupFinalizing); err != nil {
-		log.Error("UpdateFinalizeTxHashAndRollupStatus failed", "index", batch.Index, "batch hash", batch.Hash, "tx hash", finalizeTxHash.String(), "err", err)
+	if err := r.batchOrm.UpdateFinalizeTxHashAndRollupStatus(ctx, batch.Hash, finalizeTxHash.String(), types.RollupFinalizing); err != nil {
+		logger.Error("UpdateFinalizeTxHashAndRollupStatus failed", "index", batch.Index, "batch hash", batch.Hash, "tx hash", finalizeTxHash.String(), "err", err)
+		return err
+	}
+	if err := r.batchOrm.UpdateFinalizationStatus(ctx, batch.Hash, types.FinalizationSubmitted); err != nil {
+		logger.Error("UpdateFinalizationStatus failed", "index", batch.Index, "batch hash", batch.Hash, "err", err)
 		return err
 	}
 	r.metrics.rollupL2RelayerProcessCommittedBatchesFinalizedSuccessTotal.Inc()
@@ -615,14 +768,15 @@ type batchStatusResponse struct {
 	Data    bool   `json:"data"`
 }
 
-func (r *Layer2Relayer) getBatchStatusByIndex(batch *orm.Batch) (bool, error) {
-	chunks, getChunkErr := r.chunkOrm.GetChunksInRange(r.ctx, batch.StartChunkIndex, batch.EndChunkIndex)
+func (r *Layer2Relayer) getBatchStatusByIndex(ctx context.Context, batch *orm.Batch) (bool, error) {
+	logger := butils.Logger(ctx, "batchID", batch.Hash)
+	chunks, getChunkErr := r.chunkOrm.GetChunksInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex)
 	if getChunkErr != nil {
-		log.Error("Layer2Relayer.getBatchStatusByIndex get chunks range failed", "startChunkIndex", batch.StartChunkIndex, "endChunkIndex", batch.EndChunkIndex, "err", getChunkErr)
+		logger.Error("Layer2Relayer.getBatchStatusByIndex get chunks range failed", "startChunkIndex", batch.StartChunkIndex, "endChunkIndex", batch.EndChunkIndex, "err", getChunkErr)
 		return false, getChunkErr
 	}
 	if len(chunks) == 0 {
-		log.Error("Layer2Relayer.getBatchStatusByIndex get empty chunks", "startChunkIndex", batch.StartChunkIndex, "endChunkIndex", batch.EndChunkIndex)
+		logger.Error("Layer2Relayer.getBatchStatusByIndex get empty chunks", "startChunkIndex", batch.StartChunkIndex, "endChunkIndex", batch.EndChunkIndex)
 		return false, fmt.Errorf("startChunksIndex:%d endChunkIndex:%d get empty chunks", batch.StartChunkIndex, batch.EndChunkIndex)
 	}
 
@@ -654,7 +808,61 @@ func (r *Layer2Relayer) getBatchStatusByIndex(batch *orm.Batch) (bool, error) {
 	return response.Data, nil
 }
 
-func (r *Layer2Relayer) handleConfirmation(cfm *sender.Confirmation) {
+// CommitChunkCalldata returns the encoding of the chunk of the given index in the commitBatch calldata sent by
+// ProcessPendingBatches, the ScrollChain contract has no commitChunk function: the chunks are committed by batch.
+func (r *Layer2Relayer) CommitChunkCalldata(ctx context.Context, chunkIndex uint64) ([]byte, error) {
+	chunks, err := r.chunkOrm.GetChunksInRange(ctx, chunkIndex, chunkIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk, index: %d: %w", chunkIndex, err)
+	}
+	return r.encodeChunk(ctx, chunks[0])
+}
+
+// encodeChunk returns the DA encoding of the chunk committed in the _chunks of commitBatch.
+func (r *Layer2Relayer) encodeChunk(ctx context.Context, c *orm.Chunk) ([]byte, error) {
+	blocks, err := r.l2BlockOrm.GetL2BlocksInRange(ctx, c.StartBlockNumber, c.EndBlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blocks: %w", err)
+	}
+	daChunk, err := codecv0.NewDAChunk(&encoding.Chunk{Blocks: blocks}, c.TotalL1MessagesPoppedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize new DA chunk: %w", err)
+	}
+	daChunkBytes, err := daChunk.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DA chunk: %w", err)
+	}
+	return daChunkBytes, nil
+}
+
+// CostPredictor returns the finalization cost predictor of the rollup relayer, nil for the gas oracle.
+func (r *Layer2Relayer) CostPredictor() *GasCostPredictor {
+	return r.costPredictor
+}
+
+// HealthCheck checks the health of all the senders used by the relayer.
+func (r *Layer2Relayer) HealthCheck(ctx context.Context) error {
+	for _, s := range []*sender.Sender{r.commitSender, r.finalizeSender, r.gasOracleSender} {
+		if s == nil {
+			continue
+		}
+		if err := s.HealthCheck(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendAlert posts a critical event to the alert webhook, failures are only logged.
+func (r *Layer2Relayer) sendAlert(title, body string) {
+	if err := r.alertWebhook.send(r.ctx, title, body); err != nil {
+		log.Warn("Failed to send alert", "title", title, "err", err)
+	}
+}
+
+func (r *Layer2Relayer) handleConfirmation(cfm *sender.Confirmation) error {
+	// the context ids of all the layer2 relayer transactions are batch hashes.
+	logger := butils.Logger(r.ctx, "batchID", cfm.ContextID)
 	switch cfm.SenderType {
 	case types.SenderTypeCommitBatch:
 		var status types.RollupStatus
@@ -664,27 +872,41 @@ func (r *Layer2Relayer) handleConfirmation(cfm *sender.Confirmation) {
 		} else {
 			status = types.RollupCommitFailed
 			r.metrics.rollupL2BatchesCommittedConfirmedFailedTotal.Inc()
-			log.Warn("CommitBatchTxType transaction confirmed but failed in layer1", "confirmation", cfm)
+			logger.Warn("CommitBatchTxType transaction confirmed but failed in layer1", "confirmation", cfm)
 		}
 
 		err := r.batchOrm.UpdateCommitTxHashAndRollupStatus(r.ctx, cfm.ContextID, cfm.TxHash.String(), status)
 		if err != nil {
-			log.Warn("UpdateCommitTxHashAndRollupStatus failed", "confirmation", cfm, "err", err)
+			return newTransientError("failed to UpdateCommitTxHashAndRollupStatus, context ID: %s: %w", cfm.ContextID, err)
 		}
 	case types.SenderTypeFinalizeBatch:
 		var status types.RollupStatus
+		finalizationStatus := types.FinalizationFailed
 		if cfm.IsSuccessful {
 			status = types.RollupFinalized
+			finalizationStatus = types.FinalizationConfirmed
 			r.metrics.rollupL2BatchesFinalizedConfirmedTotal.Inc()
 		} else {
 			status = types.RollupFinalizeFailed
 			r.metrics.rollupL2BatchesFinalizedConfirmedFailedTotal.Inc()
-			log.Warn("FinalizeBatchTxType transaction confirmed but failed in layer1", "confirmation", cfm)
+			logger.Warn("FinalizeBatchTxType transaction confirmed but failed in layer1", "confirmation", cfm)
+			r.sendAlert("FinalizeBatchFailed", fmt.Sprintf("finalizeBatch transaction %s failed in layer1, batch hash: %s", cfm.TxHash.String(), cfm.ContextID))
 		}
 
 		err := r.batchOrm.UpdateFinalizeTxHashAndRollupStatus(r.ctx, cfm.ContextID, cfm.TxHash.String(), status)
 		if err != nil {
-			log.Warn("UpdateFinalizeTxHashAndRollupStatus failed", "confirmation", cfm, "err", err)
+			return newTransientError("failed to UpdateFinalizeTxHashAndRollupStatus, context ID: %s: %w", cfm.ContextID, err)
+		}
+		if cfm.IsSuccessful && r.costPredictor != nil {
+			if err := r.costPredictor.RecordFinalization(r.ctx, cfm); err != nil {
+				logger.Warn("Failed to record the finalization cost", "err", err)
+			}
+		}
+		if cfm.IsSuccessful && r.finalityChecker != nil {
+			// the finalization stays submitted until it is deep enough on both chains.
+			go r.waitForFinality(r.ctx, cfm.ContextID, cfm.TxHash)
+		} else if err := r.batchOrm.UpdateFinalizationStatus(r.ctx, cfm.ContextID, finalizationStatus); err != nil {
+			return newTransientError("failed to UpdateFinalizationStatus, context ID: %s: %w", cfm.ContextID, err)
 		}
 	case types.SenderTypeL2GasOracle:
 		batchHash := cfm.ContextID
@@ -695,18 +917,72 @@ func (r *Layer2Relayer) handleConfirmation(cfm *sender.Confirmation) {
 		} else {
 			status = types.GasOracleImportedFailed
 			r.metrics.rollupL2UpdateGasOracleConfirmedFailedTotal.Inc()
-			log.Warn("UpdateGasOracleTxType transaction confirmed but failed in layer1", "confirmation", cfm)
+			logger.Warn("UpdateGasOracleTxType transaction confirmed but failed in layer1", "confirmation", cfm)
+			r.sendAlert("L2GasOracleImportFailed", fmt.Sprintf("setL2BaseFee transaction %s failed in layer1, batch hash: %s", cfm.TxHash.String(), cfm.ContextID))
 		}
 
 		err := r.batchOrm.UpdateL2GasOracleStatusAndOracleTxHash(r.ctx, batchHash, status, cfm.TxHash.String())
 		if err != nil {
-			log.Warn("UpdateL2GasOracleStatusAndOracleTxHash failed", "confirmation", cfm, "err", err)
+			return newTransientError("failed to UpdateL2GasOracleStatusAndOracleTxHash, context ID: %s: %w", cfm.ContextID, err)
 		}
 	default:
-		log.Warn("Unknown transaction type", "confirmation", cfm)
+		return newPermanentError("unknown transaction type: %v, context ID: %s", cfm.SenderType, cfm.ContextID)
+	}
+
+	logger.Info("Transaction confirmed in layer1", "confirmation", cfm)
+	return nil
+}
+
+// waitForFinality marks the finalization of the batch as confirmed once its finalize transaction has the l1
+// confirmations and its last block the l2 confirmations of the finality checker.
+func (r *Layer2Relayer) waitForFinality(ctx context.Context, batchHash string, finalizeTxHash common.Hash) {
+	logger := butils.Logger(ctx, "batchID", batchHash)
+	batches, err := r.batchOrm.GetBatches(ctx, map[string]interface{}{"hash = ?": batchHash}, nil, 1)
+	if err != nil || len(batches) != 1 {
+		logger.Error("failed to get the finalized batch", "err", err)
+		return
+	}
+	chunks, err := r.chunkOrm.GetChunksInRange(ctx, batches[0].EndChunkIndex, batches[0].EndChunkIndex)
+	if err != nil || len(chunks) != 1 {
+		logger.Error("failed to get the last chunk of the finalized batch", "end chunk index", batches[0].EndChunkIndex, "err", err)
+		return
 	}
 
-	log.Info("Transaction confirmed in layer1", "confirmation", cfm)
+	event, ok := <-r.finalityChecker.Wait(ctx, batchHash, finalizeTxHash, chunks[0].EndBlockNumber)
+	if !ok {
+		return
+	}
+	if err = r.batchOrm.UpdateFinalizationStatus(ctx, batchHash, types.FinalizationConfirmed); err != nil {
+		logger.Error("failed to UpdateFinalizationStatus", "err", err)
+		return
+	}
+	r.metrics.rollupL2BatchesFinalityConfirmedTotal.Inc()
+	logger.Info("Batch finalization confirmed on both chains", "finalize tx hash", finalizeTxHash.String(), "l1 block number", event.L1BlockNumber,
+		"l1 confirmations", event.L1Confirmations, "l2 block number", event.L2BlockNumber, "l2 confirmations", event.L2Confirmations)
+}
+
+// finalityCheckResumeLimit is the number of submitted finalizations loaded per resumeFinalityChecks round.
+const finalityCheckResumeLimit = 100
+
+// resumeFinalityChecks waits again for the finality of the batches finalized before a restart.
+func (r *Layer2Relayer) resumeFinalityChecks(ctx context.Context) {
+	var afterIndex uint64
+	for {
+		batches, err := r.batchOrm.GetBatchesByFinalizationStatus(ctx, types.FinalizationSubmitted, afterIndex, finalityCheckResumeLimit)
+		if err != nil {
+			log.Error("failed to get the submitted finalizations", "after index", afterIndex, "err", err)
+			return
+		}
+		for _, batch := range batches {
+			if types.RollupStatus(batch.RollupStatus) == types.RollupFinalized && batch.FinalizeTxHash != "" {
+				go r.waitForFinality(ctx, batch.Hash, common.HexToHash(batch.FinalizeTxHash))
+			}
+		}
+		if len(batches) < finalityCheckResumeLimit {
+			return
+		}
+		afterIndex = batches[len(batches)-1].Index
+	}
 }
 
 func (r *Layer2Relayer) handleL2GasOracleConfirmLoop(ctx context.Context) {
@@ -715,7 +991,9 @@ func (r *Layer2Relayer) handleL2GasOracleConfirmLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case cfm := <-r.gasOracleSender.ConfirmChan():
-			r.handleConfirmation(cfm)
+			if err := r.handleConfirmation(cfm); err != nil {
+				LogError("Failed to handle l2 gas oracle confirmation", err, "confirmation", cfm)
+			}
 		}
 	}
 }
@@ -726,9 +1004,13 @@ func (r *Layer2Relayer) handleL2RollupRelayerConfirmLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case cfm := <-r.commitSender.ConfirmChan():
-			r.handleConfirmation(cfm)
+			if err := r.handleConfirmation(cfm); err != nil {
+				LogError("Failed to handle commit batch confirmation", err, "confirmation", cfm)
+			}
 		case cfm := <-r.finalizeSender.ConfirmChan():
-			r.handleConfirmation(cfm)
+			if err := r.handleConfirmation(cfm); err != nil {
+				LogError("Failed to handle finalize batch confirmation", err, "confirmation", cfm)
+			}
 		}
 	}
 }
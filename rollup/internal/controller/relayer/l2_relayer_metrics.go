@@ -19,10 +19,13 @@ type l2RelayerMetrics struct {
 	rollupL2BatchesCommittedConfirmedFailedTotal                prometheus.Counter
 	rollupL2BatchesFinalizedConfirmedTotal                      prometheus.Counter
 	rollupL2BatchesFinalizedConfirmedFailedTotal                prometheus.Counter
+	rollupL2BatchesFinalityConfirmedTotal                       prometheus.Counter
 	rollupL2UpdateGasOracleConfirmedTotal                       prometheus.Counter
 	rollupL2UpdateGasOracleConfirmedFailedTotal                 prometheus.Counter
 	rollupL2ChainMonitorLatestFailedCall                        prometheus.Counter
 	rollupL2ChainMonitorLatestFailedBatchStatus                 prometheus.Counter
+	rollupL2RelayerInFlightBatchCommits                         prometheus.Gauge
+	rollupFinalizationProofInvalidTotal                         prometheus.Counter
 }
 
 var (
@@ -73,6 +76,10 @@ func initL2RelayerMetrics(reg prometheus.Registerer) *l2RelayerMetrics {
 				Name: "rollup_layer2_process_finalized_batches_confirmed_total",
 				Help: "The total number of layer2 process finalized batches confirmed total",
 			}),
+			rollupL2BatchesFinalityConfirmedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer2_finalized_batches_finality_confirmed_total",
+				Help: "The total number of finalized batches confirmed once deep enough on both l1 and l2",
+			}),
 			rollupL2BatchesFinalizedConfirmedFailedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 				Name: "rollup_layer2_process_finalized_batches_confirmed_failed_total",
 				Help: "The total number of layer2 process finalized batches confirmed failed total",
@@ -93,6 +100,14 @@ func initL2RelayerMetrics(reg prometheus.Registerer) *l2RelayerMetrics {
 				Name: "rollup_layer2_chain_monitor_latest_failed_batch_status",
 				Help: "The total number of failed batch status get from chain_monitor",
 			}),
+			rollupL2RelayerInFlightBatchCommits: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_layer2_relayer_in_flight_batch_commits",
+				Help: "The number of commitBatch txs waiting for confirmation",
+			}),
+			rollupFinalizationProofInvalidTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_finalization_proof_invalid_total",
+				Help: "The total number of batch proofs rejected by the local proof verifier",
+			}),
 		}
 	})
 	return l2RelayerMetric
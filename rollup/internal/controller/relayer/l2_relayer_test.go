@@ -18,6 +18,7 @@ import (
 	"scroll-tech/common/database"
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
 
@@ -84,6 +85,86 @@ func testL2RelayerProcessPendingBatches(t *testing.T) {
 	assert.Equal(t, types.RollupCommitting, statuses[0])
 }
 
+func testL2RelayerCommitChunkCalldata(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orm.NewL2Block(db).InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2}))
+	chunkOrm := orm.NewChunk(db)
+	_, err = chunkOrm.InsertChunk(context.Background(), chunk1)
+	assert.NoError(t, err)
+	dbChunk2, err := chunkOrm.InsertChunk(context.Background(), chunk2)
+	assert.NoError(t, err)
+
+	// the encoding of the chunk in the commitBatch calldata.
+	daChunk, err := codecv0.NewDAChunk(chunk2, dbChunk2.TotalL1MessagesPoppedBefore)
+	assert.NoError(t, err)
+	expected, err := daChunk.Encode()
+	assert.NoError(t, err)
+
+	calldata, err := relayer.CommitChunkCalldata(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, calldata)
+
+	_, err = relayer.CommitChunkCalldata(context.Background(), 2)
+	assert.Error(t, err)
+}
+
+func testL2RelayerProcessPendingBatchesConcurrencyLimit(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	relayerCfg := *cfg.L2Config.RelayerConfig
+	relayerCfg.MaxConcurrentBatchCommits = 2
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, &relayerCfg, false, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err = l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	chunkOrm := orm.NewChunk(db)
+	_, err = chunkOrm.InsertChunk(context.Background(), chunk1)
+	assert.NoError(t, err)
+	_, err = chunkOrm.InsertChunk(context.Background(), chunk2)
+	assert.NoError(t, err)
+
+	batchOrm := orm.NewBatch(db)
+	var hashes []string
+	for i := uint64(0); i < 4; i++ {
+		batch := &encoding.Batch{
+			Index:           i,
+			Chunks:          []*encoding.Chunk{chunk1, chunk2},
+			StartChunkIndex: 0,
+			StartChunkHash:  chunkHash1,
+			EndChunkIndex:   1,
+			EndChunkHash:    chunkHash2,
+		}
+		dbBatch, err := batchOrm.InsertBatch(context.Background(), batch)
+		assert.NoError(t, err)
+		hashes = append(hashes, dbBatch.Hash)
+	}
+
+	relayer.ProcessPendingBatches()
+	statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitting, types.RollupCommitting, types.RollupPending, types.RollupPending}, statuses)
+
+	// The cap is reached until an in-flight commit is confirmed.
+	relayer.ProcessPendingBatches()
+	statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitting, types.RollupCommitting, types.RollupPending, types.RollupPending}, statuses)
+
+	assert.NoError(t, batchOrm.UpdateRollupStatus(context.Background(), hashes[0], types.RollupCommitted))
+	relayer.ProcessPendingBatches()
+	statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitted, types.RollupCommitting, types.RollupCommitting, types.RollupPending}, statuses)
+}
+
 func testL2RelayerProcessCommittedBatches(t *testing.T) {
 	db := setupL2RelayerDB(t)
 	defer database.CloseDB(db)
@@ -277,12 +358,19 @@ func testL2RelayerFinalizeConfirm(t *testing.T) {
 			types.RollupFinalized,
 			types.RollupFinalizeFailed,
 		}
+		expectedFinalizationStatuses := []types.BatchFinalizationStatus{
+			types.FinalizationConfirmed,
+			types.FinalizationFailed,
+		}
 
 		for i, batchHash := range batchHashes {
 			batchInDB, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{"hash": batchHash}, nil, 0)
 			if err != nil || len(batchInDB) != 1 || types.RollupStatus(batchInDB[0].RollupStatus) != expectedStatuses[i] {
 				return false
 			}
+			if types.BatchFinalizationStatus(batchInDB[0].FinalizationStatus) != expectedFinalizationStatuses[i] {
+				return false
+			}
 		}
 		return true
 	})
@@ -376,7 +464,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 			return nil, targetErr
 		})
 		defer patchGuard.Reset()
-		relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetLatestBatch", func(context.Context) (*orm.Batch, error) {
@@ -393,7 +481,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(relayer.l2Client, "SuggestGasPrice", func(ctx context.Context) (*big.Int, error) {
 			return nil, targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	patchGuard.ApplyMethodFunc(relayer.l2Client, "SuggestGasPrice", func(ctx context.Context) (*big.Int, error) {
@@ -405,7 +493,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(relayer.l2GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
 			return nil, targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, relayer.ProcessGasPriceOracle(), ErrPermanent)
 	})
 
 	patchGuard.ApplyMethodFunc(relayer.l2GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
@@ -417,7 +505,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(relayer.gasOracleSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
 			return common.Hash{}, targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	patchGuard.ApplyMethodFunc(relayer.gasOracleSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
@@ -429,13 +517,98 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(batchOrm, "UpdateL2GasOracleStatusAndOracleTxHash", func(ctx context.Context, hash string, status types.GasOracleStatus, txHash string) error {
 			return targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		assert.ErrorIs(t, relayer.ProcessGasPriceOracle(), ErrTransient)
 	})
 
 	patchGuard.ApplyMethodFunc(batchOrm, "UpdateL2GasOracleStatusAndOracleTxHash", func(ctx context.Context, hash string, status types.GasOracleStatus, txHash string) error {
 		return nil
 	})
-	relayer.ProcessGasPriceOracle()
+	assert.NoError(t, relayer.ProcessGasPriceOracle())
+}
+
+func testLayer2RelayerSubmitFinalizeProof(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, relayer)
+
+	batch := &orm.Batch{
+		Index:        1,
+		Hash:         "0x0000000000000000000000000000000000000000",
+		StateRoot:    "0x0000000000000000000000000000000000000000000000000000000000000001",
+		WithdrawRoot: "0x0000000000000000000000000000000000000000000000000000000000000002",
+	}
+
+	var batchOrm *orm.Batch
+	convey.Convey("Failed to GetVerifiedProofByHash", t, func() {
+		targetErr := errors.New("GetVerifiedProofByHash error")
+		patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetVerifiedProofByHash", func(context.Context, string) (*message.BatchProof, error) {
+			return nil, targetErr
+		})
+		defer patchGuard.Reset()
+		assert.ErrorIs(t, relayer.SubmitFinalizeProof(context.Background(), batch), ErrTransient)
+	})
+
+	convey.Convey("Proof fails the sanity check", t, func() {
+		patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetVerifiedProofByHash", func(context.Context, string) (*message.BatchProof, error) {
+			return &message.BatchProof{}, nil
+		})
+		defer patchGuard.Reset()
+		assert.ErrorIs(t, relayer.SubmitFinalizeProof(context.Background(), batch), ErrPermanent)
+	})
+
+	patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetVerifiedProofByHash", func(context.Context, string) (*message.BatchProof, error) {
+		return &message.BatchProof{
+			Proof:     make([]byte, 32),
+			Instances: make([]byte, 32),
+			Vk:        make([]byte, 32),
+		}, nil
+	})
+	defer patchGuard.Reset()
+
+	convey.Convey("Failed to get the parent batch", t, func() {
+		targetErr := errors.New("GetBatchByIndex error")
+		patchGuard.ApplyMethodFunc(batchOrm, "GetBatchByIndex", func(context.Context, uint64) (*orm.Batch, error) {
+			return nil, targetErr
+		})
+		assert.ErrorIs(t, relayer.SubmitFinalizeProof(context.Background(), batch), ErrTransient)
+	})
+
+	patchGuard.ApplyMethodFunc(batchOrm, "GetBatchByIndex", func(context.Context, uint64) (*orm.Batch, error) {
+		return &orm.Batch{StateRoot: "0x0000000000000000000000000000000000000000000000000000000000000003"}, nil
+	})
+
+	convey.Convey("Failed to send finalizeBatchWithProof tx to layer1", t, func() {
+		targetErr := errors.New("failed to send finalizeBatchWithProof tx to layer1 error")
+		patchGuard.ApplyMethodFunc(relayer.finalizeSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
+			return common.Hash{}, targetErr
+		})
+		assert.ErrorIs(t, relayer.SubmitFinalizeProof(context.Background(), batch), ErrTransient)
+	})
+
+	patchGuard.ApplyMethodFunc(relayer.finalizeSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
+		return common.HexToHash("0x56789abcdef1234"), nil
+	})
+	patchGuard.ApplyMethodFunc(batchOrm, "UpdateFinalizeTxHashAndRollupStatus", func(ctx context.Context, hash string, finalizeTxHash string, status types.RollupStatus) error {
+		assert.Equal(t, types.RollupFinalizing, status)
+		return nil
+	})
+
+	convey.Convey("UpdateFinalizationStatus failed", t, func() {
+		targetErr := errors.New("UpdateFinalizationStatus error")
+		patchGuard.ApplyMethodFunc(batchOrm, "UpdateFinalizationStatus", func(ctx context.Context, hash string, status types.BatchFinalizationStatus, dbTX ...*gorm.DB) error {
+			return targetErr
+		})
+		assert.ErrorIs(t, relayer.SubmitFinalizeProof(context.Background(), batch), ErrTransient)
+	})
+
+	patchGuard.ApplyMethodFunc(batchOrm, "UpdateFinalizationStatus", func(ctx context.Context, hash string, status types.BatchFinalizationStatus, dbTX ...*gorm.DB) error {
+		assert.Equal(t, types.FinalizationSubmitted, status)
+		return nil
+	})
+	assert.NoError(t, relayer.SubmitFinalizeProof(context.Background(), batch))
 }
 
 func mockChainMonitorServer(baseURL string) (*http.Server, error) {
@@ -488,7 +661,7 @@ func testGetBatchStatusByIndex(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 
-	status, err := relayer.getBatchStatusByIndex(dbBatch)
+	status, err := relayer.getBatchStatusByIndex(context.Background(), dbBatch)
 	assert.NoError(t, err)
 	assert.Equal(t, true, status)
 }
@@ -0,0 +1,108 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"plugin"
+	"strings"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// verifyProofSymbol is the function a verifier plugin must export, with the signature of pluginVerifyProof.
+const verifyProofSymbol = "VerifyProof"
+
+// pluginVerifyProof returns an error if the proof is invalid for the public inputs.
+type pluginVerifyProof = func(proof []byte, publicInputs [][32]byte) error
+
+// localVerifierInput is the json written to the stdin of the verifier binary.
+type localVerifierInput struct {
+	Proof        hexutil.Bytes `json:"proof"`
+	PublicInputs []common.Hash `json:"public_inputs"`
+}
+
+// LocalProofVerifier verifies the batch proofs locally, so that an invalid proof is not submitted to l1.
+type LocalProofVerifier struct {
+	binaryPath string
+	timeout    time.Duration
+	verify     pluginVerifyProof
+}
+
+// NewLocalProofVerifier returns a new instance of LocalProofVerifier, the plugin is loaded if configured.
+func NewLocalProofVerifier(cfg *config.LocalProofVerifierConfig) (*LocalProofVerifier, error) {
+	v := &LocalProofVerifier{
+		binaryPath: cfg.BinaryPath,
+		timeout:    time.Duration(cfg.TimeoutSec) * time.Second,
+	}
+	if cfg.PluginPath == "" {
+		return v, nil
+	}
+
+	p, err := plugin.Open(cfg.PluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open verifier plugin %v: %w", cfg.PluginPath, err)
+	}
+	symbol, err := p.Lookup(verifyProofSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %v in verifier plugin %v: %w", verifyProofSymbol, cfg.PluginPath, err)
+	}
+	verify, ok := symbol.(pluginVerifyProof)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type of %v in verifier plugin %v: %T", verifyProofSymbol, cfg.PluginPath, symbol)
+	}
+	v.verify = verify
+	return v, nil
+}
+
+// VerifyProofLocally verifies the proof for the public inputs, the returned error wraps ErrProofInvalid if the
+// verifier rejects the proof, the other errors are failures to run the verifier.
+func (v *LocalProofVerifier) VerifyProofLocally(proof []byte, publicInputs []common.Hash) error {
+	if v.verify != nil {
+		inputs := make([][32]byte, len(publicInputs))
+		for i, input := range publicInputs {
+			inputs[i] = input
+		}
+		if err := v.verify(proof, inputs); err != nil {
+			return fmt.Errorf("%w: %v", ErrProofInvalid, err)
+		}
+		return nil
+	}
+
+	input, err := json.Marshal(&localVerifierInput{Proof: proof, PublicInputs: publicInputs})
+	if err != nil {
+		return fmt.Errorf("failed to encode verifier input: %w", err)
+	}
+	ctx := context.Background()
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, v.binaryPath)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = &stderr
+	// the children of a killed verifier may hold its stderr open.
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return fmt.Errorf("verifier %v timed out: %w", v.binaryPath, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%w: verifier exit code %d: %s", ErrProofInvalid, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run verifier %v: %w", v.binaryPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// writeVerifierScript writes an executable shell script verifier and returns its path.
+func writeVerifierScript(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "verifier.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestLocalProofVerifierBinary(t *testing.T) {
+	proof := []byte{0x01, 0x02}
+	publicInputs := []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2")}
+
+	// the input is written to the stdin of a valid verifier.
+	inputPath := filepath.Join(t.TempDir(), "input.json")
+	v, err := NewLocalProofVerifier(&config.LocalProofVerifierConfig{BinaryPath: writeVerifierScript(t, "cat > "+inputPath+"\n")})
+	require.NoError(t, err)
+	assert.NoError(t, v.VerifyProofLocally(proof, publicInputs))
+	raw, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+	var input localVerifierInput
+	require.NoError(t, json.Unmarshal(raw, &input))
+	assert.Equal(t, proof, []byte(input.Proof))
+	assert.Equal(t, publicInputs, input.PublicInputs)
+
+	// a non zero exit code rejects the proof.
+	v, err = NewLocalProofVerifier(&config.LocalProofVerifierConfig{BinaryPath: writeVerifierScript(t, "echo bad proof >&2\nexit 1\n")})
+	require.NoError(t, err)
+	err = v.VerifyProofLocally(proof, publicInputs)
+	assert.ErrorIs(t, err, ErrProofInvalid)
+	assert.ErrorContains(t, err, "bad proof")
+
+	// a verifier failing to run does not reject the proof.
+	v, err = NewLocalProofVerifier(&config.LocalProofVerifierConfig{BinaryPath: filepath.Join(t.TempDir(), "missing")})
+	require.NoError(t, err)
+	err = v.VerifyProofLocally(proof, publicInputs)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrProofInvalid))
+
+	v, err = NewLocalProofVerifier(&config.LocalProofVerifierConfig{BinaryPath: writeVerifierScript(t, "sleep 5\n"), TimeoutSec: 1})
+	require.NoError(t, err)
+	err = v.VerifyProofLocally(proof, publicInputs)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrProofInvalid))
+}
+
+func TestLocalProofVerifierPlugin(t *testing.T) {
+	_, err := NewLocalProofVerifier(&config.LocalProofVerifierConfig{PluginPath: filepath.Join(t.TempDir(), "missing.so")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const (
+	// ProofAggregationInterval is the interval AggregateReadyBatches should be called at.
+	ProofAggregationInterval = 10 * time.Second
+	// proofAggregationBatchLimit is the maximum number of batches aggregated per AggregateReadyBatches.
+	proofAggregationBatchLimit = 10
+)
+
+// aggregationRequest is the body posted to the aggregation service.
+type aggregationRequest struct {
+	BatchIndex  uint64               `json:"batch_index"`
+	BatchHash   string               `json:"batch_hash"`
+	ChunkProofs []message.ChunkProof `json:"chunk_proofs"`
+}
+
+// aggregationResponse is the aggregated proof returned by the aggregation service.
+type aggregationResponse struct {
+	AggregatedProofData    []byte `json:"aggregated_proof_data"`
+	AggregatedPublicInputs []byte `json:"aggregated_public_inputs"`
+}
+
+// ProofAggregator aggregates the proofs of the chunks of a batch into one proof through an aggregation service.
+// The aggregated proofs are stored in the aggregated_proof table.
+type ProofAggregator struct {
+	cfg *config.ProofAggregationConfig
+
+	db                 *gorm.DB
+	batchOrm           *orm.Batch
+	chunkOrm           *orm.Chunk
+	aggregatedProofOrm *orm.AggregatedProof
+
+	client *resty.Client
+
+	metrics *proofAggregatorMetrics
+}
+
+// NewProofAggregator returns a new instance of ProofAggregator.
+func NewProofAggregator(cfg *config.ProofAggregationConfig, db *gorm.DB, reg prometheus.Registerer) *ProofAggregator {
+	client := resty.New()
+	if cfg.TimeoutSec > 0 {
+		client.SetTimeout(time.Duration(cfg.TimeoutSec) * time.Second)
+	}
+	return &ProofAggregator{
+		cfg:                cfg,
+		db:                 db,
+		batchOrm:           orm.NewBatch(db),
+		chunkOrm:           orm.NewChunk(db),
+		aggregatedProofOrm: orm.NewAggregatedProof(db),
+		client:             client,
+		metrics:            initProofAggregatorMetrics(reg),
+	}
+}
+
+// AggregateReadyBatches aggregates the chunk proofs of the batches whose chunks are all proven and not aggregated yet.
+// It returns the first error of the batches, the other batches are still aggregated.
+func (a *ProofAggregator) AggregateReadyBatches(ctx context.Context) error {
+	fields := map[string]interface{}{
+		"chunk_proofs_status = ?":      int(types.ChunkProofsStatusReady),
+		"proof_aggregation_status = ?": int(types.ProofAggregationPending),
+	}
+	batches, err := a.batchOrm.GetBatches(ctx, fields, []string{"index ASC"}, proofAggregationBatchLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get the batches ready for proof aggregation: %w", err)
+	}
+
+	var firstErr error
+	for _, batch := range batches {
+		if err = a.AggregatePendingChunkProofs(ctx, batch.Index); err != nil {
+			log.Warn("Failed to aggregate chunk proofs", "index", batch.Index, "hash", batch.Hash, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// AggregatePendingChunkProofs fetches the proofs of the chunks of a batch, aggregates them through the aggregation
+// service and stores the aggregated proof, the proof aggregation of the batch is then complete. The batch is marked
+// as failed if the service rejects the chunk proofs, it stays pending on the other errors.
+func (a *ProofAggregator) AggregatePendingChunkProofs(ctx context.Context, batchIndex uint64) error {
+	batch, err := a.batchOrm.GetBatchByIndex(ctx, batchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get batch, index: %d: %w", batchIndex, err)
+	}
+	if batch == nil {
+		return fmt.Errorf("batch not found, index: %d", batchIndex)
+	}
+	if types.ProofAggregationStatus(batch.ProofAggregationStatus) == types.ProofAggregationComplete {
+		return nil
+	}
+
+	chunkProofs, err := a.getChunkProofs(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	aggregated, rejected, err := a.requestAggregation(ctx, batch, chunkProofs)
+	if err != nil {
+		if rejected {
+			a.metrics.rollupProofAggregationFailedTotal.Inc()
+			if updateErr := a.batchOrm.UpdateProofAggregationStatus(ctx, batch.Hash, types.ProofAggregationFailed); updateErr != nil {
+				log.Error("Failed to update proof aggregation status", "index", batchIndex, "err", updateErr)
+			}
+		}
+		return err
+	}
+
+	proof := &message.AggregatedProof{
+		BatchIndex:             batchIndex,
+		ChunkProofs:            chunkProofs,
+		AggregatedProofData:    aggregated.AggregatedProofData,
+		AggregatedPublicInputs: aggregated.AggregatedPublicInputs,
+	}
+	err = a.db.Transaction(func(dbTX *gorm.DB) error {
+		if err := a.aggregatedProofOrm.InsertAggregatedProof(ctx, batch.Hash, proof, dbTX); err != nil {
+			return err
+		}
+		return a.batchOrm.UpdateProofAggregationStatus(ctx, batch.Hash, types.ProofAggregationComplete, dbTX)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.metrics.rollupProofAggregationCompletedTotal.Inc()
+	log.Info("Chunk proofs aggregated", "index", batchIndex, "hash", batch.Hash, "chunks", len(chunkProofs))
+	return nil
+}
+
+// getChunkProofs returns the proofs of the chunks of the batch in order, all the chunks must be proven.
+func (a *ProofAggregator) getChunkProofs(ctx context.Context, batch *orm.Batch) ([]message.ChunkProof, error) {
+	chunks, err := a.chunkOrm.GetChunksInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the chunks of batch, index: %d: %w", batch.Index, err)
+	}
+
+	chunkProofs := make([]message.ChunkProof, 0, len(chunks))
+	for _, chunk := range chunks {
+		if len(chunk.Proof) == 0 {
+			return nil, fmt.Errorf("chunk proof not ready, batch index: %d, chunk index: %d", batch.Index, chunk.Index)
+		}
+		var chunkProof message.ChunkProof
+		if err = json.Unmarshal(chunk.Proof, &chunkProof); err != nil {
+			return nil, fmt.Errorf("failed to decode chunk proof, chunk index: %d: %w", chunk.Index, err)
+		}
+		chunkProofs = append(chunkProofs, chunkProof)
+	}
+	return chunkProofs, nil
+}
+
+// requestAggregation posts the chunk proofs of the batch to the aggregation service and returns the aggregated proof.
+// rejected is true if the service refused the chunk proofs, rather than being unavailable.
+func (a *ProofAggregator) requestAggregation(ctx context.Context, batch *orm.Batch, chunkProofs []message.ChunkProof) (*aggregationResponse, bool, error) {
+	var aggregated aggregationResponse
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetBody(&aggregationRequest{BatchIndex: batch.Index, BatchHash: batch.Hash, ChunkProofs: chunkProofs}).
+		SetResult(&aggregated).
+		Post(a.cfg.AggregationServiceURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to request proof aggregation: %w", err)
+	}
+	if resp.IsError() {
+		rejected := resp.StatusCode() < 500
+		return nil, rejected, fmt.Errorf("failed to request proof aggregation, status: %s, body: %s", resp.Status(), resp.String())
+	}
+	if len(aggregated.AggregatedProofData) == 0 {
+		return nil, false, fmt.Errorf("empty aggregated proof, batch index: %d", batch.Index)
+	}
+	return &aggregated, false, nil
+}
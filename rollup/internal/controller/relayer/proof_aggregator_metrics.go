@@ -0,0 +1,34 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type proofAggregatorMetrics struct {
+	rollupProofAggregationCompletedTotal prometheus.Counter
+	rollupProofAggregationFailedTotal    prometheus.Counter
+}
+
+var (
+	initProofAggregatorMetricOnce sync.Once
+	proofAggregatorMetric         *proofAggregatorMetrics
+)
+
+func initProofAggregatorMetrics(reg prometheus.Registerer) *proofAggregatorMetrics {
+	initProofAggregatorMetricOnce.Do(func() {
+		proofAggregatorMetric = &proofAggregatorMetrics{
+			rollupProofAggregationCompletedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_aggregation_completed_total",
+				Help: "The total number of batches whose chunk proofs are aggregated",
+			}),
+			rollupProofAggregationFailedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_aggregation_failed_total",
+				Help: "The total number of batches whose chunk proofs are rejected by the aggregation service",
+			}),
+		}
+	})
+	return proofAggregatorMetric
+}
@@ -0,0 +1,59 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func TestProofAggregatorRequestAggregation(t *testing.T) {
+	chunkProofs := []message.ChunkProof{{Proof: []byte{1}}, {Proof: []byte{2}}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var req aggregationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, chunkProofs, req.ChunkProofs)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.BatchIndex {
+		case 1:
+			assert.Equal(t, "0x01", req.BatchHash)
+			assert.NoError(t, json.NewEncoder(w).Encode(&aggregationResponse{AggregatedProofData: []byte{3}, AggregatedPublicInputs: []byte{4}}))
+		case 2:
+			http.Error(w, "invalid chunk proof", http.StatusBadRequest)
+		case 3:
+			assert.NoError(t, json.NewEncoder(w).Encode(&aggregationResponse{}))
+		default:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	a := NewProofAggregator(&config.ProofAggregationConfig{AggregationServiceURL: server.URL, TimeoutSec: 5}, nil, nil)
+
+	aggregated, rejected, err := a.requestAggregation(context.Background(), &orm.Batch{Index: 1, Hash: "0x01"}, chunkProofs)
+	assert.NoError(t, err)
+	assert.False(t, rejected)
+	assert.Equal(t, &aggregationResponse{AggregatedProofData: []byte{3}, AggregatedPublicInputs: []byte{4}}, aggregated)
+
+	_, rejected, err = a.requestAggregation(context.Background(), &orm.Batch{Index: 2, Hash: "0x02"}, chunkProofs)
+	assert.ErrorContains(t, err, "invalid chunk proof")
+	assert.True(t, rejected)
+
+	_, rejected, err = a.requestAggregation(context.Background(), &orm.Batch{Index: 3, Hash: "0x03"}, chunkProofs)
+	assert.ErrorContains(t, err, "empty aggregated proof")
+	assert.False(t, rejected)
+
+	// the batch stays pending while the service is unavailable.
+	_, rejected, err = a.requestAggregation(context.Background(), &orm.Batch{Index: 4, Hash: "0x04"}, chunkProofs)
+	assert.ErrorContains(t, err, "500")
+	assert.False(t, rejected)
+}
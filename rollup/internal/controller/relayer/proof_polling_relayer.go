@@ -0,0 +1,252 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const (
+	// defaultProofPollInterval is the interval between two polls if it is not configured.
+	defaultProofPollInterval = 10 * time.Second
+	// defaultProofPollMaxBackoff is the upper bound of the delay between two polls of a batch if it is not configured.
+	defaultProofPollMaxBackoff = 10 * time.Minute
+	// proofPollBatchLimit is the maximum number of batches polled per PollBatchProofStatus.
+	proofPollBatchLimit = 100
+)
+
+// The statuses of a batch proof returned by the proving service, proven and failed are terminal.
+const (
+	ProofStatusPending = "pending"
+	ProofStatusProving = "proving"
+	ProofStatusProven  = "proven"
+	ProofStatusFailed  = "failed"
+)
+
+// ProofStatus is the proof of a batch returned by the proving service, Proof is set if Status is proven.
+type ProofStatus struct {
+	Status string              `json:"status"`
+	Proof  *message.BatchProof `json:"proof,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// proofPollBackoff is the next time a batch whose proof is not ready is polled.
+type proofPollBackoff struct {
+	delay      time.Duration
+	nextPollAt time.Time
+}
+
+// ProofPollingRelayer polls an external proving service for the proofs of the committed batches and stores them,
+// the batches are then ready to be finalized with proof. The batches whose proof is not ready are polled again
+// with an exponential backoff, and given up as failed to prove once they were committed dead_letter_after_sec ago.
+type ProofPollingRelayer struct {
+	cfg *config.ProofPollingConfig
+
+	db       *gorm.DB
+	batchOrm *orm.Batch
+
+	client *resty.Client
+
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	// The backoff of the batches whose proof is not ready by batch hash, only accessed by PollBatchProofStatus.
+	backoffs map[string]*proofPollBackoff
+
+	metrics *proofPollingRelayerMetrics
+}
+
+// NewProofPollingRelayer returns a new instance of ProofPollingRelayer.
+func NewProofPollingRelayer(cfg *config.ProofPollingConfig, db *gorm.DB, reg prometheus.Registerer) *ProofPollingRelayer {
+	client := resty.New().SetBaseURL(strings.TrimSuffix(cfg.ProvingServiceURL, "/"))
+	if cfg.TimeoutSec > 0 {
+		client.SetTimeout(time.Duration(cfg.TimeoutSec) * time.Second)
+	}
+	pollInterval := defaultProofPollInterval
+	if cfg.PollIntervalSec > 0 {
+		pollInterval = time.Duration(cfg.PollIntervalSec) * time.Second
+	}
+	maxBackoff := defaultProofPollMaxBackoff
+	if cfg.MaxBackoffSec > 0 {
+		maxBackoff = time.Duration(cfg.MaxBackoffSec) * time.Second
+	}
+	return &ProofPollingRelayer{
+		cfg:          cfg,
+		db:           db,
+		batchOrm:     orm.NewBatch(db),
+		client:       client,
+		pollInterval: pollInterval,
+		maxBackoff:   maxBackoff,
+		backoffs:     make(map[string]*proofPollBackoff),
+		metrics:      initProofPollingRelayerMetrics(reg),
+	}
+}
+
+// PollInterval returns the interval PollBatchProofStatus should be called at.
+func (r *ProofPollingRelayer) PollInterval() time.Duration {
+	return r.pollInterval
+}
+
+// PollBatchProofStatus polls the proof status of the committed batches not proven yet, whose backoff is elapsed,
+// and updates their proving status. It returns the first error of the batches, the other batches are still polled.
+func (r *ProofPollingRelayer) PollBatchProofStatus(ctx context.Context) error {
+	fields := map[string]interface{}{
+		"rollup_status = ?":   int(types.RollupCommitted),
+		"proving_status IN ?": []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)},
+	}
+	batches, err := r.batchOrm.GetBatches(ctx, fields, nil, proofPollBatchLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get the committed batches: %w", err)
+	}
+
+	now := time.Now()
+	polled := make(map[string]struct{}, len(batches))
+	var firstErr error
+	for _, batch := range batches {
+		polled[batch.Hash] = struct{}{}
+		if err = r.pollBatch(ctx, batch, now); err != nil {
+			log.Warn("Failed to poll batch proof status", "index", batch.Index, "hash", batch.Hash, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	// forget the batches proven or reverted meanwhile.
+	for hash := range r.backoffs {
+		if _, ok := polled[hash]; !ok {
+			delete(r.backoffs, hash)
+		}
+	}
+	return firstErr
+}
+
+// pollBatch queries the proof status of a batch unless it is backing off, and stores the result.
+func (r *ProofPollingRelayer) pollBatch(ctx context.Context, batch *orm.Batch, now time.Time) error {
+	if r.isDeadLetter(batch, now) {
+		log.Error("Batch proof never arrived, giving up", "index", batch.Index, "hash", batch.Hash, "committed at", batch.CommittedAt)
+		if err := r.batchOrm.UpdateProvingStatus(ctx, batch.Hash, types.ProvingTaskFailed); err != nil {
+			return err
+		}
+		r.metrics.rollupProofPollingDeadLetterTotal.Inc()
+		delete(r.backoffs, batch.Hash)
+		return nil
+	}
+	if backoff, ok := r.backoffs[batch.Hash]; ok && now.Before(backoff.nextPollAt) {
+		return nil
+	}
+
+	status, err := r.queryProofStatus(ctx, batch.Hash)
+	if err != nil {
+		r.backOff(batch.Hash, now)
+		return err
+	}
+
+	switch status.Status {
+	case ProofStatusProven:
+		if status.Proof == nil {
+			r.backOff(batch.Hash, now)
+			return fmt.Errorf("proven batch without proof, hash: %s", batch.Hash)
+		}
+		if err = status.Proof.SanityCheck(); err != nil {
+			r.backOff(batch.Hash, now)
+			return fmt.Errorf("invalid proof, hash: %s: %w", batch.Hash, err)
+		}
+		if err = r.storeProof(ctx, batch, status.Proof, now); err != nil {
+			return err
+		}
+		r.metrics.rollupProofPollingProvenTotal.Inc()
+		delete(r.backoffs, batch.Hash)
+		log.Info("Batch proof stored", "index", batch.Index, "hash", batch.Hash)
+	case ProofStatusFailed:
+		if err = r.batchOrm.UpdateProvingStatus(ctx, batch.Hash, types.ProvingTaskFailed); err != nil {
+			return err
+		}
+		r.metrics.rollupProofPollingFailedTotal.Inc()
+		delete(r.backoffs, batch.Hash)
+		log.Error("Proving service failed to prove batch", "index", batch.Index, "hash", batch.Hash, "error", status.Error)
+	case ProofStatusPending, ProofStatusProving:
+		if status.Status == ProofStatusProving && types.ProvingStatus(batch.ProvingStatus) == types.ProvingTaskUnassigned {
+			if err = r.batchOrm.UpdateProvingStatus(ctx, batch.Hash, types.ProvingTaskAssigned); err != nil {
+				return err
+			}
+		}
+		r.backOff(batch.Hash, now)
+	default:
+		r.backOff(batch.Hash, now)
+		return fmt.Errorf("unknown proof status %q, hash: %s", status.Status, batch.Hash)
+	}
+	return nil
+}
+
+// isDeadLetter returns true if the batch was committed longer than dead_letter_after_sec ago.
+func (r *ProofPollingRelayer) isDeadLetter(batch *orm.Batch, now time.Time) bool {
+	if r.cfg.DeadLetterAfterSec == 0 || batch.CommittedAt == nil {
+		return false
+	}
+	return now.Sub(*batch.CommittedAt) > time.Duration(r.cfg.DeadLetterAfterSec)*time.Second
+}
+
+// backOff doubles the delay before the next poll of a batch, starting from the poll interval up to the max backoff.
+func (r *ProofPollingRelayer) backOff(hash string, now time.Time) {
+	backoff, ok := r.backoffs[hash]
+	if !ok {
+		backoff = &proofPollBackoff{delay: r.pollInterval}
+		r.backoffs[hash] = backoff
+	} else {
+		backoff.delay *= 2
+	}
+	if backoff.delay > r.maxBackoff {
+		backoff.delay = r.maxBackoff
+	}
+	backoff.nextPollAt = now.Add(backoff.delay)
+}
+
+// storeProof stores the proof of the batch and marks it as verified, the proof time is measured from the commit.
+func (r *ProofPollingRelayer) storeProof(ctx context.Context, batch *orm.Batch, proof *message.BatchProof, now time.Time) error {
+	var proofTimeSec uint64
+	if batch.CommittedAt != nil && now.After(*batch.CommittedAt) {
+		proofTimeSec = uint64(now.Sub(*batch.CommittedAt).Seconds())
+	}
+	return r.db.Transaction(func(dbTX *gorm.DB) error {
+		if err := r.batchOrm.UpdateProofByHash(ctx, batch.Hash, proof, proofTimeSec, dbTX); err != nil {
+			return err
+		}
+		return r.batchOrm.UpdateProvingStatus(ctx, batch.Hash, types.ProvingTaskVerified, dbTX)
+	})
+}
+
+// queryProofStatus gets the proof status of a batch from the proving service, a batch unknown to it is pending.
+func (r *ProofPollingRelayer) queryProofStatus(ctx context.Context, batchHash string) (*ProofStatus, error) {
+	r.metrics.rollupProofPollingRequestsTotal.Inc()
+	var status ProofStatus
+	resp, err := r.client.R().
+		SetContext(ctx).
+		SetResult(&status).
+		Get("/api/v1/proof/" + url.PathEscape(batchHash))
+	if err != nil {
+		r.metrics.rollupProofPollingRequestFailureTotal.Inc()
+		return nil, fmt.Errorf("failed to query proof status: %w", err)
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return &ProofStatus{Status: ProofStatusPending}, nil
+	}
+	if resp.IsError() {
+		r.metrics.rollupProofPollingRequestFailureTotal.Inc()
+		return nil, fmt.Errorf("failed to query proof status, status: %s, body: %s", resp.Status(), resp.String())
+	}
+	return &status, nil
+}
@@ -0,0 +1,49 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type proofPollingRelayerMetrics struct {
+	rollupProofPollingRequestsTotal       prometheus.Counter
+	rollupProofPollingRequestFailureTotal prometheus.Counter
+	rollupProofPollingProvenTotal         prometheus.Counter
+	rollupProofPollingFailedTotal         prometheus.Counter
+	rollupProofPollingDeadLetterTotal     prometheus.Counter
+}
+
+var (
+	initProofPollingRelayerMetricOnce sync.Once
+	proofPollingRelayerMetric         *proofPollingRelayerMetrics
+)
+
+func initProofPollingRelayerMetrics(reg prometheus.Registerer) *proofPollingRelayerMetrics {
+	initProofPollingRelayerMetricOnce.Do(func() {
+		proofPollingRelayerMetric = &proofPollingRelayerMetrics{
+			rollupProofPollingRequestsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_polling_requests_total",
+				Help: "The total number of proof status requests sent to the proving service",
+			}),
+			rollupProofPollingRequestFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_polling_request_failure_total",
+				Help: "The total number of failed proof status requests",
+			}),
+			rollupProofPollingProvenTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_polling_proven_total",
+				Help: "The total number of batch proofs stored from the proving service",
+			}),
+			rollupProofPollingFailedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_polling_failed_total",
+				Help: "The total number of batches the proving service failed to prove",
+			}),
+			rollupProofPollingDeadLetterTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_polling_dead_letter_total",
+				Help: "The total number of batches whose proof was given up after dead_letter_after_sec",
+			}),
+		}
+	})
+	return proofPollingRelayerMetric
+}
@@ -0,0 +1,76 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestProofPollingRelayerQueryProofStatus(t *testing.T) {
+	var paths []string
+	proof := &message.BatchProof{Proof: make([]byte, 64), Instances: []byte{1}, Vk: []byte{2}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/proof/0x01":
+			assert.NoError(t, json.NewEncoder(w).Encode(&ProofStatus{Status: ProofStatusProven, Proof: proof}))
+		case "/api/v1/proof/0x02":
+			assert.NoError(t, json.NewEncoder(w).Encode(&ProofStatus{Status: ProofStatusFailed, Error: "out of memory"}))
+		case "/api/v1/proof/0x03":
+			http.Error(w, "batch not found", http.StatusNotFound)
+		default:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	r := NewProofPollingRelayer(&config.ProofPollingConfig{ProvingServiceURL: server.URL + "/", TimeoutSec: 5}, nil, nil)
+
+	status, err := r.queryProofStatus(context.Background(), "0x01")
+	assert.NoError(t, err)
+	assert.Equal(t, &ProofStatus{Status: ProofStatusProven, Proof: proof}, status)
+
+	status, err = r.queryProofStatus(context.Background(), "0x02")
+	assert.NoError(t, err)
+	assert.Equal(t, ProofStatusFailed, status.Status)
+	assert.Equal(t, "out of memory", status.Error)
+
+	// a batch unknown to the proving service is not proven yet.
+	status, err = r.queryProofStatus(context.Background(), "0x03")
+	assert.NoError(t, err)
+	assert.Equal(t, ProofStatusPending, status.Status)
+
+	_, err = r.queryProofStatus(context.Background(), "0x04")
+	assert.ErrorContains(t, err, "500")
+	assert.Equal(t, []string{"/api/v1/proof/0x01", "/api/v1/proof/0x02", "/api/v1/proof/0x03", "/api/v1/proof/0x04"}, paths)
+}
+
+func TestProofPollingRelayerBackoff(t *testing.T) {
+	r := NewProofPollingRelayer(&config.ProofPollingConfig{ProvingServiceURL: "http://localhost", PollIntervalSec: 10, MaxBackoffSec: 60}, nil, nil)
+	now := time.Now()
+
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		r.backOff("0x01", now)
+		delays = append(delays, r.backoffs["0x01"].nextPollAt.Sub(now))
+	}
+	assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}, delays)
+
+	r.backOff("0x02", now)
+	assert.Equal(t, 10*time.Second, r.backoffs["0x02"].delay)
+
+	defaults := NewProofPollingRelayer(&config.ProofPollingConfig{ProvingServiceURL: "http://localhost"}, nil, nil)
+	assert.Equal(t, defaultProofPollInterval, defaults.PollInterval())
+	assert.Equal(t, defaultProofPollMaxBackoff, defaults.maxBackoff)
+}
@@ -0,0 +1,202 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// proofQueuePollInterval is the interval the idle workers check for the proof jobs not signalled through Enqueue.
+const proofQueuePollInterval = 10 * time.Second
+
+// proofRequest is the body posted to the proof service.
+type proofRequest struct {
+	BatchIndex uint64 `json:"batch_index"`
+	BatchHash  string `json:"batch_hash"`
+}
+
+// ProofQueue generates the proofs of the committed batches through a proof service, ahead of their finalization.
+// The jobs are stored in the proof_jobs table, so they survive restarts and each of them is run by a single worker.
+type ProofQueue struct {
+	ctx context.Context
+	cfg *config.ProofQueueConfig
+
+	db          *gorm.DB
+	batchOrm    *orm.Batch
+	proofJobOrm *orm.ProofJob
+
+	client *resty.Client
+
+	// wakeup signals the idle workers that a job is enqueued.
+	wakeup chan struct{}
+	// proofReady receives the index of the batches whose proof is stored.
+	proofReady chan uint64
+
+	metrics *proofQueueMetrics
+}
+
+// NewProofQueue returns a new instance of ProofQueue.
+func NewProofQueue(ctx context.Context, cfg *config.ProofQueueConfig, db *gorm.DB, reg prometheus.Registerer) *ProofQueue {
+	client := resty.New()
+	if cfg.TimeoutSec > 0 {
+		client.SetTimeout(time.Duration(cfg.TimeoutSec) * time.Second)
+	}
+	return &ProofQueue{
+		ctx:         ctx,
+		cfg:         cfg,
+		db:          db,
+		batchOrm:    orm.NewBatch(db),
+		proofJobOrm: orm.NewProofJob(db),
+		client:      client,
+		wakeup:      make(chan struct{}, cfg.ProofWorkers),
+		proofReady:  make(chan uint64, cfg.ProofWorkers),
+		metrics:     initProofQueueMetrics(reg),
+	}
+}
+
+// Start resumes the jobs interrupted by the last shutdown and starts the workers.
+func (q *ProofQueue) Start() error {
+	resumed, err := q.proofJobOrm.ResetRunningProofJobs(q.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reset running proof jobs: %w", err)
+	}
+	if resumed > 0 {
+		log.Info("Resumed interrupted proof jobs", "count", resumed)
+	}
+	for i := 0; i < q.cfg.ProofWorkers; i++ {
+		go q.worker()
+	}
+	return nil
+}
+
+// Enqueue adds a proof job for the batch of batchIndex, it does nothing if the batch already has one.
+func (q *ProofQueue) Enqueue(batchIndex uint64) error {
+	batch, err := q.batchOrm.GetBatchByIndex(q.ctx, batchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get batch, index: %d: %w", batchIndex, err)
+	}
+	if batch == nil {
+		return fmt.Errorf("batch not found, index: %d", batchIndex)
+	}
+	inserted, err := q.proofJobOrm.InsertProofJob(q.ctx, batchIndex, batch.Hash)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return nil
+	}
+	q.metrics.rollupProofQueueEnqueuedTotal.Inc()
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ProofReady returns the channel receiving the index of the batches whose proof is stored and ready for finalization.
+func (q *ProofQueue) ProofReady() <-chan uint64 {
+	return q.proofReady
+}
+
+func (q *ProofQueue) worker() {
+	for {
+		// run the jobs until none is pending.
+		for q.processNextJob() {
+		}
+
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-q.wakeup:
+		case <-time.After(proofQueuePollInterval):
+		}
+	}
+}
+
+// processNextJob claims and runs a pending job, it returns false if there is none or the claim fails.
+func (q *ProofQueue) processNextJob() bool {
+	if q.ctx.Err() != nil {
+		return false
+	}
+	job, err := q.proofJobOrm.ClaimPendingProofJob(q.ctx)
+	if err != nil {
+		log.Error("Failed to claim proof job", "err", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	if err = q.runJob(job); err != nil {
+		if q.ctx.Err() != nil {
+			// leave the job running, it is resumed at the next startup.
+			log.Info("Proof job interrupted", "batch index", job.BatchIndex, "err", err)
+			return false
+		}
+		log.Error("Proof job failed", "batch index", job.BatchIndex, "batch hash", job.BatchHash, "err", err)
+		q.metrics.rollupProofQueueFailedTotal.Inc()
+		if updateErr := q.proofJobOrm.UpdateProofJobStatus(q.ctx, job.BatchIndex, types.ProofJobFailed); updateErr != nil {
+			log.Error("Failed to update proof job status", "batch index", job.BatchIndex, "err", updateErr)
+		}
+		return true
+	}
+
+	q.metrics.rollupProofQueueCompletedTotal.Inc()
+	log.Info("Proof job completed", "batch index", job.BatchIndex, "batch hash", job.BatchHash)
+	select {
+	case q.proofReady <- job.BatchIndex:
+	default:
+	}
+	return true
+}
+
+// runJob requests the proof of the batch and stores it, the batch is then ready to be finalized with proof.
+func (q *ProofQueue) runJob(job *orm.ProofJob) error {
+	start := time.Now()
+	proof, err := q.requestProof(q.ctx, job.BatchIndex, job.BatchHash)
+	if err != nil {
+		return err
+	}
+	proofTimeSec := uint64(time.Since(start).Seconds())
+
+	return q.db.Transaction(func(dbTX *gorm.DB) error {
+		if err := q.batchOrm.UpdateProofByHash(q.ctx, job.BatchHash, proof, proofTimeSec, dbTX); err != nil {
+			return err
+		}
+		if err := q.batchOrm.UpdateProvingStatus(q.ctx, job.BatchHash, types.ProvingTaskVerified, dbTX); err != nil {
+			return err
+		}
+		return q.proofJobOrm.UpdateProofJobStatus(q.ctx, job.BatchIndex, types.ProofJobCompleted, dbTX)
+	})
+}
+
+// requestProof posts the batch to the proof service and returns the checked proof.
+func (q *ProofQueue) requestProof(ctx context.Context, batchIndex uint64, batchHash string) (*message.BatchProof, error) {
+	var proof message.BatchProof
+	resp, err := q.client.R().
+		SetContext(ctx).
+		SetBody(&proofRequest{BatchIndex: batchIndex, BatchHash: batchHash}).
+		SetResult(&proof).
+		Post(q.cfg.ProofServiceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request proof: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to request proof, status: %s, body: %s", resp.Status(), resp.String())
+	}
+	if err = proof.SanityCheck(); err != nil {
+		return nil, fmt.Errorf("invalid proof: %w", err)
+	}
+	return &proof, nil
+}
@@ -0,0 +1,39 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type proofQueueMetrics struct {
+	rollupProofQueueEnqueuedTotal  prometheus.Counter
+	rollupProofQueueCompletedTotal prometheus.Counter
+	rollupProofQueueFailedTotal    prometheus.Counter
+}
+
+var (
+	initProofQueueMetricOnce sync.Once
+	proofQueueMetric         *proofQueueMetrics
+)
+
+func initProofQueueMetrics(reg prometheus.Registerer) *proofQueueMetrics {
+	initProofQueueMetricOnce.Do(func() {
+		proofQueueMetric = &proofQueueMetrics{
+			rollupProofQueueEnqueuedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_queue_enqueued_total",
+				Help: "The total number of proof jobs enqueued",
+			}),
+			rollupProofQueueCompletedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_queue_completed_total",
+				Help: "The total number of proof jobs completed",
+			}),
+			rollupProofQueueFailedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_proof_queue_failed_total",
+				Help: "The total number of proof jobs failed",
+			}),
+		}
+	})
+	return proofQueueMetric
+}
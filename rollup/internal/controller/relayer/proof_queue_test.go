@@ -0,0 +1,50 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestProofQueueRequestProof(t *testing.T) {
+	var requests []proofRequest
+	proof := &message.BatchProof{Proof: make([]byte, 64), Instances: []byte{1}, Vk: []byte{2}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req proofRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		switch req.BatchIndex {
+		case 1:
+			w.Header().Set("Content-Type", "application/json")
+			assert.NoError(t, json.NewEncoder(w).Encode(proof))
+		case 2:
+			w.Header().Set("Content-Type", "application/json")
+			assert.NoError(t, json.NewEncoder(w).Encode(&message.BatchProof{Proof: make([]byte, 33)}))
+		default:
+			http.Error(w, "batch not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	q := NewProofQueue(context.Background(), &config.ProofQueueConfig{ProofServiceURL: server.URL, ProofWorkers: 1, TimeoutSec: 5}, nil, nil)
+
+	got, err := q.requestProof(context.Background(), 1, "0x01")
+	assert.NoError(t, err)
+	assert.Equal(t, proof, got)
+	assert.Equal(t, proofRequest{BatchIndex: 1, BatchHash: "0x01"}, requests[0])
+
+	_, err = q.requestProof(context.Background(), 2, "0x02")
+	assert.ErrorContains(t, err, "invalid proof")
+
+	_, err = q.requestProof(context.Background(), 3, "0x03")
+	assert.ErrorContains(t, err, "404")
+	assert.Len(t, requests, 3)
+}
@@ -110,17 +110,29 @@ func TestFunctions(t *testing.T) {
 	// Run l1 relayer test cases.
 	t.Run("TestCreateNewL1Relayer", testCreateNewL1Relayer)
 	t.Run("TestL1RelayerGasOracleConfirm", testL1RelayerGasOracleConfirm)
+	t.Run("TestL1RelayerStop", testL1RelayerStop)
+	t.Run("TestL1RelayerConcurrentConfirm", testL1RelayerConcurrentConfirm)
 	t.Run("TestL1RelayerProcessGasPriceOracle", testL1RelayerProcessGasPriceOracle)
+	t.Run("TestL1RelayerProcessGasPriceOracleBatch", testL1RelayerProcessGasPriceOracleBatch)
+	t.Run("TestL1RelayerSetGasPriceManually", testL1RelayerSetGasPriceManually)
+	t.Run("TestGasOracleRelayEndToEnd", testGasOracleRelayEndToEnd)
+	t.Run("TestL1RelayerWaitForConfirmation", testL1RelayerWaitForConfirmation)
 
 	// Run l2 relayer test cases.
 	t.Run("TestCreateNewRelayer", testCreateNewRelayer)
 	t.Run("TestL2RelayerProcessPendingBatches", testL2RelayerProcessPendingBatches)
+	t.Run("TestL2RelayerProcessPendingBatchesConcurrencyLimit", testL2RelayerProcessPendingBatchesConcurrencyLimit)
+	t.Run("TestL2RelayerCommitChunkCalldata", testL2RelayerCommitChunkCalldata)
 	t.Run("TestL2RelayerProcessCommittedBatches", testL2RelayerProcessCommittedBatches)
 	t.Run("TestL2RelayerFinalizeTimeoutBatches", testL2RelayerFinalizeTimeoutBatches)
 	t.Run("TestL2RelayerCommitConfirm", testL2RelayerCommitConfirm)
 	t.Run("TestL2RelayerFinalizeConfirm", testL2RelayerFinalizeConfirm)
 	t.Run("TestL2RelayerGasOracleConfirm", testL2RelayerGasOracleConfirm)
 	t.Run("TestLayer2RelayerProcessGasPriceOracle", testLayer2RelayerProcessGasPriceOracle)
+	t.Run("TestLayer2RelayerSubmitFinalizeProof", testLayer2RelayerSubmitFinalizeProof)
+
+	// Run state root relayer test cases.
+	t.Run("TestStateRootRelayerProcessStateRoot", testStateRootRelayerProcessStateRoot)
 	// test getBatchStatusByIndex
 	t.Run("TestGetBatchStatusByIndex", testGetBatchStatusByIndex)
 }
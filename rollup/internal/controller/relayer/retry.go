@@ -0,0 +1,48 @@
+package relayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// retryWithBackoff runs fn until it succeeds or cfg.MaxRetries retries are used up,
+// doubling the delay between attempts from cfg.BaseDelayMs up to cfg.MaxDelayMs.
+// A nil cfg means fn is attempted exactly once.
+// It returns early with ctx.Err() if ctx is done while waiting for the next attempt.
+func retryWithBackoff(ctx context.Context, cfg *config.RetryConfig, name string, fn func() error) error {
+	var maxRetries uint64
+	var delay, maxDelay time.Duration
+	if cfg != nil {
+		maxRetries = cfg.MaxRetries
+		delay = time.Duration(cfg.BaseDelayMs) * time.Millisecond
+		maxDelay = time.Duration(cfg.MaxDelayMs) * time.Millisecond
+	}
+
+	var err error
+	for attempt := uint64(0); ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= maxRetries {
+			return err
+		}
+
+		if maxDelay > 0 && delay > maxDelay {
+			delay = maxDelay
+		}
+		log.Debug("retrying after failure", "name", name, "attempt", attempt+1, "delay", delay, "err", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,68 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestRetryWithBackoff(t *testing.T) {
+	retryCfg := &config.RetryConfig{MaxRetries: 5, BaseDelayMs: 1, MaxDelayMs: 4}
+	targetErr := errors.New("transient error")
+
+	t.Run("eventual success", func(t *testing.T) {
+		attempts := 0
+		err := retryWithBackoff(context.Background(), retryCfg, "test", func() error {
+			attempts++
+			if attempts < 3 {
+				return targetErr
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		attempts := 0
+		err := retryWithBackoff(context.Background(), retryCfg, "test", func() error {
+			attempts++
+			return targetErr
+		})
+		assert.ErrorIs(t, err, targetErr)
+		assert.Equal(t, 6, attempts)
+	})
+
+	t.Run("nil config attempts once", func(t *testing.T) {
+		attempts := 0
+		err := retryWithBackoff(context.Background(), nil, "test", func() error {
+			attempts++
+			return targetErr
+		})
+		assert.ErrorIs(t, err, targetErr)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slowCfg := &config.RetryConfig{MaxRetries: 10, BaseDelayMs: 60000, MaxDelayMs: 60000}
+		attempts := 0
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		err := retryWithBackoff(ctx, slowCfg, "test", func() error {
+			attempts++
+			return targetErr
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
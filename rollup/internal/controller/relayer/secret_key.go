@@ -0,0 +1,52 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smTypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// secretValueGetter is implemented by secretsmanager.Client.
+type secretValueGetter interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// newSecretsManagerClient returns a Secrets Manager client configured by the standard AWS SDK environment,
+// e.g. AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or the shared config files.
+func newSecretsManagerClient(ctx context.Context) (secretValueGetter, error) {
+	awsCfg, err := awsConfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the aws config: %w", err)
+	}
+	return secretsmanager.NewFromConfig(awsCfg), nil
+}
+
+// fetchSecretPrivateKey returns the private key stored hex-encoded, with or without 0x prefix, in the secret secretID.
+func fetchSecretPrivateKey(ctx context.Context, client secretValueGetter, secretID string) (*ecdsa.PrivateKey, error) {
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)})
+	if err != nil {
+		var notFound *smTypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("secret %s not found: %w", secretID, err)
+		}
+		return nil, fmt.Errorf("failed to get the value of secret %s: %w", secretID, err)
+	}
+	if output.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no string value", secretID)
+	}
+
+	privKey, err := crypto.ToECDSA(common.FromHex(strings.TrimSpace(*output.SecretString)))
+	if err != nil {
+		return nil, fmt.Errorf("secret %s is not a valid hex-encoded private key: %w", secretID, err)
+	}
+	return privKey, nil
+}
@@ -0,0 +1,60 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smTypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSecretsClient returns the secret strings by secret id.
+type fakeSecretsClient map[string]*string
+
+func (c fakeSecretsClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	secret, ok := c[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, &smTypes.ResourceNotFoundException{Message: aws.String("Secrets Manager can't find the specified secret.")}
+	}
+	if secret == nil {
+		return &secretsmanager.GetSecretValueOutput{SecretBinary: []byte{1}}, nil
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: secret}, nil
+}
+
+func TestFetchSecretPrivateKey(t *testing.T) {
+	key := "1313131313131313131313131313131313131313131313131313131313131313"
+	expected, err := crypto.ToECDSA(common.FromHex(key))
+	assert.NoError(t, err)
+
+	client := fakeSecretsClient{
+		"plain":    aws.String(key),
+		"prefixed": aws.String("0x" + key + "\n"),
+		"short":    aws.String("0x1313"),
+		"garbage":  aws.String("not a private key"),
+		"binary":   nil,
+	}
+
+	for _, id := range []string{"plain", "prefixed"} {
+		privKey, err := fetchSecretPrivateKey(context.Background(), client, id)
+		assert.NoError(t, err, id)
+		assert.Equal(t, expected, privKey, id)
+	}
+
+	_, err = fetchSecretPrivateKey(context.Background(), client, "missing")
+	var notFound *smTypes.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound))
+	assert.ErrorContains(t, err, "secret missing not found")
+
+	for _, id := range []string{"short", "garbage"} {
+		_, err = fetchSecretPrivateKey(context.Background(), client, id)
+		assert.ErrorContains(t, err, "not a valid hex-encoded private key", id)
+	}
+	_, err = fetchSecretPrivateKey(context.Background(), client, "binary")
+	assert.ErrorContains(t, err, "has no string value")
+}
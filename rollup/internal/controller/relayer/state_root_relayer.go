@@ -0,0 +1,147 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/sender"
+	"scroll-tech/rollup/internal/orm"
+	butils "scroll-tech/rollup/internal/utils"
+)
+
+// StateRootRelayer relays the state root of the latest finalized batch to the L1 state root oracle
+// through setL2StateRoot, the progress is tracked by the state root relay status of the batch.
+type StateRootRelayer struct {
+	ctx context.Context
+
+	batchOrm *orm.Batch
+	chunkOrm *orm.Chunk
+
+	stateRootSender     *sender.Sender
+	stateRootOracleABI  *abi.ABI
+	stateRootOracleAddr common.Address
+
+	metrics *stateRootRelayerMetrics
+}
+
+// NewStateRootRelayer returns a new instance of StateRootRelayer.
+func NewStateRootRelayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfig, reg prometheus.Registerer) (*StateRootRelayer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid state_root_relayer config: %w", err)
+	}
+	if cfg.StateRootSenderPrivateKey == nil {
+		return nil, fmt.Errorf("no state root sender private key configured")
+	}
+
+	stateRootSender, err := sender.NewSender(ctx, withoutFlashbots(cfg.SenderConfig), cfg.StateRootSenderPrivateKey, "state_root_relayer", "state_root_sender", types.SenderTypeStateRootOracle, db, reg)
+	if err != nil {
+		addr := crypto.PubkeyToAddress(cfg.StateRootSenderPrivateKey.PublicKey)
+		return nil, fmt.Errorf("new state root sender failed for address %s, err: %w", addr.Hex(), err)
+	}
+
+	r := &StateRootRelayer{
+		ctx: ctx,
+
+		batchOrm: orm.NewBatch(db),
+		chunkOrm: orm.NewChunk(db),
+
+		stateRootSender:     stateRootSender,
+		stateRootOracleABI:  bridgeAbi.L1StateRootOracleABI,
+		stateRootOracleAddr: cfg.StateRootOracleContractAddress,
+
+		metrics: initStateRootRelayerMetrics(reg),
+	}
+
+	go r.handleConfirmLoop(ctx)
+	return r, nil
+}
+
+// ProcessStateRoot relays the state root of the latest finalized batch if it is not relayed yet.
+// The returned error wraps either ErrTransient or ErrPermanent.
+func (r *StateRootRelayer) ProcessStateRoot() error {
+	r.metrics.rollupStateRootRelayerRunTotal.Inc()
+	ctx := butils.WithTrace(r.ctx, butils.NewTraceID())
+	batch, err := r.batchOrm.GetLatestFinalizedBatch(ctx)
+	if err != nil {
+		return newTransientError("failed to GetLatestFinalizedBatch: %w", err)
+	}
+	if batch == nil || types.StateRootRelayStatus(batch.StateRootRelayStatus) != types.StateRootRelayPending {
+		return nil
+	}
+
+	chunks, err := r.chunkOrm.GetChunksInRange(ctx, batch.EndChunkIndex, batch.EndChunkIndex)
+	if err != nil {
+		return newTransientError("failed to get the last chunk of batch, batch hash: %s: %w", batch.Hash, err)
+	}
+	if len(chunks) != 1 {
+		return newPermanentError("last chunk of batch not found, batch hash: %s, chunk index: %d", batch.Hash, batch.EndChunkIndex)
+	}
+	blockNumber := chunks[0].EndBlockNumber
+
+	data, err := r.stateRootOracleABI.Pack("setL2StateRoot", common.HexToHash(batch.StateRoot), new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return newPermanentError("failed to pack setL2StateRoot, batch hash: %s: %w", batch.Hash, err)
+	}
+
+	hash, err := r.stateRootSender.SendTransaction(batch.Hash, &r.stateRootOracleAddr, big.NewInt(0), data, 0)
+	if err != nil {
+		return newTransientError("failed to send setL2StateRoot tx to layer1, batch hash: %s: %w", batch.Hash, err)
+	}
+
+	err = r.batchOrm.UpdateStateRootRelayStatusAndTxHash(ctx, batch.Hash, types.StateRootRelaying, hash.String())
+	if err != nil {
+		return newTransientError("failed to UpdateStateRootRelayStatusAndTxHash, batch hash: %s: %w", batch.Hash, err)
+	}
+	butils.Logger(ctx, "batchID", batch.Hash).Info("Relay l2 state root", "txHash", hash.String(), "stateRoot", batch.StateRoot, "blockNumber", blockNumber)
+	return nil
+}
+
+// Stop stops the state root sender.
+func (r *StateRootRelayer) Stop() {
+	r.stateRootSender.Stop()
+}
+
+func (r *StateRootRelayer) handleConfirmation(cfm *sender.Confirmation) error {
+	// the context ids of the state root relayer transactions are batch hashes.
+	logger := butils.Logger(r.ctx, "batchID", cfm.ContextID)
+	var status types.StateRootRelayStatus
+	if cfm.IsSuccessful {
+		status = types.StateRootRelayed
+		r.metrics.rollupStateRootRelayerConfirmedTotal.Inc()
+	} else {
+		status = types.StateRootRelayFailed
+		r.metrics.rollupStateRootRelayerFailedTotal.Inc()
+		logger.Warn("SetL2StateRoot transaction confirmed but failed in layer1", "confirmation", cfm)
+	}
+
+	err := r.batchOrm.UpdateStateRootRelayStatusAndTxHash(r.ctx, cfm.ContextID, status, cfm.TxHash.String())
+	if err != nil {
+		return newTransientError("failed to UpdateStateRootRelayStatusAndTxHash, context ID: %s: %w", cfm.ContextID, err)
+	}
+	logger.Info("Transaction confirmed in layer1", "confirmation", cfm)
+	return nil
+}
+
+func (r *StateRootRelayer) handleConfirmLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfm := <-r.stateRootSender.ConfirmChan():
+			if err := r.handleConfirmation(cfm); err != nil {
+				LogError("Failed to handle state root confirmation", err, "confirmation", cfm)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type stateRootRelayerMetrics struct {
+	rollupStateRootRelayerRunTotal       prometheus.Counter
+	rollupStateRootRelayerConfirmedTotal prometheus.Counter
+	rollupStateRootRelayerFailedTotal    prometheus.Counter
+}
+
+var (
+	initStateRootRelayerMetricOnce sync.Once
+	stateRootRelayerMetric         *stateRootRelayerMetrics
+)
+
+func initStateRootRelayerMetrics(reg prometheus.Registerer) *stateRootRelayerMetrics {
+	initStateRootRelayerMetricOnce.Do(func() {
+		stateRootRelayerMetric = &stateRootRelayerMetrics{
+			rollupStateRootRelayerRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_state_root_relayer_run_total",
+				Help: "The total number of state root relayer runs",
+			}),
+			rollupStateRootRelayerConfirmedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_state_root_relayer_confirmed_total",
+				Help: "The total number of setL2StateRoot transactions confirmed in layer1",
+			}),
+			rollupStateRootRelayerFailedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_state_root_relayer_failed_total",
+				Help: "The total number of setL2StateRoot transactions failed in layer1",
+			}),
+		}
+	})
+	return stateRootRelayerMetric
+}
@@ -0,0 +1,121 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func newTestStateRootRelayerConfig() *config.RelayerConfig {
+	relayerCfg := *cfg.L2Config.RelayerConfig
+	relayerCfg.StateRootSenderPrivateKey = relayerCfg.GasOracleSenderPrivateKey
+	relayerCfg.StateRootOracleContractAddress = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	return &relayerCfg
+}
+
+func testStateRootRelayerProcessStateRoot(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relayer, err := NewStateRootRelayer(ctx, db, newTestStateRootRelayerConfig(), nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, relayer)
+	defer relayer.Stop()
+
+	var batchOrm *orm.Batch
+	var chunkOrm *orm.Chunk
+	convey.Convey("Failed to GetLatestFinalizedBatch", t, func() {
+		targetErr := errors.New("GetLatestFinalizedBatch error")
+		patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetLatestFinalizedBatch", func(context.Context) (*orm.Batch, error) {
+			return nil, targetErr
+		})
+		defer patchGuard.Reset()
+		assert.ErrorIs(t, relayer.ProcessStateRoot(), ErrTransient)
+	})
+
+	convey.Convey("No finalized batch", t, func() {
+		patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetLatestFinalizedBatch", func(context.Context) (*orm.Batch, error) {
+			return nil, nil
+		})
+		defer patchGuard.Reset()
+		assert.NoError(t, relayer.ProcessStateRoot())
+	})
+
+	convey.Convey("State root already relayed", t, func() {
+		patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetLatestFinalizedBatch", func(context.Context) (*orm.Batch, error) {
+			return &orm.Batch{StateRootRelayStatus: int16(types.StateRootRelayed)}, nil
+		})
+		defer patchGuard.Reset()
+		assert.NoError(t, relayer.ProcessStateRoot())
+	})
+
+	patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetLatestFinalizedBatch", func(context.Context) (*orm.Batch, error) {
+		batch := orm.Batch{
+			Hash:                 "0x0000000000000000000000000000000000000000",
+			StateRoot:            "0x0000000000000000000000000000000000000000000000000000000000000001",
+			EndChunkIndex:        1,
+			StateRootRelayStatus: int16(types.StateRootRelayPending),
+		}
+		return &batch, nil
+	})
+	defer patchGuard.Reset()
+
+	convey.Convey("Failed to get the last chunk of batch", t, func() {
+		targetErr := errors.New("GetChunksInRange error")
+		patchGuard.ApplyMethodFunc(chunkOrm, "GetChunksInRange", func(context.Context, uint64, uint64) ([]*orm.Chunk, error) {
+			return nil, targetErr
+		})
+		assert.ErrorIs(t, relayer.ProcessStateRoot(), ErrTransient)
+	})
+
+	convey.Convey("Last chunk of batch not found", t, func() {
+		patchGuard.ApplyMethodFunc(chunkOrm, "GetChunksInRange", func(context.Context, uint64, uint64) ([]*orm.Chunk, error) {
+			return nil, nil
+		})
+		assert.ErrorIs(t, relayer.ProcessStateRoot(), ErrPermanent)
+	})
+
+	patchGuard.ApplyMethodFunc(chunkOrm, "GetChunksInRange", func(context.Context, uint64, uint64) ([]*orm.Chunk, error) {
+		return []*orm.Chunk{{Index: 1, EndBlockNumber: 100}}, nil
+	})
+
+	convey.Convey("Failed to send setL2StateRoot tx to layer1", t, func() {
+		targetErr := errors.New("failed to send setL2StateRoot tx to layer1 error")
+		patchGuard.ApplyMethodFunc(relayer.stateRootSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
+			return common.Hash{}, targetErr
+		})
+		assert.ErrorIs(t, relayer.ProcessStateRoot(), ErrTransient)
+	})
+
+	patchGuard.ApplyMethodFunc(relayer.stateRootSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
+		return common.HexToHash("0x56789abcdef1234"), nil
+	})
+
+	convey.Convey("UpdateStateRootRelayStatusAndTxHash failed", t, func() {
+		targetErr := errors.New("UpdateStateRootRelayStatusAndTxHash error")
+		patchGuard.ApplyMethodFunc(batchOrm, "UpdateStateRootRelayStatusAndTxHash", func(ctx context.Context, hash string, status types.StateRootRelayStatus, txHash string) error {
+			return targetErr
+		})
+		assert.ErrorIs(t, relayer.ProcessStateRoot(), ErrTransient)
+	})
+
+	patchGuard.ApplyMethodFunc(batchOrm, "UpdateStateRootRelayStatusAndTxHash", func(ctx context.Context, hash string, status types.StateRootRelayStatus, txHash string) error {
+		assert.Equal(t, types.StateRootRelaying, status)
+		return nil
+	})
+	assert.NoError(t, relayer.ProcessStateRoot())
+}
@@ -0,0 +1,235 @@
+package sender
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+)
+
+const (
+	// defaultBatchingWindow is the time the transactions are queued for if batching_window_ms is not configured.
+	defaultBatchingWindow = 500 * time.Millisecond
+	// batchedContextIDSeparator joins the context IDs of the transactions merged into a multicall.
+	batchedContextIDSeparator = "|"
+)
+
+// batchedTx is a transaction queued by the BatchTransactionOptimizer.
+type batchedTx struct {
+	contextID        string
+	target           common.Address
+	value            *big.Int
+	data             []byte
+	fallbackGasLimit uint64
+	// hash is the synthetic hash returned to the caller, the confirmation of the transaction carries it.
+	hash common.Hash
+}
+
+// BatchTransactionOptimizer queues the transactions sent with SendBatchableTransaction within a batching window and,
+// if they are all to the same contract, sends them as a single Multicall3 aggregate3Value transaction, the others are
+// sent one by one.
+// Each queued transaction gets a synthetic hash, and is confirmed with it on the ConfirmChan of the sender once the
+// transaction it is sent in is confirmed. The calls of a multicall are made by the multicall contract, so only the
+// contracts not checking the caller can be called through it.
+type BatchTransactionOptimizer struct {
+	sender           *Sender
+	multicallAddress common.Address
+	batchingWindow   time.Duration
+
+	// sendMu serializes the flushes with the transactions sent directly through the sender, as they share its nonce.
+	sendMu sync.Mutex
+
+	mu    sync.Mutex
+	queue []*batchedTx
+	timer *time.Timer
+	seq   uint64
+	// the queued transactions sent in each pending transaction, by the context ID of the pending transaction.
+	inflight map[string][]*batchedTx
+
+	// confirmCh receives the confirmations of the sender, split per queued transaction.
+	confirmCh chan *Confirmation
+}
+
+// newBatchTransactionOptimizer returns the optimizer of the sender, it splits the confirmations of the sender
+// until the sender is stopped.
+func newBatchTransactionOptimizer(s *Sender, multicallAddress common.Address, batchingWindow time.Duration) *BatchTransactionOptimizer {
+	if batchingWindow <= 0 {
+		batchingWindow = defaultBatchingWindow
+	}
+	o := &BatchTransactionOptimizer{
+		sender:           s,
+		multicallAddress: multicallAddress,
+		batchingWindow:   batchingWindow,
+		inflight:         make(map[string][]*batchedTx),
+		confirmCh:        make(chan *Confirmation, cap(s.confirmCh)),
+	}
+	go o.splitConfirmations()
+	return o
+}
+
+// enqueue queues a transaction until the end of the batching window and returns its synthetic hash.
+func (o *BatchTransactionOptimizer) enqueue(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) common.Hash {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, o.seq)
+	tx := &batchedTx{
+		contextID:        contextID,
+		target:           *target,
+		value:            value,
+		data:             data,
+		fallbackGasLimit: fallbackGasLimit,
+		hash:             crypto.Keccak256Hash([]byte(o.sender.name), []byte(contextID), target.Bytes(), data, seq),
+	}
+	o.queue = append(o.queue, tx)
+	if o.timer == nil {
+		o.timer = time.AfterFunc(o.batchingWindow, o.flush)
+	}
+	o.sender.metrics.batchedTransactionTotal.WithLabelValues(o.sender.service, o.sender.name).Inc()
+	return tx.hash
+}
+
+// flush sends the queued transactions, the queued transactions failing to be sent are confirmed as failed.
+func (o *BatchTransactionOptimizer) flush() {
+	o.mu.Lock()
+	queue := o.queue
+	o.queue = nil
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	o.mu.Unlock()
+
+	if len(queue) == 0 {
+		return
+	}
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+	if len(queue) > 1 && sameTarget(queue) {
+		o.send(queue)
+		return
+	}
+	for _, tx := range queue {
+		o.send([]*batchedTx{tx})
+	}
+}
+
+// send sends the transactions, as a multicall if there are more than one.
+func (o *BatchTransactionOptimizer) send(txs []*batchedTx) {
+	contextID, target, value, data, fallbackGasLimit, err := o.packTransactions(txs)
+	if err == nil {
+		// the pending transaction is registered before it is sent, so that its confirmation is always split.
+		o.mu.Lock()
+		o.inflight[contextID] = txs
+		o.mu.Unlock()
+
+		_, err = o.sender.sendTransactionWithFeeCaps(contextID, &target, value, data, fallbackGasLimit, nil)
+	}
+	if err != nil {
+		o.mu.Lock()
+		delete(o.inflight, contextID)
+		o.mu.Unlock()
+
+		log.Error("failed to send batched transactions", "service", o.sender.service, "name", o.sender.name, "context ID", contextID, "count", len(txs), "err", err)
+		for _, tx := range txs {
+			o.confirmCh <- &Confirmation{ContextID: tx.contextID, IsSuccessful: false, TxHash: tx.hash, SenderType: o.sender.senderType}
+		}
+		return
+	}
+	if len(txs) > 1 {
+		o.sender.metrics.batchedMulticallTotal.WithLabelValues(o.sender.service, o.sender.name).Inc()
+		log.Info("sent batched transactions in a multicall", "service", o.sender.service, "name", o.sender.name, "target", target.Hex(), "count", len(txs))
+	}
+}
+
+// packTransactions returns the transaction sending txs, a multicall of them if there are more than one.
+func (o *BatchTransactionOptimizer) packTransactions(txs []*batchedTx) (string, common.Address, *big.Int, []byte, uint64, error) {
+	if len(txs) == 1 {
+		return txs[0].contextID, txs[0].target, txs[0].value, txs[0].data, txs[0].fallbackGasLimit, nil
+	}
+
+	contextIDs := make([]string, 0, len(txs))
+	calls := make([]bridgeAbi.Multicall3Call3Value, 0, len(txs))
+	value := new(big.Int)
+	var fallbackGasLimit uint64
+	for _, tx := range txs {
+		contextIDs = append(contextIDs, tx.contextID)
+		txValue := tx.value
+		if txValue == nil {
+			txValue = new(big.Int)
+		}
+		calls = append(calls, bridgeAbi.Multicall3Call3Value{Target: tx.target, Value: txValue, CallData: tx.data})
+		value.Add(value, txValue)
+		fallbackGasLimit += tx.fallbackGasLimit
+	}
+	contextID := strings.Join(contextIDs, batchedContextIDSeparator)
+	data, err := bridgeAbi.Multicall3ABI.Pack("aggregate3Value", calls)
+	if err != nil {
+		return contextID, common.Address{}, nil, nil, 0, fmt.Errorf("failed to pack aggregate3Value: %w", err)
+	}
+	return contextID, o.multicallAddress, value, data, fallbackGasLimit, nil
+}
+
+// splitConfirmations forwards the confirmations of the sender, the confirmation of a transaction sent by the
+// optimizer is forwarded once per queued transaction it contains.
+func (o *BatchTransactionOptimizer) splitConfirmations() {
+	for {
+		select {
+		case <-o.sender.ctx.Done():
+			return
+		case <-o.sender.stopCh:
+			return
+		case cfm := <-o.sender.confirmCh:
+			o.mu.Lock()
+			txs, ok := o.inflight[cfm.ContextID]
+			delete(o.inflight, cfm.ContextID)
+			o.mu.Unlock()
+
+			if !ok {
+				// the multicalls sent before a restart are split by their context ID, with the hash of the multicall.
+				for _, contextID := range strings.Split(cfm.ContextID, batchedContextIDSeparator) {
+					split := *cfm
+					split.ContextID = contextID
+					o.confirmCh <- &split
+				}
+				continue
+			}
+			// the queued transactions share the receipt of the transaction they are sent in.
+			for _, tx := range txs {
+				split := *cfm
+				split.ContextID = tx.contextID
+				split.TxHash = tx.hash
+				o.confirmCh <- &split
+			}
+		}
+	}
+}
+
+// lockSend locks the sender against the flushes of the batching optimizer, it returns the unlock function.
+func (s *Sender) lockSend() func() {
+	if s.batchingOptimizer == nil {
+		return func() {}
+	}
+	s.batchingOptimizer.sendMu.Lock()
+	return s.batchingOptimizer.sendMu.Unlock
+}
+
+// sameTarget returns true if all the transactions are to the same contract.
+func sameTarget(txs []*batchedTx) bool {
+	for _, tx := range txs[1:] {
+		if tx.target != txs[0].target {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+)
+
+func receiveConfirmations(t *testing.T, ch <-chan *Confirmation, n int) []*Confirmation {
+	var cfms []*Confirmation
+	for i := 0; i < n; i++ {
+		select {
+		case cfm := <-ch:
+			cfms = append(cfms, cfm)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d confirmations, expected %d", len(cfms), n)
+		}
+	}
+	return cfms
+}
+
+func TestBatchTransactionOptimizer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &ethclient.Client{}
+	s := &Sender{
+		ctx:        ctx,
+		config:     &config.SenderConfig{SimulateOnly: true},
+		client:     client,
+		auth:       &bind.TransactOpts{Nonce: big.NewInt(0)},
+		senderType: types.SenderTypeL1GasOracle,
+		confirmCh:  make(chan *Confirmation, 128),
+		stopCh:     make(chan struct{}),
+		metrics:    initSenderMetrics(nil),
+	}
+	multicall := common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	// the window is long enough for the queued transactions to be flushed by the test only.
+	s.batchingOptimizer = newBatchTransactionOptimizer(s, multicall, time.Hour)
+
+	var callErr error
+	var calls []ethereum.CallMsg
+	patches := gomonkey.ApplyMethodFunc(client, "CallContract", func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+		calls = append(calls, msg)
+		return nil, callErr
+	})
+	defer patches.Reset()
+
+	// the transactions to the same contract are merged into a multicall.
+	to := common.HexToAddress("0x1")
+	var hashes []common.Hash
+	for i, contextID := range []string{"a", "b", "c"} {
+		hash, err := s.SendBatchableTransaction(contextID, &to, big.NewInt(int64(i+1)), []byte{byte(i)}, 0)
+		assert.NoError(t, err)
+		hashes = append(hashes, hash)
+	}
+	assert.NotEqual(t, hashes[0], hashes[1])
+	assert.Empty(t, calls)
+	s.batchingOptimizer.flush()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, &multicall, calls[0].To)
+	assert.Equal(t, big.NewInt(6), calls[0].Value)
+	assert.Equal(t, bridgeAbi.Multicall3ABI.Methods["aggregate3Value"].ID, calls[0].Data[:4])
+
+	// the confirmation of the multicall confirms each transaction with its synthetic hash.
+	s.flushSimulatedConfirmations()
+	cfms := receiveConfirmations(t, s.ConfirmChan(), 3)
+	for i, contextID := range []string{"a", "b", "c"} {
+		assert.Equal(t, &Confirmation{ContextID: contextID, IsSuccessful: true, TxHash: hashes[i], SenderType: types.SenderTypeL1GasOracle}, cfms[i])
+	}
+
+	// the transactions to different contracts are sent one by one.
+	calls = nil
+	to2 := common.HexToAddress("0x2")
+	hashD, err := s.SendBatchableTransaction("d", &to, big.NewInt(0), []byte{1}, 0)
+	assert.NoError(t, err)
+	hashE, err := s.SendBatchableTransaction("e", &to2, big.NewInt(0), []byte{2}, 0)
+	assert.NoError(t, err)
+	s.batchingOptimizer.flush()
+	assert.Len(t, calls, 2)
+	assert.Equal(t, &to, calls[0].To)
+	assert.Equal(t, []byte{1}, calls[0].Data)
+	assert.Equal(t, &to2, calls[1].To)
+	s.flushSimulatedConfirmations()
+	cfms = receiveConfirmations(t, s.ConfirmChan(), 2)
+	assert.Equal(t, hashD, cfms[0].TxHash)
+	assert.Equal(t, "e", cfms[1].ContextID)
+	assert.Equal(t, hashE, cfms[1].TxHash)
+
+	// the transactions failing to be sent are confirmed as failed.
+	callErr = errors.New("execution reverted")
+	hashF, err := s.SendBatchableTransaction("f", &to, big.NewInt(0), []byte{1}, 0)
+	assert.NoError(t, err)
+	_, err = s.SendBatchableTransaction("g", &to, big.NewInt(0), []byte{2}, 0)
+	assert.NoError(t, err)
+	s.batchingOptimizer.flush()
+	cfms = receiveConfirmations(t, s.ConfirmChan(), 2)
+	assert.Equal(t, &Confirmation{ContextID: "f", IsSuccessful: false, TxHash: hashF, SenderType: types.SenderTypeL1GasOracle}, cfms[0])
+	assert.False(t, cfms[1].IsSuccessful)
+
+	// the confirmations of the transactions not sent by the optimizer, e.g. a multicall sent before a restart, are split.
+	s.SendConfirmation(&Confirmation{ContextID: "h|i", IsSuccessful: true, TxHash: common.HexToHash("0x1234")})
+	cfms = receiveConfirmations(t, s.ConfirmChan(), 2)
+	assert.Equal(t, "h", cfms[0].ContextID)
+	assert.Equal(t, "i", cfms[1].ContextID)
+	assert.Equal(t, common.HexToHash("0x1234"), cfms[1].TxHash)
+
+	// the receipt of the multicall is the one of each transaction it contains.
+	callErr = nil
+	hashJ, err := s.SendBatchableTransaction("j", &to, big.NewInt(0), []byte{1}, 0)
+	assert.NoError(t, err)
+	hashK, err := s.SendBatchableTransaction("k", &to, big.NewInt(0), []byte{2}, 0)
+	assert.NoError(t, err)
+	s.batchingOptimizer.flush()
+	s.SendConfirmation(&Confirmation{ContextID: "j|k", IsSuccessful: true, TxHash: common.HexToHash("0x5678"), SenderType: types.SenderTypeL1GasOracle,
+		BlockNumber: 10, GasUsed: 21000, EffectiveGasPrice: big.NewInt(7)})
+	cfms = receiveConfirmations(t, s.ConfirmChan(), 2)
+	for i, hash := range []common.Hash{hashJ, hashK} {
+		assert.Equal(t, hash, cfms[i].TxHash)
+		assert.Equal(t, uint64(10), cfms[i].BlockNumber)
+		assert.Equal(t, uint64(21000), cfms[i].GasUsed)
+		assert.Equal(t, big.NewInt(7), cfms[i].EffectiveGasPrice)
+	}
+}
+
+func TestBatchTransactionOptimizerWindow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &Sender{
+		ctx:       ctx,
+		config:    &config.SenderConfig{SimulateOnly: true},
+		client:    &ethclient.Client{},
+		auth:      &bind.TransactOpts{Nonce: big.NewInt(0)},
+		confirmCh: make(chan *Confirmation, 128),
+		stopCh:    make(chan struct{}),
+		metrics:   initSenderMetrics(nil),
+	}
+	flushed := make(chan struct{}, 1)
+	patches := gomonkey.ApplyMethodFunc(s.client, "CallContract", func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+		flushed <- struct{}{}
+		return nil, nil
+	})
+	defer patches.Reset()
+
+	s.batchingOptimizer = newBatchTransactionOptimizer(s, common.HexToAddress("0xca11"), 50*time.Millisecond)
+	to := common.HexToAddress("0x1")
+	start := time.Now()
+	_, err := s.SendBatchableTransaction("a", &to, big.NewInt(0), nil, 0)
+	assert.NoError(t, err)
+	select {
+	case <-flushed:
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("queued transaction not flushed at the end of the batching window")
+	}
+
+	// the transactions not sent as batchable are sent right away, e.g. the calls restricted to the sender.
+	_, err = s.SendTransaction("b", &to, big.NewInt(0), nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, flushed, 1)
+	<-flushed
+	_, err = s.SendTransactionWithFeeCaps("c", &to, big.NewInt(0), nil, 0, &FeeCaps{})
+	assert.NoError(t, err)
+	assert.Len(t, flushed, 1)
+}
@@ -0,0 +1,138 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+const (
+	// blobFieldElements the number of field elements in a blob.
+	blobFieldElements = 4096
+	// blobFieldElementDataSize the number of data bytes in a field element,
+	// the first byte of each 32-byte field element is kept zero to stay below the BLS modulus.
+	blobFieldElementDataSize = 31
+	// blobDataSize the number of data bytes in a blob.
+	blobDataSize = blobFieldElements * blobFieldElementDataSize
+	// maxBlobsPerTx the maximum number of blobs of a transaction, limited by the blob gas of a block.
+	maxBlobsPerTx = 6
+
+	// EIP-4844 blob base fee parameters.
+	minBlobGasPrice            = 1
+	blobGasPriceUpdateFraction = 3338477
+)
+
+// SendBlobTransaction sends a signed EIP-4844 transaction carrying blobData in its blobs.
+// The KZG commitments and proofs of the blobs are computed locally, gasLimit must not be zero.
+func (s *Sender) SendBlobTransaction(contextID string, to *common.Address, value *big.Int, blobData []byte, gasLimit uint64) (common.Hash, error) {
+	defer s.lockSend()()
+	s.metrics.sendTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+	if to == nil {
+		return common.Hash{}, errors.New("blob transaction must have a recipient")
+	}
+	if gasLimit == 0 {
+		return common.Hash{}, errors.New("blob transaction must have a gas limit")
+	}
+
+	sidecar, err := makeBlobTxSidecar(blobData)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to make blob tx sidecar, err: %w", err)
+	}
+
+	return s.sendTransaction(contextID, to, value, nil, gasLimit, func(uint64) (*FeeData, error) {
+		return s.getBlobFeeData(gasLimit, sidecar)
+	})
+}
+
+// getBlobFeeData returns the fee data of a blob transaction, based on the base fee and the excess blob gas of the latest block.
+func (s *Sender) getBlobFeeData(gasLimit uint64, sidecar *gethTypes.BlobTxSidecar) (*FeeData, error) {
+	header, err := s.client.HeaderByNumber(s.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header by number, err: %w", err)
+	}
+	if header.BaseFee == nil || header.ExcessBlobGas == nil {
+		return nil, errors.New("blob tx not supported: header.BaseFee or header.ExcessBlobGas is nil")
+	}
+
+	gasTipCap, err := s.client.SuggestGasTipCap(s.ctx)
+	if err != nil {
+		log.Error("getBlobFeeData SuggestGasTipCap failure", "error", err)
+		return nil, err
+	}
+	gasTipCap, gasFeeCap := capDynamicFee(gasTipCap, header.BaseFee.Uint64(), s.mergeFeeCaps(nil))
+
+	return &FeeData{
+		gasLimit:      gasLimit,
+		gasTipCap:     gasTipCap,
+		gasFeeCap:     gasFeeCap,
+		blobGasFeeCap: new(big.Int).Mul(calcBlobFee(*header.ExcessBlobGas), big.NewInt(2)),
+		sidecar:       sidecar,
+	}, nil
+}
+
+// makeBlobTxSidecar splits data into blobs and computes their KZG commitments and proofs.
+func makeBlobTxSidecar(data []byte) (*gethTypes.BlobTxSidecar, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty blob data")
+	}
+	numBlobs := (len(data) + blobDataSize - 1) / blobDataSize
+	if numBlobs > maxBlobsPerTx {
+		return nil, fmt.Errorf("blob data too large, size: %d, max size: %d", len(data), maxBlobsPerTx*blobDataSize)
+	}
+
+	sidecar := &gethTypes.BlobTxSidecar{}
+	for i := 0; i < numBlobs; i++ {
+		var blob kzg4844.Blob
+		chunk := data[i*blobDataSize:]
+		if len(chunk) > blobDataSize {
+			chunk = chunk[:blobDataSize]
+		}
+		for j := 0; j*blobFieldElementDataSize < len(chunk); j++ {
+			end := (j + 1) * blobFieldElementDataSize
+			if end > len(chunk) {
+				end = len(chunk)
+			}
+			copy(blob[j*32+1:], chunk[j*blobFieldElementDataSize:end])
+		}
+
+		commitment, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute blob commitment, blob index: %d, err: %w", i, err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute blob proof, blob index: %d, err: %w", i, err)
+		}
+
+		sidecar.Blobs = append(sidecar.Blobs, blob)
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+	return sidecar, nil
+}
+
+// calcBlobFee calculates the blob base fee from the excess blob gas of a header, as specified in EIP-4844.
+func calcBlobFee(excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(minBlobGasPrice), new(big.Int).SetUint64(excessBlobGas), big.NewInt(blobGasPriceUpdateFraction))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using Taylor expansion.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
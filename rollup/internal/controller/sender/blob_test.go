@@ -0,0 +1,55 @@
+package sender
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcBlobFee(t *testing.T) {
+	tests := []struct {
+		excessBlobGas uint64
+		blobFee       int64
+	}{
+		{0, 1},
+		{2314057, 1},
+		{2314058, 2},
+		{10 * 1024 * 1024, 23},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, big.NewInt(tt.blobFee), calcBlobFee(tt.excessBlobGas), "excess blob gas: %d", tt.excessBlobGas)
+	}
+}
+
+func TestMakeBlobTxSidecar(t *testing.T) {
+	_, err := makeBlobTxSidecar(nil)
+	assert.Error(t, err)
+
+	_, err = makeBlobTxSidecar(make([]byte, maxBlobsPerTx*blobDataSize+1))
+	assert.Error(t, err)
+
+	data := make([]byte, blobDataSize+100)
+	for i := range data {
+		data[i] = byte(i%255 + 1)
+	}
+	sidecar, err := makeBlobTxSidecar(data)
+	assert.NoError(t, err)
+	assert.Len(t, sidecar.Blobs, 2)
+	assert.Len(t, sidecar.Commitments, 2)
+	assert.Len(t, sidecar.Proofs, 2)
+	assert.Len(t, sidecar.BlobHashes(), 2)
+
+	var decoded []byte
+	for i, blob := range sidecar.Blobs {
+		assert.NoError(t, kzg4844.VerifyBlobProof(blob, sidecar.Commitments[i], sidecar.Proofs[i]))
+		for j := 0; j < blobFieldElements; j++ {
+			assert.Equal(t, byte(0), blob[j*32])
+			decoded = append(decoded, blob[j*32+1:(j+1)*32]...)
+		}
+	}
+	assert.True(t, bytes.Equal(data, decoded[:len(data)]))
+	assert.True(t, bytes.Equal(make([]byte, len(decoded)-len(data)), decoded[len(data):]))
+}
@@ -0,0 +1,74 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// ErrRPCTimeout is the error injected by ChaosSender in place of sending a transaction.
+var ErrRPCTimeout = errors.New("chaos sender: injected rpc timeout")
+
+// ChaosSender wraps a Sender and fails its transaction submissions at random with ErrRPCTimeout,
+// to exercise the fault paths of the callers and of the confirmation loop in tests.
+// A failed submission is not sent to the underlying Sender. The failures stop once ctx is done.
+type ChaosSender struct {
+	*Sender
+
+	ctx       context.Context
+	errorRate float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewChaosSender returns a ChaosSender failing each submission of underlying with probability errorRate.
+func NewChaosSender(ctx context.Context, underlying *Sender, errorRate float64) *ChaosSender {
+	return &ChaosSender{
+		Sender:    underlying,
+		ctx:       ctx,
+		errorRate: errorRate,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())), // #nosec G404
+	}
+}
+
+// SendTransaction sends a transaction through the underlying Sender, unless a failure is injected.
+func (c *ChaosSender) SendTransaction(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	return c.SendTransactionWithFeeCaps(contextID, target, value, data, fallbackGasLimit, nil)
+}
+
+// SendTransactionWithFeeCaps sends a transaction through the underlying Sender, unless a failure is injected.
+func (c *ChaosSender) SendTransactionWithFeeCaps(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, feeCaps *FeeCaps) (common.Hash, error) {
+	if c.injectFailure() {
+		log.Debug("chaos sender injected a failure", "service", c.service, "name", c.name, "context ID", contextID)
+		return common.Hash{}, ErrRPCTimeout
+	}
+	return c.Sender.SendTransactionWithFeeCaps(contextID, target, value, data, fallbackGasLimit, feeCaps)
+}
+
+// SendBatchableTransaction sends a batchable transaction through the underlying Sender, unless a failure is injected.
+func (c *ChaosSender) SendBatchableTransaction(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	if c.injectFailure() {
+		log.Debug("chaos sender injected a failure", "service", c.service, "name", c.name, "context ID", contextID)
+		return common.Hash{}, ErrRPCTimeout
+	}
+	return c.Sender.SendBatchableTransaction(contextID, target, value, data, fallbackGasLimit)
+}
+
+func (c *ChaosSender) injectFailure() bool {
+	select {
+	case <-c.ctx.Done():
+		return false
+	default:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Float64() < c.errorRate
+}
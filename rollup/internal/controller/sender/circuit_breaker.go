@@ -0,0 +1,146 @@
+package sender
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrCircuitOpen is returned by SendTransaction when the circuit breaker of the sender is open.
+var ErrCircuitOpen = errors.New("sender circuit breaker is open")
+
+// CircuitState the state of the sender circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed transactions are sent as usual.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen new transactions are rejected until the cooldown elapses.
+	CircuitOpen
+	// CircuitHalfOpen a single probe transaction is allowed, its confirmation decides the next state.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "CircuitClosed"
+	case CircuitOpen:
+		return "CircuitOpen"
+	case CircuitHalfOpen:
+		return "CircuitHalfOpen"
+	default:
+		return "CircuitUnknown"
+	}
+}
+
+// circuitBreaker opens after threshold consecutive failed confirmations, a zero threshold disables it.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	threshold uint64
+	cooldown  time.Duration
+	gauge     prometheus.Gauge
+	now       func() time.Time
+
+	state               CircuitState
+	consecutiveFailures uint64
+	openedAt            time.Time
+	probing             bool
+}
+
+func newCircuitBreaker(threshold uint64, cooldown time.Duration, gauge prometheus.Gauge) *circuitBreaker {
+	cb := &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		gauge:     gauge,
+		now:       time.Now,
+	}
+	cb.setState(CircuitClosed)
+	return cb
+}
+
+// currentState returns the state, moving from open to half-open once the cooldown has elapsed.
+func (cb *circuitBreaker) currentState() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.refreshState()
+}
+
+// allow returns ErrCircuitOpen if a new transaction must not be sent.
+// In half-open state only one probe transaction is allowed at a time.
+func (cb *circuitBreaker) allow() error {
+	if cb.threshold == 0 {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.refreshState() {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probing {
+			return ErrCircuitOpen
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+// release gives back the half-open probe slot when the probe transaction was not sent.
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+// onConfirmation records the result of a confirmed transaction.
+func (cb *circuitBreaker) onConfirmation(isSuccessful bool) {
+	if cb.threshold == 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if isSuccessful {
+		cb.consecutiveFailures = 0
+		cb.probing = false
+		cb.setState(CircuitClosed)
+		return
+	}
+
+	cb.consecutiveFailures++
+	switch cb.refreshState() {
+	case CircuitHalfOpen:
+		cb.probing = false
+		cb.open()
+	case CircuitClosed:
+		if cb.consecutiveFailures >= cb.threshold {
+			cb.open()
+		}
+	}
+}
+
+func (cb *circuitBreaker) open() {
+	cb.openedAt = cb.now()
+	cb.setState(CircuitOpen)
+}
+
+func (cb *circuitBreaker) refreshState() CircuitState {
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.cooldown {
+		cb.setState(CircuitHalfOpen)
+	}
+	return cb.state
+}
+
+func (cb *circuitBreaker) setState(state CircuitState) {
+	cb.state = state
+	if cb.gauge != nil {
+		cb.gauge.Set(float64(state))
+	}
+}
@@ -0,0 +1,68 @@
+package sender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_circuit_breaker_state"})
+	cb := newCircuitBreaker(3, time.Minute, gauge)
+	now := time.Now()
+	cb.now = func() time.Time { return now }
+
+	// Closed: failures below the threshold keep the circuit closed.
+	cb.onConfirmation(false)
+	cb.onConfirmation(false)
+	assert.Equal(t, CircuitClosed, cb.currentState())
+	assert.NoError(t, cb.allow())
+
+	// A success resets the consecutive failure count.
+	cb.onConfirmation(true)
+	cb.onConfirmation(false)
+	cb.onConfirmation(false)
+	assert.Equal(t, CircuitClosed, cb.currentState())
+
+	// Closed -> Open.
+	cb.onConfirmation(false)
+	assert.Equal(t, CircuitOpen, cb.currentState())
+	assert.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+	assert.Equal(t, float64(CircuitOpen), testutil.ToFloat64(gauge))
+
+	// Open -> HalfOpen after the cooldown, only one probe is allowed.
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.currentState())
+	assert.NoError(t, cb.allow())
+	assert.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+
+	// A probe that is not sent gives back the slot.
+	cb.release()
+	assert.NoError(t, cb.allow())
+
+	// HalfOpen -> Open when the probe fails.
+	cb.onConfirmation(false)
+	assert.Equal(t, CircuitOpen, cb.currentState())
+	assert.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+
+	// HalfOpen -> Closed when the probe succeeds.
+	now = now.Add(time.Minute)
+	assert.NoError(t, cb.allow())
+	cb.onConfirmation(true)
+	assert.Equal(t, CircuitClosed, cb.currentState())
+	assert.NoError(t, cb.allow())
+	assert.NoError(t, cb.allow())
+	assert.Equal(t, float64(CircuitClosed), testutil.ToFloat64(gauge))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(0, time.Minute, nil)
+	for i := 0; i < 10; i++ {
+		cb.onConfirmation(false)
+	}
+	assert.Equal(t, CircuitClosed, cb.currentState())
+	assert.NoError(t, cb.allow())
+}
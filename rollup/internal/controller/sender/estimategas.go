@@ -8,12 +8,16 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
 )
 
+// defaultGasEstimateBufferPercent is the percentage of gas added to the estimated gas limit if it is not configured.
+const defaultGasEstimateBufferPercent = 20
+
 func (s *Sender) estimateLegacyGas(to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (*FeeData, error) {
-	gasPrice, err := s.client.SuggestGasPrice(s.ctx)
+	gasPrice, err := s.gasPriceStrategy.GetGasPrice(s.ctx)
 	if err != nil {
-		log.Error("estimateLegacyGas SuggestGasPrice failure", "error", err)
+		log.Error("estimateLegacyGas GetGasPrice failure", "error", err)
 		return nil, err
 	}
 	gasLimit, _, err := s.estimateGasLimit(to, data, gasPrice, nil, nil, value, false)
@@ -25,7 +29,7 @@ func (s *Sender) estimateLegacyGas(to *common.Address, value *big.Int, data []by
 		}
 		gasLimit = fallbackGasLimit
 	} else {
-		gasLimit = gasLimit * 12 / 10 // 20% extra gas to avoid out of gas error
+		gasLimit = s.applyGasEstimateBuffer(gasLimit)
 	}
 	return &FeeData{
 		gasPrice: gasPrice,
@@ -33,14 +37,14 @@ func (s *Sender) estimateLegacyGas(to *common.Address, value *big.Int, data []by
 	}, nil
 }
 
-func (s *Sender) estimateDynamicGas(to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64) (*FeeData, error) {
+func (s *Sender) estimateDynamicGas(to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64, feeCaps *FeeCaps) (*FeeData, error) {
 	gasTipCap, err := s.client.SuggestGasTipCap(s.ctx)
 	if err != nil {
 		log.Error("estimateDynamicGas SuggestGasTipCap failure", "error", err)
 		return nil, err
 	}
 
-	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(new(big.Int).SetUint64(baseFee), big.NewInt(2)))
+	gasTipCap, gasFeeCap := capDynamicFee(gasTipCap, baseFee, feeCaps)
 	gasLimit, accessList, err := s.estimateGasLimit(to, data, nil, gasTipCap, gasFeeCap, value, true)
 	if err != nil {
 		log.Error("estimateDynamicGas estimateGasLimit failure",
@@ -51,7 +55,7 @@ func (s *Sender) estimateDynamicGas(to *common.Address, value *big.Int, data []b
 		}
 		gasLimit = fallbackGasLimit
 	} else {
-		gasLimit = gasLimit * 12 / 10 // 20% extra gas to avoid out of gas error
+		gasLimit = s.applyGasEstimateBuffer(gasLimit)
 	}
 	feeData := &FeeData{
 		gasLimit:  gasLimit,
@@ -64,6 +68,50 @@ func (s *Sender) estimateDynamicGas(to *common.Address, value *big.Int, data []b
 	return feeData, nil
 }
 
+// applyGasEstimateBuffer adds GasEstimateBufferPercent of the estimated gas limit, to avoid out of gas errors
+// caused by state changes between the estimation and the inclusion of the transaction.
+func (s *Sender) applyGasEstimateBuffer(gasLimit uint64) uint64 {
+	bufferPercent := s.config.GasEstimateBufferPercent
+	if bufferPercent == 0 {
+		bufferPercent = defaultGasEstimateBufferPercent
+	}
+	buffer := gasLimit * bufferPercent / 100
+	s.metrics.rollupSenderGasEstimateBufferApplied.WithLabelValues(s.service, s.name).Observe(float64(buffer))
+	return gasLimit + buffer
+}
+
+// capDynamicFee returns the gas tip cap and the gas fee cap (tip + 2 * base fee) capped by feeCaps.
+// If the base fee plus the tip exceeds the max fee, the fees are clamped with a warning instead of failing,
+// the transaction then waits for the base fee to drop or to be escalated.
+func capDynamicFee(gasTipCap *big.Int, baseFee uint64, feeCaps *FeeCaps) (*big.Int, *big.Int) {
+	if feeCaps != nil && feeCaps.MaxPriorityFeePerGas != nil && gasTipCap.Cmp(feeCaps.MaxPriorityFeePerGas) > 0 {
+		gasTipCap = new(big.Int).Set(feeCaps.MaxPriorityFeePerGas)
+	}
+
+	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(new(big.Int).SetUint64(baseFee), big.NewInt(2)))
+	if feeCaps == nil || feeCaps.MaxFeePerGas == nil || gasFeeCap.Cmp(feeCaps.MaxFeePerGas) <= 0 {
+		return gasTipCap, gasFeeCap
+	}
+	gasFeeCap = new(big.Int).Set(feeCaps.MaxFeePerGas)
+
+	baseFeeBig := new(big.Int).SetUint64(baseFee)
+	if required := new(big.Int).Add(baseFeeBig, gasTipCap); required.Cmp(gasFeeCap) > 0 {
+		log.Warn("base fee plus tip exceeds max fee, clamping", "base fee", baseFee, "gas tip cap", gasTipCap.Uint64(), "max fee", gasFeeCap.Uint64())
+		gasTipCap = new(big.Int).Sub(gasFeeCap, baseFeeBig)
+		if gasTipCap.Sign() < 0 {
+			gasTipCap = big.NewInt(0)
+		}
+	}
+	return gasTipCap, gasFeeCap
+}
+
+func gweiToWei(gwei uint64) *big.Int {
+	if gwei == 0 {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(gwei), big.NewInt(params.GWei))
+}
+
 func (s *Sender) estimateGasLimit(to *common.Address, data []byte, gasPrice, gasTipCap, gasFeeCap, value *big.Int, useAccessList bool) (uint64, *types.AccessList, error) {
 	msg := ethereum.CallMsg{
 		From:      s.auth.From,
@@ -0,0 +1,118 @@
+package sender
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestCapDynamicFee(t *testing.T) {
+	tests := []struct {
+		name              string
+		gasTipCap         int64
+		baseFee           uint64
+		feeCaps           *FeeCaps
+		expectedGasTipCap int64
+		expectedGasFeeCap int64
+	}{
+		{"no caps", 2, 10, nil, 2, 22},
+		{"below caps", 2, 10, &FeeCaps{big.NewInt(5), big.NewInt(30)}, 2, 22},
+		{"tip capped", 8, 10, &FeeCaps{big.NewInt(5), nil}, 5, 25},
+		{"fee capped", 2, 10, &FeeCaps{nil, big.NewInt(15)}, 2, 15},
+		{"base fee plus tip exceeds max fee", 2, 20, &FeeCaps{nil, big.NewInt(21)}, 1, 21},
+		{"base fee exceeds max fee", 2, 30, &FeeCaps{big.NewInt(5), big.NewInt(21)}, 0, 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasTipCap, gasFeeCap := capDynamicFee(big.NewInt(tt.gasTipCap), tt.baseFee, tt.feeCaps)
+			assert.Equal(t, tt.expectedGasTipCap, gasTipCap.Int64())
+			assert.Equal(t, tt.expectedGasFeeCap, gasFeeCap.Int64())
+			assert.True(t, gasTipCap.Cmp(gasFeeCap) <= 0)
+		})
+	}
+}
+
+func TestEstimateDynamicGasWithFeeCaps(t *testing.T) {
+	client := &ethclient.Client{}
+	s := &Sender{
+		ctx:    context.Background(),
+		config: &config.SenderConfig{TxType: DynamicFeeTxType, DefaultMaxPriorityFeeGwei: 2, DefaultMaxFeeGwei: 100},
+		client: client,
+		auth:   &bind.TransactOpts{Nonce: big.NewInt(0)},
+	}
+	s.defaultFeeCaps = &FeeCaps{
+		MaxPriorityFeePerGas: gweiToWei(s.config.DefaultMaxPriorityFeeGwei),
+		MaxFeePerGas:         gweiToWei(s.config.DefaultMaxFeeGwei),
+	}
+
+	// The mock chain suggests a 3 gwei tip, the base fee is changed by each test case.
+	var baseFee *big.Int
+	patches := gomonkey.ApplyMethodFunc(client, "HeaderByNumber", func(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+		return &gethTypes.Header{Number: big.NewInt(100), BaseFee: baseFee}, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(client, "SuggestGasTipCap", func(ctx context.Context) (*big.Int, error) {
+		return big.NewInt(3 * params.GWei), nil
+	})
+	patches.ApplyMethodFunc(client, "EstimateGas", func(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+		return 0, ethereum.NotFound
+	})
+
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei)) }
+	tests := []struct {
+		name              string
+		baseFee           *big.Int
+		feeCaps           *FeeCaps
+		expectedGasTipCap *big.Int
+		expectedGasFeeCap *big.Int
+	}{
+		{"low base fee", gwei(10), nil, gwei(2), gwei(22)},
+		{"fee cap reached", gwei(60), nil, gwei(2), gwei(100)},
+		{"base fee plus tip exceeds max fee", gwei(99), nil, gwei(1), gwei(100)},
+		{"per call override", gwei(99), &FeeCaps{MaxPriorityFeePerGas: gwei(3), MaxFeePerGas: gwei(300)}, gwei(3), gwei(201)},
+		{"per call partial override", gwei(10), &FeeCaps{MaxFeePerGas: gwei(15)}, gwei(2), gwei(15)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseFee = tt.baseFee
+			_, currentBaseFee, err := s.getBlockNumberAndBaseFee(context.Background())
+			assert.NoError(t, err)
+
+			feeData, err := s.getFeeData(&common.Address{}, big.NewInt(0), nil, 21000, currentBaseFee, tt.feeCaps)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedGasTipCap, feeData.gasTipCap)
+			assert.Equal(t, tt.expectedGasFeeCap, feeData.gasFeeCap)
+			assert.Equal(t, uint64(21000), feeData.gasLimit)
+		})
+	}
+}
+
+func TestApplyGasEstimateBuffer(t *testing.T) {
+	s := &Sender{
+		config:  &config.SenderConfig{},
+		service: "test",
+		name:    "gas_estimate_buffer",
+		metrics: initSenderMetrics(nil),
+	}
+	// 20% by default.
+	assert.Equal(t, uint64(120000), s.applyGasEstimateBuffer(100000))
+
+	s.config.GasEstimateBufferPercent = 35
+	assert.Equal(t, uint64(135000), s.applyGasEstimateBuffer(100000))
+
+	s.config.GasEstimateBufferPercent = 1
+	assert.Equal(t, uint64(21210), s.applyGasEstimateBuffer(21000))
+}
@@ -0,0 +1,60 @@
+package sender
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// broadcastTransaction sends the signed tx to the endpoint, and to the standby endpoint if the endpoint is unreachable.
+// The same signed tx is sent to both, so a failover never consumes another nonce.
+// The tx is privately sent to the Flashbots relay instead if it is configured, i.e. for the commit sender.
+func (s *Sender) broadcastTransaction(tx *gethTypes.Transaction) error {
+	if s.flashbots != nil {
+		// the relay does not accept blob transactions.
+		if tx.Type() != gethTypes.BlobTxType {
+			return s.flashbots.sendPrivateTransaction(s.ctx, tx)
+		}
+		log.Warn("blob tx is not supported by the flashbots relay, send it to the endpoint", "service", s.service, "name", s.name, "tx hash", tx.Hash().String())
+	}
+
+	err := s.client.SendTransaction(s.ctx, tx)
+	if err == nil || s.standbyClient == nil || !isConnectionError(err) {
+		return err
+	}
+
+	s.metrics.rollupSenderFailoverTotal.WithLabelValues(s.service, s.name).Inc()
+	log.Warn("endpoint unreachable, send tx to the standby endpoint", "service", s.service, "name", s.name, "tx hash", tx.Hash().String(), "nonce", tx.Nonce(), "err", err)
+	standbyErr := s.standbyClient.SendTransaction(s.ctx, tx)
+	if standbyErr != nil && isAlreadyKnownError(standbyErr) {
+		// the endpoint received the tx before the connection broke.
+		return nil
+	}
+	return standbyErr
+}
+
+// isConnectionError returns true if err is a failure to reach the endpoint, rather than an error returned by the node.
+func isConnectionError(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		// the endpoint is behind an unavailable proxy.
+		return httpErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isAlreadyKnownError returns true if err rejects a tx already in the txpool.
+func isAlreadyKnownError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
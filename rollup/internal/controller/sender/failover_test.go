@@ -0,0 +1,140 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// newSendRawTransactionServer returns a mock rpc server answering eth_sendRawTransaction with rpcErr if it is set,
+// the received transactions are recorded in order.
+func newSendRawTransactionServer(t *testing.T, rpcErr *string) (*httptest.Server, *[]*gethTypes.Transaction) {
+	var txs []*gethTypes.Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []hexutil.Bytes `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "eth_sendRawTransaction", req.Method)
+
+		tx := new(gethTypes.Transaction)
+		assert.NoError(t, tx.UnmarshalBinary(req.Params[0]))
+		txs = append(txs, tx)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr != nil && *rpcErr != "" {
+			resp["error"] = map[string]interface{}{"code": -32000, "message": *rpcErr}
+		} else {
+			resp["result"] = tx.Hash()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server, &txs
+}
+
+func TestSenderFailover(t *testing.T) {
+	// the primary endpoint refuses the connections.
+	primaryServer := httptest.NewServer(http.NotFoundHandler())
+	primaryURL := primaryServer.URL
+	primaryServer.Close()
+	primary, err := ethclient.Dial(primaryURL)
+	assert.NoError(t, err)
+
+	var standbyErr string
+	standbyServer, standbyTxs := newSendRawTransactionServer(t, &standbyErr)
+	standby, err := ethclient.Dial(standbyServer.URL)
+	assert.NoError(t, err)
+
+	priv, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	chainID := big.NewInt(534352)
+	auth, err := bind.NewKeyedTransactorWithChainID(priv, chainID)
+	assert.NoError(t, err)
+	auth.Nonce = big.NewInt(5)
+
+	s := &Sender{
+		ctx:           context.Background(),
+		config:        &config.SenderConfig{TxType: LegacyTxType},
+		client:        primary,
+		standbyClient: standby,
+		chainID:       chainID,
+		auth:          auth,
+		service:       "test",
+		name:          "failover",
+		metrics:       initSenderMetrics(nil),
+	}
+	failovers := s.metrics.rollupSenderFailoverTotal.WithLabelValues("test", "failover")
+	initialFailovers := testutil.ToFloat64(failovers)
+
+	to := common.HexToAddress("0x1")
+	feeData := &FeeData{gasPrice: big.NewInt(1000), gasLimit: 21000}
+	tx, err := s.createAndSendTx(feeData, &to, big.NewInt(0), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *standbyTxs, 1)
+	assert.Equal(t, tx.Hash(), (*standbyTxs)[0].Hash())
+	assert.Equal(t, uint64(5), tx.Nonce())
+	assert.Equal(t, uint64(6), s.auth.Nonce.Uint64())
+	assert.Equal(t, initialFailovers+1, testutil.ToFloat64(failovers))
+
+	// a tx the primary received before the connection broke is already known by the standby.
+	standbyErr = "already known"
+	tx, err = s.createAndSendTx(feeData, &to, big.NewInt(0), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), tx.Nonce())
+	assert.Equal(t, uint64(7), s.auth.Nonce.Uint64())
+
+	// the nonce is not consumed if the standby rejects the tx.
+	standbyErr = "insufficient funds for gas * price + value"
+	_, err = s.createAndSendTx(feeData, &to, big.NewInt(0), nil, nil)
+	assert.ErrorContains(t, err, "insufficient funds")
+	assert.Equal(t, uint64(7), s.auth.Nonce.Uint64())
+	assert.Equal(t, initialFailovers+3, testutil.ToFloat64(failovers))
+
+	// the errors returned by a reachable primary endpoint are not failed over.
+	primaryErr := "replacement transaction underpriced"
+	primaryServer2, primaryTxs := newSendRawTransactionServer(t, &primaryErr)
+	s.client, err = ethclient.Dial(primaryServer2.URL)
+	assert.NoError(t, err)
+	_, err = s.createAndSendTx(feeData, &to, big.NewInt(0), nil, nil)
+	assert.ErrorContains(t, err, "underpriced")
+	assert.Len(t, *primaryTxs, 1)
+	assert.Len(t, *standbyTxs, 3)
+	assert.Equal(t, initialFailovers+3, testutil.ToFloat64(failovers))
+}
+
+type mockRPCError struct{}
+
+func (mockRPCError) Error() string  { return "execution reverted" }
+func (mockRPCError) ErrorCode() int { return 3 }
+
+func TestIsConnectionError(t *testing.T) {
+	assert.True(t, isConnectionError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.True(t, isConnectionError(fmt.Errorf("post: %w", io.EOF)))
+	assert.True(t, isConnectionError(rpc.HTTPError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}))
+	assert.False(t, isConnectionError(rpc.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}))
+	assert.False(t, isConnectionError(mockRPCError{}))
+	assert.False(t, isConnectionError(errors.New("nonce too low")))
+}
@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// defaultMinBumpPercent the minimum fee bump of a stuck transaction replacement if none is configured,
+// above the 10% required by the txpool.
+const defaultMinBumpPercent = 12.5
+
+// errFeeBumpLimitReached is returned when a transaction is not replaced because it was bumped MaxBumps times.
+var errFeeBumpLimitReached = errors.New("fee bump limit reached")
+
+// FeeBumpPolicy the replacement rules of the pending transactions of a sender.
+type FeeBumpPolicy struct {
+	// MinBumpPercent the minimum fee increase of a stuck transaction replacement in percent of the replaced fee,
+	// so that it is accepted by the txpool even if the escalate multiple is lower.
+	MinBumpPercent float64
+	// MaxBumpPercent the maximum fee increase of a replacement in percent of the replaced fee, unbounded if 0.
+	MaxBumpPercent float64
+	// MaxBumps the maximum number of replacements of a transaction, unlimited if 0.
+	MaxBumps int
+	// BumpInterval the minimum time between two replacements of a transaction.
+	BumpInterval time.Duration
+}
+
+// newFeeBumpPolicy returns the fee bump policy of cfg, the default policy if cfg is nil.
+func newFeeBumpPolicy(cfg *config.FeeBumpPolicyConfig) *FeeBumpPolicy {
+	if cfg == nil {
+		return &FeeBumpPolicy{MinBumpPercent: defaultMinBumpPercent}
+	}
+	policy := &FeeBumpPolicy{
+		MinBumpPercent: cfg.MinBumpPercent,
+		MaxBumpPercent: cfg.MaxBumpPercent,
+		MaxBumps:       cfg.MaxBumps,
+		BumpInterval:   time.Duration(cfg.BumpIntervalSec) * time.Second,
+	}
+	if policy.MinBumpPercent == 0 {
+		policy.MinBumpPercent = defaultMinBumpPercent
+	}
+	return policy
+}
+
+// NextGasPrice returns current bumped by MinBumpPercent rounded up, at least 1 wei more than current,
+// and false if the transaction was already bumped MaxBumps times.
+func (p *FeeBumpPolicy) NextGasPrice(current *big.Int, bumpCount int) (*big.Int, bool) {
+	if p.MaxBumps > 0 && bumpCount >= p.MaxBumps {
+		return current, false
+	}
+	bumped := scaleByPercent(current, p.MinBumpPercent, true)
+	if bumped.Cmp(current) <= 0 {
+		bumped = new(big.Int).Add(current, big.NewInt(1))
+	}
+	return bumped, true
+}
+
+// capGasPrice returns price capped at current bumped by MaxBumpPercent rounded down, price if MaxBumpPercent is 0.
+func (p *FeeBumpPolicy) capGasPrice(current, price *big.Int) *big.Int {
+	if p.MaxBumpPercent <= 0 {
+		return price
+	}
+	limit := scaleByPercent(current, p.MaxBumpPercent, false)
+	if price.Cmp(limit) > 0 {
+		return limit
+	}
+	return price
+}
+
+// scaleByPercent returns fee * (100 + percent) / 100, percent is rounded to basis points.
+func scaleByPercent(fee *big.Int, percent float64, roundUp bool) *big.Int {
+	den := big.NewInt(10000)
+	scaled := new(big.Int).Mul(fee, big.NewInt(10000+int64(math.Round(percent*100))))
+	if roundUp {
+		scaled.Add(scaled, new(big.Int).Sub(den, big.NewInt(1)))
+	}
+	return scaled.Div(scaled, den)
+}
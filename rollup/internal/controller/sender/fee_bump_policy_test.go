@@ -0,0 +1,70 @@
+package sender
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestNewFeeBumpPolicy(t *testing.T) {
+	assert.Equal(t, &FeeBumpPolicy{MinBumpPercent: 12.5}, newFeeBumpPolicy(nil))
+	assert.Equal(t, &FeeBumpPolicy{MinBumpPercent: 12.5, MaxBumps: 3}, newFeeBumpPolicy(&config.FeeBumpPolicyConfig{MaxBumps: 3}))
+	assert.Equal(t, &FeeBumpPolicy{MinBumpPercent: 20, MaxBumpPercent: 50, MaxBumps: 5, BumpInterval: time.Minute},
+		newFeeBumpPolicy(&config.FeeBumpPolicyConfig{MinBumpPercent: 20, MaxBumpPercent: 50, MaxBumps: 5, BumpIntervalSec: 60}))
+}
+
+func TestFeeBumpPolicyNextGasPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    FeeBumpPolicy
+		current   int64
+		bumpCount int
+		expected  int64
+		allowed   bool
+	}{
+		{"zero fee", FeeBumpPolicy{MinBumpPercent: 12.5}, 0, 0, 1, true},
+		{"1 wei more", FeeBumpPolicy{MinBumpPercent: 12.5}, 1, 0, 2, true},
+		{"rounded up below 1 wei", FeeBumpPolicy{MinBumpPercent: 12.5}, 8, 0, 9, true},
+		{"rounded up", FeeBumpPolicy{MinBumpPercent: 12.5}, 9, 0, 11, true},
+		{"exact", FeeBumpPolicy{MinBumpPercent: 12.5}, 1000, 0, 1125, true},
+		{"rounded up exact", FeeBumpPolicy{MinBumpPercent: 12.5}, 1001, 0, 1127, true},
+		{"fractional percent", FeeBumpPolicy{MinBumpPercent: 10.01}, 10000, 0, 11001, true},
+		{"zero percent", FeeBumpPolicy{}, 1000, 0, 1001, true},
+		{"unlimited bumps", FeeBumpPolicy{MinBumpPercent: 10}, 1000, 100, 1100, true},
+		{"last bump", FeeBumpPolicy{MinBumpPercent: 10, MaxBumps: 3}, 1000, 2, 1100, true},
+		{"max bumps reached", FeeBumpPolicy{MinBumpPercent: 10, MaxBumps: 3}, 1000, 3, 1000, false},
+		{"max bumps exceeded", FeeBumpPolicy{MinBumpPercent: 10, MaxBumps: 3}, 1000, 4, 1000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, allowed := tt.policy.NextGasPrice(big.NewInt(tt.current), tt.bumpCount)
+			assert.Equal(t, big.NewInt(tt.expected), next)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestFeeBumpPolicyCapGasPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   FeeBumpPolicy
+		current  int64
+		price    int64
+		expected int64
+	}{
+		{"unbounded", FeeBumpPolicy{MinBumpPercent: 12.5}, 1000, 5000, 5000},
+		{"below the cap", FeeBumpPolicy{MaxBumpPercent: 50}, 1000, 1499, 1499},
+		{"at the cap", FeeBumpPolicy{MaxBumpPercent: 50}, 1000, 1500, 1500},
+		{"above the cap", FeeBumpPolicy{MaxBumpPercent: 50}, 1000, 1501, 1500},
+		{"cap rounded down", FeeBumpPolicy{MaxBumpPercent: 12.5}, 1001, 2000, 1126},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, big.NewInt(tt.expected), tt.policy.capGasPrice(big.NewInt(tt.current), big.NewInt(tt.price)))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/accounts"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+
+	"scroll-tech/common/types"
+)
+
+const (
+	// flashbotsSignatureHeader is the header authenticating the requests to the Flashbots relay.
+	flashbotsSignatureHeader = "X-Flashbots-Signature"
+	// flashbotsRequestTimeout is the timeout of a request to the Flashbots relay.
+	flashbotsRequestTimeout = 10 * time.Second
+)
+
+// The statuses returned by eth_getPrivateTransactionStatus of the transactions dropped by the relay.
+const (
+	privateTxStatusFailed    = "FAILED"
+	privateTxStatusCancelled = "CANCELLED"
+)
+
+// flashbotsClient sends the transactions privately to a Flashbots relay, so they are not seen in the public mempool
+// before their inclusion.
+type flashbotsClient struct {
+	url        string
+	signingKey *ecdsa.PrivateKey
+	httpClient *http.Client
+}
+
+func newFlashbotsClient(url string, signingKey *ecdsa.PrivateKey) *flashbotsClient {
+	return &flashbotsClient{
+		url:        url,
+		signingKey: signingKey,
+		httpClient: &http.Client{Timeout: flashbotsRequestTimeout},
+	}
+}
+
+type flashbotsRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type flashbotsResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// signature returns the X-Flashbots-Signature header value of the request body: the address of the signing key
+// and its signature of the hex encoded keccak256 hash of the body, as an EIP-191 message.
+func (c *flashbotsClient) signature(body []byte) (string, error) {
+	hash := crypto.Keccak256Hash(body).Hex()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(hash)), c.signingKey)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(c.signingKey.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
+
+// call sends a signed JSON-RPC request to the relay and decodes its result into result.
+func (c *flashbotsClient) call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	body, err := json.Marshal(&flashbotsRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+	signature, err := c.signature(body)
+	if err != nil {
+		return fmt.Errorf("failed to sign %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(flashbotsSignatureHeader, signature)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request failed, status: %s, body: %s", method, resp.Status, string(respBody))
+	}
+
+	var rpcResp flashbotsResponse
+	if err = json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s error, code: %d, message: %s", method, rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// sendPrivateTransaction sends the signed tx to the relay with eth_sendPrivateTransaction.
+func (c *flashbotsClient) sendPrivateTransaction(ctx context.Context, tx *gethTypes.Transaction) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	params := map[string]interface{}{"tx": hexutil.Encode(data)}
+	return c.call(ctx, nil, "eth_sendPrivateTransaction", params)
+}
+
+// getPrivateTransactionStatus returns the status of the tx on the relay with eth_getPrivateTransactionStatus.
+func (c *flashbotsClient) getPrivateTransactionStatus(ctx context.Context, hash common.Hash) (string, error) {
+	var result struct {
+		Status string `json:"status"`
+	}
+	if err := c.call(ctx, &result, "eth_getPrivateTransactionStatus", hash); err != nil {
+		return "", err
+	}
+	return result.Status, nil
+}
+
+// checkPrivateTransactions polls the relay for the status of the pending transactions, the transactions dropped by the
+// relay (e.g. not included before their max block number) are sent to it again. The confirmations are still
+// handled by checkPendingTransaction, from the receipts of the included transactions.
+func (s *Sender) checkPrivateTransactions() {
+	txns, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(s.ctx, s.senderType, s.auth.From, 100)
+	if err != nil {
+		log.Error("failed to load pending transactions", "sender meta", s.getSenderMeta(), "err", err)
+		return
+	}
+
+	for _, txn := range txns {
+		// the replaced transactions are superseded on the relay too.
+		if txn.Status != types.TxStatusPending {
+			continue
+		}
+		tx := new(gethTypes.Transaction)
+		if err = tx.DecodeRLP(rlp.NewStream(bytes.NewReader(txn.RLPEncoding), 0)); err != nil {
+			log.Error("failed to decode RLP", "context ID", txn.ContextID, "sender meta", s.getSenderMeta(), "err", err)
+			continue
+		}
+		if tx.Type() == gethTypes.BlobTxType {
+			continue
+		}
+
+		status, err := s.flashbots.getPrivateTransactionStatus(s.ctx, tx.Hash())
+		if err != nil {
+			log.Warn("failed to get private transaction status", "service", s.service, "name", s.name, "hash", tx.Hash().String(), "err", err)
+			continue
+		}
+		s.metrics.rollupSenderPrivateTxStatusTotal.WithLabelValues(s.service, s.name, status).Inc()
+
+		switch status {
+		case privateTxStatusFailed, privateTxStatusCancelled:
+			log.Warn("private transaction dropped by the relay, send it again", "service", s.service, "name", s.name, "context ID", txn.ContextID, "hash", tx.Hash().String(), "nonce", tx.Nonce(), "status", status)
+			if err = s.flashbots.sendPrivateTransaction(s.ctx, tx); err != nil {
+				log.Error("failed to send private transaction again", "service", s.service, "name", s.name, "hash", tx.Hash().String(), "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/accounts"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// newFlashbotsServer returns a mock Flashbots relay checking the request signatures of signer, it records the
+// private transactions in order and answers eth_getPrivateTransactionStatus from statuses.
+func newFlashbotsServer(t *testing.T, signer common.Address, statuses map[common.Hash]string) (*httptest.Server, *[]*gethTypes.Transaction) {
+	var txs []*gethTypes.Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		// the signature is of the hex encoded body hash, as an EIP-191 message.
+		parts := strings.Split(r.Header.Get(flashbotsSignatureHeader), ":")
+		assert.Len(t, parts, 2)
+		assert.Equal(t, signer.Hex(), parts[0])
+		sig, err := hexutil.Decode(parts[1])
+		assert.NoError(t, err)
+		pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), sig)
+		assert.NoError(t, err)
+		assert.Equal(t, signer, crypto.PubkeyToAddress(*pubKey))
+
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_sendPrivateTransaction":
+			var params struct {
+				Tx hexutil.Bytes `json:"tx"`
+			}
+			assert.NoError(t, json.Unmarshal(req.Params[0], &params))
+			tx := new(gethTypes.Transaction)
+			assert.NoError(t, tx.UnmarshalBinary(params.Tx))
+			txs = append(txs, tx)
+			resp["result"] = tx.Hash()
+		case "eth_getPrivateTransactionStatus":
+			var hash common.Hash
+			assert.NoError(t, json.Unmarshal(req.Params[0], &hash))
+			resp["result"] = map[string]interface{}{"status": statuses[hash]}
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server, &txs
+}
+
+func TestFlashbotsClient(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	priv, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(priv, big.NewInt(1))
+	assert.NoError(t, err)
+	to := common.HexToAddress("0x1")
+	tx, err := auth.Signer(auth.From, gethTypes.NewTx(&gethTypes.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1000), Gas: 50000, To: &to}))
+	assert.NoError(t, err)
+
+	server, txs := newFlashbotsServer(t, crypto.PubkeyToAddress(signingKey.PublicKey), map[common.Hash]string{tx.Hash(): "INCLUDED"})
+	client := newFlashbotsClient(server.URL, signingKey)
+
+	assert.NoError(t, client.sendPrivateTransaction(context.Background(), tx))
+	assert.Len(t, *txs, 1)
+	assert.Equal(t, tx.Hash(), (*txs)[0].Hash())
+
+	status, err := client.getPrivateTransactionStatus(context.Background(), tx.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, "INCLUDED", status)
+
+	err = client.call(context.Background(), nil, "eth_sendBundle")
+	assert.ErrorContains(t, err, "method not found")
+}
+
+func TestCheckPrivateTransactions(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	priv, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(priv, big.NewInt(1))
+	assert.NoError(t, err)
+
+	to := common.HexToAddress("0x1")
+	signTx := func(nonce uint64) *gethTypes.Transaction {
+		tx, signErr := auth.Signer(auth.From, gethTypes.NewTx(&gethTypes.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1000), Gas: 50000, To: &to}))
+		assert.NoError(t, signErr)
+		return tx
+	}
+	pendingTx := func(tx *gethTypes.Transaction, status types.TxStatus) orm.PendingTransaction {
+		var buf bytes.Buffer
+		assert.NoError(t, tx.EncodeRLP(&buf))
+		return orm.PendingTransaction{Nonce: tx.Nonce(), RLPEncoding: buf.Bytes(), Status: status}
+	}
+	// nonce 1 is pending on the relay, nonce 2 failed, nonce 3 was cancelled and nonce 4 failed but is replaced.
+	tx1, tx2, tx3, tx4 := signTx(1), signTx(2), signTx(3), signTx(4)
+	statuses := map[common.Hash]string{
+		tx1.Hash(): "PENDING",
+		tx2.Hash(): privateTxStatusFailed,
+		tx3.Hash(): privateTxStatusCancelled,
+		tx4.Hash(): privateTxStatusFailed,
+	}
+	server, txs := newFlashbotsServer(t, crypto.PubkeyToAddress(signingKey.PublicKey), statuses)
+
+	client := &ethclient.Client{}
+	pendingTransactionOrm := &orm.PendingTransaction{}
+	s := &Sender{
+		ctx:                   context.Background(),
+		config:                &config.SenderConfig{},
+		client:                client,
+		flashbots:             newFlashbotsClient(server.URL, signingKey),
+		auth:                  auth,
+		service:               "test",
+		name:                  "flashbots",
+		senderType:            types.SenderTypeCommitBatch,
+		pendingTransactionOrm: pendingTransactionOrm,
+		metrics:               initSenderMetrics(nil),
+	}
+	failed := testutil.ToFloat64(s.metrics.rollupSenderPrivateTxStatusTotal.WithLabelValues("test", "flashbots", privateTxStatusFailed))
+
+	patches := gomonkey.ApplyMethodFunc(pendingTransactionOrm, "GetPendingOrReplacedTransactionsBySenderTypeAndAddress", func(ctx context.Context, senderType types.SenderType, senderAddress common.Address, limit int) ([]orm.PendingTransaction, error) {
+		return []orm.PendingTransaction{
+			pendingTx(tx1, types.TxStatusPending),
+			pendingTx(tx2, types.TxStatusPending),
+			pendingTx(tx3, types.TxStatusPending),
+			pendingTx(tx4, types.TxStatusReplaced),
+		}, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(client, "SendTransaction", func(ctx context.Context, tx *gethTypes.Transaction) error {
+		t.Fatal("the transactions must not be sent to the public endpoint")
+		return nil
+	})
+
+	s.checkPrivateTransactions()
+	assert.Len(t, *txs, 2)
+	assert.Equal(t, tx2.Hash(), (*txs)[0].Hash())
+	assert.Equal(t, tx3.Hash(), (*txs)[1].Hash())
+	assert.Equal(t, failed+1, testutil.ToFloat64(s.metrics.rollupSenderPrivateTxStatusTotal.WithLabelValues("test", "flashbots", privateTxStatusFailed)))
+
+	// the new transactions are privately sent too.
+	assert.NoError(t, s.broadcastTransaction(signTx(5)))
+	assert.Len(t, *txs, 3)
+}
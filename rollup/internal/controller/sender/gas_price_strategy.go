@@ -0,0 +1,96 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const (
+	// SuggestedGasPriceStrategyType uses the gas price suggested by the endpoint, the default strategy.
+	SuggestedGasPriceStrategyType = "Suggested"
+
+	// FixedGasPriceStrategyType uses the fixed gas price of the sender config.
+	FixedGasPriceStrategyType = "Fixed"
+
+	// L1OracleGasPriceStrategyType uses the base fee of the latest l1 block stored by the l1 watcher.
+	L1OracleGasPriceStrategyType = "L1Oracle"
+)
+
+// GasPriceStrategy determines the gas price of LegacyTx and AccessListTx transactions.
+type GasPriceStrategy interface {
+	GetGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// SuggestedGasPriceStrategy returns the gas price suggested by the endpoint.
+type SuggestedGasPriceStrategy struct {
+	client *ethclient.Client
+}
+
+// GetGasPrice implements GasPriceStrategy.
+func (s *SuggestedGasPriceStrategy) GetGasPrice(ctx context.Context) (*big.Int, error) {
+	return s.client.SuggestGasPrice(ctx)
+}
+
+// FixedGasPriceStrategy returns a fixed gas price.
+type FixedGasPriceStrategy struct {
+	gasPrice *big.Int
+}
+
+// GetGasPrice implements GasPriceStrategy.
+func (s *FixedGasPriceStrategy) GetGasPrice(context.Context) (*big.Int, error) {
+	return new(big.Int).Set(s.gasPrice), nil
+}
+
+// L1OracleGasPriceStrategy returns the base fee of the latest l1 block stored in db, it only makes sense for the senders to l1.
+type L1OracleGasPriceStrategy struct {
+	l1BlockOrm *orm.L1Block
+}
+
+// GetGasPrice implements GasPriceStrategy.
+func (s *L1OracleGasPriceStrategy) GetGasPrice(ctx context.Context) (*big.Int, error) {
+	height, err := s.l1BlockOrm.GetLatestL1BlockHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest l1 block height: %w", err)
+	}
+	blocks, _, err := s.l1BlockOrm.GetL1BlocksInRange(ctx, height, height, 1, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get l1 block %d: %w", height, err)
+	}
+	if len(blocks) != 1 || blocks[0].BaseFee == 0 {
+		return nil, fmt.Errorf("no l1 base fee found at height %d", height)
+	}
+	return new(big.Int).SetUint64(blocks[0].BaseFee), nil
+}
+
+// newGasPriceStrategy returns the gas price strategy of the sender named name, the one of its name in the
+// GasPriceStrategies of cfg if any, the GasPriceStrategyType of cfg otherwise.
+func newGasPriceStrategy(cfg *config.SenderConfig, name string, client *ethclient.Client, db *gorm.DB) (GasPriceStrategy, error) {
+	strategyType, fixedGasPrice := cfg.GasPriceStrategyType, cfg.FixedGasPrice
+	if override := cfg.GasPriceStrategies[name]; override != nil {
+		strategyType, fixedGasPrice = override.Type, override.FixedGasPrice
+	}
+	if strategyType != "" && strategyType != SuggestedGasPriceStrategyType && cfg.TxType == DynamicFeeTxType {
+		return nil, fmt.Errorf("gas price strategy %v is not supported by %v", strategyType, DynamicFeeTxType)
+	}
+
+	switch strategyType {
+	case "", SuggestedGasPriceStrategyType:
+		return &SuggestedGasPriceStrategy{client: client}, nil
+	case FixedGasPriceStrategyType:
+		if fixedGasPrice == 0 {
+			return nil, fmt.Errorf("fixed_gas_price is required by the %v gas price strategy", FixedGasPriceStrategyType)
+		}
+		return &FixedGasPriceStrategy{gasPrice: new(big.Int).SetUint64(fixedGasPrice)}, nil
+	case L1OracleGasPriceStrategyType:
+		return &L1OracleGasPriceStrategy{l1BlockOrm: orm.NewL1Block(db)}, nil
+	default:
+		return nil, fmt.Errorf("unknown gas price strategy: %v", strategyType)
+	}
+}
@@ -0,0 +1,94 @@
+package sender
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func TestNewGasPriceStrategy(t *testing.T) {
+	client := &ethclient.Client{}
+
+	strategy, err := newGasPriceStrategy(&config.SenderConfig{TxType: LegacyTxType}, "test_sender", client, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &SuggestedGasPriceStrategy{}, strategy)
+
+	strategy, err = newGasPriceStrategy(&config.SenderConfig{TxType: DynamicFeeTxType, GasPriceStrategyType: SuggestedGasPriceStrategyType}, "test_sender", client, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &SuggestedGasPriceStrategy{}, strategy)
+
+	strategy, err = newGasPriceStrategy(&config.SenderConfig{TxType: LegacyTxType, GasPriceStrategyType: FixedGasPriceStrategyType, FixedGasPrice: 7}, "test_sender", client, nil)
+	assert.NoError(t, err)
+	gasPrice, err := strategy.GetGasPrice(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(7), gasPrice)
+
+	strategy, err = newGasPriceStrategy(&config.SenderConfig{TxType: AccessListTxType, GasPriceStrategyType: L1OracleGasPriceStrategyType}, "test_sender", client, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &L1OracleGasPriceStrategy{}, strategy)
+
+	_, err = newGasPriceStrategy(&config.SenderConfig{TxType: LegacyTxType, GasPriceStrategyType: FixedGasPriceStrategyType}, "test_sender", client, nil)
+	assert.Error(t, err)
+	_, err = newGasPriceStrategy(&config.SenderConfig{TxType: DynamicFeeTxType, GasPriceStrategyType: FixedGasPriceStrategyType, FixedGasPrice: 7}, "test_sender", client, nil)
+	assert.Error(t, err)
+	_, err = newGasPriceStrategy(&config.SenderConfig{TxType: LegacyTxType, GasPriceStrategyType: "Unknown"}, "test_sender", client, nil)
+	assert.Error(t, err)
+}
+
+func TestNewGasPriceStrategyPerSender(t *testing.T) {
+	client := &ethclient.Client{}
+	// the senders of a relayer share its sender config.
+	cfg := &config.SenderConfig{
+		TxType:               LegacyTxType,
+		GasPriceStrategyType: L1OracleGasPriceStrategyType,
+		GasPriceStrategies: map[string]*config.GasPriceStrategyConfig{
+			"finalize_sender": {Type: FixedGasPriceStrategyType, FixedGasPrice: 9},
+		},
+	}
+
+	strategy, err := newGasPriceStrategy(cfg, "gas_oracle_sender", client, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &L1OracleGasPriceStrategy{}, strategy)
+
+	strategy, err = newGasPriceStrategy(cfg, "finalize_sender", client, nil)
+	assert.NoError(t, err)
+	gasPrice, err := strategy.GetGasPrice(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(9), gasPrice)
+
+	// the overrides are validated as the shared strategy.
+	cfg.GasPriceStrategies["commit_sender"] = &config.GasPriceStrategyConfig{Type: FixedGasPriceStrategyType}
+	_, err = newGasPriceStrategy(cfg, "commit_sender", client, nil)
+	assert.Error(t, err)
+}
+
+func TestL1OracleGasPriceStrategy(t *testing.T) {
+	l1BlockOrm := &orm.L1Block{}
+	strategy := &L1OracleGasPriceStrategy{l1BlockOrm: l1BlockOrm}
+
+	var blocks []*orm.L1Block
+	patches := gomonkey.ApplyMethodFunc(l1BlockOrm, "GetLatestL1BlockHeight", func(context.Context) (uint64, error) {
+		return 100, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(l1BlockOrm, "GetL1BlocksInRange", func(_ context.Context, startHeight, endHeight uint64, _, _ int) ([]*orm.L1Block, int64, error) {
+		assert.Equal(t, uint64(100), startHeight)
+		assert.Equal(t, uint64(100), endHeight)
+		return blocks, int64(len(blocks)), nil
+	})
+
+	_, err := strategy.GetGasPrice(context.Background())
+	assert.Error(t, err)
+
+	blocks = []*orm.L1Block{{Number: 100, BaseFee: 3000}}
+	gasPrice, err := strategy.GetGasPrice(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(3000), gasPrice)
+}
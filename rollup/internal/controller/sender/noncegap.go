@@ -0,0 +1,113 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+const (
+	// maxNonceGapScan is the maximum number of nonces above the confirmed nonce checked by a FillNonceGap call.
+	maxNonceGapScan = 100
+
+	// selfTransferGasLimit is the fallback gas limit of the self-transfers filling the nonce gaps.
+	selfTransferGasLimit = 21000
+)
+
+// FillNonceGap unblocks the nonces of the sender stuck behind a dropped transaction. Each nonce between the confirmed
+// nonce of the sender account and the local nonce without a transaction known by the endpoint is filled:
+// the recorded transaction of the nonce is broadcast again, or a zero-value self-transfer is sent if there is none.
+// The sends wait for it, so that the nonce of a transaction being sent is not taken for a gap.
+func (s *Sender) FillNonceGap(ctx context.Context) error {
+	if s.config.SimulateOnly {
+		return nil
+	}
+	s.nonceMu.Lock()
+	defer s.nonceMu.Unlock()
+
+	confirmedNonce, err := s.client.NonceAt(ctx, s.auth.From, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce for address %s, err: %w", s.auth.From.Hex(), err)
+	}
+	tip := s.auth.Nonce.Uint64()
+	if confirmedNonce >= tip {
+		return nil
+	}
+	if tip-confirmedNonce > maxNonceGapScan {
+		tip = confirmedNonce + maxNonceGapScan
+	}
+
+	pendingTxs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(ctx, s.senderType, s.auth.From, 10*maxNonceGapScan)
+	if err != nil {
+		return fmt.Errorf("failed to load pending transactions, err: %w", err)
+	}
+	// the transactions of each nonce in ascending order by fee, the last one is the latest replacement.
+	txsByNonce := make(map[uint64][]*gethTypes.Transaction)
+	for _, pendingTx := range pendingTxs {
+		tx := new(gethTypes.Transaction)
+		if err = tx.DecodeRLP(rlp.NewStream(bytes.NewReader(pendingTx.RLPEncoding), 0)); err != nil {
+			return fmt.Errorf("failed to decode RLP, context ID: %s, err: %w", pendingTx.ContextID, err)
+		}
+		txsByNonce[tx.Nonce()] = append(txsByNonce[tx.Nonce()], tx)
+	}
+
+	for nonce := confirmedNonce; nonce < tip; nonce++ {
+		known, err := s.isAnyTxKnown(ctx, txsByNonce[nonce])
+		if err != nil {
+			return err
+		}
+		if known {
+			continue
+		}
+
+		if txs := txsByNonce[nonce]; len(txs) > 0 {
+			tx := txs[len(txs)-1]
+			if err = s.broadcastTransaction(tx); err != nil {
+				return fmt.Errorf("failed to broadcast dropped transaction again, nonce: %d, hash: %s, err: %w", nonce, tx.Hash().String(), err)
+			}
+			log.Warn("broadcast dropped transaction again", "service", s.service, "name", s.name, "from", s.auth.From.String(), "nonce", nonce, "hash", tx.Hash().String())
+		} else {
+			tx, err := s.sendSelfTransfer(ctx, nonce)
+			if err != nil {
+				return fmt.Errorf("failed to fill nonce gap, nonce: %d, err: %w", nonce, err)
+			}
+			log.Warn("filled nonce gap with a self-transfer", "service", s.service, "name", s.name, "from", s.auth.From.String(), "nonce", nonce, "hash", tx.Hash().String())
+		}
+		s.metrics.rollupSenderNonceGapsFilled.WithLabelValues(s.service, s.name).Inc()
+	}
+	return nil
+}
+
+// isAnyTxKnown returns true if the endpoint knows any of txs, either pending or included.
+func (s *Sender) isAnyTxKnown(ctx context.Context, txs []*gethTypes.Transaction) (bool, error) {
+	for _, tx := range txs {
+		_, _, err := s.client.TransactionByHash(ctx, tx.Hash())
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return false, fmt.Errorf("failed to get transaction by hash %s, err: %w", tx.Hash().String(), err)
+		}
+	}
+	return false, nil
+}
+
+// sendSelfTransfer sends a zero-value transfer to the sender itself at nonce.
+func (s *Sender) sendSelfTransfer(ctx context.Context, nonce uint64) (*gethTypes.Transaction, error) {
+	_, baseFee, err := s.getBlockNumberAndBaseFee(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number and base fee, err: %w", err)
+	}
+	feeData, err := s.getFeeData(&s.auth.From, big.NewInt(0), nil, selfTransferGasLimit, baseFee, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee data, err: %w", err)
+	}
+	return s.createAndSendTx(feeData, &s.auth.From, big.NewInt(0), nil, &nonce)
+}
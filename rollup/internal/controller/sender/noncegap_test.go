@@ -0,0 +1,217 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func TestFillNonceGap(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	chainID := big.NewInt(534352)
+	auth, err := bind.NewKeyedTransactorWithChainID(priv, chainID)
+	assert.NoError(t, err)
+	auth.Nonce = big.NewInt(7)
+
+	client := &ethclient.Client{}
+	pendingTransactionOrm := &orm.PendingTransaction{}
+	s := &Sender{
+		ctx:                   context.Background(),
+		config:                &config.SenderConfig{TxType: LegacyTxType},
+		client:                client,
+		chainID:               chainID,
+		auth:                  auth,
+		service:               "test",
+		name:                  "nonce_gap",
+		senderType:            types.SenderTypeCommitBatch,
+		pendingTransactionOrm: pendingTransactionOrm,
+		gasPriceStrategy:      &FixedGasPriceStrategy{gasPrice: big.NewInt(1000)},
+		metrics:               initSenderMetrics(nil),
+	}
+	filled := s.metrics.rollupSenderNonceGapsFilled.WithLabelValues("test", "nonce_gap")
+	initialFilled := testutil.ToFloat64(filled)
+
+	to := common.HexToAddress("0x1")
+	signTx := func(nonce uint64) *gethTypes.Transaction {
+		tx, signErr := auth.Signer(auth.From, gethTypes.NewTx(&gethTypes.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1000), Gas: 50000, To: &to}))
+		assert.NoError(t, signErr)
+		return tx
+	}
+	pendingTx := func(tx *gethTypes.Transaction) orm.PendingTransaction {
+		var buf bytes.Buffer
+		assert.NoError(t, tx.EncodeRLP(&buf))
+		return orm.PendingTransaction{Nonce: tx.Nonce(), RLPEncoding: buf.Bytes()}
+	}
+	// nonce 3 is pending, nonce 4 is dropped, nonce 5 has no recorded transaction and nonce 6 is pending.
+	tx3, tx4, tx6 := signTx(3), signTx(4), signTx(6)
+	known := map[common.Hash]bool{tx3.Hash(): true, tx6.Hash(): true}
+
+	var sent []*gethTypes.Transaction
+	patches := gomonkey.ApplyMethodFunc(client, "NonceAt", func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+		return 3, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(client, "TransactionByHash", func(ctx context.Context, hash common.Hash) (*gethTypes.Transaction, bool, error) {
+		if known[hash] {
+			return nil, true, nil
+		}
+		return nil, false, ethereum.NotFound
+	})
+	patches.ApplyMethodFunc(client, "SendTransaction", func(ctx context.Context, tx *gethTypes.Transaction) error {
+		sent = append(sent, tx)
+		return nil
+	})
+	patches.ApplyMethodFunc(client, "HeaderByNumber", func(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+		return &gethTypes.Header{Number: big.NewInt(100)}, nil
+	})
+	patches.ApplyMethodFunc(client, "EstimateGas", func(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+		return 21000, nil
+	})
+	patches.ApplyMethodFunc(pendingTransactionOrm, "GetPendingOrReplacedTransactionsBySenderTypeAndAddress",
+		func(ctx context.Context, senderType types.SenderType, senderAddress common.Address, limit int) ([]orm.PendingTransaction, error) {
+			return []orm.PendingTransaction{pendingTx(tx3), pendingTx(tx4), pendingTx(tx6)}, nil
+		})
+
+	assert.NoError(t, s.FillNonceGap(context.Background()))
+	assert.Len(t, sent, 2)
+	assert.Equal(t, tx4.Hash(), sent[0].Hash())
+	assert.Equal(t, uint64(5), sent[1].Nonce())
+	assert.Equal(t, auth.From, *sent[1].To())
+	assert.Zero(t, sent[1].Value().Sign())
+	assert.Equal(t, uint64(7), s.auth.Nonce.Uint64())
+	assert.Equal(t, initialFilled+2, testutil.ToFloat64(filled))
+
+	// nothing to fill once all the nonces are confirmed.
+	sent = nil
+	patches.ApplyMethodFunc(client, "NonceAt", func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+		return 7, nil
+	})
+	assert.NoError(t, s.FillNonceGap(context.Background()))
+	assert.Empty(t, sent)
+}
+
+func TestFillNonceGapWhileSending(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	chainID := big.NewInt(534352)
+	auth, err := bind.NewKeyedTransactorWithChainID(priv, chainID)
+	assert.NoError(t, err)
+	auth.Nonce = big.NewInt(5)
+
+	client := &ethclient.Client{}
+	pendingTransactionOrm := &orm.PendingTransaction{}
+	s := &Sender{
+		ctx:                   context.Background(),
+		config:                &config.SenderConfig{TxType: LegacyTxType},
+		client:                client,
+		chainID:               chainID,
+		auth:                  auth,
+		service:               "test",
+		name:                  "nonce_gap_while_sending",
+		senderType:            types.SenderTypeCommitBatch,
+		pendingTransactionOrm: pendingTransactionOrm,
+		pendingTxWindow:       newPendingTxWindow(0, 0, 0, nil),
+		circuitBreaker:        newCircuitBreaker(0, 0, nil),
+		gasPriceStrategy:      &FixedGasPriceStrategy{gasPrice: big.NewInt(1000)},
+		metrics:               initSenderMetrics(nil),
+	}
+
+	var (
+		mu      sync.Mutex
+		sent    []*gethTypes.Transaction
+		records []orm.PendingTransaction
+	)
+	patches := gomonkey.ApplyMethodFunc(client, "NonceAt", func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+		return 5, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(client, "TransactionByHash", func(ctx context.Context, hash common.Hash) (*gethTypes.Transaction, bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, tx := range sent {
+			if tx.Hash() == hash {
+				return tx, true, nil
+			}
+		}
+		return nil, false, ethereum.NotFound
+	})
+	patches.ApplyMethodFunc(client, "SendTransaction", func(ctx context.Context, tx *gethTypes.Transaction) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, tx)
+		return nil
+	})
+	patches.ApplyMethodFunc(client, "HeaderByNumber", func(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+		return &gethTypes.Header{Number: big.NewInt(100)}, nil
+	})
+	patches.ApplyMethodFunc(client, "EstimateGas", func(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+		return 21000, nil
+	})
+	// the send is held between the nonce increment and the insertion of its pending transaction.
+	inserting := make(chan struct{})
+	insert := make(chan struct{})
+	patches.ApplyMethodFunc(pendingTransactionOrm, "InsertPendingTransaction",
+		func(ctx context.Context, contextID string, senderMeta *orm.SenderMeta, tx *gethTypes.Transaction, submitBlockNumber uint64, dbTX ...*gorm.DB) error {
+			close(inserting)
+			<-insert
+			var buf bytes.Buffer
+			assert.NoError(t, tx.EncodeRLP(&buf))
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, orm.PendingTransaction{Nonce: tx.Nonce(), RLPEncoding: buf.Bytes()})
+			return nil
+		})
+	patches.ApplyMethodFunc(pendingTransactionOrm, "GetPendingOrReplacedTransactionsBySenderTypeAndAddress",
+		func(ctx context.Context, senderType types.SenderType, senderAddress common.Address, limit int) ([]orm.PendingTransaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]orm.PendingTransaction(nil), records...), nil
+		})
+
+	to := common.HexToAddress("0x1")
+	sendDone := make(chan error)
+	go func() {
+		_, sendErr := s.SendTransaction("send", &to, big.NewInt(0), nil, 0)
+		sendDone <- sendErr
+	}()
+	<-inserting
+
+	fillDone := make(chan error)
+	go func() {
+		fillDone <- s.FillNonceGap(context.Background())
+	}()
+	select {
+	case <-fillDone:
+		t.Fatal("FillNonceGap should wait for the send in progress")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(insert)
+	assert.NoError(t, <-sendDone)
+	assert.NoError(t, <-fillDone)
+
+	// the nonce of the send is not filled with a self-transfer.
+	assert.Len(t, sent, 1)
+	assert.Equal(t, uint64(5), sent[0].Nonce())
+	assert.Equal(t, to, *sent[0].To())
+	assert.Equal(t, uint64(6), s.auth.Nonce.Uint64())
+}
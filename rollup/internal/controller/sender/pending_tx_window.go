@@ -0,0 +1,92 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultMaxPendingTxsWait is the time a send waits for a free pending transaction slot if max_pending_txs_wait_sec is not configured.
+const defaultMaxPendingTxsWait = time.Minute
+
+// ErrTooManyPendingTxs is returned by SendTransaction when no pending transaction slot is freed within the maximum wait,
+// or the sender is stopped while waiting.
+var ErrTooManyPendingTxs = errors.New("too many pending transactions")
+
+// pendingTxWindow limits the number of pending transactions, a zero limit disables it.
+type pendingTxWindow struct {
+	mu sync.Mutex
+
+	limit   int
+	maxWait time.Duration
+	gauge   prometheus.Gauge
+
+	count int
+	// freedCh is closed and replaced each time a slot is freed, to wake up the waiting senders.
+	freedCh chan struct{}
+}
+
+func newPendingTxWindow(limit int, count int, maxWait time.Duration, gauge prometheus.Gauge) *pendingTxWindow {
+	if maxWait <= 0 {
+		maxWait = defaultMaxPendingTxsWait
+	}
+	w := &pendingTxWindow{
+		limit:   limit,
+		maxWait: maxWait,
+		gauge:   gauge,
+		freedCh: make(chan struct{}),
+	}
+	w.setCount(count)
+	return w
+}
+
+// acquire takes a pending transaction slot, blocking until one is free, ctx is done or the maximum wait has elapsed.
+func (w *pendingTxWindow) acquire(ctx context.Context) error {
+	var timeout <-chan time.Time
+	for {
+		w.mu.Lock()
+		if w.limit <= 0 || w.count < w.limit {
+			w.setCount(w.count + 1)
+			w.mu.Unlock()
+			return nil
+		}
+		freedCh := w.freedCh
+		w.mu.Unlock()
+
+		if timeout == nil {
+			timer := time.NewTimer(w.maxWait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return ErrTooManyPendingTxs
+		case <-timeout:
+			return ErrTooManyPendingTxs
+		case <-freedCh:
+		}
+	}
+}
+
+// release frees a pending transaction slot, when the transaction is confirmed or was not sent.
+func (w *pendingTxWindow) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count == 0 {
+		return
+	}
+	w.setCount(w.count - 1)
+	close(w.freedCh)
+	w.freedCh = make(chan struct{})
+}
+
+func (w *pendingTxWindow) setCount(count int) {
+	w.count = count
+	if w.gauge != nil {
+		w.gauge.Set(float64(count))
+	}
+}
@@ -0,0 +1,72 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingTxWindow(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_pending_tx_count"})
+	w := newPendingTxWindow(2, 1, 0, gauge)
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+
+	assert.NoError(t, w.acquire(context.Background()))
+	assert.Equal(t, float64(2), testutil.ToFloat64(gauge))
+
+	// The window is full, acquire fails once the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, w.acquire(ctx), ErrTooManyPendingTxs)
+
+	// A blocked acquire returns once a slot is freed.
+	acquired := make(chan error)
+	go func() {
+		acquired <- w.acquire(context.Background())
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire should block while the window is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	w.release()
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("acquire should return after a slot is freed")
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(gauge))
+
+	w.release()
+	w.release()
+	w.release()
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+}
+
+func TestPendingTxWindowUnlimited(t *testing.T) {
+	w := newPendingTxWindow(0, 0, 0, nil)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, w.acquire(context.Background()))
+	}
+}
+
+func TestPendingTxWindowMaxWait(t *testing.T) {
+	w := newPendingTxWindow(1, 1, 20*time.Millisecond, nil)
+
+	// the sends not bounded by their own context get ErrTooManyPendingTxs once the maximum wait has elapsed.
+	start := time.Now()
+	assert.ErrorIs(t, w.acquire(context.Background()), ErrTooManyPendingTxs)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// a slot freed within the maximum wait is taken.
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		w.release()
+	}()
+	assert.NoError(t, w.acquire(context.Background()))
+}
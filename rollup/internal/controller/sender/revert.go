@@ -0,0 +1,65 @@
+package sender
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// revertReasonLabelLen is the maximum length in bytes of the revert reason label of revert_reasons_total.
+const revertReasonLabelLen = 32
+
+// revertReason replays the failed transaction with eth_call at its confirmation block and returns the revert reason.
+// It returns an empty string if the transaction does not revert when replayed.
+func (s *Sender) revertReason(tx *gethTypes.Transaction, blockNumber *big.Int) string {
+	msg := ethereum.CallMsg{
+		From:  s.auth.From,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, err := s.client.CallContract(s.ctx, msg, blockNumber)
+	if err == nil {
+		log.Warn("failed transaction does not revert when replayed", "hash", tx.Hash().String(), "block number", blockNumber)
+		return ""
+	}
+	return decodeRevertReason(err)
+}
+
+// decodeRevertReason decodes the Error(string) revert data carried by the eth_call error. The raw revert data is
+// returned if it is not an Error(string), e.g. a custom error, and the error message if there is no revert data.
+func decodeRevertReason(err error) string {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err.Error()
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return err.Error()
+	}
+	reason, unpackErr := abi.UnpackRevert(data)
+	if unpackErr != nil {
+		return hexData
+	}
+	return reason
+}
+
+// revertReasonLabel truncates the revert reason to a bounded metric label.
+func revertReasonLabel(reason string) string {
+	if len(reason) > revertReasonLabelLen {
+		reason = reason[:revertReasonLabelLen]
+	}
+	return strings.ToValidUTF8(reason, "")
+}
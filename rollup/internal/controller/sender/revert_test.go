@@ -0,0 +1,59 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDataError struct {
+	data interface{}
+}
+
+func (e *mockDataError) Error() string {
+	return "execution reverted"
+}
+
+func (e *mockDataError) ErrorData() interface{} {
+	return e.data
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	// Error(string) with the reason "Batch already committed"
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000017" +
+		"426174636820616c726561647920636f6d6d6974746564000000000000000000"
+	customErrorData := hexutil.Encode([]byte{0xde, 0xad, 0xbe, 0xef})
+
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{"error string", &mockDataError{data: revertData}, "Batch already committed"},
+		{"wrapped error string", fmt.Errorf("call failed: %w", &mockDataError{data: revertData}), "Batch already committed"},
+		{"custom error", &mockDataError{data: customErrorData}, customErrorData},
+		{"no revert data", &mockDataError{}, "execution reverted"},
+		{"invalid revert data", &mockDataError{data: "0xzz"}, "execution reverted"},
+		{"plain error", errors.New("out of gas"), "out of gas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.reason, decodeRevertReason(tt.err))
+		})
+	}
+}
+
+func TestRevertReasonLabel(t *testing.T) {
+	assert.Equal(t, "", revertReasonLabel(""))
+	assert.Equal(t, "Batch already committed", revertReasonLabel("Batch already committed"))
+	assert.Equal(t, strings.Repeat("a", 32), revertReasonLabel(strings.Repeat("a", 40)))
+	// a multi-byte character cut at the label length is dropped.
+	assert.Equal(t, strings.Repeat("a", 31), revertReasonLabel(strings.Repeat("a", 31)+"é"))
+}
@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
@@ -45,6 +47,13 @@ type Confirmation struct {
 	IsSuccessful bool
 	TxHash       common.Hash
 	SenderType   types.SenderType
+	// RevertReason the revert reason of a failed transaction, empty if it is not found.
+	RevertReason string
+	// BlockNumber, GasUsed and EffectiveGasPrice are the ones of the receipt of the transaction, unset if the
+	// transaction failed to be sent or was simulated.
+	BlockNumber       uint64
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
 }
 
 // FeeData fee struct used to estimate gas price
@@ -56,6 +65,16 @@ type FeeData struct {
 	accessList gethTypes.AccessList
 
 	gasLimit uint64
+
+	// blob fields, only set for blob transactions
+	blobGasFeeCap *big.Int
+	sidecar       *gethTypes.BlobTxSidecar
+}
+
+// FeeCaps caps the fees per gas of DynamicFeeTx, a nil field is not capped.
+type FeeCaps struct {
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
 }
 
 // Sender Transaction sender to send transaction to l1/l2 geth
@@ -69,7 +88,15 @@ type Sender struct {
 	name       string
 	senderType types.SenderType
 
+	// The client the signed transactions are sent to when the endpoint is unreachable, nil if no standby endpoint is configured.
+	standbyClient *ethclient.Client
+	// The client the signed transactions are privately sent to, nil if no Flashbots relay is configured.
+	flashbots *flashbotsClient
+
 	auth *bind.TransactOpts
+	// nonceMu guards the local nonce of auth, from the nonce increment of a send to the insertion of its pending
+	// transaction, so that FillNonceGap never sees a sent nonce without its pending transaction.
+	nonceMu sync.Mutex
 
 	db                    *gorm.DB
 	pendingTransactionOrm *orm.PendingTransaction
@@ -77,6 +104,17 @@ type Sender struct {
 	confirmCh chan *Confirmation
 	stopCh    chan struct{}
 
+	circuitBreaker   *circuitBreaker
+	pendingTxWindow  *pendingTxWindow
+	defaultFeeCaps   *FeeCaps
+	gasPriceStrategy GasPriceStrategy
+	feeBumpPolicy    *FeeBumpPolicy
+
+	simulatedConfirmations simulatedConfirmations
+
+	// batchingOptimizer merges the transactions sent within a batching window, nil if it is not enabled.
+	batchingOptimizer *BatchTransactionOptimizer
+
 	metrics *senderMetrics
 }
 
@@ -92,28 +130,51 @@ func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.Pri
 	}
 
 	client := ethclient.NewClient(rpcClient)
+	gasPriceStrategy, err := newGasPriceStrategy(config, name, client, db)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas price strategy, err: %w", err)
+	}
+
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chain ID, err: %w", err)
 	}
 
-	auth, err := bind.NewKeyedTransactorWithChainID(priv, chainID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create transactor with chain ID %v, err: %w", chainID, err)
+	var standbyClient *ethclient.Client
+	if config.StandbyEndpoint != "" {
+		standbyClient, err = ethclient.Dial(config.StandbyEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial standby eth client, err: %w", err)
+		}
+		standbyChainID, chainIDErr := standbyClient.ChainID(ctx)
+		if chainIDErr != nil {
+			// the standby endpoint may be down at startup too, it is only required when the primary endpoint is unreachable.
+			log.Warn("failed to get chain ID of standby endpoint", "service", service, "name", name, "err", chainIDErr)
+		} else if standbyChainID.Cmp(chainID) != 0 {
+			return nil, fmt.Errorf("standby endpoint chain ID %v mismatches the endpoint chain ID %v", standbyChainID, chainID)
+		}
 	}
 
-	// Set pending nonce
-	nonce, err := client.PendingNonceAt(ctx, auth.From)
+	var flashbots *flashbotsClient
+	if config.FlashbotsRelayURL != "" {
+		if config.FlashbotsSigningKey == nil {
+			return nil, errors.New("flashbots signing key is required by the flashbots relay")
+		}
+		flashbots = newFlashbotsClient(config.FlashbotsRelayURL, config.FlashbotsSigningKey)
+	}
+
+	auth, err := bind.NewKeyedTransactorWithChainID(priv, chainID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pending nonce for address %s, err: %w", auth.From.Hex(), err)
+		return nil, fmt.Errorf("failed to create transactor with chain ID %v, err: %w", chainID, err)
 	}
-	auth.Nonce = big.NewInt(int64(nonce))
 
 	sender := &Sender{
 		ctx:                   ctx,
 		config:                config,
 		gethClient:            gethclient.New(rpcClient),
 		client:                client,
+		standbyClient:         standbyClient,
+		flashbots:             flashbots,
 		chainID:               chainID,
 		auth:                  auth,
 		db:                    db,
@@ -123,8 +184,33 @@ func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.Pri
 		name:                  name,
 		service:               service,
 		senderType:            senderType,
+		gasPriceStrategy:      gasPriceStrategy,
+		feeBumpPolicy:         newFeeBumpPolicy(config.FeeBumpPolicy),
+	}
+
+	// Set pending nonce
+	if err = sender.ResyncNonce(ctx); err != nil {
+		return nil, err
+	}
+
+	sender.defaultFeeCaps = &FeeCaps{
+		MaxPriorityFeePerGas: gweiToWei(config.DefaultMaxPriorityFeeGwei),
+		MaxFeePerGas:         gweiToWei(config.DefaultMaxFeeGwei),
 	}
 	sender.metrics = initSenderMetrics(reg)
+	sender.circuitBreaker = newCircuitBreaker(config.CircuitBreakerThreshold, time.Duration(config.CircuitBreakerCooldownSec)*time.Second,
+		sender.metrics.circuitBreakerState.WithLabelValues(service, name))
+
+	if config.EnableBatchingOptimizer {
+		sender.batchingOptimizer = newBatchTransactionOptimizer(sender, config.BatchingMulticallAddress, time.Duration(config.BatchingWindowMs)*time.Millisecond)
+	}
+
+	pendingTxCount, err := sender.pendingTransactionOrm.GetPendingTransactionCountBySenderTypeAndAddress(ctx, senderType, auth.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transaction count for address %s, err: %w", auth.From.Hex(), err)
+	}
+	sender.pendingTxWindow = newPendingTxWindow(config.MaxPendingTxs, int(pendingTxCount), time.Duration(config.MaxPendingTxsWaitSec)*time.Second,
+		sender.metrics.pendingTxCount.WithLabelValues(service, name))
 
 	go sender.loop(ctx)
 
@@ -136,14 +222,40 @@ func (s *Sender) GetChainID() *big.Int {
 	return s.chainID
 }
 
+// HealthCheck checks that the endpoint is reachable and the sender account has a non-zero balance.
+func (s *Sender) HealthCheck(ctx context.Context) error {
+	balance, err := s.client.BalanceAt(ctx, s.auth.From, nil)
+	if err != nil {
+		s.metrics.healthCheckFailuresTotal.WithLabelValues(s.service, s.name).Inc()
+		return fmt.Errorf("failed to get balance of %s, service: %s, name: %s, err: %w", s.auth.From.Hex(), s.service, s.name, err)
+	}
+	if balance.Sign() == 0 {
+		s.metrics.healthCheckFailuresTotal.WithLabelValues(s.service, s.name).Inc()
+		return fmt.Errorf("zero balance of %s, service: %s, name: %s", s.auth.From.Hex(), s.service, s.name)
+	}
+	return nil
+}
+
+// CircuitState returns the current state of the sender circuit breaker.
+func (s *Sender) CircuitState() CircuitState {
+	return s.circuitBreaker.currentState()
+}
+
 // Stop stop the sender module.
 func (s *Sender) Stop() {
+	if s.batchingOptimizer != nil {
+		s.batchingOptimizer.flush()
+	}
 	close(s.stopCh)
 	log.Info("sender stopped", "name", s.name, "service", s.service, "address", s.auth.From.String())
 }
 
-// ConfirmChan channel used to communicate with transaction sender
+// ConfirmChan channel used to communicate with transaction sender, the transactions merged by the batching optimizer
+// are confirmed one by one with their synthetic hash.
 func (s *Sender) ConfirmChan() <-chan *Confirmation {
+	if s.batchingOptimizer != nil {
+		return s.batchingOptimizer.confirmCh
+	}
 	return s.confirmCh
 }
 
@@ -153,31 +265,105 @@ func (s *Sender) SendConfirmation(cfm *Confirmation) {
 	s.confirmCh <- cfm
 }
 
-func (s *Sender) getFeeData(target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64) (*FeeData, error) {
+func (s *Sender) getFeeData(target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64, feeCaps *FeeCaps) (*FeeData, error) {
 	if s.config.TxType == DynamicFeeTxType {
-		return s.estimateDynamicGas(target, value, data, fallbackGasLimit, baseFee)
+		return s.estimateDynamicGas(target, value, data, fallbackGasLimit, baseFee, s.mergeFeeCaps(feeCaps))
 	}
 	return s.estimateLegacyGas(target, value, data, fallbackGasLimit)
 }
 
+// mergeFeeCaps returns the per call fee caps, falling back to the default fee caps of the sender for nil fields.
+func (s *Sender) mergeFeeCaps(feeCaps *FeeCaps) *FeeCaps {
+	merged := &FeeCaps{}
+	if s.defaultFeeCaps != nil {
+		*merged = *s.defaultFeeCaps
+	}
+	if feeCaps != nil {
+		if feeCaps.MaxPriorityFeePerGas != nil {
+			merged.MaxPriorityFeePerGas = feeCaps.MaxPriorityFeePerGas
+		}
+		if feeCaps.MaxFeePerGas != nil {
+			merged.MaxFeePerGas = feeCaps.MaxFeePerGas
+		}
+	}
+	return merged
+}
+
 // SendTransaction send a signed L2tL1 transaction.
 func (s *Sender) SendTransaction(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	return s.SendTransactionWithFeeCaps(contextID, target, value, data, fallbackGasLimit, nil)
+}
+
+// SendBatchableTransaction sends a transaction that may be merged into a multicall with the other batchable
+// transactions to the same contract. If the batching optimizer is enabled, the transaction is queued and its synthetic
+// hash is returned, it is sent as SendTransaction otherwise. The multicall contract is the caller of the merged calls,
+// so only the transactions not depending on the sender, e.g. small value transfers, must be sent this way.
+func (s *Sender) SendBatchableTransaction(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	if s.batchingOptimizer != nil && target != nil {
+		return s.batchingOptimizer.enqueue(contextID, target, value, data, fallbackGasLimit), nil
+	}
+	return s.SendTransaction(contextID, target, value, data, fallbackGasLimit)
+}
+
+// SendTransactionWithFeeCaps send a signed L2tL1 transaction, feeCaps overrides the default fee caps of DynamicFeeTx.
+func (s *Sender) SendTransactionWithFeeCaps(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, feeCaps *FeeCaps) (common.Hash, error) {
+	defer s.lockSend()()
+	return s.sendTransactionWithFeeCaps(contextID, target, value, data, fallbackGasLimit, feeCaps)
+}
+
+func (s *Sender) sendTransactionWithFeeCaps(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, feeCaps *FeeCaps) (common.Hash, error) {
 	s.metrics.sendTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+	return s.sendTransaction(contextID, target, value, data, fallbackGasLimit, func(baseFee uint64) (*FeeData, error) {
+		return s.getFeeData(target, value, data, fallbackGasLimit, baseFee, feeCaps)
+	})
+}
+
+// sendTransaction sends a transaction whose fee data is returned by getFeeData, and records it as pending.
+func (s *Sender) sendTransaction(contextID string, target *common.Address, value *big.Int, data []byte, gasLimit uint64, getFeeData func(baseFee uint64) (*FeeData, error)) (common.Hash, error) {
 	var (
 		feeData *FeeData
 		tx      *gethTypes.Transaction
 		err     error
 	)
 
+	if s.config.SimulateOnly {
+		s.nonceMu.Lock()
+		defer s.nonceMu.Unlock()
+		return s.simulateTransaction(contextID, target, value, data)
+	}
+
+	if err = s.pendingTxWindow.acquire(s.ctx); err != nil {
+		log.Warn("reject sending transaction", "service", s.service, "name", s.name, "context ID", contextID, "max pending txs", s.config.MaxPendingTxs, "err", err)
+		return common.Hash{}, err
+	}
+	defer func() {
+		if err != nil {
+			s.pendingTxWindow.release()
+		}
+	}()
+
+	if err = s.circuitBreaker.allow(); err != nil {
+		log.Warn("reject sending transaction", "service", s.service, "name", s.name, "context ID", contextID, "err", err)
+		return common.Hash{}, err
+	}
+	defer func() {
+		if err != nil {
+			s.circuitBreaker.release()
+		}
+	}()
+
+	s.nonceMu.Lock()
+	defer s.nonceMu.Unlock()
+
 	blockNumber, baseFee, err := s.getBlockNumberAndBaseFee(s.ctx)
 	if err != nil {
 		log.Error("failed to get block number and base fee", "error", err)
 		return common.Hash{}, fmt.Errorf("failed to get block number and base fee, err: %w", err)
 	}
 
-	if feeData, err = s.getFeeData(target, value, data, fallbackGasLimit, baseFee); err != nil {
+	if feeData, err = getFeeData(baseFee); err != nil {
 		s.metrics.sendTransactionFailureGetFee.WithLabelValues(s.service, s.name).Inc()
-		log.Error("failed to get fee data", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "fallback gas limit", fallbackGasLimit, "err", err)
+		log.Error("failed to get fee data", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "gas limit", gasLimit, "err", err)
 		return common.Hash{}, fmt.Errorf("failed to get fee data, err: %w", err)
 	}
 
@@ -205,8 +391,26 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 		nonce = *overrideNonce
 	}
 
-	switch s.config.TxType {
-	case LegacyTxType:
+	switch {
+	case feeData.sidecar != nil:
+		txData = &gethTypes.BlobTx{
+			ChainID:    uint256.MustFromBig(s.chainID),
+			Nonce:      nonce,
+			GasTipCap:  uint256.MustFromBig(feeData.gasTipCap),
+			GasFeeCap:  uint256.MustFromBig(feeData.gasFeeCap),
+			Gas:        feeData.gasLimit,
+			To:         *target,
+			Value:      uint256.MustFromBig(value),
+			Data:       common.CopyBytes(data),
+			AccessList: feeData.accessList,
+			BlobFeeCap: uint256.MustFromBig(feeData.blobGasFeeCap),
+			BlobHashes: feeData.sidecar.BlobHashes(),
+			Sidecar:    feeData.sidecar,
+			V:          new(uint256.Int),
+			R:          new(uint256.Int),
+			S:          new(uint256.Int),
+		}
+	case s.config.TxType == LegacyTxType:
 		// for ganache mock node
 		txData = &gethTypes.LegacyTx{
 			Nonce:    nonce,
@@ -219,7 +423,7 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 			R:        new(big.Int),
 			S:        new(big.Int),
 		}
-	case AccessListTxType:
+	case s.config.TxType == AccessListTxType:
 		txData = &gethTypes.AccessListTx{
 			ChainID:    s.chainID,
 			Nonce:      nonce,
@@ -257,7 +461,7 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 		return nil, err
 	}
 
-	if err = s.client.SendTransaction(s.ctx, tx); err != nil {
+	if err = s.broadcastTransaction(tx); err != nil {
 		log.Error("failed to send tx", "tx hash", tx.Hash().String(), "from", s.auth.From.String(), "nonce", tx.Nonce(), "err", err)
 		// Check if contain nonce, and reset nonce
 		// only reset nonce when it is not from resubmit
@@ -279,6 +483,10 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 		s.metrics.currentGasPrice.WithLabelValues(s.service, s.name).Set(float64(feeData.gasPrice.Uint64()))
 	}
 
+	if feeData.blobGasFeeCap != nil {
+		s.metrics.currentBlobGasFeeCap.WithLabelValues(s.service, s.name).Set(float64(feeData.blobGasFeeCap.Uint64()))
+	}
+
 	s.metrics.currentGasLimit.WithLabelValues(s.service, s.name).Set(float64(feeData.gasLimit))
 
 	// update nonce when it is not from resubmit
@@ -288,17 +496,45 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 	return tx, nil
 }
 
-// resetNonce reset nonce if send signed tx failed.
-func (s *Sender) resetNonce(ctx context.Context) {
+// ResyncNonce resets the nonce of the sender to the pending nonce of the sender account on chain.
+// It is called on startup and when sending a transaction fails because of the nonce, and can be used by operator tooling.
+func (s *Sender) ResyncNonce(ctx context.Context) error {
+	s.nonceMu.Lock()
+	defer s.nonceMu.Unlock()
+	return s.resyncNonce(ctx)
+}
+
+func (s *Sender) resyncNonce(ctx context.Context) error {
 	nonce, err := s.client.PendingNonceAt(ctx, s.auth.From)
 	if err != nil {
+		return fmt.Errorf("failed to get pending nonce for address %s, err: %w", s.auth.From.Hex(), err)
+	}
+	if s.auth.Nonce != nil && s.auth.Nonce.Uint64() != nonce {
+		log.Warn("resync sender nonce", "service", s.service, "name", s.name, "address", s.auth.From.String(), "local nonce", s.auth.Nonce.Uint64(), "pending nonce", nonce)
+	}
+	s.auth.Nonce = new(big.Int).SetUint64(nonce)
+	return nil
+}
+
+// resetNonce reset nonce if send signed tx failed, nonceMu must be held.
+func (s *Sender) resetNonce(ctx context.Context) {
+	if err := s.resyncNonce(ctx); err != nil {
 		log.Warn("failed to reset nonce", "address", s.auth.From.String(), "err", err)
-		return
 	}
-	s.auth.Nonce = big.NewInt(int64(nonce))
 }
 
 func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64) (*gethTypes.Transaction, error) {
+	return s.replaceTransaction(tx, baseFee, false, 0)
+}
+
+// replaceTransaction resends tx with the same nonce and escalated fees, tx was already replaced bumpCount times.
+// The fees of a stuck transaction are bumped by at least the MinBumpPercent of the fee bump policy, and the fee
+// increases are capped by its MaxBumpPercent. errFeeBumpLimitReached is returned once MaxBumps is reached.
+func (s *Sender) replaceTransaction(tx *gethTypes.Transaction, baseFee uint64, stuck bool, bumpCount int) (*gethTypes.Transaction, error) {
+	if _, ok := s.feeBumpPolicy.NextGasPrice(tx.GasFeeCap(), bumpCount); !ok {
+		return nil, fmt.Errorf("%w, hash: %s, bumps: %d", errFeeBumpLimitReached, tx.Hash().String(), bumpCount)
+	}
+
 	escalateMultipleNum := new(big.Int).SetUint64(s.config.EscalateMultipleNum)
 	escalateMultipleDen := new(big.Int).SetUint64(s.config.EscalateMultipleDen)
 	maxGasPrice := new(big.Int).SetUint64(s.config.MaxGasPrice)
@@ -312,11 +548,56 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64)
 
 	var feeData FeeData
 	feeData.gasLimit = tx.Gas()
-	switch s.config.TxType {
-	case LegacyTxType, AccessListTxType: // `LegacyTxType`is for ganache mock node
+	switch {
+	case tx.Type() == gethTypes.BlobTxType:
+		// The blob pool requires all the fees of a blob transaction replacement to be doubled.
+		originalGasTipCap := tx.GasTipCap()
+		originalGasFeeCap := tx.GasFeeCap()
+		originalBlobGasFeeCap := tx.BlobGasFeeCap()
+
+		gasTipCap := doubleFee(originalGasTipCap)
+		gasFeeCap := doubleFee(originalGasFeeCap)
+		blobGasFeeCap := doubleFee(originalBlobGasFeeCap)
+
+		// adjust for rising basefee
+		if currentGasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).SetUint64(baseFee)); gasFeeCap.Cmp(currentGasFeeCap) < 0 {
+			gasFeeCap = currentGasFeeCap
+		}
+
+		// but don't exceed maxGasPrice
+		if gasFeeCap.Cmp(maxGasPrice) > 0 {
+			log.Warn("blob tx gas fee cap capped by max gas price, the replacement may be underpriced", "adjusted", gasFeeCap.Uint64(), "max gas price", maxGasPrice.Uint64())
+			gasFeeCap = maxGasPrice
+		}
+
+		// gasTipCap <= gasFeeCap
+		if gasTipCap.Cmp(gasFeeCap) > 0 {
+			gasTipCap = gasFeeCap
+		}
+
+		feeData.gasTipCap = gasTipCap
+		feeData.gasFeeCap = gasFeeCap
+		feeData.blobGasFeeCap = blobGasFeeCap
+		feeData.accessList = tx.AccessList()
+		feeData.sidecar = tx.BlobTxSidecar()
+		if feeData.sidecar == nil {
+			return nil, fmt.Errorf("blob tx sidecar is missing, tx hash: %s", tx.Hash().String())
+		}
+		txInfo["original_gas_tip_cap"] = originalGasTipCap.Uint64()
+		txInfo["adjusted_gas_tip_cap"] = gasTipCap.Uint64()
+		txInfo["original_gas_fee_cap"] = originalGasFeeCap.Uint64()
+		txInfo["adjusted_gas_fee_cap"] = gasFeeCap.Uint64()
+		txInfo["original_blob_gas_fee_cap"] = originalBlobGasFeeCap.Uint64()
+		txInfo["adjusted_blob_gas_fee_cap"] = blobGasFeeCap.Uint64()
+	case s.config.TxType == LegacyTxType, s.config.TxType == AccessListTxType: // `LegacyTxType`is for ganache mock node
 		originalGasPrice := tx.GasPrice()
 		gasPrice := new(big.Int).Mul(escalateMultipleNum, originalGasPrice)
 		gasPrice = gasPrice.Div(gasPrice, escalateMultipleDen)
+		if stuck {
+			minGasPrice, _ := s.feeBumpPolicy.NextGasPrice(originalGasPrice, bumpCount)
+			gasPrice = maxBig(gasPrice, minGasPrice)
+		}
+		gasPrice = s.feeBumpPolicy.capGasPrice(originalGasPrice, gasPrice)
 		if gasPrice.Cmp(maxGasPrice) > 0 {
 			gasPrice = maxGasPrice
 		}
@@ -347,6 +628,15 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64)
 			gasFeeCap = currentGasFeeCap
 		}
 
+		if stuck {
+			minGasTipCap, _ := s.feeBumpPolicy.NextGasPrice(originalGasTipCap, bumpCount)
+			minGasFeeCap, _ := s.feeBumpPolicy.NextGasPrice(originalGasFeeCap, bumpCount)
+			gasTipCap = maxBig(gasTipCap, minGasTipCap)
+			gasFeeCap = maxBig(gasFeeCap, minGasFeeCap)
+		}
+		gasTipCap = s.feeBumpPolicy.capGasPrice(originalGasTipCap, gasTipCap)
+		gasFeeCap = s.feeBumpPolicy.capGasPrice(originalGasFeeCap, gasFeeCap)
+
 		// but don't exceed maxGasPrice
 		if gasFeeCap.Cmp(maxGasPrice) > 0 {
 			gasFeeCap = maxGasPrice
@@ -387,10 +677,34 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64)
 	return tx, nil
 }
 
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// isTxStuck returns true if the pending transaction has been submitted for longer than StuckTxTimeoutSec.
+func (s *Sender) isTxStuck(txn *orm.PendingTransaction) bool {
+	if s.config.StuckTxTimeoutSec == 0 {
+		return false
+	}
+	return time.Since(txn.CreatedAt) >= time.Duration(s.config.StuckTxTimeoutSec)*time.Second
+}
+
+// doubleFee returns twice the fee, at least 1 wei so that a zero fee is bumped as well.
+func doubleFee(fee *big.Int) *big.Int {
+	if fee.Sign() == 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Mul(fee, big.NewInt(2))
+}
+
 // checkPendingTransaction checks the confirmation status of pending transactions against the latest confirmed block number.
 // If a transaction hasn't been confirmed after a certain number of blocks, it will be resubmitted with an increased gas price.
 func (s *Sender) checkPendingTransaction() {
 	s.metrics.senderCheckPendingTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+	s.flushSimulatedConfirmations()
 
 	blockNumber, baseFee, err := s.getBlockNumberAndBaseFee(s.ctx)
 	if err != nil {
@@ -398,7 +712,7 @@ func (s *Sender) checkPendingTransaction() {
 		return
 	}
 
-	transactionsToCheck, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(s.ctx, s.senderType, 100)
+	transactionsToCheck, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(s.ctx, s.senderType, s.auth.From, 100)
 	if err != nil {
 		log.Error("failed to load pending transactions", "sender meta", s.getSenderMeta(), "err", err)
 		return
@@ -410,6 +724,14 @@ func (s *Sender) checkPendingTransaction() {
 		return
 	}
 
+	// the replaced transactions of a context ID are the fee bumps of its pending transaction.
+	bumpCounts := make(map[string]int)
+	for _, txnToCheck := range transactionsToCheck {
+		if txnToCheck.Status == types.TxStatusReplaced {
+			bumpCounts[txnToCheck.ContextID]++
+		}
+	}
+
 	for _, txnToCheck := range transactionsToCheck {
 		tx := new(gethTypes.Transaction)
 		if err := tx.DecodeRLP(rlp.NewStream(bytes.NewReader(txnToCheck.RLPEncoding), 0)); err != nil {
@@ -420,6 +742,19 @@ func (s *Sender) checkPendingTransaction() {
 		receipt, err := s.client.TransactionReceipt(s.ctx, tx.Hash())
 		if (err == nil) && (receipt != nil) { // tx confirmed.
 			if receipt.BlockNumber.Uint64() <= confirmed {
+				// A replaced transaction may have been superseded earlier in this loop, when another transaction of the same nonce was confirmed.
+				if txnToCheck.Status == types.TxStatusReplaced {
+					status, err := s.pendingTransactionOrm.GetTxStatusByTxHash(s.ctx, tx.Hash())
+					if err != nil {
+						log.Error("failed to get transaction status by tx hash", "hash", tx.Hash().String(), "err", err)
+						return
+					}
+					if status == types.TxStatusConfirmedFailed {
+						log.Warn("transaction already superseded, ignoring its confirmation", "hash", tx.Hash().String(), "replaced by", txnToCheck.ReplacedBy)
+						continue
+					}
+				}
+
 				err := s.db.Transaction(func(dbTX *gorm.DB) error {
 					// Update the status of the transaction to TxStatusConfirmed.
 					if err := s.pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(s.ctx, tx.Hash(), types.TxStatusConfirmed, dbTX); err != nil {
@@ -427,7 +762,7 @@ func (s *Sender) checkPendingTransaction() {
 						return err
 					}
 					// Update other transactions with the same nonce and sender address as failed.
-					if err := s.pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(s.ctx, txnToCheck.SenderAddress, tx.Nonce(), tx.Hash(), dbTX); err != nil {
+					if err := s.pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(s.ctx, s.senderType, txnToCheck.SenderAddress, tx.Nonce(), tx.Hash(), dbTX); err != nil {
 						log.Error("failed to update other transactions as failed by nonce", "senderAddress", txnToCheck.SenderAddress, "nonce", tx.Nonce(), "excludedTxHash", tx.Hash(), "err", err)
 						return err
 					}
@@ -438,16 +773,38 @@ func (s *Sender) checkPendingTransaction() {
 					return
 				}
 
+				isSuccessful := receipt.Status == gethTypes.ReceiptStatusSuccessful
+				if tx.Type() == gethTypes.BlobTxType {
+					if isSuccessful {
+						s.metrics.blobTxConfirmedTotal.WithLabelValues(s.service, s.name).Inc()
+					} else {
+						s.metrics.blobTxFailedTotal.WithLabelValues(s.service, s.name).Inc()
+					}
+				}
+				var revertReason string
+				if !isSuccessful {
+					revertReason = s.revertReason(tx, receipt.BlockNumber)
+					s.metrics.revertReasonsTotal.WithLabelValues(s.service, s.name, revertReasonLabel(revertReason)).Inc()
+					log.Warn("transaction reverted", "service", s.service, "name", s.name, "context ID", txnToCheck.ContextID, "hash", tx.Hash().String(), "block number", receipt.BlockNumber, "revert reason", revertReason)
+				}
+				s.circuitBreaker.onConfirmation(isSuccessful)
+				s.pendingTxWindow.release()
+
 				// send confirm message
 				s.confirmCh <- &Confirmation{
-					ContextID:    txnToCheck.ContextID,
-					IsSuccessful: receipt.Status == gethTypes.ReceiptStatusSuccessful,
-					TxHash:       tx.Hash(),
-					SenderType:   s.senderType,
+					ContextID:         txnToCheck.ContextID,
+					IsSuccessful:      isSuccessful,
+					TxHash:            tx.Hash(),
+					SenderType:        s.senderType,
+					RevertReason:      revertReason,
+					BlockNumber:       receipt.BlockNumber.Uint64(),
+					GasUsed:           receipt.GasUsed,
+					EffectiveGasPrice: receipt.EffectiveGasPrice,
 				}
 			}
-		} else if txnToCheck.Status == types.TxStatusPending && // Only try resubmitting a new transaction based on gas price of the last transaction (status pending) with same ContextID.
-			s.config.EscalateBlocks+txnToCheck.SubmitBlockNumber <= blockNumber {
+		} else if stuck := s.isTxStuck(&txnToCheck); txnToCheck.Status == types.TxStatusPending && // Only try resubmitting a new transaction based on gas price of the last transaction (status pending) with same ContextID.
+			(s.config.EscalateBlocks+txnToCheck.SubmitBlockNumber <= blockNumber || stuck) &&
+			time.Since(txnToCheck.CreatedAt) >= s.feeBumpPolicy.BumpInterval {
 			// It's possible that the pending transaction was marked as failed earlier in this loop (e.g., if one of its replacements has already been confirmed).
 			// Therefore, we fetch the current transaction status again for accuracy before proceeding.
 			status, err := s.pendingTransactionOrm.GetTxStatusByTxHash(s.ctx, tx.Hash())
@@ -468,15 +825,21 @@ func (s *Sender) checkPendingTransaction() {
 				"nonce", tx.Nonce(),
 				"submitBlockNumber", txnToCheck.SubmitBlockNumber,
 				"currentBlockNumber", blockNumber,
-				"escalateBlocks", s.config.EscalateBlocks)
+				"escalateBlocks", s.config.EscalateBlocks,
+				"stuck", stuck)
 
-			if newTx, err := s.resubmitTransaction(tx, baseFee); err != nil {
+			if stuck {
+				s.metrics.stuckTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+			}
+			if newTx, err := s.replaceTransaction(tx, baseFee, stuck, bumpCounts[txnToCheck.ContextID]); errors.Is(err, errFeeBumpLimitReached) {
+				log.Warn("transaction not resubmitted, fee bump limit reached", "service", s.service, "name", s.name, "context ID", txnToCheck.ContextID, "hash", tx.Hash().String(), "nonce", tx.Nonce(), "max bumps", s.feeBumpPolicy.MaxBumps)
+			} else if err != nil {
 				s.metrics.resubmitTransactionFailedTotal.WithLabelValues(s.service, s.name).Inc()
 				log.Error("failed to resubmit transaction", "context ID", txnToCheck.ContextID, "sender meta", s.getSenderMeta(), "from", s.auth.From.String(), "nonce", tx.Nonce(), "err", err)
 			} else {
 				err := s.db.Transaction(func(dbTX *gorm.DB) error {
 					// Update the status of the original transaction as replaced, while still checking its confirmation status.
-					if err := s.pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(s.ctx, tx.Hash(), types.TxStatusReplaced, dbTX); err != nil {
+					if err := s.pendingTransactionOrm.UpdateTransactionAsReplaced(s.ctx, tx.Hash(), newTx.Hash(), dbTX); err != nil {
 						return fmt.Errorf("failed to update status of transaction with hash %s to TxStatusReplaced, err: %w", tx.Hash().String(), err)
 					}
 					// Record the new transaction that has replaced the original one.
@@ -499,10 +862,24 @@ func (s *Sender) loop(ctx context.Context) {
 	checkTick := time.NewTicker(time.Duration(s.config.CheckPendingTime) * time.Second)
 	defer checkTick.Stop()
 
+	var nonceGapTickCh <-chan time.Time
+	if s.config.NonceGapCheckIntervalSec > 0 {
+		nonceGapTick := time.NewTicker(time.Duration(s.config.NonceGapCheckIntervalSec) * time.Second)
+		defer nonceGapTick.Stop()
+		nonceGapTickCh = nonceGapTick.C
+	}
+
 	for {
 		select {
 		case <-checkTick.C:
 			s.checkPendingTransaction()
+			if s.flashbots != nil {
+				s.checkPrivateTransactions()
+			}
+		case <-nonceGapTickCh:
+			if err := s.FillNonceGap(ctx); err != nil {
+				log.Error("failed to fill nonce gap", "service", s.service, "name", s.name, "err", err)
+			}
 		case <-ctx.Done():
 			return
 		case <-s.stopCh:
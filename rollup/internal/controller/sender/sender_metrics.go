@@ -8,16 +8,31 @@ import (
 )
 
 type senderMetrics struct {
-	senderCheckPendingTransactionTotal *prometheus.CounterVec
-	sendTransactionTotal               *prometheus.CounterVec
-	sendTransactionFailureGetFee       *prometheus.CounterVec
-	sendTransactionFailureSendTx       *prometheus.CounterVec
-	resubmitTransactionTotal           *prometheus.CounterVec
-	resubmitTransactionFailedTotal     *prometheus.CounterVec
-	currentGasFeeCap                   *prometheus.GaugeVec
-	currentGasTipCap                   *prometheus.GaugeVec
-	currentGasPrice                    *prometheus.GaugeVec
-	currentGasLimit                    *prometheus.GaugeVec
+	senderCheckPendingTransactionTotal   *prometheus.CounterVec
+	sendTransactionTotal                 *prometheus.CounterVec
+	sendTransactionFailureGetFee         *prometheus.CounterVec
+	sendTransactionFailureSendTx         *prometheus.CounterVec
+	resubmitTransactionTotal             *prometheus.CounterVec
+	resubmitTransactionFailedTotal       *prometheus.CounterVec
+	stuckTransactionTotal                *prometheus.CounterVec
+	simulateTransactionFailedTotal       *prometheus.CounterVec
+	currentGasFeeCap                     *prometheus.GaugeVec
+	currentGasTipCap                     *prometheus.GaugeVec
+	currentGasPrice                      *prometheus.GaugeVec
+	currentGasLimit                      *prometheus.GaugeVec
+	healthCheckFailuresTotal             *prometheus.CounterVec
+	circuitBreakerState                  *prometheus.GaugeVec
+	pendingTxCount                       *prometheus.GaugeVec
+	currentBlobGasFeeCap                 *prometheus.GaugeVec
+	blobTxConfirmedTotal                 *prometheus.CounterVec
+	blobTxFailedTotal                    *prometheus.CounterVec
+	revertReasonsTotal                   *prometheus.CounterVec
+	rollupSenderFailoverTotal            *prometheus.CounterVec
+	rollupSenderGasEstimateBufferApplied *prometheus.HistogramVec
+	rollupSenderNonceGapsFilled          *prometheus.CounterVec
+	rollupSenderPrivateTxStatusTotal     *prometheus.CounterVec
+	batchedTransactionTotal              *prometheus.CounterVec
+	batchedMulticallTotal                *prometheus.CounterVec
 }
 
 var (
@@ -48,6 +63,14 @@ func initSenderMetrics(reg prometheus.Registerer) *senderMetrics {
 				Name: "rollup_sender_send_transaction_resubmit_send_transaction_failed_total",
 				Help: "The total number of failed resubmit transactions.",
 			}, []string{"service", "name"}),
+			stuckTransactionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_stuck_transaction_total",
+				Help: "The total number of transactions resubmitted for being pending longer than the stuck tx timeout.",
+			}, []string{"service", "name"}),
+			simulateTransactionFailedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_simulate_transaction_failed_total",
+				Help: "The total number of simulated transactions whose eth_call failed.",
+			}, []string{"service", "name"}),
 			currentGasFeeCap: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
 				Name: "rollup_sender_gas_fee_cap",
 				Help: "The gas fee cap of current transaction.",
@@ -68,6 +91,59 @@ func initSenderMetrics(reg prometheus.Registerer) *senderMetrics {
 				Name: "rollup_sender_check_pending_transaction_total",
 				Help: "The total number of check pending transaction.",
 			}, []string{"service", "name"}),
+			healthCheckFailuresTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_health_check_failures_total",
+				Help: "The total number of failed sender health checks.",
+			}, []string{"service", "name"}),
+			circuitBreakerState: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_circuit_breaker_state",
+				Help: "The state of the sender circuit breaker, 0: closed, 1: open, 2: half open.",
+			}, []string{"service", "name"}),
+			pendingTxCount: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_pending_tx_count",
+				Help: "The number of pending transactions of the sender.",
+			}, []string{"service", "name"}),
+			currentBlobGasFeeCap: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_blob_gas_fee_cap",
+				Help: "The blob gas fee cap of current blob transaction.",
+			}, []string{"service", "name"}),
+			blobTxConfirmedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_blob_tx_confirmed_total",
+				Help: "The total number of successfully confirmed blob transactions.",
+			}, []string{"service", "name"}),
+			blobTxFailedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_blob_tx_failed_total",
+				Help: "The total number of confirmed but failed blob transactions.",
+			}, []string{"service", "name"}),
+			revertReasonsTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_revert_reasons_total",
+				Help: "The total number of confirmed but reverted transactions by the truncated revert reason.",
+			}, []string{"service", "name", "reason"}),
+			rollupSenderFailoverTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_failover_total",
+				Help: "The total number of transactions sent to the standby endpoint as the endpoint was unreachable.",
+			}, []string{"service", "name"}),
+			rollupSenderGasEstimateBufferApplied: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "rollup_sender_gas_estimate_buffer_applied",
+				Help:    "The gas added to the estimated gas limit of each transaction.",
+				Buckets: prometheus.ExponentialBuckets(1000, 2, 16),
+			}, []string{"service", "name"}),
+			rollupSenderNonceGapsFilled: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_nonce_gaps_filled_total",
+				Help: "The total number of nonces unblocked by broadcasting a dropped transaction again or a self-transfer.",
+			}, []string{"service", "name"}),
+			rollupSenderPrivateTxStatusTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_private_tx_status_total",
+				Help: "The total number of private transaction statuses polled from the Flashbots relay by status.",
+			}, []string{"service", "name", "status"}),
+			batchedTransactionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_batched_transaction_total",
+				Help: "The total number of transactions queued by the batching optimizer.",
+			}, []string{"service", "name"}),
+			batchedMulticallTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_batched_multicall_total",
+				Help: "The total number of multicall transactions merging the queued transactions.",
+			}, []string{"service", "name"}),
 		}
 	})
 
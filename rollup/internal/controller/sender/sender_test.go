@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
@@ -27,6 +29,7 @@ import (
 
 	bridgeAbi "scroll-tech/rollup/abi"
 	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
 	"scroll-tech/rollup/mock_bridge"
 )
 
@@ -98,6 +101,7 @@ func TestSender(t *testing.T) {
 	t.Run("test new sender", testNewSender)
 	t.Run("test fallback gas limit", testFallbackGasLimit)
 	t.Run("test send and retrieve transaction", testSendAndRetrieveTransaction)
+	t.Run("test resync nonce", testResyncNonce)
 	t.Run("test access list transaction gas limit", testAccessListTransactionGasLimit)
 	t.Run("test resubmit zero gas price transaction", testResubmitZeroGasPriceTransaction)
 	t.Run("test resubmit non-zero gas price transaction", testResubmitNonZeroGasPriceTransaction)
@@ -107,6 +111,8 @@ func TestSender(t *testing.T) {
 	t.Run("test check pending transaction resubmit tx confirmed", testCheckPendingTransactionResubmitTxConfirmed)
 	t.Run("test check pending transaction replaced tx confirmed", testCheckPendingTransactionReplacedTxConfirmed)
 	t.Run("test check pending transaction multiple times with only one transaction pending", testCheckPendingTransactionTxMultipleTimesWithOnlyOneTxPending)
+	t.Run("test check pending transaction stuck tx replaced", testCheckPendingTransactionStuckTxReplaced)
+	t.Run("test chaos sender", testChaosSender)
 }
 
 func testNewSender(t *testing.T) {
@@ -160,6 +166,34 @@ func testSendAndRetrieveTransaction(t *testing.T) {
 	}
 }
 
+func testResyncNonce(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	cfgCopy := *cfg.L1Config.RelayerConfig.SenderConfig
+	cfgCopy.TxType = DynamicFeeTxType
+	s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeUnknown, db, nil)
+	assert.NoError(t, err)
+	defer s.Stop()
+
+	pendingNonce, err := s.client.PendingNonceAt(context.Background(), s.auth.From)
+	assert.NoError(t, err)
+	assert.Equal(t, pendingNonce, s.auth.Nonce.Uint64())
+
+	// Simulate a gap between the stored and the chain nonce in both directions.
+	for _, nonce := range []uint64{pendingNonce + 5, 0} {
+		s.auth.Nonce = new(big.Int).SetUint64(nonce)
+		assert.NoError(t, s.ResyncNonce(context.Background()))
+		assert.Equal(t, pendingNonce, s.auth.Nonce.Uint64())
+	}
+
+	// The resynced nonce can be used to send a transaction.
+	_, err = s.SendTransaction("0", &common.Address{}, big.NewInt(0), nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, pendingNonce+1, s.auth.Nonce.Uint64())
+}
+
 func testFallbackGasLimit(t *testing.T) {
 	for _, txType := range txTypes {
 		sqlDB, err := db.DB()
@@ -548,3 +582,122 @@ func testCheckPendingTransactionTxMultipleTimesWithOnlyOneTxPending(t *testing.T
 		patchGuard.Reset()
 	}
 }
+
+func testCheckPendingTransactionStuckTxReplaced(t *testing.T) {
+	for _, txType := range txTypes {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		assert.NoError(t, migrate.ResetDB(sqlDB))
+
+		cfgCopy := *cfg.L1Config.RelayerConfig.SenderConfig
+		cfgCopy.TxType = txType
+		cfgCopy.EscalateBlocks = 10000
+		cfgCopy.StuckTxTimeoutSec = 60
+		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeCommitBatch, db, nil)
+		assert.NoError(t, err)
+
+		originTxHash, err := s.SendTransaction("test", &common.Address{}, big.NewInt(0), nil, 0)
+		assert.NoError(t, err)
+
+		patchGuard := gomonkey.ApplyMethodFunc(s.client, "TransactionReceipt", func(_ context.Context, hash common.Hash) (*gethTypes.Receipt, error) {
+			return nil, fmt.Errorf("simulated transaction receipt error")
+		})
+
+		// The transaction is neither escalated nor stuck yet.
+		s.checkPendingTransaction()
+		txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 2)
+		assert.NoError(t, err)
+		assert.Len(t, txs, 1)
+		assert.Equal(t, types.TxStatusPending, txs[0].Status)
+
+		// Pretend the transaction was submitted before the stuck tx timeout.
+		err = db.Model(&orm.PendingTransaction{}).Where("hash = ?", originTxHash.String()).Update("created_at", time.Now().Add(-time.Hour)).Error
+		assert.NoError(t, err)
+
+		s.checkPendingTransaction()
+		txs, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 2)
+		assert.NoError(t, err)
+		assert.Len(t, txs, 2)
+		assert.Equal(t, types.TxStatusReplaced, txs[0].Status)
+		assert.Equal(t, originTxHash.String(), txs[0].Hash)
+		assert.Equal(t, txs[1].Hash, txs[0].ReplacedBy)
+		assert.Equal(t, types.TxStatusPending, txs[1].Status)
+		assert.Equal(t, txs[0].Nonce, txs[1].Nonce)
+		minGasTipCap, _ := s.feeBumpPolicy.NextGasPrice(new(big.Int).SetUint64(txs[0].GasTipCap), 0)
+		minGasFeeCap, _ := s.feeBumpPolicy.NextGasPrice(new(big.Int).SetUint64(txs[0].GasFeeCap), 0)
+		assert.GreaterOrEqual(t, txs[1].GasTipCap, minGasTipCap.Uint64())
+		assert.GreaterOrEqual(t, txs[1].GasFeeCap, minGasFeeCap.Uint64())
+
+		s.Stop()
+		patchGuard.Reset()
+	}
+}
+
+func testChaosSender(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	cfgCopy := *cfg.L1Config.RelayerConfig.SenderConfig
+	cfgCopy.TxType = DynamicFeeTxType
+	cfgCopy.CheckPendingTime = 1
+	s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeUnknown, db, nil)
+	assert.NoError(t, err)
+	defer s.Stop()
+	chaosSender := NewChaosSender(context.Background(), s, 0.2)
+
+	var mu sync.Mutex
+	confirmed := make(map[string]bool)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go func() {
+		for {
+			select {
+			case cfm := <-chaosSender.ConfirmChan():
+				mu.Lock()
+				confirmed[cfm.ContextID] = cfm.IsSuccessful
+				mu.Unlock()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	var sent []string
+	for i := 0; i < 1000; i++ {
+		contextID := fmt.Sprintf("chaos-%d", i)
+		_, err = chaosSender.SendTransaction(contextID, &common.Address{}, big.NewInt(0), nil, 0)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrRPCTimeout)
+			continue
+		}
+		sent = append(sent, contextID)
+	}
+	// about 20% of the submissions fail.
+	assert.InDelta(t, 800, len(sent), 100)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(confirmed) == len(sent)
+	}, 2*time.Minute, time.Second)
+
+	mu.Lock()
+	for _, contextID := range sent {
+		assert.True(t, confirmed[contextID], "context ID %s", contextID)
+	}
+	mu.Unlock()
+
+	txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 0)
+}
+
+func TestChaosSenderInjectFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.True(t, NewChaosSender(ctx, nil, 1).injectFailure())
+	assert.False(t, NewChaosSender(ctx, nil, 0).injectFailure())
+
+	cancel()
+	assert.False(t, NewChaosSender(ctx, nil, 1).injectFailure())
+}
@@ -0,0 +1,70 @@
+package sender
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// simulatedConfirmations holds the confirmations of the simulated transactions until the next pending transaction check.
+type simulatedConfirmations struct {
+	mu            sync.Mutex
+	confirmations []*Confirmation
+}
+
+func (c *simulatedConfirmations) add(cfm *Confirmation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.confirmations = append(c.confirmations, cfm)
+}
+
+func (c *simulatedConfirmations) take() []*Confirmation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	confirmations := c.confirmations
+	c.confirmations = nil
+	return confirmations
+}
+
+// simulateTransaction calls the transaction with eth_call instead of sending it, when SimulateOnly is set.
+// The returned hash is the keccak256 of the calldata and the nonce, the transaction is confirmed as successful on the next
+// pending transaction check, after the caller has recorded the hash, so that the confirmation path runs unchanged.
+func (s *Sender) simulateTransaction(contextID string, target *common.Address, value *big.Int, data []byte) (common.Hash, error) {
+	nonce := s.auth.Nonce.Uint64()
+	msg := ethereum.CallMsg{
+		From:  s.auth.From,
+		To:    target,
+		Value: value,
+		Data:  data,
+	}
+	result, err := s.client.CallContract(s.ctx, msg, nil)
+	if err != nil {
+		s.metrics.simulateTransactionFailedTotal.WithLabelValues(s.service, s.name).Inc()
+		log.Warn("simulated transaction failed", "service", s.service, "name", s.name, "context ID", contextID, "from", s.auth.From.String(), "nonce", nonce, "err", err)
+		return common.Hash{}, fmt.Errorf("failed to simulate transaction, err: %w", err)
+	}
+
+	hash := crypto.Keccak256Hash(data, new(big.Int).SetUint64(nonce).Bytes())
+	s.auth.Nonce = new(big.Int).SetUint64(nonce + 1)
+	log.Info("simulated transaction", "service", s.service, "name", s.name, "context ID", contextID, "from", s.auth.From.String(), "nonce", nonce, "hash", hash.String(), "result", common.Bytes2Hex(result))
+
+	s.simulatedConfirmations.add(&Confirmation{
+		ContextID:    contextID,
+		IsSuccessful: true,
+		TxHash:       hash,
+		SenderType:   s.senderType,
+	})
+	return hash, nil
+}
+
+// flushSimulatedConfirmations sends the confirmations of the simulated transactions.
+func (s *Sender) flushSimulatedConfirmations() {
+	for _, cfm := range s.simulatedConfirmations.take() {
+		s.confirmCh <- cfm
+	}
+}
@@ -0,0 +1,73 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestSimulateTransaction(t *testing.T) {
+	client := &ethclient.Client{}
+	s := &Sender{
+		ctx:        context.Background(),
+		config:     &config.SenderConfig{SimulateOnly: true},
+		client:     client,
+		auth:       &bind.TransactOpts{Nonce: big.NewInt(7)},
+		senderType: types.SenderTypeCommitBatch,
+		confirmCh:  make(chan *Confirmation, 128),
+		metrics:    initSenderMetrics(nil),
+	}
+
+	var callErr error
+	var calls []ethereum.CallMsg
+	patches := gomonkey.ApplyMethodFunc(client, "CallContract", func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+		calls = append(calls, msg)
+		return nil, callErr
+	})
+	defer patches.Reset()
+
+	to := common.HexToAddress("0x1")
+	data := []byte{1, 2, 3}
+	hash, err := s.SendTransaction("test", &to, big.NewInt(0), data, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(data, big.NewInt(7).Bytes()), hash)
+	assert.Equal(t, uint64(8), s.auth.Nonce.Uint64())
+	assert.Len(t, calls, 1)
+	assert.Equal(t, &to, calls[0].To)
+	assert.Equal(t, data, calls[0].Data)
+
+	// The same calldata gets a different hash with the next nonce.
+	hash2, err := s.SendTransaction("test2", &to, big.NewInt(0), data, 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hash2)
+
+	// The confirmations are sent on the next pending transaction check.
+	assert.Empty(t, s.confirmCh)
+	s.flushSimulatedConfirmations()
+	assert.Len(t, s.confirmCh, 2)
+	cfm := <-s.confirmCh
+	assert.Equal(t, &Confirmation{ContextID: "test", IsSuccessful: true, TxHash: hash, SenderType: types.SenderTypeCommitBatch}, cfm)
+	cfm = <-s.confirmCh
+	assert.Equal(t, "test2", cfm.ContextID)
+	assert.Equal(t, hash2, cfm.TxHash)
+
+	// A failed call is not confirmed.
+	callErr = errors.New("execution reverted")
+	_, err = s.SendTransaction("test3", &to, big.NewInt(0), data, 0)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(9), s.auth.Nonce.Uint64())
+	s.flushSimulatedConfirmations()
+	assert.Empty(t, s.confirmCh)
+}
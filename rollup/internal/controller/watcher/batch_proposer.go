@@ -2,6 +2,7 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/utils"
 )
 
 // BatchProposer proposes batches based on available unbatched chunks.
@@ -35,6 +37,10 @@ type BatchProposer struct {
 	batchTimeoutSec                 uint64
 	gasCostIncreaseMultiplier       float64
 	forkMap                         map[uint64]bool
+	batchStrategy                   BatchStrategy
+	lagAlertThresholdChunks         uint64
+	maxBlobsPerBatch                int
+	maxBatchAgeSec                  uint64
 
 	batchProposerCircleTotal           prometheus.Counter
 	proposeBatchFailureTotal           prometheus.Counter
@@ -45,6 +51,9 @@ type BatchProposer struct {
 	batchChunksNum                     prometheus.Gauge
 	batchFirstBlockTimeoutReached      prometheus.Counter
 	batchChunksProposeNotEnoughTotal   prometheus.Counter
+	rollupBatchProposerLagChunks       prometheus.Gauge
+
+	rollupBatchProposerAgeTriggeredSealsTotal prometheus.Counter
 }
 
 // NewBatchProposer creates a new BatchProposer instance.
@@ -56,6 +65,10 @@ func NewBatchProposer(ctx context.Context, cfg *config.BatchProposerConfig, chai
 		"maxL1CommitCalldataSizePerBatch", cfg.MaxL1CommitCalldataSizePerBatch,
 		"batchTimeoutSec", cfg.BatchTimeoutSec,
 		"gasCostIncreaseMultiplier", cfg.GasCostIncreaseMultiplier,
+		"batchStrategy", cfg.BatchStrategy,
+		"batchTimeWindowSec", cfg.BatchTimeWindowSec,
+		"maxBlobsPerBatch", cfg.MaxBlobsPerBatch,
+		"maxBatchAgeSeconds", cfg.MaxBatchAgeSeconds,
 		"forkHeights", forkHeights)
 
 	return &BatchProposer{
@@ -70,6 +83,10 @@ func NewBatchProposer(ctx context.Context, cfg *config.BatchProposerConfig, chai
 		batchTimeoutSec:                 cfg.BatchTimeoutSec,
 		gasCostIncreaseMultiplier:       cfg.GasCostIncreaseMultiplier,
 		forkMap:                         forkMap,
+		batchStrategy:                   NewBatchStrategy(cfg),
+		lagAlertThresholdChunks:         cfg.LagAlertThresholdChunks,
+		maxBlobsPerBatch:                cfg.MaxBlobsPerBatch,
+		maxBatchAgeSec:                  cfg.MaxBatchAgeSeconds,
 
 		batchProposerCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "rollup_propose_batch_circle_total",
@@ -107,49 +124,90 @@ func NewBatchProposer(ctx context.Context, cfg *config.BatchProposerConfig, chai
 			Name: "rollup_propose_batch_chunks_propose_not_enough_total",
 			Help: "Total number of batch chunk propose not enough",
 		}),
+		rollupBatchProposerLagChunks: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_propose_batch_lag_chunks",
+			Help: "The number of chunks not yet proposed in a batch",
+		}),
+		rollupBatchProposerAgeTriggeredSealsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_batch_age_triggered_seals_total",
+			Help: "Total number of batches sealed as their first chunk was proposed longer than the max batch age ago",
+		}),
 	}
 }
 
 // TryProposeBatch tries to propose a new batches.
 func (p *BatchProposer) TryProposeBatch() {
+	ctx := utils.WithTrace(p.ctx, utils.NewTraceID())
 	p.batchProposerCircleTotal.Inc()
-	batch, err := p.proposeBatch()
+	defer p.updateProposerLag(ctx)
+
+	logger := utils.Logger(ctx)
+	batch, err := p.proposeBatch(ctx)
 	if err != nil {
 		p.proposeBatchFailureTotal.Inc()
-		log.Error("proposeBatchChunks failed", "err", err)
+		logger.Error("proposeBatchChunks failed", "err", err)
 		return
 	}
 	if batch == nil {
 		return
 	}
 	err = p.db.Transaction(func(dbTX *gorm.DB) error {
-		batch, dbErr := p.batchOrm.InsertBatch(p.ctx, batch, dbTX)
+		batch, dbErr := p.batchOrm.InsertBatch(ctx, batch, dbTX)
 		if dbErr != nil {
-			log.Warn("BatchProposer.updateBatchInfoInDB insert batch failure",
+			logger.Warn("BatchProposer.updateBatchInfoInDB insert batch failure",
 				"start chunk index", batch.StartChunkIndex, "end chunk index", batch.EndChunkIndex, "error", dbErr)
 			return dbErr
 		}
-		dbErr = p.chunkOrm.UpdateBatchHashInRange(p.ctx, batch.StartChunkIndex, batch.EndChunkIndex, batch.Hash, dbTX)
+		dbErr = p.chunkOrm.UpdateBatchHashInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex, batch.Hash, dbTX)
 		if dbErr != nil {
-			log.Warn("BatchProposer.UpdateBatchHashInRange update the chunk's batch hash failure", "hash", batch.Hash, "error", dbErr)
+			logger.Warn("BatchProposer.UpdateBatchHashInRange update the chunk's batch hash failure", "batchID", batch.Hash, "error", dbErr)
 			return dbErr
 		}
 		return nil
 	})
 	if err != nil {
 		p.proposeBatchUpdateInfoFailureTotal.Inc()
-		log.Error("update batch info in db failed", "err", err)
+		logger.Error("update batch info in db failed", "err", err)
 	}
 }
 
-func (p *BatchProposer) proposeBatch() (*encoding.Batch, error) {
-	unbatchedChunkIndex, err := p.batchOrm.GetFirstUnbatchedChunkIndex(p.ctx)
+// updateProposerLag sets the lag gauge to the number of chunks after the latest batched chunk.
+func (p *BatchProposer) updateProposerLag(ctx context.Context) {
+	logger := utils.Logger(ctx)
+	unbatchedChunkIndex, err := p.batchOrm.GetFirstUnbatchedChunkIndex(ctx)
+	if err != nil {
+		logger.Error("failed to get first unbatched chunk index", "err", err)
+		return
+	}
+	latestChunk, err := p.chunkOrm.GetLatestChunk(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("failed to get latest chunk", "err", err)
+		return
+	}
+
+	var lag uint64
+	if latestChunk != nil && latestChunk.Index >= unbatchedChunkIndex {
+		lag = latestChunk.Index - unbatchedChunkIndex + 1
+	}
+	p.rollupBatchProposerLagChunks.Set(float64(lag))
+
+	if p.lagAlertThresholdChunks > 0 && lag > p.lagAlertThresholdChunks {
+		logger.Warn("batch proposer lag exceeds alert threshold",
+			"lag chunks", lag,
+			"threshold", p.lagAlertThresholdChunks,
+			"first unbatched chunk index", unbatchedChunkIndex)
+	}
+}
+
+func (p *BatchProposer) proposeBatch(ctx context.Context) (*encoding.Batch, error) {
+	logger := utils.Logger(ctx)
+	unbatchedChunkIndex, err := p.batchOrm.GetFirstUnbatchedChunkIndex(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// select at most p.maxChunkNumPerBatch chunks
-	dbChunks, err := p.chunkOrm.GetChunksGEIndex(p.ctx, unbatchedChunkIndex, int(p.maxChunkNumPerBatch))
+	dbChunks, err := p.chunkOrm.GetChunksGEIndex(ctx, unbatchedChunkIndex, int(p.maxChunkNumPerBatch))
 	if err != nil {
 		return nil, err
 	}
@@ -170,12 +228,12 @@ func (p *BatchProposer) proposeBatch() (*encoding.Batch, error) {
 		}
 	}
 
-	daChunks, err := p.getDAChunks(dbChunks)
+	daChunks, err := p.getDAChunks(ctx, dbChunks)
 	if err != nil {
 		return nil, err
 	}
 
-	parentDBBatch, err := p.batchOrm.GetLatestBatch(p.ctx)
+	parentDBBatch, err := p.batchOrm.GetLatestBatch(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -191,7 +249,34 @@ func (p *BatchProposer) proposeBatch() (*encoding.Batch, error) {
 		batch.ParentBatchHash = common.HexToHash(parentDBBatch.Hash)
 	}
 
+	var totalBlobCount int
 	for i, chunk := range daChunks {
+		if i != 0 && p.batchStrategy.ShouldSeal(dbChunks[:i], dbChunks[i]) {
+			logger.Debug("batch strategy seals the batch",
+				"start chunk index", dbChunks[0].Index,
+				"end chunk index", dbChunks[i-1].Index,
+				"next chunk index", dbChunks[i].Index)
+			return p.sealBatch(&batch, dbChunks)
+		}
+
+		// The blobs are estimated per chunk, a chunk does not share its last blob with the next chunk.
+		totalBlobCount += dbChunks[i].EstimateBlobCount()
+		if p.maxBlobsPerBatch > 0 && totalBlobCount > p.maxBlobsPerBatch {
+			if i == 0 {
+				return nil, fmt.Errorf(
+					"the first chunk exceeds blob count limit; start block number: %v, end block number: %v, blob count: %v, max blob count limit: %v",
+					dbChunks[0].StartBlockNumber,
+					dbChunks[0].EndBlockNumber,
+					totalBlobCount,
+					p.maxBlobsPerBatch,
+				)
+			}
+			logger.Debug("breaking blob count limit in batching",
+				"currentBlobCount", totalBlobCount,
+				"maxBlobsPerBatch", p.maxBlobsPerBatch)
+			return p.sealBatch(&batch, dbChunks)
+		}
+
 		batch.Chunks = append(batch.Chunks, chunk)
 		totalL1CommitCalldataSize, err := codecv0.EstimateBatchL1CommitCalldataSize(&batch)
 		if err != nil {
@@ -227,46 +312,37 @@ func (p *BatchProposer) proposeBatch() (*encoding.Batch, error) {
 				}
 			}
 
-			log.Debug("breaking limit condition in batching",
+			logger.Debug("breaking limit condition in batching",
 				"currentL1CommitCalldataSize", totalL1CommitCalldataSize,
 				"maxL1CommitCalldataSizePerBatch", p.maxL1CommitCalldataSizePerBatch,
 				"currentOverEstimateL1CommitGas", totalOverEstimateL1CommitGas,
 				"maxL1CommitGasPerBatch", p.maxL1CommitGasPerBatch)
 
 			batch.Chunks = batch.Chunks[:len(batch.Chunks)-1]
-			batch.StartChunkIndex = dbChunks[0].Index
-			batch.EndChunkIndex = dbChunks[batch.NumChunks()-1].Index
-			batch.StartChunkHash = common.HexToHash(dbChunks[0].Hash)
-			batch.EndChunkHash = common.HexToHash(dbChunks[batch.NumChunks()-1].Hash)
-
-			totalL1CommitCalldataSize, err := codecv0.EstimateBatchL1CommitCalldataSize(&batch)
-			if err != nil {
-				return nil, err
-			}
-
-			totalL1CommitGas, err := codecv0.EstimateBatchL1CommitGas(&batch)
-			if err != nil {
-				return nil, err
-			}
-
-			p.totalL1CommitCalldataSize.Set(float64(totalL1CommitCalldataSize))
-			p.totalL1CommitGas.Set(float64(totalL1CommitGas))
-			p.batchChunksNum.Set(float64(batch.NumChunks()))
-			return &batch, nil
+			return p.sealBatch(&batch, dbChunks)
 		}
 	}
 
 	currentTimeSec := uint64(time.Now().Unix())
-	if dbChunks[0].StartBlockTime+p.batchTimeoutSec < currentTimeSec ||
-		batch.NumChunks() == maxChunksThisBatch {
-		if dbChunks[0].StartBlockTime+p.batchTimeoutSec < currentTimeSec {
-			log.Warn("first block timeout",
+	firstBlockTimeout := dbChunks[0].StartBlockTime+p.batchTimeoutSec < currentTimeSec
+	// Unlike the first block timeout, the age does not depend on the l2 block timestamps.
+	maxAgeReached := p.maxBatchAgeSec > 0 && time.Since(dbChunks[0].CreatedAt) > time.Duration(p.maxBatchAgeSec)*time.Second
+	if firstBlockTimeout || maxAgeReached || batch.NumChunks() == maxChunksThisBatch {
+		if firstBlockTimeout {
+			logger.Warn("first block timeout",
 				"start block number", dbChunks[0].StartBlockNumber,
 				"start block timestamp", dbChunks[0].StartBlockTime,
 				"current time", currentTimeSec,
 			)
+		} else if maxAgeReached {
+			logger.Info("reached maximum batch age",
+				"start chunk index", dbChunks[0].Index,
+				"start chunk created at", dbChunks[0].CreatedAt,
+				"max batch age seconds", p.maxBatchAgeSec,
+			)
+			p.rollupBatchProposerAgeTriggeredSealsTotal.Inc()
 		} else {
-			log.Info("reached maximum number of chunks in batch",
+			logger.Info("reached maximum number of chunks in batch",
 				"chunk count", batch.NumChunks(),
 			)
 		}
@@ -294,17 +370,40 @@ func (p *BatchProposer) proposeBatch() (*encoding.Batch, error) {
 		return &batch, nil
 	}
 
-	log.Debug("pending chunks do not reach one of the constraints or contain a timeout block")
+	logger.Debug("pending chunks do not reach one of the constraints or contain a timeout block")
 	p.batchChunksProposeNotEnoughTotal.Inc()
 	return nil, nil
 }
 
-func (p *BatchProposer) getDAChunks(dbChunks []*orm.Chunk) ([]*encoding.Chunk, error) {
+// sealBatch fills the chunk range of the batch, which holds the first chunks of dbChunks, and updates the batch metrics.
+func (p *BatchProposer) sealBatch(batch *encoding.Batch, dbChunks []*orm.Chunk) (*encoding.Batch, error) {
+	batch.StartChunkIndex = dbChunks[0].Index
+	batch.EndChunkIndex = dbChunks[batch.NumChunks()-1].Index
+	batch.StartChunkHash = common.HexToHash(dbChunks[0].Hash)
+	batch.EndChunkHash = common.HexToHash(dbChunks[batch.NumChunks()-1].Hash)
+
+	totalL1CommitCalldataSize, err := codecv0.EstimateBatchL1CommitCalldataSize(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	totalL1CommitGas, err := codecv0.EstimateBatchL1CommitGas(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	p.totalL1CommitCalldataSize.Set(float64(totalL1CommitCalldataSize))
+	p.totalL1CommitGas.Set(float64(totalL1CommitGas))
+	p.batchChunksNum.Set(float64(batch.NumChunks()))
+	return batch, nil
+}
+
+func (p *BatchProposer) getDAChunks(ctx context.Context, dbChunks []*orm.Chunk) ([]*encoding.Chunk, error) {
 	chunks := make([]*encoding.Chunk, len(dbChunks))
 	for i, c := range dbChunks {
-		blocks, err := p.l2BlockOrm.GetL2BlocksInRange(p.ctx, c.StartBlockNumber, c.EndBlockNumber)
+		blocks, err := p.l2BlockOrm.GetL2BlocksInRange(ctx, c.StartBlockNumber, c.EndBlockNumber)
 		if err != nil {
-			log.Error("Failed to fetch blocks", "start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
+			utils.Logger(ctx, "chunkID", c.Hash).Error("Failed to fetch blocks", "start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
 			return nil, err
 		}
 		chunks[i] = &encoding.Chunk{
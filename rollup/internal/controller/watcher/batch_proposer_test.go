@@ -4,7 +4,9 @@ import (
 	"context"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 
@@ -23,6 +25,7 @@ func testBatchProposerLimits(t *testing.T) {
 		maxL1CommitGas             uint64
 		maxL1CommitCalldataSize    uint64
 		batchTimeoutSec            uint64
+		maxBlobs                   int
 		forkBlock                  *big.Int
 		expectedBatchesLen         int
 		expectedChunksInFirstBatch uint64 // only be checked when expectedBatchesLen > 0
@@ -87,6 +90,16 @@ func testBatchProposerLimits(t *testing.T) {
 			expectedBatchesLen:         1,
 			expectedChunksInFirstBatch: 1,
 		},
+		{
+			name:                       "MaxBlobsPerBatchIsFirstChunk",
+			maxChunkNum:                10,
+			maxL1CommitGas:             50000000000,
+			maxL1CommitCalldataSize:    1000000,
+			batchTimeoutSec:            1000000000000,
+			maxBlobs:                   1,
+			expectedBatchesLen:         1,
+			expectedChunksInFirstBatch: 1,
+		},
 		{
 			name:                       "ForkBlockReached",
 			maxChunkNum:                10,
@@ -136,6 +149,7 @@ func testBatchProposerLimits(t *testing.T) {
 				MaxL1CommitCalldataSizePerBatch: tt.maxL1CommitCalldataSize,
 				BatchTimeoutSec:                 tt.batchTimeoutSec,
 				GasCostIncreaseMultiplier:       1.2,
+				MaxBlobsPerBatch:                tt.maxBlobs,
 			}, &params.ChainConfig{
 				HomesteadBlock: tt.forkBlock,
 			}, db, nil)
@@ -220,3 +234,113 @@ func testBatchCommitGasAndCalldataSizeEstimation(t *testing.T) {
 	assert.Equal(t, uint64(258383), batches[0].TotalL1CommitGas)
 	assert.Equal(t, uint64(6035), batches[0].TotalL1CommitCalldataSize)
 }
+
+func testBatchProposerTimeWindowStrategy(t *testing.T) {
+	tests := []struct {
+		name                       string
+		batchTimeWindowSec         uint64
+		expectedBatchesLen         int
+		expectedChunksInFirstBatch int // only be checked when expectedBatchesLen > 0
+	}{
+		{
+			name:                       "NextChunkOutOfTimeWindow",
+			batchTimeWindowSec:         3,
+			expectedBatchesLen:         1,
+			expectedChunksInFirstBatch: 1,
+		},
+		{
+			name:               "AllChunksInTimeWindow",
+			batchTimeWindowSec: 4,
+			expectedBatchesLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupDB(t)
+			defer database.CloseDB(db)
+
+			l2BlockOrm := orm.NewL2Block(db)
+			err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+			assert.NoError(t, err)
+
+			cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+				MaxBlockNumPerChunk:             1,
+				MaxTxNumPerChunk:                10000,
+				MaxL1CommitGasPerChunk:          50000000000,
+				MaxL1CommitCalldataSizePerChunk: 1000000,
+				MaxRowConsumptionPerChunk:       1000000,
+				ChunkTimeoutSec:                 300,
+				GasCostIncreaseMultiplier:       1.2,
+			}, &params.ChainConfig{}, db, nil)
+			cp.TryProposeChunk() // chunk1 contains block1
+			cp.TryProposeChunk() // chunk2 contains block2
+
+			bp := NewBatchProposer(context.Background(), &config.BatchProposerConfig{
+				MaxChunkNumPerBatch:             10,
+				MaxL1CommitGasPerBatch:          50000000000,
+				MaxL1CommitCalldataSizePerBatch: 1000000,
+				BatchTimeoutSec:                 1000000000000,
+				GasCostIncreaseMultiplier:       1.2,
+				BatchStrategy:                   config.BatchStrategyTimeWindow,
+				BatchTimeWindowSec:              tt.batchTimeWindowSec,
+			}, &params.ChainConfig{}, db, nil)
+			bp.TryProposeBatch()
+
+			batchOrm := orm.NewBatch(db)
+			batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
+			assert.NoError(t, err)
+			assert.Len(t, batches, tt.expectedBatchesLen)
+			if len(batches) > 0 {
+				assert.Equal(t, uint64(0), batches[0].StartChunkIndex)
+				assert.Equal(t, uint64(tt.expectedChunksInFirstBatch-1), batches[0].EndChunkIndex)
+			}
+		})
+	}
+}
+
+func testBatchProposerMaxBatchAge(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             1,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, &params.ChainConfig{}, db, nil)
+	cp.TryProposeChunk() // chunk1 contains block1
+	cp.TryProposeChunk() // chunk2 contains block2
+
+	bp := NewBatchProposer(context.Background(), &config.BatchProposerConfig{
+		MaxChunkNumPerBatch:             10,
+		MaxL1CommitGasPerBatch:          50000000000,
+		MaxL1CommitCalldataSizePerBatch: 1000000,
+		BatchTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+		MaxBatchAgeSeconds:              1,
+	}, &params.ChainConfig{}, db, nil)
+	batchOrm := orm.NewBatch(db)
+
+	// no limit is reached and the chunks are not older than the max age yet.
+	bp.TryProposeBatch()
+	batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, batches)
+
+	time.Sleep(2 * time.Second)
+	bp.TryProposeBatch()
+	batches, err = batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, uint64(0), batches[0].StartChunkIndex)
+	assert.Equal(t, uint64(1), batches[0].EndChunkIndex)
+	assert.Equal(t, float64(1), testutil.ToFloat64(bp.rollupBatchProposerAgeTriggeredSealsTotal))
+}
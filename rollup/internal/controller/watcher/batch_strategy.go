@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// BatchStrategy decides how consecutive chunks are grouped into batches.
+type BatchStrategy interface {
+	// ShouldSeal returns true if the batch made of chunks must be sealed instead of taking newChunk.
+	ShouldSeal(chunks []*orm.Chunk, newChunk *orm.Chunk) bool
+}
+
+// NewBatchStrategy returns the batch strategy selected by the config, the count and size strategy by default.
+func NewBatchStrategy(cfg *config.BatchProposerConfig) BatchStrategy {
+	if cfg.BatchStrategy == config.BatchStrategyTimeWindow {
+		return &TimeWindowBatchStrategy{TimeWindowSec: cfg.BatchTimeWindowSec}
+	}
+	return &CountSizeBatchStrategy{
+		MaxChunkNum:               cfg.MaxChunkNumPerBatch,
+		MaxL1CommitGas:            cfg.MaxL1CommitGasPerBatch,
+		MaxL1CommitCalldataSize:   cfg.MaxL1CommitCalldataSizePerBatch,
+		GasCostIncreaseMultiplier: cfg.GasCostIncreaseMultiplier,
+	}
+}
+
+// CountSizeBatchStrategy seals a batch once it reaches the max number of chunks, or once the commit gas
+// or calldata size recorded in its chunks would exceed the limits.
+type CountSizeBatchStrategy struct {
+	MaxChunkNum               uint64
+	MaxL1CommitGas            uint64
+	MaxL1CommitCalldataSize   uint64
+	GasCostIncreaseMultiplier float64
+}
+
+// ShouldSeal implements BatchStrategy.
+func (s *CountSizeBatchStrategy) ShouldSeal(chunks []*orm.Chunk, newChunk *orm.Chunk) bool {
+	if len(chunks) == 0 {
+		return false
+	}
+	if uint64(len(chunks)) >= s.MaxChunkNum {
+		return true
+	}
+
+	totalL1CommitGas, totalL1CommitCalldataSize := newChunk.TotalL1CommitGas, newChunk.TotalL1CommitCalldataSize
+	for _, chunk := range chunks {
+		totalL1CommitGas += chunk.TotalL1CommitGas
+		totalL1CommitCalldataSize += chunk.TotalL1CommitCalldataSize
+	}
+	return uint64(s.GasCostIncreaseMultiplier*float64(totalL1CommitGas)) > s.MaxL1CommitGas ||
+		totalL1CommitCalldataSize > s.MaxL1CommitCalldataSize
+}
+
+// TimeWindowBatchStrategy groups the chunks whose last block timestamps fall within the same time window,
+// starting at the last block timestamp of the first chunk of the batch.
+type TimeWindowBatchStrategy struct {
+	TimeWindowSec uint64
+}
+
+// ShouldSeal implements BatchStrategy.
+func (s *TimeWindowBatchStrategy) ShouldSeal(chunks []*orm.Chunk, newChunk *orm.Chunk) bool {
+	if len(chunks) == 0 {
+		return false
+	}
+	return newChunk.EndBlockTime >= chunks[0].EndBlockTime+s.TimeWindowSec
+}
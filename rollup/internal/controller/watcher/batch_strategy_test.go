@@ -0,0 +1,45 @@
+package watcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func TestNewBatchStrategy(t *testing.T) {
+	assert.IsType(t, &CountSizeBatchStrategy{}, NewBatchStrategy(&config.BatchProposerConfig{}))
+	assert.IsType(t, &CountSizeBatchStrategy{}, NewBatchStrategy(&config.BatchProposerConfig{BatchStrategy: config.BatchStrategyCountSize}))
+	assert.Equal(t, &TimeWindowBatchStrategy{TimeWindowSec: 12}, NewBatchStrategy(&config.BatchProposerConfig{
+		BatchStrategy:      config.BatchStrategyTimeWindow,
+		BatchTimeWindowSec: 12,
+	}))
+}
+
+func TestCountSizeBatchStrategy(t *testing.T) {
+	s := &CountSizeBatchStrategy{
+		MaxChunkNum:               3,
+		MaxL1CommitGas:            1200,
+		MaxL1CommitCalldataSize:   100,
+		GasCostIncreaseMultiplier: 1.2,
+	}
+	chunk := &orm.Chunk{TotalL1CommitGas: 300, TotalL1CommitCalldataSize: 30}
+
+	assert.False(t, s.ShouldSeal(nil, chunk))
+	assert.False(t, s.ShouldSeal([]*orm.Chunk{chunk}, chunk))
+	assert.False(t, s.ShouldSeal([]*orm.Chunk{chunk, chunk}, &orm.Chunk{TotalL1CommitGas: 400, TotalL1CommitCalldataSize: 40}))
+	assert.True(t, s.ShouldSeal([]*orm.Chunk{chunk, chunk, chunk}, &orm.Chunk{}))
+	assert.True(t, s.ShouldSeal([]*orm.Chunk{chunk, chunk}, &orm.Chunk{TotalL1CommitGas: 401}))
+	assert.True(t, s.ShouldSeal([]*orm.Chunk{chunk, chunk}, &orm.Chunk{TotalL1CommitCalldataSize: 41}))
+}
+
+func TestTimeWindowBatchStrategy(t *testing.T) {
+	s := &TimeWindowBatchStrategy{TimeWindowSec: 12}
+	chunks := []*orm.Chunk{{EndBlockTime: 100}, {EndBlockTime: 105}}
+
+	assert.False(t, s.ShouldSeal(nil, &orm.Chunk{EndBlockTime: 200}))
+	assert.False(t, s.ShouldSeal(chunks, &orm.Chunk{EndBlockTime: 111}))
+	assert.True(t, s.ShouldSeal(chunks, &orm.Chunk{EndBlockTime: 112}))
+}
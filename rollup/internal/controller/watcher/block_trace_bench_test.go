@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"scroll-tech/common/types/encoding"
+)
+
+// blockTraceFixtures are the block traces of the benchmarks, by name.
+var blockTraceFixtures = []struct {
+	name string
+	file string
+}{
+	{"blockTrace_02", "../../../testdata/blockTrace_02.json"},
+	{"blockTrace_03", "../../../testdata/blockTrace_03.json"},
+}
+
+func readBlockTrace(b *testing.B, file string) ([]byte, *encoding.Block) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		b.Fatal(err)
+	}
+	block := &encoding.Block{}
+	if err = json.Unmarshal(data, block); err != nil {
+		b.Fatal(err)
+	}
+	return data, block
+}
+
+func BenchmarkBlockTraceJSONUnmarshal(b *testing.B) {
+	for _, fixture := range blockTraceFixtures {
+		b.Run(fixture.name, func(b *testing.B) {
+			data, _ := readBlockTrace(b, fixture.file)
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				block := &encoding.Block{}
+				if err := json.Unmarshal(data, block); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkBlockTraceBinaryMarshal measures the zstd compressed gob encoding of the blocks,
+// the encoded size is reported as a ratio of the json size.
+func BenchmarkBlockTraceBinaryMarshal(b *testing.B) {
+	for _, fixture := range blockTraceFixtures {
+		b.Run(fixture.name, func(b *testing.B) {
+			data, block := readBlockTrace(b, fixture.file)
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			var encoded []byte
+			for i := 0; i < b.N; i++ {
+				var err error
+				if encoded, err = block.MarshalBinary(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(len(encoded))/float64(len(data)), "size_ratio")
+		})
+	}
+}
+
+// BenchmarkBlockTraceZstdCompress measures the zstd compression of the raw json block traces,
+// the compressed size is reported as a ratio of the json size.
+func BenchmarkBlockTraceZstdCompress(b *testing.B) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer encoder.Close()
+
+	for _, fixture := range blockTraceFixtures {
+		b.Run(fixture.name, func(b *testing.B) {
+			data, _ := readBlockTrace(b, fixture.file)
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			var compressed []byte
+			for i := 0; i < b.N; i++ {
+				compressed = encoder.EncodeAll(data, compressed[:0])
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(len(compressed))/float64(len(data)), "size_ratio")
+		})
+	}
+}
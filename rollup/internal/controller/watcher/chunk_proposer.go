@@ -2,23 +2,38 @@ package watcher
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/database"
 	"scroll-tech/common/forks"
+	"scroll-tech/common/types"
 	"scroll-tech/common/types/encoding"
 	"scroll-tech/common/types/encoding/codecv0"
 
+	bridgeAbi "scroll-tech/rollup/abi"
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/utils"
 )
 
+// commitGasCacheSize is the number of chunk commit gas estimates kept in the cache.
+const commitGasCacheSize = 128
+
 // ChunkProposer proposes chunks based on available unchunked blocks.
 type ChunkProposer struct {
 	ctx context.Context
@@ -26,6 +41,7 @@ type ChunkProposer struct {
 
 	chunkOrm   *orm.Chunk
 	l2BlockOrm *orm.L2Block
+	batchOrm   *orm.Batch
 
 	maxBlockNumPerChunk             uint64
 	maxTxNumPerChunk                uint64
@@ -35,6 +51,16 @@ type ChunkProposer struct {
 	chunkTimeoutSec                 uint64
 	gasCostIncreaseMultiplier       float64
 	forkHeights                     []uint64
+	lagAlertThresholdBlocks         uint64
+	debugProposals                  bool
+
+	// the L1 commit gas estimation, enabled by SetL1CommitGasEstimator when maxChunkGasLimit is set.
+	maxChunkGasLimit      uint64
+	l1Client              *ethclient.Client
+	l1RollupABI           *abi.ABI
+	rollupContractAddress common.Address
+	commitSenderAddress   common.Address
+	commitGasCache        *lru.Cache
 
 	chunkProposerCircleTotal           prometheus.Counter
 	proposeChunkFailureTotal           prometheus.Counter
@@ -47,6 +73,9 @@ type ChunkProposer struct {
 	chunkBlocksNum                     prometheus.Gauge
 	chunkFirstBlockTimeoutReached      prometheus.Counter
 	chunkBlocksProposeNotEnoughTotal   prometheus.Counter
+	chunkCommitGasSplitTotal           prometheus.Counter
+	chunkCommitGasEstimateFailureTotal prometheus.Counter
+	rollupChunkProposerLagBlocks       prometheus.Gauge
 }
 
 // NewChunkProposer creates a new ChunkProposer instance.
@@ -59,13 +88,18 @@ func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, chai
 		"maxRowConsumptionPerChunk", cfg.MaxRowConsumptionPerChunk,
 		"chunkTimeoutSec", cfg.ChunkTimeoutSec,
 		"gasCostIncreaseMultiplier", cfg.GasCostIncreaseMultiplier,
+		"maxChunkGasLimit", cfg.MaxChunkGasLimit,
 		"forkHeights", forkHeights)
 
+	// the size is a positive constant, the error can be safely ignored.
+	commitGasCache, _ := lru.New(commitGasCacheSize)
+
 	return &ChunkProposer{
 		ctx:                             ctx,
 		db:                              db,
 		chunkOrm:                        orm.NewChunk(db),
 		l2BlockOrm:                      orm.NewL2Block(db),
+		batchOrm:                        orm.NewBatch(db),
 		maxBlockNumPerChunk:             cfg.MaxBlockNumPerChunk,
 		maxTxNumPerChunk:                cfg.MaxTxNumPerChunk,
 		maxL1CommitGasPerChunk:          cfg.MaxL1CommitGasPerChunk,
@@ -74,6 +108,10 @@ func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, chai
 		chunkTimeoutSec:                 cfg.ChunkTimeoutSec,
 		gasCostIncreaseMultiplier:       cfg.GasCostIncreaseMultiplier,
 		forkHeights:                     forkHeights,
+		lagAlertThresholdBlocks:         cfg.LagAlertThresholdBlocks,
+		maxChunkGasLimit:                cfg.MaxChunkGasLimit,
+		l1RollupABI:                     bridgeAbi.ScrollChainABI,
+		commitGasCache:                  commitGasCache,
 
 		chunkProposerCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "rollup_propose_chunk_circle_total",
@@ -119,39 +157,291 @@ func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, chai
 			Name: "rollup_propose_chunk_blocks_propose_not_enough_total",
 			Help: "Total number of chunk block propose not enough",
 		}),
+		chunkCommitGasSplitTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_commit_gas_split_total",
+			Help: "Total number of chunks split because the estimated commit gas exceeds the max chunk gas limit",
+		}),
+		chunkCommitGasEstimateFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_commit_gas_estimate_failure_total",
+			Help: "Total number of chunk commit gas estimation failures",
+		}),
+		rollupChunkProposerLagBlocks: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_propose_chunk_lag_blocks",
+			Help: "The number of L2 blocks not yet proposed in a chunk",
+		}),
 	}
 }
 
+// SetL1CommitGasEstimator sets the L1 client used to estimate the commit gas of proposed chunks,
+// the estimation only takes effect when max_chunk_gas_limit is configured.
+func (p *ChunkProposer) SetL1CommitGasEstimator(l1Client *ethclient.Client, rollupContractAddress common.Address, commitSenderAddress common.Address) {
+	p.l1Client = l1Client
+	p.rollupContractAddress = rollupContractAddress
+	p.commitSenderAddress = commitSenderAddress
+}
+
+// SetReadReplica serves the chunk stats from the read replicas of db, the chunks are still proposed from the primary.
+// db must be the one the proposer was created with.
+func (p *ChunkProposer) SetReadReplica(db *database.DB) {
+	p.chunkOrm = orm.NewChunk(db.Primary(), orm.WithReadReplica(db))
+}
+
+// SetDebugProposals sets whether the plan of each chunk proposal is logged as JSON.
+func (p *ChunkProposer) SetDebugProposals(debugProposals bool) {
+	p.debugProposals = debugProposals
+}
+
+// EstimateCommitGas estimates the gas of committing the chunk on L1 by calling eth_estimateGas
+// against the rollup contract, with the chunk as the only chunk of the batch following the latest committed batch.
+func (p *ChunkProposer) EstimateCommitGas(ctx context.Context, chunk *orm.Chunk) (uint64, error) {
+	key := commitGasCacheKey(chunk.StartBlockHash, chunk.EndBlockHash)
+	if gas, ok := p.commitGasCache.Get(key); ok {
+		return gas.(uint64), nil
+	}
+
+	blocks, err := p.l2BlockOrm.GetL2BlocksInRange(ctx, chunk.StartBlockNumber, chunk.EndBlockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get blocks of chunk %v: %w", chunk.Index, err)
+	}
+	return p.estimateCommitGas(ctx, &encoding.Chunk{Blocks: blocks})
+}
+
+func (p *ChunkProposer) estimateCommitGas(ctx context.Context, chunk *encoding.Chunk) (uint64, error) {
+	if p.l1Client == nil {
+		return 0, errors.New("l1 commit gas estimator is not set")
+	}
+	if len(chunk.Blocks) == 0 {
+		return 0, errors.New("empty chunk")
+	}
+
+	key := commitGasCacheKey(chunk.Blocks[0].Header.Hash().Hex(), chunk.Blocks[len(chunk.Blocks)-1].Header.Hash().Hex())
+	if gas, ok := p.commitGasCache.Get(key); ok {
+		return gas.(uint64), nil
+	}
+
+	// the latest batch is usually not committed yet, the rollup contract only accepts a committed parent batch.
+	parentBatch, err := p.batchOrm.GetLatestCommittedBatch(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest committed batch: %w", err)
+	}
+	if parentBatch == nil {
+		return 0, errors.New("no committed parent batch to commit the chunk on")
+	}
+	parentDABatch, err := codecv0.NewDABatchFromBytes(parentBatch.BatchHeader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode parent batch header, index: %v, err: %w", parentBatch.Index, err)
+	}
+
+	batch := &encoding.Batch{
+		Index:                      parentBatch.Index + 1,
+		TotalL1MessagePoppedBefore: parentDABatch.TotalL1MessagePopped,
+		ParentBatchHash:            common.HexToHash(parentBatch.Hash),
+		Chunks:                     []*encoding.Chunk{chunk},
+	}
+	daBatch, err := codecv0.NewDABatch(batch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create DA batch: %w", err)
+	}
+	daChunk, err := codecv0.NewDAChunk(chunk, batch.TotalL1MessagePoppedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create DA chunk: %w", err)
+	}
+	daChunkBytes, err := daChunk.Encode()
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode DA chunk: %w", err)
+	}
+
+	calldata, err := p.l1RollupABI.Pack("commitBatch", daBatch.Version, parentBatch.BatchHeader, [][]byte{daChunkBytes}, daBatch.SkippedL1MessageBitmap)
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack commitBatch: %w", err)
+	}
+
+	gas, err := p.l1Client.EstimateGas(ctx, ethereum.CallMsg{
+		From: p.commitSenderAddress,
+		To:   &p.rollupContractAddress,
+		Data: calldata,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate commit gas: %w", err)
+	}
+
+	p.commitGasCache.Add(key, gas)
+	return gas, nil
+}
+
+// splitChunkByCommitGas shrinks the chunk to the longest prefix of its blocks whose estimated commit gas
+// does not exceed the max chunk gas limit. The chunk is kept as is if any estimation fails, the static
+// commit gas estimation still applies in that case.
+func (p *ChunkProposer) splitChunkByCommitGas(ctx context.Context, chunk *encoding.Chunk) error {
+	if p.maxChunkGasLimit == 0 || p.l1Client == nil {
+		return nil
+	}
+
+	logger := utils.Logger(ctx)
+
+	gas, err := p.estimateCommitGas(ctx, chunk)
+	if err != nil {
+		p.chunkCommitGasEstimateFailureTotal.Inc()
+		logger.Warn("failed to estimate chunk commit gas, skip splitting", "start block number", chunk.Blocks[0].Header.Number, "err", err)
+		return nil
+	}
+	if gas <= p.maxChunkGasLimit {
+		return nil
+	}
+
+	// binary search the number of blocks, the commit gas grows with the number of blocks.
+	lo, hi := 0, len(chunk.Blocks)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		gas, err = p.estimateCommitGas(ctx, &encoding.Chunk{Blocks: chunk.Blocks[:mid]})
+		if err != nil {
+			p.chunkCommitGasEstimateFailureTotal.Inc()
+			logger.Warn("failed to estimate chunk commit gas, skip splitting", "start block number", chunk.Blocks[0].Header.Number, "blocks", mid, "err", err)
+			return nil
+		}
+		if gas <= p.maxChunkGasLimit {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		return fmt.Errorf(
+			"the first block exceeds max chunk gas limit; block number: %v, max chunk gas limit: %v",
+			chunk.Blocks[0].Header.Number,
+			p.maxChunkGasLimit,
+		)
+	}
+
+	logger.Info("split chunk by estimated commit gas",
+		"start block number", chunk.Blocks[0].Header.Number,
+		"blocks", len(chunk.Blocks),
+		"blocks after split", lo,
+		"maxChunkGasLimit", p.maxChunkGasLimit)
+	p.chunkCommitGasSplitTotal.Inc()
+	chunk.Blocks = chunk.Blocks[:lo]
+	return nil
+}
+
+func commitGasCacheKey(startBlockHash, endBlockHash string) string {
+	return startBlockHash + ":" + endBlockHash
+}
+
 // TryProposeChunk tries to propose a new chunk.
 func (p *ChunkProposer) TryProposeChunk() {
+	p.tryProposeChunk(utils.WithTrace(p.ctx, utils.NewTraceID()))
+}
+
+func (p *ChunkProposer) tryProposeChunk(ctx context.Context) {
 	p.chunkProposerCircleTotal.Inc()
-	proposedChunk, err := p.proposeChunk()
+	defer p.updateProposerLag(ctx)
+
+	logger := utils.Logger(ctx)
+	proposedChunk, err := p.proposeChunk(ctx)
 	if err != nil {
 		p.proposeChunkFailureTotal.Inc()
-		log.Error("propose new chunk failed", "err", err)
+		logger.Error("propose new chunk failed", "err", err)
 		return
 	}
 
-	if err := p.updateChunkInfoInDB(proposedChunk); err != nil {
+	if err := p.updateChunkInfoInDB(ctx, proposedChunk); err != nil {
 		p.proposeChunkUpdateInfoFailureTotal.Inc()
-		log.Error("update chunk info in orm failed", "err", err)
+		logger.Error("update chunk info in orm failed", "err", err)
+	}
+}
+
+// ReproposeFailed rolls back the earliest batch whose commit transaction failed more than olderThan ago,
+// together with the later batches and all their chunks, and proposes a new chunk from the released blocks.
+// It returns the number of rolled back chunks.
+func (p *ChunkProposer) ReproposeFailed(ctx context.Context, olderThan time.Duration) (int, error) {
+	ctx = utils.WithTrace(ctx, utils.NewTraceID())
+	failedBatch, err := p.batchOrm.GetEarliestCommitFailedBatch(ctx, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commit failed batch: %w", err)
+	}
+	if failedBatch == nil {
+		return 0, nil
+	}
+	if failedBatch.StartChunkIndex == 0 {
+		return 0, fmt.Errorf("commit failed batch includes the genesis chunk, batch index: %v", failedBatch.Index)
+	}
+
+	startChunks, err := p.chunkOrm.GetChunksInRange(ctx, failedBatch.StartChunkIndex, failedBatch.StartChunkIndex)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get start chunk of batch %v: %w", failedBatch.Index, err)
+	}
+
+	var chunks []*orm.Chunk
+	err = p.db.Transaction(func(dbTX *gorm.DB) error {
+		batches, dbErr := p.batchOrm.DeleteBatchesAfterChunkIndex(ctx, failedBatch.StartChunkIndex-1, dbTX)
+		if dbErr != nil {
+			return dbErr
+		}
+		for _, batch := range batches {
+			if status := types.RollupStatus(batch.RollupStatus); status != types.RollupPending && status != types.RollupCommitFailed {
+				return fmt.Errorf("batch after the commit failed batch is already submitted to L1, batch index: %v, rollup status: %v", batch.Index, status)
+			}
+		}
+
+		chunks, dbErr = p.chunkOrm.DeleteChunksAfterBlockNumber(ctx, startChunks[0].StartBlockNumber-1, dbTX)
+		if dbErr != nil {
+			return dbErr
+		}
+		utils.Logger(ctx, "batchID", failedBatch.Hash).Info("rolled back chunks and batches of failed commit", "batch index", failedBatch.Index, "chunks", len(chunks), "batches", len(batches))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// propose on the proposer context, ctx may be canceled once the caller returns.
+	p.tryProposeChunk(utils.WithTrace(p.ctx, utils.TraceID(ctx)))
+	return len(chunks), nil
+}
+
+// updateProposerLag sets the lag gauge to the number of L2 blocks after the latest chunked block.
+func (p *ChunkProposer) updateProposerLag(ctx context.Context) {
+	logger := utils.Logger(ctx)
+	latestBlockHeight, err := p.l2BlockOrm.GetL2BlocksLatestHeight(ctx)
+	if err != nil {
+		logger.Error("failed to get latest l2 block height", "err", err)
+		return
+	}
+	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(ctx)
+	if err != nil {
+		logger.Error("failed to get unchunked block height", "err", err)
+		return
+	}
+
+	var lag uint64
+	if latestBlockHeight >= unchunkedBlockHeight {
+		lag = latestBlockHeight - unchunkedBlockHeight + 1
+	}
+	p.rollupChunkProposerLagBlocks.Set(float64(lag))
+
+	if p.lagAlertThresholdBlocks > 0 && lag > p.lagAlertThresholdBlocks {
+		logger.Warn("chunk proposer lag exceeds alert threshold",
+			"lag blocks", lag,
+			"threshold", p.lagAlertThresholdBlocks,
+			"latest block height", latestBlockHeight,
+			"unchunked block height", unchunkedBlockHeight)
 	}
 }
 
-func (p *ChunkProposer) updateChunkInfoInDB(chunk *encoding.Chunk) error {
+func (p *ChunkProposer) updateChunkInfoInDB(ctx context.Context, chunk *encoding.Chunk) error {
 	if chunk == nil {
 		return nil
 	}
 
 	p.proposeChunkUpdateInfoTotal.Inc()
 	err := p.db.Transaction(func(dbTX *gorm.DB) error {
-		dbChunk, err := p.chunkOrm.InsertChunk(p.ctx, chunk, dbTX)
+		dbChunk, err := p.chunkOrm.InsertChunk(ctx, chunk, dbTX)
 		if err != nil {
-			log.Warn("ChunkProposer.InsertChunk failed", "err", err)
+			utils.Logger(ctx).Warn("ChunkProposer.InsertChunk failed", "err", err)
 			return err
 		}
-		if err := p.l2BlockOrm.UpdateChunkHashInRange(p.ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber, dbChunk.Hash, dbTX); err != nil {
-			log.Error("failed to update chunk_hash for l2_blocks", "chunk hash", dbChunk.Hash, "start block", dbChunk.StartBlockNumber, "end block", dbChunk.EndBlockNumber, "err", err)
+		if err := p.l2BlockOrm.UpdateChunkHashInRange(ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber, dbChunk.Hash, dbTX); err != nil {
+			utils.Logger(ctx, "chunkID", dbChunk.Hash).Error("failed to update chunk_hash for l2_blocks", "start block", dbChunk.StartBlockNumber, "end block", dbChunk.EndBlockNumber, "err", err)
 			return err
 		}
 		return nil
@@ -159,8 +449,96 @@ func (p *ChunkProposer) updateChunkInfoInDB(chunk *encoding.Chunk) error {
 	return err
 }
 
-func (p *ChunkProposer) proposeChunk() (*encoding.Chunk, error) {
-	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(p.ctx)
+// The reasons a chunk proposal is sealed.
+const (
+	ChunkSealReasonTxNum          = "tx_num"
+	ChunkSealReasonCalldataSize   = "calldata_size"
+	ChunkSealReasonCommitGas      = "commit_gas"
+	ChunkSealReasonRowConsumption = "row_consumption"
+	ChunkSealReasonTimeout        = "timeout"
+	ChunkSealReasonBlockNum       = "block_num"
+)
+
+// ChunkProposalPlan is the chunk the proposer would propose next and the estimates it is checked against the limits with.
+type ChunkProposalPlan struct {
+	// The numbers of the blocks of the chunk, or of the pending blocks if the chunk is not sealed.
+	CandidateBlocks               []uint64 `json:"candidate_blocks"`
+	NumTransactions               uint64   `json:"num_transactions"`
+	EstimatedL1CommitGas          uint64   `json:"estimated_l1_commit_gas"`
+	EstimatedL1CommitCalldataSize uint64   `json:"estimated_l1_commit_calldata_size"`
+	RowConsumptionMax             uint64   `json:"row_consumption_max"`
+	// The reason the chunk is sealed, empty if the pending blocks reach neither a limit nor the timeout yet.
+	SealReason string `json:"seal_reason,omitempty"`
+
+	chunk *encoding.Chunk
+}
+
+// DumpProposalPlan returns the chunk the proposer would propose next without proposing it, the proposer logic is
+// run in a read-only transaction. It returns nil if there is no pending block.
+func (p *ChunkProposer) DumpProposalPlan(ctx context.Context) (*ChunkProposalPlan, error) {
+	var plan *ChunkProposalPlan
+	err := p.db.WithContext(ctx).Transaction(func(dbTX *gorm.DB) error {
+		dryRun := *p
+		dryRun.chunkOrm = orm.NewChunk(dbTX)
+		dryRun.l2BlockOrm = orm.NewL2Block(dbTX)
+		dryRun.batchOrm = orm.NewBatch(dbTX)
+		var err error
+		plan, err = dryRun.planChunk(ctx)
+		return err
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (p *ChunkProposer) proposeChunk(ctx context.Context) (*encoding.Chunk, error) {
+	logger := utils.Logger(ctx)
+	plan, err := p.planChunk(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, nil
+	}
+	if p.debugProposals {
+		if planJSON, jsonErr := json.Marshal(plan); jsonErr == nil {
+			logger.Info("chunk proposal plan", "plan", string(planJSON))
+		}
+	}
+
+	switch plan.SealReason {
+	case "":
+		logger.Debug("pending blocks do not reach one of the constraints or contain a timeout block")
+		p.chunkBlocksProposeNotEnoughTotal.Inc()
+		return nil, nil
+	case ChunkSealReasonTimeout:
+		logger.Warn("first block timeout",
+			"block number", plan.chunk.Blocks[0].Header.Number,
+			"block timestamp", plan.chunk.Blocks[0].Header.Time,
+			"current time", time.Now().Unix(),
+		)
+		p.chunkFirstBlockTimeoutReached.Inc()
+	case ChunkSealReasonBlockNum:
+		logger.Info("reached maximum number of blocks in chunk",
+			"start block number", plan.chunk.Blocks[0].Header.Number,
+			"block count", len(plan.chunk.Blocks),
+		)
+		p.chunkFirstBlockTimeoutReached.Inc()
+	}
+
+	p.chunkTxNum.Set(float64(plan.NumTransactions))
+	p.totalL1CommitCalldataSize.Set(float64(plan.EstimatedL1CommitCalldataSize))
+	p.chunkEstimateL1CommitGas.Set(float64(plan.EstimatedL1CommitGas))
+	p.maxTxConsumption.Set(float64(plan.RowConsumptionMax))
+	p.chunkBlocksNum.Set(float64(len(plan.chunk.Blocks)))
+	return plan.chunk, nil
+}
+
+// planChunk selects the blocks of the next chunk, it returns nil if there is no pending block.
+func (p *ChunkProposer) planChunk(ctx context.Context) (*ChunkProposalPlan, error) {
+	logger := utils.Logger(ctx)
+	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -172,11 +550,19 @@ func (p *ChunkProposer) proposeChunk() (*encoding.Chunk, error) {
 	}
 
 	// select at most maxBlocksThisChunk blocks
-	blocks, err := p.l2BlockOrm.GetL2BlocksGEHeight(p.ctx, unchunkedBlockHeight, int(maxBlocksThisChunk))
+	blocks, err := p.l2BlockOrm.GetL2BlocksGEHeight(ctx, unchunkedBlockHeight, int(maxBlocksThisChunk))
 	if err != nil {
 		return nil, err
 	}
 
+	// the blocks after a missing block, e.g. a quarantined one, are not chunked until it is stored.
+	// the first chunk is not checked, it starts at the lowest stored block.
+	if unchunkedBlockHeight > 1 && len(blocks) > 0 && blocks[0].Header.Number.Uint64() != unchunkedBlockHeight {
+		logger.Warn("l2 block missing, waiting for it before proposing the next chunk", "number", unchunkedBlockHeight)
+		return nil, nil
+	}
+	blocks = contiguousBlocks(blocks)
+
 	if len(blocks) == 0 {
 		return nil, nil
 	}
@@ -204,10 +590,8 @@ func (p *ChunkProposer) proposeChunk() (*encoding.Chunk, error) {
 
 		totalOverEstimateL1CommitGas := uint64(p.gasCostIncreaseMultiplier * float64(totalL1CommitGas))
 
-		if totalTxNum > p.maxTxNumPerChunk ||
-			totalL1CommitCalldataSize > p.maxL1CommitCalldataSizePerChunk ||
-			totalOverEstimateL1CommitGas > p.maxL1CommitGasPerChunk ||
-			crcMax > p.maxRowConsumptionPerChunk {
+		sealReason := p.exceededChunkLimit(totalTxNum, totalL1CommitCalldataSize, totalOverEstimateL1CommitGas, crcMax)
+		if sealReason != "" {
 			// Check if the first block breaks hard limits.
 			// If so, it indicates there are bugs in sequencer, manual fix is needed.
 			if i == 0 {
@@ -248,7 +632,7 @@ func (p *ChunkProposer) proposeChunk() (*encoding.Chunk, error) {
 				}
 			}
 
-			log.Debug("breaking limit condition in chunking",
+			logger.Debug("breaking limit condition in chunking",
 				"totalTxNum", totalTxNum,
 				"maxTxNumPerChunk", p.maxTxNumPerChunk,
 				"currentL1CommitCalldataSize", totalL1CommitCalldataSize,
@@ -259,72 +643,123 @@ func (p *ChunkProposer) proposeChunk() (*encoding.Chunk, error) {
 				"p.maxRowConsumptionPerChunk", p.maxRowConsumptionPerChunk)
 
 			chunk.Blocks = chunk.Blocks[:len(chunk.Blocks)-1]
+			return p.newChunkProposalPlan(ctx, &chunk, sealReason)
+		}
+	}
 
-			crcMax, err := chunk.CrcMax()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get crc max: %w", err)
-			}
+	currentTimeSec := uint64(time.Now().Unix())
+	if chunk.Blocks[0].Header.Time+p.chunkTimeoutSec < currentTimeSec {
+		return p.newChunkProposalPlan(ctx, &chunk, ChunkSealReasonTimeout)
+	}
+	if uint64(len(chunk.Blocks)) == maxBlocksThisChunk {
+		return p.newChunkProposalPlan(ctx, &chunk, ChunkSealReasonBlockNum)
+	}
+	return p.newChunkProposalPlan(ctx, &chunk, "")
+}
 
-			totalL1CommitCalldataSize, err := codecv0.EstimateChunkL1CommitCalldataSize(&chunk)
-			if err != nil {
-				return nil, fmt.Errorf("failed to estimate chunk L1 commit calldata size: %w", err)
-			}
+// exceededChunkLimit returns the reason of the first limit exceeded by a chunk, empty if the chunk is within all of them.
+func (p *ChunkProposer) exceededChunkLimit(totalTxNum, totalL1CommitCalldataSize, totalOverEstimateL1CommitGas, crcMax uint64) string {
+	switch {
+	case totalTxNum > p.maxTxNumPerChunk:
+		return ChunkSealReasonTxNum
+	case totalL1CommitCalldataSize > p.maxL1CommitCalldataSizePerChunk:
+		return ChunkSealReasonCalldataSize
+	case totalOverEstimateL1CommitGas > p.maxL1CommitGasPerChunk:
+		return ChunkSealReasonCommitGas
+	case crcMax > p.maxRowConsumptionPerChunk:
+		return ChunkSealReasonRowConsumption
+	}
+	return ""
+}
 
-			totalL1CommitGas, err := codecv0.EstimateChunkL1CommitGas(&chunk)
-			if err != nil {
-				return nil, fmt.Errorf("failed to estimate chunk L1 commit gas: %w", err)
-			}
+// ChunkSimulationResult is the estimates of a chunk made of a list of blocks, and whether the proposer would seal
+// the chunk before all the blocks fit in.
+type ChunkSimulationResult struct {
+	EstimatedGas           uint64 `json:"estimated_gas"`
+	EstimatedCalldataBytes uint64 `json:"estimated_calldata_bytes"`
+	// The first limit exceeded by the chunk, empty if it is within all of them.
+	SealReason string `json:"seal_reason,omitempty"`
+	WouldSeal  bool   `json:"would_seal"`
+}
 
-			p.chunkTxNum.Set(float64(chunk.NumTransactions()))
-			p.totalL1CommitCalldataSize.Set(float64(totalL1CommitCalldataSize))
-			p.chunkEstimateL1CommitGas.Set(float64(totalL1CommitGas))
-			p.maxTxConsumption.Set(float64(crcMax))
-			p.chunkBlocksNum.Set(float64(len(chunk.Blocks)))
-			return &chunk, nil
-		}
+// SimulateChunk checks a chunk made of all the blocks against the limits of the proposer, for the tooling evaluating
+// a proposer config offline. The commit gas is the codec estimate, the commit gas limit is checked against it
+// increased by the gas cost multiplier. The timeout is not checked, and neither the db nor the l1 node is used.
+func (p *ChunkProposer) SimulateChunk(blocks []*encoding.Block) (*ChunkSimulationResult, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("no block to simulate")
 	}
 
-	currentTimeSec := uint64(time.Now().Unix())
-	if chunk.Blocks[0].Header.Time+p.chunkTimeoutSec < currentTimeSec ||
-		uint64(len(chunk.Blocks)) == maxBlocksThisChunk {
-		if chunk.Blocks[0].Header.Time+p.chunkTimeoutSec < currentTimeSec {
-			log.Warn("first block timeout",
-				"block number", chunk.Blocks[0].Header.Number,
-				"block timestamp", chunk.Blocks[0].Header.Time,
-				"current time", currentTimeSec,
-			)
-		} else {
-			log.Info("reached maximum number of blocks in chunk",
-				"start block number", chunk.Blocks[0].Header.Number,
-				"block count", len(chunk.Blocks),
-			)
-		}
+	chunk := &encoding.Chunk{Blocks: blocks}
+	crcMax, err := chunk.CrcMax()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crc max: %w", err)
+	}
+	totalL1CommitCalldataSize, err := codecv0.EstimateChunkL1CommitCalldataSize(chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate chunk L1 commit calldata size: %w", err)
+	}
+	totalL1CommitGas, err := codecv0.EstimateChunkL1CommitGas(chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate chunk L1 commit gas: %w", err)
+	}
+	totalOverEstimateL1CommitGas := uint64(p.gasCostIncreaseMultiplier * float64(totalL1CommitGas))
 
-		crcMax, err := chunk.CrcMax()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get crc max: %w", err)
-		}
+	sealReason := p.exceededChunkLimit(chunk.NumTransactions(), totalL1CommitCalldataSize, totalOverEstimateL1CommitGas, crcMax)
+	if sealReason == "" && uint64(len(blocks)) > p.maxBlockNumPerChunk {
+		sealReason = ChunkSealReasonBlockNum
+	}
+	return &ChunkSimulationResult{
+		EstimatedGas:           totalL1CommitGas,
+		EstimatedCalldataBytes: totalL1CommitCalldataSize,
+		SealReason:             sealReason,
+		WouldSeal:              sealReason != "",
+	}, nil
+}
 
-		totalL1CommitCalldataSize, err := codecv0.EstimateChunkL1CommitCalldataSize(&chunk)
-		if err != nil {
-			return nil, fmt.Errorf("failed to estimate chunk L1 commit calldata size: %w", err)
+// newChunkProposalPlan returns the plan of the chunk, a sealed chunk is split by commit gas first.
+func (p *ChunkProposer) newChunkProposalPlan(ctx context.Context, chunk *encoding.Chunk, sealReason string) (*ChunkProposalPlan, error) {
+	if sealReason != "" {
+		if err := p.splitChunkByCommitGas(ctx, chunk); err != nil {
+			return nil, err
 		}
+	}
 
-		totalL1CommitGas, err := codecv0.EstimateChunkL1CommitGas(&chunk)
-		if err != nil {
-			return nil, fmt.Errorf("failed to estimate chunk L1 commit gas: %w", err)
-		}
+	crcMax, err := chunk.CrcMax()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crc max: %w", err)
+	}
 
-		p.chunkFirstBlockTimeoutReached.Inc()
-		p.chunkTxNum.Set(float64(chunk.NumTransactions()))
-		p.totalL1CommitCalldataSize.Set(float64(totalL1CommitCalldataSize))
-		p.chunkEstimateL1CommitGas.Set(float64(totalL1CommitGas))
-		p.maxTxConsumption.Set(float64(crcMax))
-		p.chunkBlocksNum.Set(float64(len(chunk.Blocks)))
-		return &chunk, nil
+	totalL1CommitCalldataSize, err := codecv0.EstimateChunkL1CommitCalldataSize(chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate chunk L1 commit calldata size: %w", err)
+	}
+
+	totalL1CommitGas, err := codecv0.EstimateChunkL1CommitGas(chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate chunk L1 commit gas: %w", err)
 	}
 
-	log.Debug("pending blocks do not reach one of the constraints or contain a timeout block")
-	p.chunkBlocksProposeNotEnoughTotal.Inc()
-	return nil, nil
+	plan := &ChunkProposalPlan{
+		NumTransactions:               chunk.NumTransactions(),
+		EstimatedL1CommitGas:          totalL1CommitGas,
+		EstimatedL1CommitCalldataSize: totalL1CommitCalldataSize,
+		RowConsumptionMax:             crcMax,
+		SealReason:                    sealReason,
+		chunk:                         chunk,
+	}
+	for _, block := range chunk.Blocks {
+		plan.CandidateBlocks = append(plan.CandidateBlocks, block.Header.Number.Uint64())
+	}
+	return plan, nil
+}
+
+// contiguousBlocks returns the longest prefix of blocks with consecutive numbers.
+func contiguousBlocks(blocks []*encoding.Block) []*encoding.Block {
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i].Header.Number.Uint64() != blocks[i-1].Header.Number.Uint64()+1 {
+			return blocks[:i]
+		}
+	}
+	return blocks
 }
@@ -2,14 +2,25 @@ package watcher
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"math/big"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 
 	"scroll-tech/common/database"
+	"scroll-tech/common/types"
 	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
 
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
@@ -198,3 +209,293 @@ func testChunkProposerLimits(t *testing.T) {
 		})
 	}
 }
+
+func testChunkProposerCommitGasLimit(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+
+	// the parent batch of the estimated commitBatch calls, once committed.
+	batchOrm := orm.NewBatch(db)
+	parentBatch, err := batchOrm.InsertBatch(context.Background(), &encoding.Batch{
+		Chunks: []*encoding.Chunk{{Blocks: []*encoding.Block{block1}}},
+	})
+	assert.NoError(t, err)
+
+	newChunkProposer := func(maxChunkGasLimit uint64) *ChunkProposer {
+		return NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+			MaxBlockNumPerChunk:             2,
+			MaxTxNumPerChunk:                10000,
+			MaxL1CommitGasPerChunk:          50000000000,
+			MaxL1CommitCalldataSizePerChunk: 1000000,
+			MaxRowConsumptionPerChunk:       1000000,
+			ChunkTimeoutSec:                 300,
+			GasCostIncreaseMultiplier:       1.2,
+			MaxChunkGasLimit:                maxChunkGasLimit,
+		}, &params.ChainConfig{}, db, nil)
+	}
+
+	// the mock L1 estimates the commit gas from the calldata size.
+	l1Client := &ethclient.Client{}
+	var estimateGasCalls int
+	patches := gomonkey.ApplyMethodFunc(l1Client, "EstimateGas", func(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+		estimateGasCalls++
+		return uint64(len(msg.Data)) * 16, nil
+	})
+	defer patches.Reset()
+
+	cp := newChunkProposer(0)
+	cp.SetL1CommitGasEstimator(l1Client, common.Address{}, common.Address{})
+
+	// the batches not committed on L1 are not used as the parent batch.
+	_, err = cp.estimateCommitGas(context.Background(), &encoding.Chunk{Blocks: []*encoding.Block{block1}})
+	assert.Error(t, err)
+	assert.Zero(t, estimateGasCalls)
+	assert.NoError(t, batchOrm.UpdateRollupStatus(context.Background(), parentBatch.Hash, types.RollupCommitted))
+
+	gasOneBlock, err := cp.estimateCommitGas(context.Background(), &encoding.Chunk{Blocks: []*encoding.Block{block1}})
+	assert.NoError(t, err)
+	gasTwoBlocks, err := cp.estimateCommitGas(context.Background(), &encoding.Chunk{Blocks: []*encoding.Block{block1, block2}})
+	assert.NoError(t, err)
+	assert.Less(t, gasOneBlock, gasTwoBlocks)
+	assert.Equal(t, 2, estimateGasCalls)
+
+	// the estimates are cached.
+	_, err = cp.estimateCommitGas(context.Background(), &encoding.Chunk{Blocks: []*encoding.Block{block1, block2}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, estimateGasCalls)
+
+	// the first block exceeds the limit, no chunk is proposed.
+	cp = newChunkProposer(gasOneBlock - 1)
+	cp.SetL1CommitGasEstimator(l1Client, common.Address{}, common.Address{})
+	cp.TryProposeChunk()
+
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 0)
+
+	// the two-block chunk exceeds the limit and is split.
+	cp = newChunkProposer(gasTwoBlocks - 1)
+	cp.SetL1CommitGasEstimator(l1Client, common.Address{}, common.Address{})
+	cp.TryProposeChunk()
+
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, block1.Header.Number.Uint64(), chunks[0].StartBlockNumber)
+	assert.Equal(t, block1.Header.Number.Uint64(), chunks[0].EndBlockNumber)
+
+	gas, err := cp.EstimateCommitGas(context.Background(), chunks[0])
+	assert.NoError(t, err)
+	assert.Equal(t, gasOneBlock, gas)
+
+	// the chunk is kept as is if an estimation fails while splitting it, as if the first estimation failed.
+	patches.ApplyMethodFunc(l1Client, "EstimateGas", func(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+		gas := uint64(len(msg.Data)) * 16
+		if gas < gasTwoBlocks {
+			return 0, errors.New("estimate gas failed")
+		}
+		return gas, nil
+	})
+	cp = newChunkProposer(gasTwoBlocks - 1)
+	cp.SetL1CommitGasEstimator(l1Client, common.Address{}, common.Address{})
+	chunk := &encoding.Chunk{Blocks: []*encoding.Block{block1, block2}}
+	assert.NoError(t, cp.splitChunkByCommitGas(context.Background(), chunk))
+	assert.Len(t, chunk.Blocks, 2)
+}
+
+func testChunkProposerReproposeFailed(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+	batches := prepareReorgDetectorDB(t, db)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             100,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+	}, &params.ChainConfig{}, db, nil)
+
+	// No failed commit yet.
+	count, err := cp.ReproposeFailed(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	batchOrm := orm.NewBatch(db)
+	assert.NoError(t, batchOrm.UpdateRollupStatus(context.Background(), batches[1].Hash, types.RollupCommitFailed))
+
+	// The commit failed too recently.
+	count, err = cp.ReproposeFailed(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = cp.ReproposeFailed(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	batchCount, err := batchOrm.GetBatchCount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), batchCount)
+
+	chunks, err := orm.NewChunk(db).GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, block2.Header.Number.Uint64(), chunks[1].StartBlockNumber)
+	assert.Equal(t, block2.Header.Number.Uint64(), chunks[1].EndBlockNumber)
+	assert.Empty(t, chunks[1].BatchHash)
+}
+
+func testChunkProposerChunkStats(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+	prepareReorgDetectorDB(t, db)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{}, &params.ChainConfig{}, db, nil)
+
+	chunks, err := orm.NewChunk(db).GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+
+	report, err := cp.ChunkStats(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, len(chunks), report.ChunkCount)
+	assert.Equal(t, uint64(1), report.BlockCount.Min)
+
+	report, err = cp.ChunkStats(context.Background(), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.ChunkCount)
+	assert.Equal(t, ChunkStatsSummary{}, report.TxCount)
+}
+
+func testChunkProposerDumpProposalPlan(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	assert.NoError(t, l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2}))
+
+	newChunkProposer := func(maxBlockNum uint64, chunkTimeoutSec uint64) *ChunkProposer {
+		return NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+			MaxBlockNumPerChunk:             maxBlockNum,
+			MaxTxNumPerChunk:                10000,
+			MaxL1CommitGasPerChunk:          50000000000,
+			MaxL1CommitCalldataSizePerChunk: 1000000,
+			MaxRowConsumptionPerChunk:       1000000,
+			ChunkTimeoutSec:                 chunkTimeoutSec,
+			GasCostIncreaseMultiplier:       1.2,
+		}, &params.ChainConfig{}, db, nil)
+	}
+
+	// No limit reached, the pending blocks are not sealed.
+	plan, err := newChunkProposer(100, 1000000000000).DumpProposalPlan(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, plan)
+	assert.Empty(t, plan.SealReason)
+	assert.Equal(t, []uint64{block1.Header.Number.Uint64(), block2.Header.Number.Uint64()}, plan.CandidateBlocks)
+	assert.NotZero(t, plan.EstimatedL1CommitGas)
+	assert.NotZero(t, plan.EstimatedL1CommitCalldataSize)
+
+	plan, err = newChunkProposer(1, 1000000000000).DumpProposalPlan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ChunkSealReasonBlockNum, plan.SealReason)
+	assert.Equal(t, []uint64{block1.Header.Number.Uint64()}, plan.CandidateBlocks)
+
+	cp := newChunkProposer(100, 0)
+	plan, err = cp.DumpProposalPlan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ChunkSealReasonTimeout, plan.SealReason)
+	assert.Len(t, plan.CandidateBlocks, 2)
+
+	// The dry run does not propose the chunk.
+	chunks, err := orm.NewChunk(db).GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	cp.TryProposeChunk()
+	plan, err = cp.DumpProposalPlan(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, plan)
+}
+
+func TestContiguousBlocks(t *testing.T) {
+	blocks := func(numbers ...int64) []*encoding.Block {
+		var blocks []*encoding.Block
+		for _, number := range numbers {
+			blocks = append(blocks, &encoding.Block{Header: &gethTypes.Header{Number: big.NewInt(number)}})
+		}
+		return blocks
+	}
+	assert.Empty(t, contiguousBlocks(nil))
+	assert.Len(t, contiguousBlocks(blocks(5)), 1)
+	assert.Len(t, contiguousBlocks(blocks(5, 6, 7)), 3)
+	assert.Len(t, contiguousBlocks(blocks(5, 6, 8, 9)), 2)
+	assert.Len(t, contiguousBlocks(blocks(5, 7)), 1)
+}
+
+func TestChunkProposerSimulateChunk(t *testing.T) {
+	var blocks []*encoding.Block
+	for _, path := range []string{"../../../testdata/blockTrace_02.json", "../../../testdata/blockTrace_03.json"} {
+		trace, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		block := &encoding.Block{}
+		assert.NoError(t, json.Unmarshal(trace, block))
+		blocks = append(blocks, block)
+	}
+	chunk := &encoding.Chunk{Blocks: blocks}
+	crcMax, err := chunk.CrcMax()
+	assert.NoError(t, err)
+
+	// the limits are exactly reached by the chunk.
+	limits := config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:       uint64(len(blocks)),
+		MaxTxNumPerChunk:          chunk.NumTransactions(),
+		MaxRowConsumptionPerChunk: crcMax,
+		GasCostIncreaseMultiplier: 1,
+	}
+	limits.MaxL1CommitGasPerChunk, err = codecv0.EstimateChunkL1CommitGas(chunk)
+	assert.NoError(t, err)
+	limits.MaxL1CommitCalldataSizePerChunk, err = codecv0.EstimateChunkL1CommitCalldataSize(chunk)
+	assert.NoError(t, err)
+
+	simulate := func(cfg config.ChunkProposerConfig) *ChunkSimulationResult {
+		cp := NewChunkProposer(context.Background(), &cfg, &params.ChainConfig{}, nil, nil)
+		result, err := cp.SimulateChunk(blocks)
+		assert.NoError(t, err)
+		return result
+	}
+
+	result := simulate(limits)
+	assert.False(t, result.WouldSeal)
+	assert.Empty(t, result.SealReason)
+	assert.Equal(t, limits.MaxL1CommitGasPerChunk, result.EstimatedGas)
+	assert.Equal(t, limits.MaxL1CommitCalldataSizePerChunk, result.EstimatedCalldataBytes)
+
+	tests := []struct {
+		reason string
+		exceed func(cfg *config.ChunkProposerConfig)
+	}{
+		{ChunkSealReasonBlockNum, func(cfg *config.ChunkProposerConfig) { cfg.MaxBlockNumPerChunk-- }},
+		{ChunkSealReasonTxNum, func(cfg *config.ChunkProposerConfig) { cfg.MaxTxNumPerChunk-- }},
+		{ChunkSealReasonCalldataSize, func(cfg *config.ChunkProposerConfig) { cfg.MaxL1CommitCalldataSizePerChunk-- }},
+		{ChunkSealReasonCommitGas, func(cfg *config.ChunkProposerConfig) { cfg.MaxL1CommitGasPerChunk-- }},
+		{ChunkSealReasonCommitGas, func(cfg *config.ChunkProposerConfig) { cfg.GasCostIncreaseMultiplier = 1.2 }},
+		{ChunkSealReasonRowConsumption, func(cfg *config.ChunkProposerConfig) { cfg.MaxRowConsumptionPerChunk-- }},
+	}
+	for _, tt := range tests {
+		cfg := limits
+		tt.exceed(&cfg)
+		result = simulate(cfg)
+		assert.True(t, result.WouldSeal, tt.reason)
+		assert.Equal(t, tt.reason, result.SealReason)
+	}
+
+	cp := NewChunkProposer(context.Background(), &limits, &params.ChainConfig{}, nil, nil)
+	_, err = cp.SimulateChunk(nil)
+	assert.Error(t, err)
+}
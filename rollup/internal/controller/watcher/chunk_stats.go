@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ChunkStatsSummary is the distribution of a chunk metric over the proposed chunks.
+type ChunkStatsSummary struct {
+	Min  uint64  `json:"min"`
+	Max  uint64  `json:"max"`
+	Mean float64 `json:"mean"`
+	P95  uint64  `json:"p95"`
+}
+
+// ChunkStatsReport is the aggregate statistics of the chunks proposed since a given time.
+type ChunkStatsReport struct {
+	Since      time.Time         `json:"since"`
+	ChunkCount int               `json:"chunk_count"`
+	TxCount    ChunkStatsSummary `json:"tx_count"`
+	GasUsed    ChunkStatsSummary `json:"gas_used"`
+	BlockCount ChunkStatsSummary `json:"block_count"`
+}
+
+// ChunkStats returns the statistics of the l2 tx count, the l2 gas used and the block count of the chunks proposed after since.
+func (p *ChunkProposer) ChunkStats(ctx context.Context, since time.Time) (*ChunkStatsReport, error) {
+	chunks, err := p.chunkOrm.GetChunksCreatedAfter(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks created after %v: %w", since, err)
+	}
+
+	txCounts := make([]uint64, len(chunks))
+	gasUsed := make([]uint64, len(chunks))
+	blockCounts := make([]uint64, len(chunks))
+	for i, chunk := range chunks {
+		txCounts[i] = chunk.TotalL2TxNum
+		gasUsed[i] = chunk.TotalL2TxGas
+		blockCounts[i] = chunk.EndBlockNumber - chunk.StartBlockNumber + 1
+	}
+
+	return &ChunkStatsReport{
+		Since:      since,
+		ChunkCount: len(chunks),
+		TxCount:    summarizeChunkStats(txCounts),
+		GasUsed:    summarizeChunkStats(gasUsed),
+		BlockCount: summarizeChunkStats(blockCounts),
+	}, nil
+}
+
+// summarizeChunkStats returns the summary of values, the p95 is computed with the nearest-rank method.
+// values is sorted in place.
+func summarizeChunkStats(values []uint64) ChunkStatsSummary {
+	if len(values) == 0 {
+		return ChunkStatsSummary{}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	// nearest rank: ceil(0.95 * n), 1-based
+	rank := (95*len(values) + 99) / 100
+	return ChunkStatsSummary{
+		Min:  values[0],
+		Max:  values[len(values)-1],
+		Mean: sum / float64(len(values)),
+		P95:  values[rank-1],
+	}
+}
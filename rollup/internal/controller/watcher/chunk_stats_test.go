@@ -0,0 +1,20 @@
+package watcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeChunkStats(t *testing.T) {
+	assert.Equal(t, ChunkStatsSummary{}, summarizeChunkStats(nil))
+	assert.Equal(t, ChunkStatsSummary{Min: 7, Max: 7, Mean: 7, P95: 7}, summarizeChunkStats([]uint64{7}))
+
+	values := make([]uint64, 0, 100)
+	for i := 100; i >= 1; i-- {
+		values = append(values, uint64(i))
+	}
+	assert.Equal(t, ChunkStatsSummary{Min: 1, Max: 100, Mean: 50.5, P95: 95}, summarizeChunkStats(values))
+
+	assert.Equal(t, ChunkStatsSummary{Min: 1, Max: 10, Mean: 4, P95: 10}, summarizeChunkStats([]uint64{10, 1, 1}))
+}
@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// l1EthClient is the subset of ethclient.Client used by L1WatcherClient, implemented by ethclient.Client
+// and instrumentedEthClient.
+type l1EthClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]gethTypes.Log, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error)
+}
+
+// instrumentedEthClient records the duration of the rpc calls of client in a histogram labeled by rpc method.
+type instrumentedEthClient struct {
+	client   l1EthClient
+	duration *prometheus.HistogramVec
+}
+
+func newInstrumentedEthClient(client l1EthClient, duration *prometheus.HistogramVec) *instrumentedEthClient {
+	return &instrumentedEthClient{client: client, duration: duration}
+}
+
+// observe records the time elapsed since start for method.
+func (c *instrumentedEthClient) observe(method string, start time.Time) {
+	c.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// BlockNumber calls eth_blockNumber.
+func (c *instrumentedEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	defer c.observe("eth_blockNumber", time.Now())
+	return c.client.BlockNumber(ctx)
+}
+
+// HeaderByNumber calls eth_getBlockByNumber.
+func (c *instrumentedEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+	defer c.observe("eth_getBlockByNumber", time.Now())
+	return c.client.HeaderByNumber(ctx, number)
+}
+
+// FilterLogs calls eth_getLogs.
+func (c *instrumentedEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]gethTypes.Log, error) {
+	defer c.observe("eth_getLogs", time.Now())
+	return c.client.FilterLogs(ctx, q)
+}
+
+// TransactionReceipt calls eth_getTransactionReceipt.
+func (c *instrumentedEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error) {
+	defer c.observe("eth_getTransactionReceipt", time.Now())
+	return c.client.TransactionReceipt(ctx, txHash)
+}
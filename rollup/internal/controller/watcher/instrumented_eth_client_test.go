@@ -0,0 +1,71 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeL1EthClient answers the rpc calls without a node, FilterLogs fails.
+type fakeL1EthClient struct{}
+
+func (fakeL1EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 10, nil
+}
+
+func (fakeL1EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+	return &gethTypes.Header{Number: number}, nil
+}
+
+func (fakeL1EthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]gethTypes.Log, error) {
+	return nil, errors.New("query timeout")
+}
+
+func (fakeL1EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error) {
+	return &gethTypes.Receipt{TxHash: txHash}, nil
+}
+
+func TestInstrumentedEthClient(t *testing.T) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_rpc_duration_seconds"}, []string{"method"})
+	var client l1EthClient = newInstrumentedEthClient(fakeL1EthClient{}, duration)
+	ctx := context.Background()
+
+	number, err := client.BlockNumber(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), number)
+	for i := int64(0); i < 2; i++ {
+		header, err := client.HeaderByNumber(ctx, big.NewInt(i))
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(i), header.Number)
+	}
+	// the failed calls are recorded too.
+	_, err = client.FilterLogs(ctx, ethereum.FilterQuery{})
+	assert.EqualError(t, err, "query timeout")
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash("0x1"))
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0x1"), receipt.TxHash)
+
+	assert.Equal(t, 4, testutil.CollectAndCount(duration))
+	counts := map[string]uint64{"eth_blockNumber": 1, "eth_getBlockByNumber": 2, "eth_getLogs": 1, "eth_getTransactionReceipt": 1}
+	for method, count := range counts {
+		histogram := duration.WithLabelValues(method).(prometheus.Histogram)
+		assert.Equal(t, count, histogramSampleCount(t, histogram), method)
+	}
+}
+
+func histogramSampleCount(t *testing.T, histogram prometheus.Histogram) uint64 {
+	metric := make(chan prometheus.Metric, 1)
+	histogram.Collect(metric)
+	var m dto.Metric
+	assert.NoError(t, (<-metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
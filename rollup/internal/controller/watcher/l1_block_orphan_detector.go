@@ -0,0 +1,159 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// l1BlockChainValidationInterval is the number of inserted l1 blocks between two validations of the stored chain,
+// it is also the number of the latest stored blocks validated at startup.
+const l1BlockChainValidationInterval = 100
+
+// errL1WatcherHalted is returned by the watcher halted on a deep reorg.
+var errL1WatcherHalted = errors.New("l1 watcher halted on a deep reorg, waiting for acknowledgement")
+
+// SetMaxExpectedReorgDepth sets the max number of stored blocks replaced by a reorg, a deeper reorg is alerted and,
+// if halt is set, halts the watcher until AcknowledgeDeepReorg is called. The depth is not checked if 0.
+func (w *L1WatcherClient) SetMaxExpectedReorgDepth(depth uint64, halt bool) {
+	w.maxExpectedReorgDepth = depth
+	w.haltOnDeepReorg = halt
+}
+
+// AcknowledgeDeepReorg resumes the watcher halted on a deep reorg.
+func (w *L1WatcherClient) AcknowledgeDeepReorg() {
+	if w.halted.CompareAndSwap(true, false) {
+		log.Warn("Deep l1 reorg acknowledged, resume l1 watcher")
+	}
+}
+
+// validateBlockChain checks the parent hash continuity of the l1 blocks stored since the last validation, and
+// back-fills the stored blocks from the first orphan with the canonical blocks. It is retried by the next
+// FetchBlockHeader if it fails.
+func (w *L1WatcherClient) validateBlockChain() {
+	from, to := w.chainValidatedHeight, w.processedBlockHeight
+	if from > to {
+		from = to
+	}
+
+	orphans, err := w.l1BlockOrm.ValidateParentHashChain(w.ctx, from, to)
+	if err != nil {
+		log.Warn("Failed to validate the parent hash chain of the l1 blocks", "from", from, "to", to, "err", err)
+		return
+	}
+	if len(orphans) > 0 {
+		w.metrics.rollupL1WatcherOrphanBlocksTotal.Add(float64(len(orphans)))
+		log.Warn("Detected orphan l1 blocks", "from", from, "to", to, "orphans", orphans)
+		replaced, err := w.backfillBlocks(orphans[0]-1, to)
+		if err != nil {
+			log.Warn("Failed to back-fill the orphan l1 blocks", "from", orphans[0]-1, "to", to, "err", err)
+			return
+		}
+		log.Info("Back-filled the orphan l1 blocks", "from", orphans[0]-1, "to", to, "replaced", len(replaced))
+		w.checkReorgDepth(orphans, replaced)
+	}
+
+	w.chainValidatedHeight = to
+	w.blocksSinceChainValidation = 0
+}
+
+// checkReorgDepth alerts the reorgs replacing more stored blocks than maxExpectedReorgDepth, and halts the watcher
+// if haltOnDeepReorg is set.
+func (w *L1WatcherClient) checkReorgDepth(orphans []uint64, replaced []orm.L1Block) {
+	depth := uint64(len(replaced))
+	if w.maxExpectedReorgDepth == 0 || depth <= w.maxExpectedReorgDepth {
+		return
+	}
+
+	replacedHeights := make([]uint64, 0, len(replaced))
+	replacedHashes := make([]string, 0, len(replaced))
+	for _, block := range replaced {
+		replacedHeights = append(replacedHeights, block.Number)
+		replacedHashes = append(replacedHashes, block.Hash)
+	}
+	w.metrics.rollupL1WatcherDeepReorgDetected.Inc()
+	log.Error("Detected l1 reorg deeper than expected", "depth", depth, "max expected depth", w.maxExpectedReorgDepth,
+		"orphans", orphans, "replaced heights", replacedHeights, "replaced hashes", replacedHashes, "halt", w.haltOnDeepReorg)
+	if w.haltOnDeepReorg {
+		w.halted.Store(true)
+	}
+}
+
+// backfillBlocks replaces the stored l1 blocks from height from with the canonical blocks in [from, to] of the l1 node,
+// and returns the stored blocks replaced by a different block, i.e. reorged. The reorged blocks right below from are
+// replaced too, up to l1BlockChainValidationInterval blocks. As with a reorg, the gas oracle status of the back-filled
+// blocks is reset to pending.
+func (w *L1WatcherClient) backfillBlocks(from, to uint64) ([]orm.L1Block, error) {
+	var lowest uint64
+	if from > l1BlockChainValidationInterval {
+		lowest = from - l1BlockChainValidationInterval
+	}
+	stored, err := w.l1BlockOrm.GetL1Blocks(w.ctx, map[string]interface{}{"number >= ?": lowest, "number <= ?": to})
+	if err != nil {
+		return nil, err
+	}
+	storedBlocks := make(map[uint64]orm.L1Block, len(stored))
+	for _, block := range stored {
+		storedBlocks[block.Number] = block
+	}
+
+	var replaced []orm.L1Block
+	fetchBlock := func(height uint64) (*orm.L1Block, error) {
+		header, err := w.client.HeaderByNumber(w.ctx, new(big.Int).SetUint64(height))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block header, height: %d: %w", height, err)
+		}
+		if header == nil {
+			return nil, fmt.Errorf("received nil block header, height: %d", height)
+		}
+		var baseFee uint64
+		if header.BaseFee != nil {
+			baseFee = header.BaseFee.Uint64()
+		}
+		block := &orm.L1Block{
+			Number:          header.Number.Uint64(),
+			Hash:            header.Hash().String(),
+			ParentHash:      header.ParentHash.String(),
+			BaseFee:         baseFee,
+			GasOracleStatus: int16(types.GasOraclePending),
+		}
+		if storedBlock, ok := storedBlocks[height]; ok && storedBlock.Hash != block.Hash {
+			replaced = append(replaced, storedBlock)
+		}
+		return block, nil
+	}
+
+	l1Blocks := make([]orm.L1Block, 0, to-from+1)
+	for height := from; height <= to; height++ {
+		block, err := fetchBlock(height)
+		if err != nil {
+			return nil, err
+		}
+		l1Blocks = append(l1Blocks, *block)
+	}
+	// the reorged blocks are linked to each other, so only the first one is an orphan, walk down to the fork.
+	for height := from; height > lowest; height-- {
+		if storedBlock, ok := storedBlocks[height]; !ok || storedBlock.Hash == l1Blocks[0].Hash {
+			break
+		}
+		if _, ok := storedBlocks[height-1]; !ok {
+			break
+		}
+		block, err := fetchBlock(height - 1)
+		if err != nil {
+			return nil, err
+		}
+		l1Blocks = append([]orm.L1Block{*block}, l1Blocks...)
+	}
+
+	if err = w.l1BlockOrm.InsertL1Blocks(w.ctx, l1Blocks); err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
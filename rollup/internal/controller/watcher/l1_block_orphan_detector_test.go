@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// mockL1ChainClient serves the headers of a chain of blocks starting from block 1.
+type mockL1ChainClient struct {
+	fakeL1EthClient
+	headers []*gethTypes.Header
+}
+
+func (c *mockL1ChainClient) HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+	return c.headers[number.Uint64()-1], nil
+}
+
+func testL1WatcherValidateBlockChain(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	var headers []*gethTypes.Header
+	for i := int64(1); i <= 5; i++ {
+		header := &gethTypes.Header{Number: big.NewInt(i), BaseFee: big.NewInt(100)}
+		if len(headers) > 0 {
+			header.ParentHash = headers[len(headers)-1].Hash()
+		}
+		headers = append(headers, header)
+	}
+	stored := func(header *gethTypes.Header) orm.L1Block {
+		return orm.L1Block{Number: header.Number.Uint64(), Hash: header.Hash().String(), ParentHash: header.ParentHash.String()}
+	}
+	// block 3 is not linked to block 2, block 4 is missing.
+	l1BlockOrm := orm.NewL1Block(db)
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []orm.L1Block{
+		stored(headers[0]),
+		stored(headers[1]),
+		{Number: 3, Hash: common.HexToHash("0x3").String(), ParentHash: common.HexToHash("0x2").String()},
+		stored(headers[4]),
+	}))
+
+	watcher := NewL1WatcherClient(context.Background(), nil, 0, rpc.LatestBlockNumber, common.Address{}, common.Address{}, db, nil)
+	watcher.client = &mockL1ChainClient{headers: headers}
+	assert.Equal(t, uint64(5), watcher.ProcessedBlockHeight())
+
+	orphansBefore := testutil.ToFloat64(watcher.metrics.rollupL1WatcherOrphanBlocksTotal)
+	watcher.validateBlockChain()
+	assert.Equal(t, orphansBefore+2, testutil.ToFloat64(watcher.metrics.rollupL1WatcherOrphanBlocksTotal))
+	assert.Equal(t, uint64(5), watcher.chainValidatedHeight)
+	assert.Equal(t, 0, watcher.blocksSinceChainValidation)
+
+	// the orphan blocks are back-filled with the canonical ones.
+	orphans, err := l1BlockOrm.ValidateParentHashChain(context.Background(), 1, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 5)
+	for i, block := range blocks {
+		assert.Equal(t, headers[i].Hash().String(), block.Hash)
+	}
+}
+
+func testL1WatcherDeepReorg(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	var headers, reorgedHeaders []*gethTypes.Header
+	for i := int64(1); i <= 5; i++ {
+		header := &gethTypes.Header{Number: big.NewInt(i)}
+		reorgedHeader := &gethTypes.Header{Number: big.NewInt(i), Extra: []byte("reorged")}
+		if len(headers) > 0 {
+			header.ParentHash = headers[len(headers)-1].Hash()
+			reorgedHeader.ParentHash = reorgedHeaders[len(reorgedHeaders)-1].Hash()
+		}
+		headers = append(headers, header)
+		reorgedHeaders = append(reorgedHeaders, reorgedHeader)
+	}
+	// blocks 2 to 4 are reorged, the reorged blocks are linked to each other so only block 5 is an orphan.
+	reorgedHeaders[1].ParentHash = headers[0].Hash()
+	stored := func(header *gethTypes.Header) orm.L1Block {
+		return orm.L1Block{Number: header.Number.Uint64(), Hash: header.Hash().String(), ParentHash: header.ParentHash.String()}
+	}
+	l1BlockOrm := orm.NewL1Block(db)
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []orm.L1Block{
+		stored(headers[0]),
+		stored(reorgedHeaders[1]),
+		stored(reorgedHeaders[2]),
+		stored(reorgedHeaders[3]),
+		stored(headers[4]),
+	}))
+
+	watcher := NewL1WatcherClient(context.Background(), nil, 0, rpc.LatestBlockNumber, common.Address{}, common.Address{}, db, nil)
+	watcher.client = &mockL1ChainClient{headers: headers}
+	watcher.SetMaxExpectedReorgDepth(2, true)
+
+	deepReorgsBefore := testutil.ToFloat64(watcher.metrics.rollupL1WatcherDeepReorgDetected)
+	watcher.validateBlockChain()
+	assert.Equal(t, deepReorgsBefore+1, testutil.ToFloat64(watcher.metrics.rollupL1WatcherDeepReorgDetected))
+
+	// all the reorged blocks are replaced.
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 5)
+	for i, block := range blocks {
+		assert.Equal(t, headers[i].Hash().String(), block.Hash)
+	}
+
+	// the watcher is halted until the reorg is acknowledged.
+	assert.ErrorIs(t, watcher.FetchBlockHeader(5), errL1WatcherHalted)
+	assert.ErrorIs(t, watcher.FetchContractEvent(), errL1WatcherHalted)
+	watcher.AcknowledgeDeepReorg()
+	assert.NoError(t, watcher.FetchBlockHeader(5))
+}
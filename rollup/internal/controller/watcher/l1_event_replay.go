@@ -0,0 +1,128 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// eventReplayClient serves the event logs of a file in place of the l1 node, e.g. to reproduce an incident offline.
+// Its head is the block of the last log, and its headers are synthetic: only their number is set.
+type eventReplayClient struct {
+	logs []gethTypes.Log
+	head uint64
+}
+
+// newEventReplayClient reads a JSON array of event logs in the format of the eth_getLogs responses.
+func newEventReplayClient(file string) (*eventReplayClient, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event replay file: %w", err)
+	}
+	var logs []gethTypes.Log
+	if err = json.Unmarshal(data, &logs); err != nil {
+		return nil, fmt.Errorf("failed to decode event replay file %s: %w", file, err)
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	c := &eventReplayClient{logs: logs}
+	if len(logs) > 0 {
+		c.head = logs[len(logs)-1].BlockNumber
+	}
+	return c, nil
+}
+
+// BlockNumber returns the block of the last log.
+func (c *eventReplayClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+// HeaderByNumber returns a header with only its number set, the head for nil and the block tags.
+func (c *eventReplayClient) HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+	if number == nil || number.Sign() < 0 {
+		return &gethTypes.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+	}
+	if number.Uint64() > c.head {
+		return nil, ethereum.NotFound
+	}
+	return &gethTypes.Header{Number: new(big.Int).Set(number)}, nil
+}
+
+// FilterLogs returns the logs of the file matching q, like eth_getLogs.
+func (c *eventReplayClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]gethTypes.Log, error) {
+	if q.BlockHash != nil {
+		return nil, fmt.Errorf("event replay does not support filtering by block hash")
+	}
+	fromBlock, toBlock := uint64(0), c.head
+	if q.FromBlock != nil {
+		fromBlock = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil && q.ToBlock.Sign() >= 0 {
+		toBlock = q.ToBlock.Uint64()
+	}
+
+	var logs []gethTypes.Log
+	for _, vLog := range c.logs {
+		if vLog.BlockNumber < fromBlock || vLog.BlockNumber > toBlock {
+			continue
+		}
+		if len(q.Addresses) > 0 && !containsAddress(q.Addresses, vLog.Address) {
+			continue
+		}
+		if !matchTopics(q.Topics, vLog.Topics) {
+			continue
+		}
+		logs = append(logs, vLog)
+	}
+	return logs, nil
+}
+
+// TransactionReceipt returns ethereum.NotFound, the file only holds event logs.
+func (c *eventReplayClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTopics reports whether topics match the topic filter of a query: each position matches any of its hashes,
+// an empty position matches anything.
+func matchTopics(filter [][]common.Hash, topics []common.Hash) bool {
+	if len(filter) > len(topics) {
+		return false
+	}
+	for i, sub := range filter {
+		if len(sub) == 0 {
+			continue
+		}
+		var match bool
+		for _, topic := range sub {
+			if topic == topics[i] {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
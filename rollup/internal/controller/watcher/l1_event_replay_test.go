@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+)
+
+// eventReplayFixture holds 3 QueueTransaction logs of the message queue at 0x0 in blocks 5 and 7,
+// and a log of another contract in block 6.
+const eventReplayFixture = "../../../testdata/l1_events_replay.json"
+
+func TestEventReplayClient(t *testing.T) {
+	ctx := context.Background()
+	client, err := newEventReplayClient(eventReplayFixture)
+	assert.NoError(t, err)
+
+	head, err := client.BlockNumber(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), head)
+
+	header, err := client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), header.Number.Uint64())
+	header, err = client.HeaderByNumber(ctx, big.NewInt(6))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), header.Number.Uint64())
+	_, err = client.HeaderByNumber(ctx, big.NewInt(8))
+	assert.ErrorIs(t, err, ethereum.NotFound)
+
+	messageQueue := common.HexToAddress("0x0")
+	query := ethereum.FilterQuery{
+		FromBlock: big.NewInt(0),
+		ToBlock:   big.NewInt(7),
+		Addresses: []common.Address{messageQueue},
+		Topics:    [][]common.Hash{{bridgeAbi.L1QueueTransactionEventSignature}},
+	}
+	logs, err := client.FilterLogs(ctx, query)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 3)
+	for _, vLog := range logs {
+		assert.Equal(t, messageQueue, vLog.Address)
+	}
+
+	query.FromBlock = big.NewInt(6)
+	logs, err = client.FilterLogs(ctx, query)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	// all the logs of the range match a query without addresses nor topics.
+	logs, err = client.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(6), ToBlock: big.NewInt(6)})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	query.Topics = [][]common.Hash{{bridgeAbi.L1CommitBatchEventSignature, bridgeAbi.L1FinalizeBatchEventSignature}}
+	logs, err = client.FilterLogs(ctx, query)
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+
+	_, err = client.TransactionReceipt(ctx, common.HexToHash("0x1000"))
+	assert.ErrorIs(t, err, ethereum.NotFound)
+
+	_, err = newEventReplayClient("not_exist.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// wsReconnectMinBackoff is the delay before the first reconnection of a dropped new heads subscription.
+	wsReconnectMinBackoff = time.Second
+	// wsReconnectMaxBackoff is the upper bound of the delay between two reconnections.
+	wsReconnectMaxBackoff = time.Minute
+)
+
+// headSubscriber is implemented by ethclient.Client.
+type headSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *gethTypes.Header) (ethereum.Subscription, error)
+	Close()
+}
+
+// dialHeadSubscriber connects to the websocket endpoint of a l1 node.
+func dialHeadSubscriber(ctx context.Context, endpoint string) (headSubscriber, error) {
+	client, err := rpc.DialWebsocket(ctx, endpoint, "")
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// SubscribeNewHeads subscribes to the new heads of the l1 node at the websocket endpoint, and signals them on the
+// returned channel, the heads not consumed yet are coalesced. A dropped subscription is reestablished with an
+// exponential backoff, so the caller must keep polling meanwhile. The subscription ends when ctx is done.
+func (w *L1WatcherClient) SubscribeNewHeads(ctx context.Context, endpoint string) <-chan struct{} {
+	return w.subscribeNewHeads(ctx, endpoint, dialHeadSubscriber, wsReconnectMinBackoff, wsReconnectMaxBackoff)
+}
+
+func (w *L1WatcherClient) subscribeNewHeads(ctx context.Context, endpoint string, dial func(context.Context, string) (headSubscriber, error), minBackoff, maxBackoff time.Duration) <-chan struct{} {
+	newHeads := make(chan struct{}, 1)
+	go func() {
+		backoff := minBackoff
+		for {
+			subscribed, err := w.forwardNewHeads(ctx, endpoint, dial, newHeads)
+			if ctx.Err() != nil {
+				return
+			}
+			// the backoff only grows while the node stays unreachable.
+			if subscribed {
+				backoff = minBackoff
+			}
+			log.Warn("L1 new heads subscription dropped, reconnecting", "endpoint", endpoint, "backoff", backoff, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			w.metrics.rollupL1WatcherWSReconnectsTotal.Inc()
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}()
+	return newHeads
+}
+
+// forwardNewHeads signals the new heads of a subscription until it fails, it returns whether it was subscribed.
+func (w *L1WatcherClient) forwardNewHeads(ctx context.Context, endpoint string, dial func(context.Context, string) (headSubscriber, error), newHeads chan<- struct{}) (bool, error) {
+	client, err := dial(ctx, endpoint)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+
+	headers := make(chan *gethTypes.Header, 16)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return false, err
+	}
+	defer sub.Unsubscribe()
+	log.Info("Subscribed to the l1 new heads", "endpoint", endpoint)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err = <-sub.Err():
+			return true, err
+		case header := <-headers:
+			log.Debug("Received l1 new head", "number", header.Number)
+			select {
+			case newHeads <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
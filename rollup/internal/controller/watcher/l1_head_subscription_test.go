@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSubscription fails with the error sent on errCh.
+type fakeSubscription struct {
+	errCh chan error
+	once  sync.Once
+}
+
+func (s *fakeSubscription) Unsubscribe() {
+	s.once.Do(func() { close(s.errCh) })
+}
+
+func (s *fakeSubscription) Err() <-chan error {
+	return s.errCh
+}
+
+// fakeHeadSubscriber hands its subscription channels to the test.
+type fakeHeadSubscriber struct {
+	subs chan *fakeHeadSub
+}
+
+type fakeHeadSub struct {
+	headers chan<- *gethTypes.Header
+	sub     *fakeSubscription
+}
+
+func (c *fakeHeadSubscriber) SubscribeNewHead(ctx context.Context, ch chan<- *gethTypes.Header) (ethereum.Subscription, error) {
+	sub := &fakeSubscription{errCh: make(chan error, 1)}
+	c.subs <- &fakeHeadSub{headers: ch, sub: sub}
+	return sub, nil
+}
+
+func (c *fakeHeadSubscriber) Close() {}
+
+func TestL1WatcherSubscribeNewHeads(t *testing.T) {
+	w := &L1WatcherClient{metrics: initL1WatcherMetrics(nil)}
+	client := &fakeHeadSubscriber{subs: make(chan *fakeHeadSub)}
+	var mu sync.Mutex
+	var dials int
+	dial := func(ctx context.Context, endpoint string) (headSubscriber, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials++
+		// the node is unreachable at the second dial.
+		if dials == 2 {
+			return nil, errors.New("connection refused")
+		}
+		return client, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reconnects := testutil.ToFloat64(w.metrics.rollupL1WatcherWSReconnectsTotal)
+	newHeads := w.subscribeNewHeads(ctx, "ws://localhost:8546", dial, time.Millisecond, 4*time.Millisecond)
+
+	sub := <-client.subs
+	sub.headers <- &gethTypes.Header{Number: big.NewInt(1)}
+	sub.headers <- &gethTypes.Header{Number: big.NewInt(2)}
+	select {
+	case <-newHeads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("new head not signaled")
+	}
+
+	// the subscription is reestablished after the connection drops, the dial failure included.
+	sub.sub.errCh <- errors.New("websocket: close 1006")
+	sub = <-client.subs
+	assert.Equal(t, reconnects+2, testutil.ToFloat64(w.metrics.rollupL1WatcherWSReconnectsTotal))
+	sub.headers <- &gethTypes.Header{Number: big.NewInt(3)}
+	select {
+	case <-newHeads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("new head not signaled after reconnection")
+	}
+
+	cancel()
+	select {
+	case <-sub.sub.errCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
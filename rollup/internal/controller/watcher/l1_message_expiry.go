@@ -0,0 +1,30 @@
+package watcher
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// SetMessageExpiryDuration sets the time in seconds after which the l1 messages still pending since they were saved
+// are marked as expired by FetchContractEvent. The messages do not expire if 0.
+func (w *L1WatcherClient) SetMessageExpiryDuration(ttlSec uint64) {
+	w.messageExpiryDuration = time.Duration(ttlSec) * time.Second
+}
+
+// expireOldMessages marks the l1 messages pending for longer than the expiry duration as expired, it is retried by
+// the next FetchContractEvent if it fails.
+func (w *L1WatcherClient) expireOldMessages() {
+	if w.messageExpiryDuration <= 0 {
+		return
+	}
+	expired, err := w.l1MessageOrm.ExpireOldMessages(w.ctx, w.messageExpiryDuration)
+	if err != nil {
+		log.Error("failed to expire old l1 messages", "ttl", w.messageExpiryDuration, "err", err)
+		return
+	}
+	if expired > 0 {
+		w.metrics.rollupL1MessageExpiredTotal.Add(float64(expired))
+		log.Warn("expired pending l1 messages", "count", expired, "ttl", w.messageExpiryDuration)
+	}
+}
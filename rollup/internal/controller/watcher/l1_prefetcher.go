@@ -0,0 +1,109 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
+
+	"scroll-tech/rollup/internal/utils"
+)
+
+// defaultL1PrefetchPollInterval is the interval the prefetcher waits for a new confirmed l1 block.
+const defaultL1PrefetchPollInterval = 3 * time.Second
+
+// l1HeaderClient is implemented by ethclient.Client.
+type l1HeaderClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error)
+}
+
+// l1HeaderPrefetcher fetches the headers of the confirmed l1 blocks ahead of the watcher, up to depth of them in parallel,
+// and delivers them in ascending order through buffer. The buffer holds at most depth headers, the prefetcher waits
+// for the watcher to consume them when it is full.
+type l1HeaderPrefetcher struct {
+	client        l1HeaderClient
+	confirmations rpc.BlockNumber
+	depth         uint64
+	pollInterval  time.Duration
+
+	buffer     chan *gethTypes.Header
+	queueDepth prometheus.Gauge
+}
+
+func newL1HeaderPrefetcher(client l1HeaderClient, confirmations rpc.BlockNumber, depth uint64, queueDepth prometheus.Gauge) *l1HeaderPrefetcher {
+	return &l1HeaderPrefetcher{
+		client:        client,
+		confirmations: confirmations,
+		depth:         depth,
+		pollInterval:  defaultL1PrefetchPollInterval,
+		buffer:        make(chan *gethTypes.Header, depth),
+		queueDepth:    queueDepth,
+	}
+}
+
+// run prefetches the headers from height next on until ctx is done. When the confirmed head is more than depth
+// blocks ahead, the older blocks are skipped, as the watcher only saves the latest block without prefetching.
+func (p *l1HeaderPrefetcher) run(ctx context.Context, next uint64) {
+	for {
+		head, err := utils.GetLatestConfirmedBlockNumber(ctx, p.client, p.confirmations)
+		if err != nil {
+			log.Warn("Failed to get latest confirmed l1 block number for prefetching", "err", err)
+		} else if head >= next {
+			if head-next >= p.depth {
+				next = head - p.depth + 1
+			}
+			headers, fetchErr := p.fetchHeaders(ctx, next, head)
+			if fetchErr == nil {
+				for _, header := range headers {
+					select {
+					case <-ctx.Done():
+						return
+					case p.buffer <- header:
+						p.queueDepth.Set(float64(len(p.buffer)))
+					}
+				}
+				next = head + 1
+				continue
+			}
+			log.Warn("Failed to prefetch l1 block headers", "from", next, "to", head, "err", fetchErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+// fetchHeaders fetches the headers of the blocks in [from, to] in parallel.
+func (p *l1HeaderPrefetcher) fetchHeaders(ctx context.Context, from, to uint64) ([]*gethTypes.Header, error) {
+	var eg errgroup.Group
+	headers := make([]*gethTypes.Header, to-from+1)
+	for i := range headers {
+		i := i
+		eg.Go(func() error {
+			number := from + uint64(i)
+			header, err := p.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				return fmt.Errorf("failed to get block header, height: %d: %w", number, err)
+			}
+			if header == nil {
+				return fmt.Errorf("received nil block header, height: %d", number)
+			}
+			headers[i] = header
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
@@ -0,0 +1,99 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockL1HeaderClient serves headers up to head, each header request takes delay.
+type mockL1HeaderClient struct {
+	head  atomic.Uint64
+	delay time.Duration
+}
+
+func (c *mockL1HeaderClient) BlockNumber(context.Context) (uint64, error) {
+	return c.head.Load(), nil
+}
+
+func (c *mockL1HeaderClient) HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(c.delay):
+	}
+	return &gethTypes.Header{Number: new(big.Int).Set(number)}, nil
+}
+
+func TestL1HeaderPrefetcher(t *testing.T) {
+	client := &mockL1HeaderClient{delay: 20 * time.Millisecond}
+	client.head.Store(100)
+	queueDepth := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_prefetch_queue_depth"})
+	prefetcher := newL1HeaderPrefetcher(client, rpc.LatestBlockNumber, 4, queueDepth)
+	prefetcher.pollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go prefetcher.run(ctx, 1)
+
+	// the buffer never holds more than depth headers while nobody consumes them.
+	assert.Eventually(t, func() bool { return len(prefetcher.buffer) == 4 }, time.Second, 10*time.Millisecond)
+	client.head.Store(200)
+	for i := 0; i < 20; i++ {
+		time.Sleep(10 * time.Millisecond)
+		assert.LessOrEqual(t, len(prefetcher.buffer), 4)
+		assert.LessOrEqual(t, testutil.ToFloat64(queueDepth), float64(4))
+	}
+	assert.Equal(t, 4, cap(prefetcher.buffer))
+
+	// the blocks more than depth below the head are skipped, the others are delivered in ascending order.
+	expected := []uint64{97, 98, 99, 100, 197, 198, 199, 200}
+	for _, number := range expected {
+		select {
+		case header := <-prefetcher.buffer:
+			assert.Equal(t, number, header.Number.Uint64())
+		case <-time.After(time.Second):
+			t.Fatalf("header %d not prefetched", number)
+		}
+	}
+}
+
+func TestL1WatcherTakePrefetchedHeaders(t *testing.T) {
+	header := func(number uint64, parentHash common.Hash) *gethTypes.Header {
+		return &gethTypes.Header{Number: new(big.Int).SetUint64(number), ParentHash: parentHash}
+	}
+	header11 := header(11, common.Hash{})
+	header12 := header(12, header11.Hash())
+	header13 := header(13, common.HexToHash("0x1")) // fetched before a reorg
+	header14 := header(14, header13.Hash())
+	header16 := header(16, common.Hash{})
+
+	w := &L1WatcherClient{
+		processedBlockHeight: 10,
+		prefetchBuffer:       make(chan *gethTypes.Header, 8),
+		metrics:              initL1WatcherMetrics(nil),
+	}
+	for _, h := range []*gethTypes.Header{header(9, common.Hash{}), header11, header12, header13, header14, header16} {
+		w.prefetchBuffer <- h
+	}
+
+	assert.Equal(t, []*gethTypes.Header{header11, header12}, w.takePrefetchedHeaders(15))
+	assert.Equal(t, header16, w.prefetchedHeader)
+	assert.Empty(t, w.prefetchBuffer)
+
+	w.processedBlockHeight = 15
+	assert.Equal(t, []*gethTypes.Header{header16}, w.takePrefetchedHeaders(20))
+	assert.Nil(t, w.takePrefetchedHeaders(20))
+
+	// prefetching is not started.
+	assert.Nil(t, (&L1WatcherClient{}).takePrefetchedHeaders(20))
+}
@@ -3,7 +3,11 @@ package watcher
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	geth "github.com/scroll-tech/go-ethereum"
@@ -19,10 +23,14 @@ import (
 	"scroll-tech/common/types"
 
 	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
 	"scroll-tech/rollup/internal/utils"
 )
 
+// l1WatcherStateName is the name of the l1 watcher state in DB.
+const l1WatcherStateName = "l1_watcher"
+
 type rollupEvent struct {
 	batchHash common.Hash
 	txHash    common.Hash
@@ -32,10 +40,12 @@ type rollupEvent struct {
 // L1WatcherClient will listen for smart contract events from Eth L1.
 type L1WatcherClient struct {
 	ctx          context.Context
-	client       *ethclient.Client
+	client       l1EthClient
 	l1MessageOrm *orm.L1Message
 	l1BlockOrm   *orm.L1Block
 	batchOrm     *orm.Batch
+	stateOrm     *orm.WatcherState
+	db           *gorm.DB
 
 	// The number of new blocks to wait for a block to be confirmed
 	confirmations rpc.BlockNumber
@@ -46,10 +56,46 @@ type L1WatcherClient struct {
 	scrollChainAddress common.Address
 	scrollChainABI     *abi.ABI
 
-	// The height of the block that the watcher has retrieved event logs
+	// The number of blocks on top of an event log block before the event is saved in DB
+	confirmationDepth uint64
+	// The unconfirmed event logs after processedMsgHeight, in ascending order of block number
+	unconfirmedLogs []gethTypes.Log
+	// The height of the block that the watcher has saved the events of
 	processedMsgHeight uint64
+	// The height of the block that the watcher has retrieved event logs
+	fetchedMsgHeight uint64
 	// The height of the block that the watcher has retrieved header rlp
 	processedBlockHeight uint64
+	// The height the parent hash chain of the stored blocks is validated up to
+	chainValidatedHeight uint64
+	// The number of blocks inserted since the parent hash chain was last validated
+	blocksSinceChainValidation int
+	// The max number of stored blocks replaced by a reorg before it is alerted, disabled if 0
+	maxExpectedReorgDepth uint64
+	// Whether the watcher halts on a reorg deeper than maxExpectedReorgDepth
+	haltOnDeepReorg bool
+	// Whether the watcher is halted on a deep reorg until AcknowledgeDeepReorg is called
+	halted atomic.Bool
+	// The time after which the pending l1 messages are marked as expired, they do not expire if 0
+	messageExpiryDuration time.Duration
+
+	// The number of blocks of each event logs query of BackfillEvents
+	backfillChunkSize uint64
+	// The max number of times the block range of an event logs query is bisected on too many results errors
+	maxFilterLogSplitDepth int
+
+	// The map[common.Address]struct{} of the senders allowed to queue L1 messages, all senders are allowed if empty.
+	allowedSenders atomic.Value
+
+	// The block the events are reprocessed from instead of the stored heights, 0 if disabled
+	startBlockOverride int64
+	// Whether startBlockOverride is set and not applied yet by FetchContractEvent
+	startBlockOverridePending atomic.Bool
+
+	// The prefetched block headers in ascending order, nil if prefetching is not started
+	prefetchBuffer chan *gethTypes.Header
+	// The prefetched header above the height requested by the last FetchBlockHeader
+	prefetchedHeader *gethTypes.Header
 
 	metrics *l1WatcherMetrics
 }
@@ -76,12 +122,21 @@ func NewL1WatcherClient(ctx context.Context, client *ethclient.Client, startHeig
 		savedL1BlockHeight = startHeight
 	}
 
-	return &L1WatcherClient{
+	// the latest stored blocks are validated by the first FetchBlockHeader.
+	var chainValidatedHeight uint64
+	if savedL1BlockHeight > l1BlockChainValidationInterval {
+		chainValidatedHeight = savedL1BlockHeight - l1BlockChainValidationInterval
+	}
+
+	metrics := initL1WatcherMetrics(reg)
+	w := &L1WatcherClient{
 		ctx:           ctx,
-		client:        client,
+		client:        newInstrumentedEthClient(client, metrics.rollupL1WatcherRPCDurationSeconds),
 		l1MessageOrm:  l1MessageOrm,
 		l1BlockOrm:    l1BlockOrm,
 		batchOrm:      orm.NewBatch(db),
+		stateOrm:      orm.NewWatcherState(db),
+		db:            db,
 		confirmations: confirmations,
 
 		messageQueueAddress: messageQueueAddress,
@@ -91,9 +146,24 @@ func NewL1WatcherClient(ctx context.Context, client *ethclient.Client, startHeig
 		scrollChainABI:     bridgeAbi.ScrollChainABI,
 
 		processedMsgHeight:   uint64(savedHeight),
+		fetchedMsgHeight:     uint64(savedHeight),
 		processedBlockHeight: savedL1BlockHeight,
-		metrics:              initL1WatcherMetrics(reg),
+		chainValidatedHeight: chainValidatedHeight,
+		metrics:              metrics,
+
+		blocksSinceChainValidation: l1BlockChainValidationInterval,
 	}
+
+	// the override is kept until it is cleared, the events are reprocessed from it again after a restart.
+	startBlockOverride, err := w.stateOrm.GetStartBlockOverride(ctx, l1WatcherStateName)
+	if err != nil {
+		log.Warn("Failed to fetch start block override from db", "err", err)
+	} else if startBlockOverride > 0 {
+		atomic.StoreInt64(&w.startBlockOverride, int64(startBlockOverride))
+		w.startBlockOverridePending.Store(true)
+		log.Warn("L1 watcher start block override is set", "block", startBlockOverride)
+	}
+	return w
 }
 
 // ProcessedBlockHeight get processedBlockHeight
@@ -114,35 +184,159 @@ func (w *L1WatcherClient) SetConfirmations(confirmations rpc.BlockNumber) {
 	w.confirmations = confirmations
 }
 
-// FetchBlockHeader pull latest L1 blocks and save in DB
-func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
-	w.metrics.l1WatcherFetchBlockHeaderTotal.Inc()
+// SetConfirmationDepth sets the number of blocks on top of an event log block before the event is saved in DB.
+func (w *L1WatcherClient) SetConfirmationDepth(confirmationDepth uint64) {
+	w.confirmationDepth = confirmationDepth
+}
+
+// SetBackfillChunkSize sets the number of blocks of each event logs query of BackfillEvents.
+func (w *L1WatcherClient) SetBackfillChunkSize(backfillChunkSize uint64) {
+	w.backfillChunkSize = backfillChunkSize
+}
+
+// SetMaxFilterLogSplitDepth sets the max number of times the block range of an event logs query is bisected
+// when the node rejects it for returning too many results.
+func (w *L1WatcherClient) SetMaxFilterLogSplitDepth(maxFilterLogSplitDepth int) {
+	w.maxFilterLogSplitDepth = maxFilterLogSplitDepth
+}
 
-	var block *gethTypes.Header
-	block, err := w.client.HeaderByNumber(w.ctx, big.NewInt(int64(blockHeight)))
+// SetEventReplayFile makes the watcher read the event logs from a JSON array file in the format of the eth_getLogs
+// responses instead of calling the l1 node. The replayed logs are final: the confirmations are reset to latest
+// and the confirmation depth to 0, so it must be called after SetConfirmations and SetConfirmationDepth.
+func (w *L1WatcherClient) SetEventReplayFile(file string) error {
+	client, err := newEventReplayClient(file)
 	if err != nil {
-		log.Warn("Failed to get block", "height", blockHeight, "err", err)
 		return err
 	}
+	w.client = client
+	w.confirmations = rpc.LatestBlockNumber
+	w.confirmationDepth = 0
+	log.Warn("L1 watcher replays the event logs of a file instead of calling the l1 node", "file", file, "logs", len(client.logs), "head", client.head)
+	return nil
+}
+
+// SetAllowedSenders sets the senders allowed to queue L1 messages, the QueueTransaction events of other senders are dropped.
+// All senders are allowed if addresses is empty.
+func (w *L1WatcherClient) SetAllowedSenders(addresses []common.Address) {
+	allowedSenders := make(map[common.Address]struct{}, len(addresses))
+	for _, address := range addresses {
+		allowedSenders[address] = struct{}{}
+	}
+	w.allowedSenders.Store(allowedSenders)
+}
+
+// SetAllowedSendersWatcher makes the watcher pick up the allowed senders delivered by aw.
+func (w *L1WatcherClient) SetAllowedSendersWatcher(aw config.AllowedL1SendersWatcher) {
+	updateCh := aw.Watch(w.ctx)
+	go func() {
+		for addresses := range updateCh {
+			w.SetAllowedSenders(addresses)
+			log.Info("Update allowed l1 sender addresses", "addresses", addresses)
+		}
+	}()
+}
+
+// isSenderAllowed returns true if sender is allowed to queue L1 messages.
+func (w *L1WatcherClient) isSenderAllowed(sender common.Address) bool {
+	allowedSenders, _ := w.allowedSenders.Load().(map[common.Address]struct{})
+	if len(allowedSenders) == 0 {
+		return true
+	}
+	_, ok := allowedSenders[sender]
+	return ok
+}
+
+// SetStartBlockOverride makes the next FetchContractEvent reprocess the events from block, ignoring the stored heights,
+// e.g. to recover the events lost in a data-loss incident. The override is persisted, so the events are reprocessed from
+// block again after each restart until ClearStartBlockOverride is called.
+func (w *L1WatcherClient) SetStartBlockOverride(block uint64) error {
+	if block == 0 {
+		return errors.New("start block override must be positive")
+	}
+	if err := w.stateOrm.SetStartBlockOverride(w.ctx, l1WatcherStateName, block); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&w.startBlockOverride, int64(block))
+	w.startBlockOverridePending.Store(true)
+	log.Warn("Set l1 watcher start block override", "block", block)
+	return nil
+}
+
+// ClearStartBlockOverride clears the start block override, the watcher then resumes from the stored heights after a restart.
+// The events being reprocessed since the override was applied are not affected.
+func (w *L1WatcherClient) ClearStartBlockOverride() error {
+	if err := w.stateOrm.SetStartBlockOverride(w.ctx, l1WatcherStateName, 0); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&w.startBlockOverride, 0)
+	w.startBlockOverridePending.Store(false)
+	log.Info("Cleared l1 watcher start block override")
+	return nil
+}
+
+// applyStartBlockOverride moves the event heights before the start block override if it is pending.
+func (w *L1WatcherClient) applyStartBlockOverride() {
+	if !w.startBlockOverridePending.CompareAndSwap(true, false) {
+		return
+	}
+	block := atomic.LoadInt64(&w.startBlockOverride)
+	if block <= 0 {
+		return
+	}
+	log.Warn("Reprocess l1 events from the start block override", "block", block, "processed height", w.processedMsgHeight)
+	w.unconfirmedLogs = nil
+	w.processedMsgHeight = uint64(block) - 1
+	w.fetchedMsgHeight = w.processedMsgHeight
+	w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(w.processedMsgHeight))
+}
 
-	if block == nil {
-		log.Warn("Received nil block", "height", blockHeight)
-		return errors.New("received nil block")
+// StartPrefetch starts fetching the headers of up to depth confirmed blocks ahead of FetchBlockHeader in the background.
+// FetchBlockHeader then also saves the prefetched blocks below the requested height. It must be called at most once.
+func (w *L1WatcherClient) StartPrefetch(depth uint64) {
+	prefetcher := newL1HeaderPrefetcher(w.client, w.confirmations, depth, w.metrics.rollupL1WatcherPrefetchQueueDepth)
+	w.prefetchBuffer = prefetcher.buffer
+	go prefetcher.run(w.ctx, w.processedBlockHeight+1)
+}
+
+// FetchBlockHeader pull latest L1 blocks and save in DB
+func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
+	if w.halted.Load() {
+		return errL1WatcherHalted
 	}
+	w.metrics.l1WatcherFetchBlockHeaderTotal.Inc()
 
-	var baseFee uint64
-	if block.BaseFee != nil {
-		baseFee = block.BaseFee.Uint64()
+	headers := w.takePrefetchedHeaders(blockHeight)
+	if len(headers) == 0 || headers[len(headers)-1].Number.Uint64() != blockHeight {
+		block, err := w.client.HeaderByNumber(w.ctx, big.NewInt(int64(blockHeight)))
+		if err != nil {
+			log.Warn("Failed to get block", "height", blockHeight, "err", err)
+			return err
+		}
+
+		if block == nil {
+			log.Warn("Received nil block", "height", blockHeight)
+			return errors.New("received nil block")
+		}
+		headers = append(headers, block)
 	}
 
-	l1Block := orm.L1Block{
-		Number:          blockHeight,
-		Hash:            block.Hash().String(),
-		BaseFee:         baseFee,
-		GasOracleStatus: int16(types.GasOraclePending),
+	l1Blocks := make([]orm.L1Block, 0, len(headers))
+	for _, header := range headers {
+		var baseFee uint64
+		if header.BaseFee != nil {
+			baseFee = header.BaseFee.Uint64()
+		}
+
+		l1Blocks = append(l1Blocks, orm.L1Block{
+			Number:          header.Number.Uint64(),
+			Hash:            header.Hash().String(),
+			ParentHash:      header.ParentHash.String(),
+			BaseFee:         baseFee,
+			GasOracleStatus: int16(types.GasOraclePending),
+		})
 	}
 
-	err = w.l1BlockOrm.InsertL1Blocks(w.ctx, []orm.L1Block{l1Block})
+	err := w.l1BlockOrm.InsertL1Blocks(w.ctx, l1Blocks)
 	if err != nil {
 		log.Warn("Failed to insert L1 block to db", "blockHeight", blockHeight, "err", err)
 		return err
@@ -151,21 +345,74 @@ func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
 	// update processed height
 	w.processedBlockHeight = blockHeight
 	w.metrics.l1WatcherFetchBlockHeaderProcessedBlockHeight.Set(float64(w.processedBlockHeight))
+
+	w.blocksSinceChainValidation += len(l1Blocks)
+	if w.blocksSinceChainValidation >= l1BlockChainValidationInterval {
+		w.validateBlockChain()
+	}
 	return nil
 }
 
+// takePrefetchedHeaders returns the prefetched headers above processedBlockHeight up to blockHeight in ascending order,
+// without waiting for the ones not prefetched yet. The headers not linked to their predecessor by parent hash, e.g.
+// fetched before a reorg, are dropped with the ones after them.
+func (w *L1WatcherClient) takePrefetchedHeaders(blockHeight uint64) []*gethTypes.Header {
+	if w.prefetchBuffer == nil {
+		return nil
+	}
+
+	var headers []*gethTypes.Header
+	linked := true
+	for {
+		header := w.prefetchedHeader
+		w.prefetchedHeader = nil
+		if header == nil {
+			select {
+			case header = <-w.prefetchBuffer:
+				w.metrics.rollupL1WatcherPrefetchQueueDepth.Set(float64(len(w.prefetchBuffer)))
+			default:
+				return headers
+			}
+		}
+
+		number := header.Number.Uint64()
+		if number > blockHeight {
+			w.prefetchedHeader = header
+			return headers
+		}
+		if number <= w.processedBlockHeight || !linked {
+			continue
+		}
+		if len(headers) > 0 {
+			prev := headers[len(headers)-1]
+			if prev.Number.Uint64()+1 == number && prev.Hash() != header.ParentHash {
+				log.Warn("Dropped prefetched l1 block headers not linked by parent hash", "height", number)
+				linked = false
+				continue
+			}
+		}
+		headers = append(headers, header)
+	}
+}
+
 // FetchContractEvent pull latest event logs from given contract address and save in DB
 func (w *L1WatcherClient) FetchContractEvent() error {
+	if w.halted.Load() {
+		return errL1WatcherHalted
+	}
 	defer func() {
-		log.Info("l1 watcher fetchContractEvent", "w.processedMsgHeight", w.processedMsgHeight)
+		log.Info("l1 watcher fetchContractEvent", "w.processedMsgHeight", w.processedMsgHeight, "w.fetchedMsgHeight", w.fetchedMsgHeight)
 	}()
+	w.applyStartBlockOverride()
+	w.expireOldMessages()
+
 	blockHeight, err := utils.GetLatestConfirmedBlockNumber(w.ctx, w.client, w.confirmations)
 	if err != nil {
 		log.Error("failed to get block number", "err", err)
 		return err
 	}
 
-	fromBlock := int64(w.processedMsgHeight) + 1
+	fromBlock := int64(w.fetchedMsgHeight) + 1
 	toBlock := int64(blockHeight)
 
 	for from := fromBlock; from <= toBlock; from += contractEventsBlocksFetchLimit {
@@ -177,86 +424,315 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 		}
 
 		// warning: uint int conversion...
-		query := geth.FilterQuery{
-			FromBlock: big.NewInt(from), // inclusive
-			ToBlock:   big.NewInt(to),   // inclusive
-			Addresses: []common.Address{
-				w.scrollChainAddress,
-				w.messageQueueAddress,
-			},
-			Topics: make([][]common.Hash, 1),
-		}
-		query.Topics[0] = make([]common.Hash, 3)
-		query.Topics[0][0] = bridgeAbi.L1QueueTransactionEventSignature
-		query.Topics[0][1] = bridgeAbi.L1CommitBatchEventSignature
-		query.Topics[0][2] = bridgeAbi.L1FinalizeBatchEventSignature
-
-		logs, err := w.client.FilterLogs(w.ctx, query)
+		logs, err := w.filterContractEvents(w.ctx, uint64(from), uint64(to), 0)
 		if err != nil {
 			log.Warn("Failed to get event logs", "err", err)
 			return err
 		}
-		if len(logs) == 0 {
-			w.processedMsgHeight = uint64(to)
-			w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(to))
-			continue
+		if len(logs) > 0 {
+			log.Info("Received new L1 events", "fromBlock", from, "toBlock", to, "cnt", len(logs))
+		}
+
+		w.unconfirmedLogs = append(w.unconfirmedLogs, logs...)
+		w.fetchedMsgHeight = uint64(to)
+		if ok, err := w.flushConfirmedLogs(blockHeight); !ok {
+			return err
 		}
+	}
 
-		log.Info("Received new L1 events", "fromBlock", from, "toBlock", to, "cnt", len(logs))
+	// flush the buffered logs confirmed by the blocks since the last fetch.
+	if _, err := w.flushConfirmedLogs(blockHeight); err != nil {
+		return err
+	}
+	return nil
+}
 
-		sentMessageEvents, rollupEvents, err := w.parseBridgeEventLogs(logs)
+// contractEventsQuery returns the query of the bridge event logs in blocks [from, to].
+func (w *L1WatcherClient) contractEventsQuery(from, to *big.Int) geth.FilterQuery {
+	query := geth.FilterQuery{
+		FromBlock: from, // inclusive
+		ToBlock:   to,   // inclusive
+		Addresses: []common.Address{
+			w.scrollChainAddress,
+			w.messageQueueAddress,
+		},
+		Topics: make([][]common.Hash, 1),
+	}
+	query.Topics[0] = make([]common.Hash, 3)
+	query.Topics[0][0] = bridgeAbi.L1QueueTransactionEventSignature
+	query.Topics[0][1] = bridgeAbi.L1CommitBatchEventSignature
+	query.Topics[0][2] = bridgeAbi.L1FinalizeBatchEventSignature
+	return query
+}
+
+// filterContractEvents returns the bridge event logs in blocks [from, to]. If the node rejects the query for returning
+// too many results, the range is bisected and each half queried recursively, up to maxFilterLogSplitDepth times.
+func (w *L1WatcherClient) filterContractEvents(ctx context.Context, from, to uint64, depth int) ([]gethTypes.Log, error) {
+	logs, err := w.client.FilterLogs(ctx, w.contractEventsQuery(new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)))
+	if err == nil || !isTooManyResultsError(err) || depth >= w.maxFilterLogSplitDepth || from >= to {
+		return logs, err
+	}
+
+	w.metrics.rollupL1WatcherFilterLogSplitsTotal.Inc()
+	mid := from + (to-from)/2
+	log.Info("Split event logs query returning too many results", "fromBlock", from, "toBlock", to, "depth", depth+1)
+	logs, err = w.filterContractEvents(ctx, from, mid, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	upperLogs, err := w.filterContractEvents(ctx, mid+1, to, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	return append(logs, upperLogs...), nil
+}
+
+// isTooManyResultsError returns true if err is the rejection of an event logs query returning too many results.
+func isTooManyResultsError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "more than 10000 results") ||
+		strings.Contains(msg, "too many results") ||
+		strings.Contains(msg, "log response size exceeded")
+}
+
+// BackfillEvents fetches the bridge event logs in blocks [fromBlock, toBlock] and saves the events missing in DB,
+// backfillChunkSize blocks at a time, each chunk in a single DB transaction.
+// Existing l1 messages are skipped and rollup statuses only move forward, so it is safe to re-run on the same range.
+// It does not change the heights tracked by FetchContractEvent.
+func (w *L1WatcherClient) BackfillEvents(ctx context.Context, fromBlock, toBlock uint64) error {
+	if fromBlock > toBlock {
+		return fmt.Errorf("invalid backfill range, from block: %d, to block: %d", fromBlock, toBlock)
+	}
+	chunkSize := w.backfillChunkSize
+	if chunkSize == 0 {
+		chunkSize = uint64(contractEventsBlocksFetchLimit)
+	}
+
+	w.metrics.l1WatcherBackfillProgress.Set(0)
+	for from := fromBlock; from <= toBlock; from += chunkSize {
+		to := from + chunkSize - 1
+		if to > toBlock || to < from {
+			to = toBlock
+		}
+
+		logs, err := w.filterContractEvents(ctx, from, to, 0)
+		if err != nil {
+			return fmt.Errorf("failed to get event logs in blocks [%d, %d]: %w", from, to, err)
+		}
+		if err = w.backfillEventLogs(ctx, logs); err != nil {
+			return fmt.Errorf("failed to backfill events in blocks [%d, %d]: %w", from, to, err)
+		}
+
+		w.metrics.l1WatcherBackfillProgress.Set(float64(to-fromBlock+1) / float64(toBlock-fromBlock+1))
+		log.Info("Backfilled L1 events", "fromBlock", from, "toBlock", to, "cnt", len(logs))
+		if to == toBlock {
+			break
+		}
+	}
+	return nil
+}
+
+// backfillEventLogs saves the events of logs missing in DB in a single transaction.
+func (w *L1WatcherClient) backfillEventLogs(ctx context.Context, logs []gethTypes.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	sentMessageEvents, rollupEvents, err := w.parseBridgeEventLogs(logs)
+	if err != nil {
+		return fmt.Errorf("failed to parse event logs: %w", err)
+	}
+
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		l1MessageOrm := orm.NewL1Message(tx)
+		batchOrm := orm.NewBatch(tx)
+
+		var queueIndices []uint64
+		for _, msg := range sentMessageEvents {
+			queueIndices = append(queueIndices, msg.QueueIndex)
+		}
+		existingMessages, err := l1MessageOrm.GetL1MessagesByQueueIndices(ctx, queueIndices)
+		if err != nil {
+			return err
+		}
+		existingQueueIndices := make(map[uint64]struct{}, len(existingMessages))
+		for _, msg := range existingMessages {
+			existingQueueIndices[msg.QueueIndex] = struct{}{}
+		}
+		var missingMessages []*orm.L1Message
+		for _, msg := range sentMessageEvents {
+			if _, ok := existingQueueIndices[msg.QueueIndex]; !ok {
+				missingMessages = append(missingMessages, msg)
+			}
+		}
+		saved, err := l1MessageOrm.SaveL1Messages(ctx, missingMessages)
 		if err != nil {
-			log.Error("Failed to parse emitted events log", "err", err)
 			return err
 		}
-		sentMessageCount := int64(len(sentMessageEvents))
-		rollupEventCount := int64(len(rollupEvents))
-		w.metrics.l1WatcherFetchContractEventSentEventsTotal.Add(float64(sentMessageCount))
-		w.metrics.l1WatcherFetchContractEventRollupEventsTotal.Add(float64(rollupEventCount))
-		log.Info("L1 events types", "SentMessageCount", sentMessageCount, "RollupEventCount", rollupEventCount)
+		w.recordDuplicateEvents(len(missingMessages), saved)
 
-		// use rollup event to update rollup results db status
+		if len(rollupEvents) == 0 {
+			return nil
+		}
 		var batchHashes []string
 		for _, event := range rollupEvents {
 			batchHashes = append(batchHashes, event.batchHash.String())
 		}
-		statuses, err := w.batchOrm.GetRollupStatusByHashList(w.ctx, batchHashes)
+		batches, err := batchOrm.GetBatches(ctx, map[string]interface{}{"hash IN ?": batchHashes}, nil, 0)
 		if err != nil {
-			log.Error("Failed to GetRollupStatusByHashList", "err", err)
 			return err
 		}
-		if len(statuses) != len(batchHashes) {
-			log.Error("RollupStatus.Length mismatch with batchHashes.Length", "RollupStatus.Length", len(statuses), "batchHashes.Length", len(batchHashes))
-			return nil
+		statuses := make(map[string]types.RollupStatus, len(batches))
+		for _, batch := range batches {
+			statuses[batch.Hash] = types.RollupStatus(batch.RollupStatus)
 		}
-
-		for index, event := range rollupEvents {
+		for _, event := range rollupEvents {
 			batchHash := event.batchHash.String()
-			status := statuses[index]
+			status, ok := statuses[batchHash]
+			if !ok {
+				log.Warn("Skip backfilling rollup event of unknown batch", "batch hash", batchHash, "status", event.status)
+				continue
+			}
 			// only update when db status is before event status
-			if event.status > status {
-				if event.status == types.RollupFinalized {
-					err = w.batchOrm.UpdateFinalizeTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
-				} else if event.status == types.RollupCommitted {
-					err = w.batchOrm.UpdateCommitTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
-				}
-				if err != nil {
-					log.Error("Failed to update Rollup/Finalize TxHash and Status", "err", err)
-					return err
-				}
+			if event.status <= status {
+				continue
 			}
+			if event.status == types.RollupFinalized {
+				err = batchOrm.UpdateFinalizeTxHashAndRollupStatus(ctx, batchHash, event.txHash.String(), event.status)
+			} else if event.status == types.RollupCommitted {
+				err = batchOrm.UpdateCommitTxHashAndRollupStatus(ctx, batchHash, event.txHash.String(), event.status)
+			}
+			if err != nil {
+				return err
+			}
+			statuses[batchHash] = event.status
 		}
+		return nil
+	})
+}
 
-		if err = w.l1MessageOrm.SaveL1Messages(w.ctx, sentMessageEvents); err != nil {
-			return err
+// flushConfirmedLogs saves the events of the buffered logs that are at least confirmationDepth blocks below head.
+// If it fails, the buffer is dropped so that the logs after processedMsgHeight are fetched again, and false is returned.
+func (w *L1WatcherClient) flushConfirmedLogs(head uint64) (bool, error) {
+	defer func() {
+		w.metrics.l1WatcherUnconfirmedEvents.Set(float64(len(w.unconfirmedLogs)))
+	}()
+
+	if head < w.confirmationDepth {
+		return true, nil
+	}
+	confirmedHeight := head - w.confirmationDepth
+	if confirmedHeight > w.fetchedMsgHeight {
+		confirmedHeight = w.fetchedMsgHeight
+	}
+	if confirmedHeight <= w.processedMsgHeight {
+		return true, nil
+	}
+
+	var numConfirmed int
+	for numConfirmed < len(w.unconfirmedLogs) && w.unconfirmedLogs[numConfirmed].BlockNumber <= confirmedHeight {
+		numConfirmed++
+	}
+	confirmedLogs := w.unconfirmedLogs[:numConfirmed]
+
+	// the logs were fetched while their blocks were unconfirmed, check that the blocks are still canonical.
+	if w.confirmationDepth > 0 {
+		for i, vLog := range confirmedLogs {
+			if i > 0 && vLog.BlockNumber == confirmedLogs[i-1].BlockNumber {
+				continue
+			}
+			header, err := w.client.HeaderByNumber(w.ctx, new(big.Int).SetUint64(vLog.BlockNumber))
+			if err != nil {
+				log.Warn("Failed to get block header of event log", "height", vLog.BlockNumber, "err", err)
+				w.dropUnconfirmedLogs()
+				return false, err
+			}
+			if header.Hash() != vLog.BlockHash {
+				log.Warn("L1 reorg detected, refetch event logs", "height", vLog.BlockNumber, "log block hash", vLog.BlockHash, "canonical block hash", header.Hash())
+				w.dropUnconfirmedLogs()
+				return false, fmt.Errorf("l1 block %v of event log reorged", vLog.BlockNumber)
+			}
 		}
+	}
 
-		w.processedMsgHeight = uint64(to)
+	if len(confirmedLogs) > 0 {
+		if ok, err := w.saveEvents(confirmedLogs); !ok {
+			w.dropUnconfirmedLogs()
+			return false, err
+		}
 		w.metrics.l1WatcherFetchContractEventSuccessTotal.Inc()
-		w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(w.processedMsgHeight))
 	}
 
-	return nil
+	w.unconfirmedLogs = w.unconfirmedLogs[numConfirmed:]
+	w.processedMsgHeight = confirmedHeight
+	w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(w.processedMsgHeight))
+	return true, nil
+}
+
+// dropUnconfirmedLogs drops the buffered logs, they are fetched again from processedMsgHeight.
+func (w *L1WatcherClient) dropUnconfirmedLogs() {
+	w.unconfirmedLogs = nil
+	w.fetchedMsgHeight = w.processedMsgHeight
+}
+
+// saveEvents saves the events of logs in DB, it returns false if the events are not saved.
+func (w *L1WatcherClient) saveEvents(logs []gethTypes.Log) (bool, error) {
+	sentMessageEvents, rollupEvents, err := w.parseBridgeEventLogs(logs)
+	if err != nil {
+		log.Error("Failed to parse emitted events log", "err", err)
+		return false, err
+	}
+	sentMessageCount := int64(len(sentMessageEvents))
+	rollupEventCount := int64(len(rollupEvents))
+	w.metrics.l1WatcherFetchContractEventSentEventsTotal.Add(float64(sentMessageCount))
+	w.metrics.l1WatcherFetchContractEventRollupEventsTotal.Add(float64(rollupEventCount))
+	log.Info("L1 events types", "SentMessageCount", sentMessageCount, "RollupEventCount", rollupEventCount)
+
+	// use rollup event to update rollup results db status
+	var batchHashes []string
+	for _, event := range rollupEvents {
+		batchHashes = append(batchHashes, event.batchHash.String())
+	}
+	statuses, err := w.batchOrm.GetRollupStatusByHashList(w.ctx, batchHashes)
+	if err != nil {
+		log.Error("Failed to GetRollupStatusByHashList", "err", err)
+		return false, err
+	}
+	if len(statuses) != len(batchHashes) {
+		log.Error("RollupStatus.Length mismatch with batchHashes.Length", "RollupStatus.Length", len(statuses), "batchHashes.Length", len(batchHashes))
+		return false, nil
+	}
+
+	for index, event := range rollupEvents {
+		batchHash := event.batchHash.String()
+		status := statuses[index]
+		// only update when db status is before event status
+		if event.status > status {
+			if event.status == types.RollupFinalized {
+				err = w.batchOrm.UpdateFinalizeTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
+			} else if event.status == types.RollupCommitted {
+				err = w.batchOrm.UpdateCommitTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
+			}
+			if err != nil {
+				log.Error("Failed to update Rollup/Finalize TxHash and Status", "err", err)
+				return false, err
+			}
+		}
+	}
+
+	saved, err := w.l1MessageOrm.SaveL1Messages(w.ctx, sentMessageEvents)
+	if err != nil {
+		return false, err
+	}
+	w.recordDuplicateEvents(len(sentMessageEvents), saved)
+	return true, nil
+}
+
+// recordDuplicateEvents counts the QueueTransaction events skipped as already saved, e.g. refetched after an RPC retry.
+func (w *L1WatcherClient) recordDuplicateEvents(total int, saved int64) {
+	if duplicates := int64(total) - saved; duplicates > 0 {
+		w.metrics.rollupL1WatcherDuplicateEventsSkipped.Add(float64(duplicates))
+		log.Info("Skip duplicate L1 QueueTransaction events", "duplicates", duplicates)
+	}
 }
 
 func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1Message, []rollupEvent, error) {
@@ -274,6 +750,12 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 				return l1Messages, rollupEvents, err
 			}
 
+			if !w.isSenderAllowed(event.Sender) {
+				w.metrics.rollupL1WatcherFilteredEventsTotal.Inc()
+				log.Warn("Drop layer1 QueueTransaction event of a sender not allowed", "sender", event.Sender, "queue index", event.QueueIndex, "tx hash", vLog.TxHash)
+				continue
+			}
+
 			msgHash := common.BytesToHash(crypto.Keccak256(event.Data))
 
 			l1Messages = append(l1Messages, &orm.L1Message{
@@ -286,6 +768,7 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 				Calldata:   common.Bytes2Hex(event.Data),
 				GasLimit:   event.GasLimit.Uint64(),
 				Layer1Hash: vLog.TxHash.Hex(),
+				LogIndex:   vLog.Index,
 			})
 		case bridgeAbi.L1CommitBatchEventSignature:
 			event := bridgeAbi.L1CommitBatchEvent{}
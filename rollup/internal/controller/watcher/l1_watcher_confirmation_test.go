@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestL1WatcherClientConfirmationDepth(t *testing.T) {
+	client := &ethclient.Client{}
+	watcher := &L1WatcherClient{
+		ctx:               context.Background(),
+		client:            client,
+		confirmationDepth: 3,
+		metrics:           initL1WatcherMetrics(nil),
+	}
+
+	var head uint64
+	headers := make(map[uint64]*types.Header)
+	for i := uint64(0); i <= 20; i++ {
+		headers[i] = &types.Header{Number: new(big.Int).SetUint64(i)}
+	}
+	// one event log in each of the blocks 2, 5, 6 and 9.
+	eventLogs := func() []types.Log {
+		var logs []types.Log
+		for _, number := range []uint64{2, 5, 6, 9} {
+			logs = append(logs, types.Log{BlockNumber: number, BlockHash: headers[number].Hash()})
+		}
+		return logs
+	}
+
+	patches := gomonkey.ApplyMethodFunc(client, "BlockNumber", func(ctx context.Context) (uint64, error) {
+		return head, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(client, "HeaderByNumber", func(ctx context.Context, number *big.Int) (*types.Header, error) {
+		return headers[number.Uint64()], nil
+	})
+	patches.ApplyMethodFunc(client, "FilterLogs", func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		var logs []types.Log
+		for _, vLog := range eventLogs() {
+			if vLog.BlockNumber >= q.FromBlock.Uint64() && vLog.BlockNumber <= q.ToBlock.Uint64() {
+				logs = append(logs, vLog)
+			}
+		}
+		return logs, nil
+	})
+	var savedHeights []uint64
+	patches.ApplyPrivateMethod(watcher, "saveEvents", func(_ *L1WatcherClient, logs []types.Log) (bool, error) {
+		for _, vLog := range logs {
+			savedHeights = append(savedHeights, vLog.BlockNumber)
+		}
+		return true, nil
+	})
+
+	// all the event logs are within the confirmation window.
+	head = 3
+	assert.NoError(t, watcher.FetchContractEvent())
+	assert.Empty(t, savedHeights)
+	assert.Len(t, watcher.unconfirmedLogs, 1)
+	assert.Equal(t, uint64(0), watcher.processedMsgHeight)
+	assert.Equal(t, uint64(3), watcher.fetchedMsgHeight)
+
+	// the event logs are flushed as the head advances.
+	head = 5
+	assert.NoError(t, watcher.FetchContractEvent())
+	assert.Equal(t, []uint64{2}, savedHeights)
+	assert.Len(t, watcher.unconfirmedLogs, 1)
+	assert.Equal(t, uint64(2), watcher.processedMsgHeight)
+
+	head = 9
+	assert.NoError(t, watcher.FetchContractEvent())
+	assert.Equal(t, []uint64{2, 5, 6}, savedHeights)
+	assert.Len(t, watcher.unconfirmedLogs, 1)
+	assert.Equal(t, uint64(6), watcher.processedMsgHeight)
+
+	// the block of a withheld event log is reorged, the logs are fetched again.
+	headers[9] = &types.Header{Number: big.NewInt(9), Extra: []byte("reorged")}
+	head = 12
+	assert.Error(t, watcher.FetchContractEvent())
+	assert.Equal(t, []uint64{2, 5, 6}, savedHeights)
+	assert.Empty(t, watcher.unconfirmedLogs)
+	assert.Equal(t, uint64(6), watcher.fetchedMsgHeight)
+
+	assert.NoError(t, watcher.FetchContractEvent())
+	assert.Equal(t, []uint64{2, 5, 6, 9}, savedHeights)
+	assert.Empty(t, watcher.unconfirmedLogs)
+	assert.Equal(t, uint64(9), watcher.processedMsgHeight)
+	assert.Equal(t, uint64(12), watcher.fetchedMsgHeight)
+}
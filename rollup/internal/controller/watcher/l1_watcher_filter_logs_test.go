@@ -0,0 +1,112 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+// newGetLogsServer returns a mock rpc server rejecting the eth_getLogs queries of more than maxBlocks blocks,
+// and returning one log per block for the others. The queried ranges are recorded in order.
+func newGetLogsServer(t *testing.T, maxBlocks uint64, rejection string) (*httptest.Server, *[][2]uint64) {
+	var queries [][2]uint64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []struct {
+				FromBlock hexutil.Uint64 `json:"fromBlock"`
+				ToBlock   hexutil.Uint64 `json:"toBlock"`
+			} `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "eth_getLogs", req.Method)
+		from, to := uint64(req.Params[0].FromBlock), uint64(req.Params[0].ToBlock)
+		queries = append(queries, [2]uint64{from, to})
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if to-from+1 > maxBlocks {
+			resp["error"] = map[string]interface{}{"code": -32005, "message": rejection}
+		} else {
+			var logs []map[string]interface{}
+			for number := from; number <= to; number++ {
+				logs = append(logs, map[string]interface{}{
+					"address":          common.Address{}.Hex(),
+					"topics":           []string{},
+					"data":             "0x",
+					"blockNumber":      hexutil.Uint64(number),
+					"transactionHash":  common.Hash{}.Hex(),
+					"transactionIndex": "0x0",
+					"blockHash":        common.Hash{}.Hex(),
+					"logIndex":         "0x0",
+					"removed":          false,
+				})
+			}
+			resp["result"] = logs
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server, &queries
+}
+
+func TestL1WatcherFilterContractEventsSplit(t *testing.T) {
+	server, queries := newGetLogsServer(t, 3, "query returned more than 10000 results")
+	client, err := ethclient.Dial(server.URL)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	w := &L1WatcherClient{ctx: context.Background(), client: client, metrics: initL1WatcherMetrics(nil)}
+	splits := testutil.ToFloat64(w.metrics.rollupL1WatcherFilterLogSplitsTotal)
+
+	// the query is not split by default.
+	_, err = w.filterContractEvents(context.Background(), 1, 10, 0)
+	assert.ErrorContains(t, err, "more than 10000 results")
+	assert.Equal(t, [][2]uint64{{1, 10}}, *queries)
+
+	*queries = nil
+	w.SetMaxFilterLogSplitDepth(2)
+	logs, err := w.filterContractEvents(context.Background(), 1, 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 10)
+	for i, vLog := range logs {
+		assert.Equal(t, uint64(i+1), vLog.BlockNumber)
+	}
+	assert.Equal(t, [][2]uint64{{1, 10}, {1, 5}, {1, 3}, {4, 5}, {6, 10}, {6, 8}, {9, 10}}, *queries)
+	assert.Equal(t, splits+3, testutil.ToFloat64(w.metrics.rollupL1WatcherFilterLogSplitsTotal))
+
+	// the halves still returning too many results at the max depth fail the query.
+	w.SetMaxFilterLogSplitDepth(1)
+	_, err = w.filterContractEvents(context.Background(), 1, 10, 0)
+	assert.ErrorContains(t, err, "more than 10000 results")
+}
+
+func TestL1WatcherFilterContractEventsOtherError(t *testing.T) {
+	server, queries := newGetLogsServer(t, 3, "internal error")
+	client, err := ethclient.Dial(server.URL)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	w := &L1WatcherClient{ctx: context.Background(), client: client, metrics: initL1WatcherMetrics(nil)}
+	w.SetMaxFilterLogSplitDepth(2)
+	_, err = w.filterContractEvents(context.Background(), 1, 10, 0)
+	assert.ErrorContains(t, err, "internal error")
+	assert.Len(t, *queries, 1)
+}
+
+func TestIsTooManyResultsError(t *testing.T) {
+	assert.True(t, isTooManyResultsError(fmt.Errorf("query returned more than 10000 results")))
+	assert.True(t, isTooManyResultsError(fmt.Errorf("eth_getLogs: Too many results, try a smaller block range")))
+	assert.True(t, isTooManyResultsError(fmt.Errorf("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range")))
+	assert.False(t, isTooManyResultsError(fmt.Errorf("internal error")))
+}
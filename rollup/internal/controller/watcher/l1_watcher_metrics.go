@@ -15,6 +15,17 @@ type l1WatcherMetrics struct {
 	l1WatcherFetchContractEventProcessedBlockHeight prometheus.Gauge
 	l1WatcherFetchContractEventSentEventsTotal      prometheus.Counter
 	l1WatcherFetchContractEventRollupEventsTotal    prometheus.Counter
+	l1WatcherUnconfirmedEvents                      prometheus.Gauge
+	l1WatcherBackfillProgress                       prometheus.Gauge
+	rollupL1WatcherDuplicateEventsSkipped           prometheus.Counter
+	rollupL1WatcherFilteredEventsTotal              prometheus.Counter
+	rollupL1WatcherPrefetchQueueDepth               prometheus.Gauge
+	rollupL1WatcherFilterLogSplitsTotal             prometheus.Counter
+	rollupL1WatcherRPCDurationSeconds               *prometheus.HistogramVec
+	rollupL1WatcherWSReconnectsTotal                prometheus.Counter
+	rollupL1WatcherOrphanBlocksTotal                prometheus.Counter
+	rollupL1WatcherDeepReorgDetected                prometheus.Counter
+	rollupL1MessageExpiredTotal                     prometheus.Counter
 }
 
 var (
@@ -53,6 +64,51 @@ func initL1WatcherMetrics(reg prometheus.Registerer) *l1WatcherMetrics {
 				Name: "rollup_l1_watcher_fetch_block_contract_event_rollup_event_total",
 				Help: "The current processed block height of l1 watcher fetch contract rollup event",
 			}),
+			l1WatcherUnconfirmedEvents: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_l1_watcher_unconfirmed_events",
+				Help: "The number of l1 event logs withheld until their blocks reach the confirmation depth",
+			}),
+			l1WatcherBackfillProgress: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_l1_watcher_backfill_progress",
+				Help: "The ratio of the blocks processed by the running l1 event backfill, between 0 and 1",
+			}),
+			rollupL1WatcherDuplicateEventsSkipped: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_duplicate_events_skipped_total",
+				Help: "The total number of l1 QueueTransaction events skipped as already saved",
+			}),
+			rollupL1WatcherFilteredEventsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_filtered_events_total",
+				Help: "The total number of l1 QueueTransaction events dropped as their sender is not allowed",
+			}),
+			rollupL1WatcherPrefetchQueueDepth: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_l1_watcher_prefetch_queue_depth",
+				Help: "The number of prefetched l1 block headers waiting to be saved",
+			}),
+			rollupL1WatcherFilterLogSplitsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_filter_log_splits_total",
+				Help: "The total number of l1 event logs queries split for returning too many results",
+			}),
+			rollupL1WatcherRPCDurationSeconds: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "rollup_l1_watcher_rpc_duration_seconds",
+				Help:    "The duration of the l1 rpc calls of the l1 watcher by method",
+				Buckets: prometheus.ExponentialBucketsRange(0.005, 30, 12),
+			}, []string{"method"}),
+			rollupL1WatcherWSReconnectsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_ws_reconnects_total",
+				Help: "The total number of reconnections of the l1 watcher new heads websocket subscription",
+			}),
+			rollupL1WatcherOrphanBlocksTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_orphan_blocks_total",
+				Help: "The total number of stored l1 blocks not linked to the stored previous block by parent hash",
+			}),
+			rollupL1WatcherDeepReorgDetected: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_deep_reorg_detected_total",
+				Help: "The total number of l1 reorgs replacing more stored blocks than the max expected reorg depth",
+			}),
+			rollupL1MessageExpiredTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_message_expired_total",
+				Help: "The total number of l1 messages marked as expired after being pending for longer than the message expiry duration",
+			}),
 		}
 	})
 	return l1WatcherMetric
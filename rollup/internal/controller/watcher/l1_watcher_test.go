@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
@@ -41,6 +42,77 @@ func testFetchContractEvent(t *testing.T) {
 	assert.NoError(t, watcher.FetchContractEvent())
 }
 
+func testL1WatcherExpireOldMessages(t *testing.T) {
+	watcher, db := setupL1Watcher(t)
+	defer database.CloseDB(db)
+
+	l1MessageOrm := orm.NewL1Message(db)
+	_, err := l1MessageOrm.SaveL1Messages(context.Background(), []*orm.L1Message{
+		{QueueIndex: 0, MsgHash: "0x0", Sender: "sender", Target: "target", Value: "0", Layer1Hash: "0x1", LogIndex: 0},
+		{QueueIndex: 1, MsgHash: "0x1", Sender: "sender", Target: "target", Value: "0", Layer1Hash: "0x1", LogIndex: 1},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Model(&orm.L1Message{}).Where("queue_index = ?", 0).Update("created_at", gorm.Expr("NOW() - INTERVAL '2 hours'")).Error)
+
+	// the messages do not expire by default.
+	assert.NoError(t, watcher.FetchContractEvent())
+	messages, err := l1MessageOrm.GetL1MessagesByQueueIndices(context.Background(), []uint64{0, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int(commonTypes.MsgPending), messages[0].Status)
+
+	watcher.SetMessageExpiryDuration(3600)
+	assert.NoError(t, watcher.FetchContractEvent())
+	messages, err = l1MessageOrm.GetL1MessagesByQueueIndices(context.Background(), []uint64{0, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int(commonTypes.MsgExpired), messages[0].Status)
+	assert.Equal(t, int(commonTypes.MsgPending), messages[1].Status)
+}
+
+// testFetchContractEventReplay replays recorded QueueTransaction logs, the saved messages must be the ones
+// parsed from the same logs fetched from a node.
+func testFetchContractEventReplay(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+	l1Cfg := cfg.L1Config
+	watcher := NewL1WatcherClient(context.Background(), nil, l1Cfg.StartHeight, l1Cfg.Confirmations, l1Cfg.L1MessageQueueAddress, l1Cfg.RelayerConfig.RollupContractAddress, db, nil)
+	assert.NoError(t, watcher.SetEventReplayFile(eventReplayFixture))
+	assert.NoError(t, watcher.FetchContractEvent())
+
+	replay, err := newEventReplayClient(eventReplayFixture)
+	assert.NoError(t, err)
+	logs, err := replay.FilterLogs(context.Background(), watcher.contractEventsQuery(big.NewInt(0), new(big.Int).SetUint64(replay.head)))
+	assert.NoError(t, err)
+	expected, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+	assert.NoError(t, err)
+	assert.Len(t, expected, 3)
+	assert.Empty(t, rollupEvents)
+
+	saved, err := orm.NewL1Message(db).GetL1MessagesByQueueIndices(context.Background(), []uint64{0, 1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, saved, len(expected))
+	for i, msg := range saved {
+		assert.Equal(t, expected[i].QueueIndex, msg.QueueIndex)
+		assert.Equal(t, expected[i].MsgHash, msg.MsgHash)
+		assert.Equal(t, expected[i].Height, msg.Height)
+		assert.Equal(t, expected[i].Sender, msg.Sender)
+		assert.Equal(t, expected[i].Target, msg.Target)
+		assert.Equal(t, expected[i].Value, msg.Value)
+		assert.Equal(t, expected[i].Calldata, msg.Calldata)
+		assert.Equal(t, expected[i].GasLimit, msg.GasLimit)
+		assert.Equal(t, expected[i].Layer1Hash, msg.Layer1Hash)
+	}
+
+	height, err := orm.NewL1Message(db).GetLayer1LatestWatchedHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), height)
+
+	// replaying again saves nothing new.
+	assert.NoError(t, watcher.FetchContractEvent())
+	saved, err = orm.NewL1Message(db).GetL1MessagesByQueueIndices(context.Background(), []uint64{0, 1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, saved, len(expected))
+}
+
 func testL1WatcherClientFetchBlockHeader(t *testing.T) {
 	watcher, db := setupL1Watcher(t)
 	defer database.CloseDB(db)
@@ -241,15 +313,15 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 	var l1MessageOrm *orm.L1Message
 	convey.Convey("db save l1 message failure", t, func() {
 		targetErr := errors.New("SaveL1Messages failure")
-		patchGuard.ApplyMethodFunc(l1MessageOrm, "SaveL1Messages", func(context.Context, []*orm.L1Message) error {
-			return targetErr
+		patchGuard.ApplyMethodFunc(l1MessageOrm, "SaveL1Messages", func(context.Context, []*orm.L1Message) (int64, error) {
+			return 0, targetErr
 		})
 		err := watcher.FetchContractEvent()
 		assert.Equal(t, targetErr.Error(), err.Error())
 	})
 
-	patchGuard.ApplyMethodFunc(l1MessageOrm, "SaveL1Messages", func(context.Context, []*orm.L1Message) error {
-		return nil
+	patchGuard.ApplyMethodFunc(l1MessageOrm, "SaveL1Messages", func(_ context.Context, messages []*orm.L1Message) (int64, error) {
+		return int64(len(messages)), nil
 	})
 
 	convey.Convey("FetchContractEvent success", t, func() {
@@ -302,6 +374,63 @@ func testParseBridgeEventLogsL1QueueTransactionEventSignature(t *testing.T) {
 		assert.Len(t, l2Messages, 1)
 		assert.Equal(t, l2Messages[0].Value, big.NewInt(1000).String())
 	})
+
+	convey.Convey("L1QueueTransactionEventSignature sender not allowed", t, func() {
+		sender := common.HexToAddress("0xb4c11951957c6f8f642c4af61cd6b24640fec6dc7fc607ee8206a99e92410d30")
+		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
+			tmpOut := out.(*bridgeAbi.L1QueueTransactionEvent)
+			tmpOut.QueueIndex = 100
+			tmpOut.Data = []byte("test data")
+			tmpOut.Sender = sender
+			tmpOut.Value = big.NewInt(1000)
+			tmpOut.Target = common.HexToAddress("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")
+			tmpOut.GasLimit = big.NewInt(10)
+			return nil
+		})
+		defer patchGuard.Reset()
+		defer watcher.SetAllowedSenders(nil)
+
+		watcher.SetAllowedSenders([]common.Address{common.HexToAddress("0x1")})
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		assert.NoError(t, err)
+		assert.Empty(t, rollupEvents)
+		assert.Empty(t, l2Messages)
+
+		watcher.SetAllowedSenders([]common.Address{common.HexToAddress("0x1"), sender})
+		l2Messages, rollupEvents, err = watcher.parseBridgeEventLogs(logs)
+		assert.NoError(t, err)
+		assert.Empty(t, rollupEvents)
+		assert.Len(t, l2Messages, 1)
+	})
+}
+
+func testParseBridgeEventLogsL1RevertedQueueTransactionEventSignature(t *testing.T) {
+	watcher, db := setupL1Watcher(t)
+	defer database.CloseDB(db)
+
+	// The dequeued (skipped or reverted) queue transactions are only tracked on L2, their events carry no new message.
+	logs := []types.Log{
+		{
+			Topics:      []common.Hash{bridgeAbi.L1MessageQueueABI.Events["DequeueTransaction"].ID},
+			BlockNumber: 100,
+			TxHash:      common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
+		},
+	}
+
+	convey.Convey("L1 reverted queue transaction event ignored", t, func() {
+		var unpacked bool
+		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
+			unpacked = true
+			return nil
+		})
+		defer patchGuard.Reset()
+
+		l1Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		assert.NoError(t, err)
+		assert.Empty(t, l1Messages)
+		assert.Empty(t, rollupEvents)
+		assert.False(t, unpacked)
+	})
 }
 
 func testParseBridgeEventLogsL1CommitBatchEventSignature(t *testing.T) {
@@ -387,3 +516,101 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 		assert.Equal(t, rollupEvents[0].status, commonTypes.RollupFinalized)
 	})
 }
+
+func testL1WatcherClientBackfillEvents(t *testing.T) {
+	watcher, db := setupL1Watcher(t)
+	defer database.CloseDB(db)
+
+	assert.Error(t, watcher.BackfillEvents(context.Background(), 10, 1))
+
+	var c *ethclient.Client
+	var queries []ethereum.FilterQuery
+	patchGuard := gomonkey.ApplyMethodFunc(c, "FilterLogs", func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		queries = append(queries, q)
+		return []types.Log{{BlockNumber: q.FromBlock.Uint64(), TxHash: common.BigToHash(q.FromBlock)}}, nil
+	})
+	defer patchGuard.Reset()
+
+	// each chunk emits one l1 message, indexed by the first block of the chunk.
+	patchGuard.ApplyPrivateMethod(watcher, "parseBridgeEventLogs", func(_ *L1WatcherClient, logs []types.Log) ([]*orm.L1Message, []rollupEvent, error) {
+		var l1Messages []*orm.L1Message
+		for _, vLog := range logs {
+			l1Messages = append(l1Messages, &orm.L1Message{
+				QueueIndex: vLog.BlockNumber,
+				MsgHash:    common.BigToHash(big.NewInt(int64(vLog.BlockNumber) + 1000)).String(),
+				Height:     vLog.BlockNumber,
+				Sender:     common.Address{}.String(),
+				Target:     common.Address{}.String(),
+				Value:      "0",
+				Calldata:   "0x",
+				Layer1Hash: vLog.TxHash.String(),
+			})
+		}
+		return l1Messages, nil, nil
+	})
+
+	watcher.SetBackfillChunkSize(4)
+	for i := 0; i < 2; i++ {
+		queries = nil
+		assert.NoError(t, watcher.BackfillEvents(context.Background(), 1, 10))
+		assert.Len(t, queries, 3)
+		assert.Equal(t, uint64(9), queries[2].FromBlock.Uint64())
+		assert.Equal(t, uint64(10), queries[2].ToBlock.Uint64())
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.metrics.l1WatcherBackfillProgress))
+	}
+
+	l1MessageOrm := orm.NewL1Message(db)
+	messages, err := l1MessageOrm.GetL1MessagesByQueueIndices(context.Background(), []uint64{1, 5, 9, 10})
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+	for i, queueIndex := range []uint64{1, 5, 9} {
+		assert.Equal(t, queueIndex, messages[i].QueueIndex)
+	}
+}
+
+func testL1WatcherClientStartBlockOverride(t *testing.T) {
+	watcher, db := setupL1Watcher(t)
+	defer database.CloseDB(db)
+
+	assert.Error(t, watcher.SetStartBlockOverride(0))
+	assert.NoError(t, watcher.SetStartBlockOverride(1))
+	assert.NoError(t, watcher.FetchContractEvent())
+
+	// the override survives restarts until it is cleared.
+	client, err := ethclient.Dial(base.L1gethImg.Endpoint())
+	assert.NoError(t, err)
+	l1Cfg := cfg.L1Config
+	restarted := NewL1WatcherClient(context.Background(), client, l1Cfg.StartHeight, l1Cfg.Confirmations, l1Cfg.L1MessageQueueAddress, l1Cfg.RelayerConfig.RollupContractAddress, db, nil)
+	assert.Equal(t, int64(1), restarted.startBlockOverride)
+	assert.True(t, restarted.startBlockOverridePending.Load())
+
+	assert.NoError(t, restarted.ClearStartBlockOverride())
+	restarted = NewL1WatcherClient(context.Background(), client, l1Cfg.StartHeight, l1Cfg.Confirmations, l1Cfg.L1MessageQueueAddress, l1Cfg.RelayerConfig.RollupContractAddress, db, nil)
+	assert.Equal(t, int64(0), restarted.startBlockOverride)
+	assert.False(t, restarted.startBlockOverridePending.Load())
+}
+
+func TestL1WatcherApplyStartBlockOverride(t *testing.T) {
+	w := &L1WatcherClient{
+		processedMsgHeight: 100,
+		fetchedMsgHeight:   105,
+		unconfirmedLogs:    []types.Log{{BlockNumber: 103}},
+		metrics:            initL1WatcherMetrics(nil),
+	}
+
+	// nothing is applied without a pending override.
+	w.applyStartBlockOverride()
+	assert.Equal(t, uint64(100), w.processedMsgHeight)
+
+	w.startBlockOverride = 50
+	w.startBlockOverridePending.Store(true)
+	w.applyStartBlockOverride()
+	assert.Equal(t, uint64(49), w.processedMsgHeight)
+	assert.Equal(t, uint64(49), w.fetchedMsgHeight)
+	assert.Empty(t, w.unconfirmedLogs)
+
+	// the override is applied once until it is set again.
+	w.processedMsgHeight, w.fetchedMsgHeight = 60, 60
+	w.applyStartBlockOverride()
+	assert.Equal(t, uint64(60), w.processedMsgHeight)
+}
@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	"scroll-tech/common/types/encoding"
+)
+
+// rawBlockFetcher is implemented by rpc.Client.
+type rawBlockFetcher interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// SetRPCClient sets the rpc client of the l2geth node the raw traces of the quarantined blocks are fetched with,
+// the quarantined blocks are stored without their raw trace if it is not set.
+func (w *L2WatcherClient) SetRPCClient(client *rpc.Client) {
+	w.rawClient = client
+}
+
+// isBlockDecodeError returns true if err is caused by a block trace that cannot be decoded, rather than by the node.
+func isBlockDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return true
+	}
+	// the required fields of the header are checked by its generated json decoder.
+	return strings.Contains(err.Error(), "missing required field")
+}
+
+// quarantineBlock stores the raw trace of a block that cannot be decoded, so that it is skipped until an operator
+// reprocesses or discards it.
+func (w *L2WatcherClient) quarantineBlock(ctx context.Context, number uint64, decodeErr error) error {
+	var rawBlock json.RawMessage
+	if w.rawClient != nil {
+		if err := w.rawClient.CallContext(ctx, &rawBlock, "scroll_getBlockByNumber", hexutil.EncodeUint64(number), true); err != nil {
+			log.Warn("failed to fetch the raw trace of the quarantined block", "number", number, "err", err)
+			rawBlock = nil
+		}
+	}
+	if err := w.quarantinedBlockOrm.InsertQuarantinedBlock(ctx, number, rawBlock, decodeErr.Error()); err != nil {
+		return err
+	}
+	w.metrics.rollupL2WatcherQuarantinedBlocksTotal.Inc()
+	log.Error("quarantined l2 block whose trace cannot be decoded", "number", number, "err", decodeErr)
+	return nil
+}
+
+// quarantinedBlockNumbers returns the set of the quarantined block numbers in [from, to].
+func (w *L2WatcherClient) quarantinedBlockNumbers(ctx context.Context, from, to uint64) (map[uint64]struct{}, error) {
+	numbers, err := w.quarantinedBlockOrm.GetQuarantinedBlockNumbers(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	quarantined := make(map[uint64]struct{}, len(numbers))
+	for _, number := range numbers {
+		quarantined[number] = struct{}{}
+	}
+	return quarantined, nil
+}
+
+// ReprocessQuarantinedBlock fetches a quarantined block again and stores it, the block is released from the
+// quarantine once stored. It stays quarantined if it still cannot be decoded.
+func (w *L2WatcherClient) ReprocessQuarantinedBlock(ctx context.Context, number uint64) error {
+	quarantined, err := w.quarantinedBlockOrm.GetQuarantinedBlock(ctx, number)
+	if err != nil {
+		return err
+	}
+	if quarantined == nil {
+		return fmt.Errorf("block %v is not quarantined", number)
+	}
+
+	block, err := w.getBlock(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err = w.storeBlocks(ctx, []*encoding.Block{block}); err != nil {
+		return err
+	}
+	if err = w.quarantinedBlockOrm.DeleteQuarantinedBlock(ctx, number); err != nil {
+		return err
+	}
+	log.Info("reprocessed quarantined l2 block", "number", number, "hash", block.Header.Hash().String())
+	return nil
+}
+
+// DiscardQuarantinedBlock gives up on a quarantined block, it stays skipped by the watcher.
+func (w *L2WatcherClient) DiscardQuarantinedBlock(ctx context.Context, number uint64) error {
+	discarded, err := w.quarantinedBlockOrm.DiscardQuarantinedBlock(ctx, number)
+	if err != nil {
+		return err
+	}
+	if !discarded {
+		return fmt.Errorf("block %v is not quarantined", number)
+	}
+	log.Warn("discarded quarantined l2 block", "number", number)
+	return nil
+}
@@ -0,0 +1,26 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBlockDecodeError(t *testing.T) {
+	var header gethTypes.Header
+	syntaxErr := json.Unmarshal([]byte(`{"number":`), &header)
+	typeErr := json.Unmarshal([]byte(`{"number": 1}`), &struct{ Number string }{})
+	missingFieldErr := json.Unmarshal([]byte(`{}`), &header)
+
+	for _, err := range []error{syntaxErr, typeErr, missingFieldErr} {
+		assert.Error(t, err)
+		assert.True(t, isBlockDecodeError(err), err.Error())
+		assert.True(t, isBlockDecodeError(fmt.Errorf("failed to GetBlockByNumberOrHash: %w. number: %v", err, 1)))
+	}
+	assert.False(t, isBlockDecodeError(errors.New("dial tcp 127.0.0.1:8545: connect: connection refused")))
+	assert.False(t, isBlockDecodeError(fmt.Errorf("fetched block does not contain RowConsumption. number: %v", 1)))
+}
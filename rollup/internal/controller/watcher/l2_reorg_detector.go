@@ -0,0 +1,147 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// ReorgDetector compares the locally stored l2 block hashes against the canonical chain,
+// and rolls back the blocks, chunks and batches built on top of an orphaned block.
+type ReorgDetector struct {
+	ctx    context.Context
+	client *ethclient.Client
+	db     *gorm.DB
+
+	l2BlockOrm *orm.L2Block
+	chunkOrm   *orm.Chunk
+	batchOrm   *orm.Batch
+
+	checkDepth uint64
+
+	reorgDetectedTotal prometheus.Counter
+	reorgDepth         prometheus.Gauge
+}
+
+// NewReorgDetector creates a new ReorgDetector instance, checkDepth is the number of latest stored blocks that are checked.
+func NewReorgDetector(ctx context.Context, client *ethclient.Client, checkDepth uint64, db *gorm.DB, reg prometheus.Registerer) *ReorgDetector {
+	return &ReorgDetector{
+		ctx:    ctx,
+		client: client,
+		db:     db,
+
+		l2BlockOrm: orm.NewL2Block(db),
+		chunkOrm:   orm.NewChunk(db),
+		batchOrm:   orm.NewBatch(db),
+
+		checkDepth: checkDepth,
+
+		reorgDetectedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_l2_watcher_reorg_detected_total",
+			Help: "The total number of l2 reorgs detected by the l2 watcher",
+		}),
+		reorgDepth: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l2_watcher_reorg_depth",
+			Help: "The number of orphaned blocks in the latest l2 reorg",
+		}),
+	}
+}
+
+// TryDetectReorg checks the latest stored blocks against the canonical chain. On a reorg, the orphaned blocks
+// and the chunks and batches containing them are deleted in a db transaction, so that the blocks are
+// re-collected by TryFetchRunningMissingBlocks and proposed again.
+func (d *ReorgDetector) TryDetectReorg() error {
+	if d.checkDepth == 0 {
+		return nil
+	}
+
+	latestHeight, err := d.l2BlockOrm.GetL2BlocksLatestHeight(d.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest l2 block height: %w", err)
+	}
+	if latestHeight == 0 {
+		return nil
+	}
+
+	forkHeight, err := d.findForkHeight(latestHeight)
+	if err != nil {
+		return err
+	}
+	if forkHeight > latestHeight {
+		return nil
+	}
+
+	log.Warn("detected l2 reorg", "fork height", forkHeight, "latest stored height", latestHeight)
+	d.reorgDetectedTotal.Inc()
+	d.reorgDepth.Set(float64(latestHeight - forkHeight + 1))
+
+	return d.rollback(forkHeight)
+}
+
+// findForkHeight returns the height of the first orphaned block, or latestHeight+1 if there is no reorg.
+func (d *ReorgDetector) findForkHeight(latestHeight uint64) (uint64, error) {
+	startHeight := uint64(1)
+	if latestHeight > d.checkDepth {
+		startHeight = latestHeight - d.checkDepth + 1
+	}
+
+	hashes, err := d.l2BlockOrm.GetL2BlockHashesInRange(d.ctx, startHeight, latestHeight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get l2 block hashes: %w", err)
+	}
+
+	for height := latestHeight; height >= startHeight; height-- {
+		header, err := d.client.HeaderByNumber(d.ctx, new(big.Int).SetUint64(height))
+		if err != nil {
+			return 0, fmt.Errorf("failed to get l2 header by number: %w, number: %v", err, height)
+		}
+		if header.Hash() == hashes[height-startHeight] {
+			return height + 1, nil
+		}
+	}
+
+	if startHeight > 1 {
+		return 0, fmt.Errorf("l2 reorg is deeper than the check depth, check depth: %v, latest stored height: %v", d.checkDepth, latestHeight)
+	}
+	return startHeight, nil
+}
+
+// rollback deletes the blocks from forkHeight on, together with the chunks and batches containing them.
+// Batches already submitted to L1 cannot be rolled back, in which case nothing is deleted.
+func (d *ReorgDetector) rollback(forkHeight uint64) error {
+	return d.db.Transaction(func(dbTX *gorm.DB) error {
+		chunks, err := d.chunkOrm.DeleteChunksAfterBlockNumber(d.ctx, forkHeight-1, dbTX)
+		if err != nil {
+			return err
+		}
+
+		if len(chunks) > 0 {
+			if chunks[0].Index == 0 {
+				return fmt.Errorf("l2 reorg includes the genesis chunk, fork height: %v", forkHeight)
+			}
+
+			batches, err := d.batchOrm.DeleteBatchesAfterChunkIndex(d.ctx, chunks[0].Index-1, dbTX)
+			if err != nil {
+				return err
+			}
+			for _, batch := range batches {
+				if status := types.RollupStatus(batch.RollupStatus); status != types.RollupPending {
+					return fmt.Errorf("l2 reorg includes a batch already submitted to L1, batch index: %v, rollup status: %v", batch.Index, status)
+				}
+			}
+			log.Info("rolled back chunks and batches of l2 reorg", "first chunk index", chunks[0].Index, "chunks", len(chunks), "batches", len(batches))
+		}
+
+		return d.l2BlockOrm.DeleteL2BlocksAfterNumber(d.ctx, forkHeight-1, dbTX)
+	})
+}
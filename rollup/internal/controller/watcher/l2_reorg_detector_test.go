@@ -0,0 +1,111 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/encoding"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+func prepareReorgDetectorDB(t *testing.T, db *gorm.DB) []*orm.Batch {
+	l2BlockOrm := orm.NewL2Block(db)
+	chunkOrm := orm.NewChunk(db)
+	batchOrm := orm.NewBatch(db)
+
+	assert.NoError(t, l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2}))
+
+	var batches []*orm.Batch
+	for i, block := range []*encoding.Block{block1, block2} {
+		chunk := &encoding.Chunk{Blocks: []*encoding.Block{block}}
+		dbChunk, err := chunkOrm.InsertChunk(context.Background(), chunk)
+		assert.NoError(t, err)
+
+		batch := &encoding.Batch{
+			Index:           uint64(i),
+			Chunks:          []*encoding.Chunk{chunk},
+			StartChunkIndex: dbChunk.Index,
+			StartChunkHash:  common.HexToHash(dbChunk.Hash),
+			EndChunkIndex:   dbChunk.Index,
+			EndChunkHash:    common.HexToHash(dbChunk.Hash),
+		}
+		dbBatch, err := batchOrm.InsertBatch(context.Background(), batch)
+		assert.NoError(t, err)
+		batches = append(batches, dbBatch)
+	}
+	return batches
+}
+
+func testL2ReorgDetector(t *testing.T) {
+	// The canonical chain keeps block1 and replaces block2.
+	reorgedHeader := gethTypes.CopyHeader(block2.Header)
+	reorgedHeader.Extra = []byte("reorged")
+	patchGuard := gomonkey.ApplyMethodFunc(l2Cli, "HeaderByNumber", func(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+		if number.Uint64() == block2.Header.Number.Uint64() {
+			return reorgedHeader, nil
+		}
+		return block1.Header, nil
+	})
+	defer patchGuard.Reset()
+
+	t.Run("rollback pending batches", func(t *testing.T) {
+		db := setupDB(t)
+		defer database.CloseDB(db)
+		prepareReorgDetectorDB(t, db)
+
+		detector := NewReorgDetector(context.Background(), l2Cli, 64, db, nil)
+		assert.NoError(t, detector.TryDetectReorg())
+
+		height, err := orm.NewL2Block(db).GetL2BlocksLatestHeight(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, block1.Header.Number.Uint64(), height)
+
+		latestChunk, err := orm.NewChunk(db).GetLatestChunk(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, block1.Header.Number.Uint64(), latestChunk.EndBlockNumber)
+
+		count, err := orm.NewBatch(db).GetBatchCount(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), count)
+
+		// No reorg left to handle.
+		assert.NoError(t, detector.TryDetectReorg())
+	})
+
+	t.Run("committed batch is not rolled back", func(t *testing.T) {
+		db := setupDB(t)
+		defer database.CloseDB(db)
+		batches := prepareReorgDetectorDB(t, db)
+		assert.NoError(t, orm.NewBatch(db).UpdateRollupStatus(context.Background(), batches[1].Hash, types.RollupCommitted))
+
+		detector := NewReorgDetector(context.Background(), l2Cli, 64, db, nil)
+		assert.Error(t, detector.TryDetectReorg())
+
+		height, err := orm.NewL2Block(db).GetL2BlocksLatestHeight(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, block2.Header.Number.Uint64(), height)
+
+		count, err := orm.NewBatch(db).GetBatchCount(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), count)
+	})
+
+	t.Run("reorg deeper than check depth", func(t *testing.T) {
+		db := setupDB(t)
+		defer database.CloseDB(db)
+		prepareReorgDetectorDB(t, db)
+
+		detector := NewReorgDetector(context.Background(), l2Cli, 1, db, nil)
+		assert.Error(t, detector.TryDetectReorg())
+	})
+}
@@ -2,8 +2,11 @@ package watcher
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
@@ -14,6 +17,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 	"gorm.io/gorm"
 
 	"scroll-tech/common/types/encoding"
@@ -30,7 +35,11 @@ type L2WatcherClient struct {
 
 	*ethclient.Client
 
-	l2BlockOrm *orm.L2Block
+	l2BlockOrm          *orm.L2Block
+	quarantinedBlockOrm *orm.QuarantinedBlock
+
+	// The client the raw traces of the quarantined blocks are fetched with, not fetched if nil
+	rawClient rawBlockFetcher
 
 	confirmations rpc.BlockNumber
 
@@ -38,6 +47,13 @@ type L2WatcherClient struct {
 	messageQueueABI      *abi.ABI
 	withdrawTrieRootSlot common.Hash
 
+	// The maximum number of parallel block requests of FetchMissingBlocks
+	fetchConcurrency int
+	// The maximum number of blocks read per second by ExportBlockTraces, unlimited if 0
+	exportRateLimit int
+	// The limiter of the rpc requests fetching the blocks, unlimited if nil
+	rpcLimiter *rate.Limiter
+
 	metrics *l2WatcherMetrics
 }
 
@@ -47,7 +63,8 @@ func NewL2WatcherClient(ctx context.Context, client *ethclient.Client, confirmat
 		ctx:    ctx,
 		Client: client,
 
-		l2BlockOrm: orm.NewL2Block(db),
+		l2BlockOrm:          orm.NewL2Block(db),
+		quarantinedBlockOrm: orm.NewQuarantinedBlock(db),
 
 		confirmations: confirmations,
 
@@ -59,8 +76,50 @@ func NewL2WatcherClient(ctx context.Context, client *ethclient.Client, confirmat
 	}
 }
 
+// SetFetchConcurrency sets the maximum number of parallel block requests of FetchMissingBlocks.
+func (w *L2WatcherClient) SetFetchConcurrency(fetchConcurrency int) {
+	w.fetchConcurrency = fetchConcurrency
+}
+
 const blockTracesFetchLimit = uint64(10)
 
+// SetExportRateLimit sets the maximum number of blocks read per second by ExportBlockTraces.
+func (w *L2WatcherClient) SetExportRateLimit(blocksPerSec int) {
+	w.exportRateLimit = blocksPerSec
+}
+
+// SetMaxRPCRequestsPerSecond sets the maximum number of rpc requests per second fetching the blocks, unlimited if 0.
+func (w *L2WatcherClient) SetMaxRPCRequestsPerSecond(requestsPerSecond float64) {
+	if requestsPerSecond <= 0 {
+		w.rpcLimiter = nil
+		return
+	}
+	w.rpcLimiter = rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+}
+
+// waitRPCRateLimit blocks until the next rpc request is allowed by the rate limit, the delay is recorded.
+func (w *L2WatcherClient) waitRPCRateLimit(ctx context.Context) error {
+	if w.rpcLimiter == nil {
+		return nil
+	}
+	reservation := w.rpcLimiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	w.metrics.rollupL2WatcherRPCRateLimitWaitSeconds.Observe(delay.Seconds())
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
 // TryFetchRunningMissingBlocks attempts to fetch and store block traces for any missing blocks.
 func (w *L2WatcherClient) TryFetchRunningMissingBlocks(blockHeight uint64) {
 	w.metrics.fetchRunningMissingBlocksTotal.Inc()
@@ -123,32 +182,138 @@ func txsToTxsData(txs gethTypes.Transactions) []*gethTypes.TransactionData {
 	return txsData
 }
 
-func (w *L2WatcherClient) getAndStoreBlocks(ctx context.Context, from, to uint64) error {
-	var blocks []*encoding.Block
-	for number := from; number <= to; number++ {
-		log.Debug("retrieving block", "height", number)
-		block, err := w.GetBlockByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)))
-		if err != nil {
-			return fmt.Errorf("failed to GetBlockByNumberOrHash: %v. number: %v", err, number)
+// FetchMissingBlocks fetches the blocks missing between the stored l2 blocks, at most maxGap blocks per call
+// starting from the lowest gap, and stores them at once. It returns the number of filled blocks.
+// The blocks are fetched with at most fetchConcurrency parallel requests.
+func (w *L2WatcherClient) FetchMissingBlocks(ctx context.Context, maxGap int) (int, error) {
+	if maxGap <= 0 {
+		return 0, fmt.Errorf("invalid max gap: %d", maxGap)
+	}
+
+	gaps, err := w.l2BlockOrm.GetL2BlockNumberGaps(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get l2 block number gaps: %w", err)
+	}
+	var numbers []uint64
+	for _, gap := range gaps {
+		// the quarantined blocks are left missing until they are reprocessed.
+		quarantined, quarantineErr := w.quarantinedBlockNumbers(ctx, gap.Start, gap.End)
+		if quarantineErr != nil {
+			return 0, quarantineErr
 		}
-		if block.RowConsumption == nil {
-			return fmt.Errorf("fetched block does not contain RowConsumption. number: %v", number)
+		for number := gap.Start; number <= gap.End && len(numbers) < maxGap; number++ {
+			if _, ok := quarantined[number]; !ok {
+				numbers = append(numbers, number)
+			}
 		}
+	}
+	if len(numbers) == 0 {
+		return 0, nil
+	}
 
-		log.Info("retrieved block", "height", block.Header().Number, "hash", block.Header().Hash().String())
+	concurrency := w.fetchConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var (
+		eg     errgroup.Group
+		blocks = make([]*encoding.Block, len(numbers))
+		sem    = make(chan struct{}, concurrency)
+	)
+	for i, number := range numbers {
+		sem <- struct{}{}
+		i, number := i, number
+		eg.Go(func() error {
+			defer func() { <-sem }()
+			block, getErr := w.getBlock(ctx, number)
+			if getErr != nil {
+				if isBlockDecodeError(getErr) {
+					return w.quarantineBlock(ctx, number, getErr)
+				}
+				return getErr
+			}
+			blocks[i] = block
+			return nil
+		})
+	}
+	if err = eg.Wait(); err != nil {
+		return 0, err
+	}
+	// the quarantined blocks are not stored.
+	fetched := blocks[:0]
+	for _, block := range blocks {
+		if block != nil {
+			fetched = append(fetched, block)
+		}
+	}
+	blocks = fetched
 
-		withdrawRoot, err3 := w.StorageAt(ctx, w.messageQueueAddress, w.withdrawTrieRootSlot, big.NewInt(int64(number)))
-		if err3 != nil {
-			return fmt.Errorf("failed to get withdrawRoot: %v. number: %v", err3, number)
+	if err = w.storeBlocks(ctx, blocks); err != nil {
+		return 0, err
+	}
+	w.metrics.fetchMissingBlocksFilledTotal.Add(float64(len(blocks)))
+	log.Info("filled missing l2 blocks", "from", numbers[0], "to", numbers[len(numbers)-1], "count", len(blocks))
+	return len(blocks), nil
+}
+
+func (w *L2WatcherClient) getBlock(ctx context.Context, number uint64) (*encoding.Block, error) {
+	log.Debug("retrieving block", "height", number)
+	if err := w.waitRPCRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	block, err := w.GetBlockByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetBlockByNumberOrHash: %w. number: %v", err, number)
+	}
+	if block.RowConsumption == nil {
+		return nil, fmt.Errorf("fetched block does not contain RowConsumption. number: %v", number)
+	}
+
+	log.Info("retrieved block", "height", block.Header().Number, "hash", block.Header().Hash().String())
+
+	if err = w.waitRPCRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	withdrawRoot, err := w.StorageAt(ctx, w.messageQueueAddress, w.withdrawTrieRootSlot, big.NewInt(int64(number)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawRoot: %v. number: %v", err, number)
+	}
+	return &encoding.Block{
+		Header:         block.Header(),
+		Transactions:   txsToTxsData(block.Transactions()),
+		WithdrawRoot:   common.BytesToHash(withdrawRoot),
+		RowConsumption: block.RowConsumption,
+	}, nil
+}
+
+// getAndStoreBlocks fetches and stores the blocks in [from, to], the blocks whose trace cannot be decoded are
+// quarantined and skipped.
+func (w *L2WatcherClient) getAndStoreBlocks(ctx context.Context, from, to uint64) error {
+	quarantined, err := w.quarantinedBlockNumbers(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	var blocks []*encoding.Block
+	for number := from; number <= to; number++ {
+		if _, ok := quarantined[number]; ok {
+			continue
 		}
-		blocks = append(blocks, &encoding.Block{
-			Header:         block.Header(),
-			Transactions:   txsToTxsData(block.Transactions()),
-			WithdrawRoot:   common.BytesToHash(withdrawRoot),
-			RowConsumption: block.RowConsumption,
-		})
+		block, err := w.getBlock(ctx, number)
+		if err != nil {
+			if !isBlockDecodeError(err) {
+				return err
+			}
+			if err = w.quarantineBlock(ctx, number, err); err != nil {
+				return err
+			}
+			continue
+		}
+		blocks = append(blocks, block)
 	}
+	return w.storeBlocks(ctx, blocks)
+}
 
+func (w *L2WatcherClient) storeBlocks(ctx context.Context, blocks []*encoding.Block) error {
 	if len(blocks) > 0 {
 		for _, block := range blocks {
 			blockL1CommitCalldataSize, err := codecv0.EstimateBlockL1CommitCalldataSize(block)
@@ -157,10 +322,99 @@ func (w *L2WatcherClient) getAndStoreBlocks(ctx context.Context, from, to uint64
 			}
 			w.metrics.rollupL2BlockL1CommitCalldataSize.Set(float64(blockL1CommitCalldataSize))
 		}
-		if err := w.l2BlockOrm.InsertL2Blocks(w.ctx, blocks); err != nil {
+		if err := w.l2BlockOrm.InsertL2Blocks(ctx, blocks); err != nil {
 			return fmt.Errorf("failed to batch insert BlockTraces: %v", err)
 		}
 	}
 
 	return nil
 }
+
+// blockTraceBackfillLimit is the number of legacy l2 blocks encoded per BackfillBlockTraceBinary round.
+const blockTraceBackfillLimit = 100
+
+// BackfillBlockTraceBinary encodes the legacy l2 blocks, stored as json only, into their binary encoding.
+// It returns once no legacy block is left, or on the first error.
+func (w *L2WatcherClient) BackfillBlockTraceBinary(ctx context.Context) error {
+	var total int
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		count, err := w.l2BlockOrm.BackfillBlockTraceBinary(ctx, blockTraceBackfillLimit)
+		if err != nil {
+			return fmt.Errorf("failed to backfill block trace binary: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		total += count
+		log.Debug("backfilled block trace binary", "count", count, "total", total)
+	}
+	if total > 0 {
+		log.Info("finished backfilling block trace binary", "total", total)
+	}
+	return nil
+}
+
+// blockTraceExportLimit is the number of l2 blocks read from the db per ExportBlockTraces round.
+const blockTraceExportLimit = 100
+
+// ExportBlockTraces writes the stored l2 blocks from fromBlock to toBlock (inclusive) to writer as newline-delimited
+// json, one block trace per line in ascending order. If afterBlockNumber is not nil, the export resumes after it,
+// e.g. after the last block written by an interrupted export.
+func (w *L2WatcherClient) ExportBlockTraces(ctx context.Context, fromBlock, toBlock uint64, afterBlockNumber *uint64, writer io.Writer) error {
+	if fromBlock > toBlock {
+		return fmt.Errorf("from block %d is greater than to block %d", fromBlock, toBlock)
+	}
+	start := fromBlock
+	if afterBlockNumber != nil && *afterBlockNumber >= start {
+		if *afterBlockNumber >= toBlock {
+			return nil
+		}
+		start = *afterBlockNumber + 1
+	}
+
+	limit := uint64(blockTraceExportLimit)
+	var limiter *rate.Limiter
+	if w.exportRateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(w.exportRateLimit), w.exportRateLimit)
+		if uint64(w.exportRateLimit) < limit {
+			limit = uint64(w.exportRateLimit)
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	for {
+		end := toBlock
+		if toBlock-start >= limit {
+			end = start + limit - 1
+		}
+		if limiter != nil {
+			if err := limiter.WaitN(ctx, int(end-start+1)); err != nil {
+				return err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		blocks, err := w.l2BlockOrm.GetL2BlocksInRange(ctx, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to get l2 blocks in range %d-%d: %w", start, end, err)
+		}
+		for i, block := range blocks {
+			if err = encoder.Encode(block); err != nil {
+				return fmt.Errorf("failed to write block trace %d: %w", start+uint64(i), err)
+			}
+		}
+		log.Debug("exported block traces", "from", start, "to", end)
+		if end == toBlock {
+			break
+		}
+		start = end + 1
+	}
+	return nil
+}
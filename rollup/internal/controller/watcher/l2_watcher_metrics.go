@@ -12,6 +12,10 @@ type l2WatcherMetrics struct {
 	fetchRunningMissingBlocksHeight   prometheus.Gauge
 	rollupL2BlocksFetchedGap          prometheus.Gauge
 	rollupL2BlockL1CommitCalldataSize prometheus.Gauge
+	fetchMissingBlocksFilledTotal     prometheus.Counter
+
+	rollupL2WatcherQuarantinedBlocksTotal  prometheus.Counter
+	rollupL2WatcherRPCRateLimitWaitSeconds prometheus.Histogram
 }
 
 var (
@@ -38,6 +42,19 @@ func initL2WatcherMetrics(reg prometheus.Registerer) *l2WatcherMetrics {
 				Name: "rollup_l2_block_l1_commit_calldata_size",
 				Help: "The l1 commitBatch calldata size of the l2 block",
 			}),
+			fetchMissingBlocksFilledTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l2_watcher_fetch_missing_blocks_filled_total",
+				Help: "The total number of l2 blocks filled into the gaps of the stored block range",
+			}),
+			rollupL2WatcherQuarantinedBlocksTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l2_watcher_quarantined_blocks_total",
+				Help: "The total number of l2 blocks quarantined because their trace cannot be decoded",
+			}),
+			rollupL2WatcherRPCRateLimitWaitSeconds: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+				Name:    "rollup_l2_watcher_rpc_rate_limit_wait_seconds",
+				Help:    "The time the l2 rpc requests of the l2 watcher are delayed by the rate limit",
+				Buckets: prometheus.ExponentialBucketsRange(0.001, 10, 12),
+			}),
 		}
 	})
 	return l2WatcherMetric
@@ -1,13 +1,19 @@
 package watcher
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 
+	"github.com/agiledragon/gomonkey/v2"
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethclient"
@@ -15,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"scroll-tech/common/database"
+	"scroll-tech/common/types/encoding"
 	cutils "scroll-tech/common/utils"
 
 	"scroll-tech/rollup/internal/orm"
@@ -54,6 +61,171 @@ func testFetchRunningMissingBlocks(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func testFetchMissingBlocks(t *testing.T) {
+	watcher, db := setupL2Watcher(t)
+	defer database.CloseDB(db)
+	watcher.SetFetchConcurrency(2)
+
+	_, err := watcher.FetchMissingBlocks(context.Background(), 0)
+	assert.Error(t, err)
+
+	latestHeight, err := l2Cli.BlockNumber(context.Background())
+	assert.NoError(t, err)
+	if latestHeight < 4 {
+		t.Skip("not enough l2 blocks")
+	}
+	watcher.TryFetchRunningMissingBlocks(latestHeight)
+
+	// remove blocks 2 and 3 from the stored range.
+	assert.NoError(t, db.Unscoped().Where("number IN ?", []uint64{2, 3}).Delete(&orm.L2Block{}).Error)
+
+	filled, err := watcher.FetchMissingBlocks(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, filled)
+	filled, err = watcher.FetchMissingBlocks(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, filled)
+	filled, err = watcher.FetchMissingBlocks(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, filled)
+
+	hashes, err := orm.NewL2Block(db).GetL2BlockHashesInRange(context.Background(), 1, latestHeight)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, int(latestHeight))
+}
+
+func testL2WatcherQuarantineBlock(t *testing.T) {
+	watcher, db := setupL2Watcher(t)
+	defer database.CloseDB(db)
+
+	decodeErr := json.Unmarshal([]byte(`{"number":`), &struct{}{})
+	assert.True(t, isBlockDecodeError(decodeErr))
+
+	// block 3 cannot be decoded, the other blocks are copies of block1.
+	patches := gomonkey.ApplyPrivateMethod(watcher, "getBlock", func(_ *L2WatcherClient, ctx context.Context, number uint64) (*encoding.Block, error) {
+		if number == 3 {
+			return nil, fmt.Errorf("failed to GetBlockByNumberOrHash: %w. number: %v", decodeErr, number)
+		}
+		header := *block1.Header
+		header.Number = new(big.Int).SetUint64(number)
+		return &encoding.Block{Header: &header, Transactions: block1.Transactions, WithdrawRoot: block1.WithdrawRoot, RowConsumption: block1.RowConsumption}, nil
+	})
+	assert.NoError(t, watcher.getAndStoreBlocks(context.Background(), 1, 5))
+	// the quarantined block is skipped without error afterwards.
+	assert.NoError(t, watcher.getAndStoreBlocks(context.Background(), 1, 5))
+	filled, err := watcher.FetchMissingBlocks(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, filled)
+	patches.Reset()
+
+	l2BlockOrm := orm.NewL2Block(db)
+	gaps, err := l2BlockOrm.GetL2BlockNumberGaps(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []orm.L2BlockNumberGap{{Start: 3, End: 3}}, gaps)
+
+	quarantinedBlockOrm := orm.NewQuarantinedBlock(db)
+	quarantined, err := quarantinedBlockOrm.GetQuarantinedBlock(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Contains(t, quarantined.Error, decodeErr.Error())
+
+	assert.Error(t, watcher.ReprocessQuarantinedBlock(context.Background(), 4))
+	assert.Error(t, watcher.DiscardQuarantinedBlock(context.Background(), 4))
+
+	latestHeight, err := l2Cli.BlockNumber(context.Background())
+	assert.NoError(t, err)
+	if latestHeight < 3 {
+		t.Skip("not enough l2 blocks")
+	}
+	assert.NoError(t, watcher.ReprocessQuarantinedBlock(context.Background(), 3))
+	quarantined, err = quarantinedBlockOrm.GetQuarantinedBlock(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Nil(t, quarantined)
+	gaps, err = l2BlockOrm.GetL2BlockNumberGaps(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, gaps)
+}
+
+func testL2WatcherExportBlockTraces(t *testing.T) {
+	watcher, db := setupL2Watcher(t)
+	defer database.CloseDB(db)
+	watcher.SetExportRateLimit(1)
+
+	assert.NoError(t, orm.NewL2Block(db).InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2}))
+	from, to := block1.Header.Number.Uint64(), block2.Header.Number.Uint64()
+
+	exported := func(buf *bytes.Buffer) []*encoding.Block {
+		var blocks []*encoding.Block
+		scanner := bufio.NewScanner(buf)
+		scanner.Buffer(nil, 16*1024*1024)
+		for scanner.Scan() {
+			block := &encoding.Block{}
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), block))
+			blocks = append(blocks, block)
+		}
+		assert.NoError(t, scanner.Err())
+		return blocks
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, watcher.ExportBlockTraces(context.Background(), from, to, nil, &buf))
+	blocks := exported(&buf)
+	assert.Len(t, blocks, 2)
+	assert.Equal(t, block1.Header.Hash(), blocks[0].Header.Hash())
+	assert.Equal(t, block2.Header.Hash(), blocks[1].Header.Hash())
+	assert.Equal(t, len(block2.Transactions), len(blocks[1].Transactions))
+
+	// resume after the first block.
+	buf.Reset()
+	assert.NoError(t, watcher.ExportBlockTraces(context.Background(), from, to, &from, &buf))
+	blocks = exported(&buf)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, block2.Header.Hash(), blocks[0].Header.Hash())
+
+	// the stored blocks must cover the range.
+	assert.Error(t, watcher.ExportBlockTraces(context.Background(), from, to+1, nil, &buf))
+}
+
+func TestL2WatcherExportBlockTracesRange(t *testing.T) {
+	watcher := NewL2WatcherClient(context.Background(), nil, rpc.LatestBlockNumber, common.Address{}, common.Hash{}, nil, nil)
+
+	var buf bytes.Buffer
+	assert.ErrorContains(t, watcher.ExportBlockTraces(context.Background(), 2, 1, nil, &buf), "greater than to block")
+
+	// the export is already complete.
+	after := uint64(10)
+	assert.NoError(t, watcher.ExportBlockTraces(context.Background(), 1, 10, &after, &buf))
+	assert.Zero(t, buf.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, watcher.ExportBlockTraces(ctx, 1, 10, nil, &buf), context.Canceled)
+}
+
+func TestL2WatcherRPCRateLimit(t *testing.T) {
+	watcher := NewL2WatcherClient(context.Background(), nil, rpc.LatestBlockNumber, common.Address{}, common.Hash{}, nil, nil)
+
+	// unlimited by default.
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, watcher.waitRPCRateLimit(context.Background()))
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the first request is not delayed, the 99 others are spaced by 100ms.
+	watcher.SetMaxRPCRequestsPerSecond(10)
+	waits := histogramSampleCount(t, watcher.metrics.rollupL2WatcherRPCRateLimitWaitSeconds)
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, watcher.waitRPCRateLimit(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 9900*time.Millisecond)
+	assert.Equal(t, waits+99, histogramSampleCount(t, watcher.metrics.rollupL2WatcherRPCRateLimitWaitSeconds))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, watcher.waitRPCRateLimit(ctx), context.Canceled)
+}
+
 func prepareWatcherClient(l2Cli *ethclient.Client, db *gorm.DB, contractAddr common.Address) *L2WatcherClient {
 	confirmations := rpc.LatestBlockNumber
 	return NewL2WatcherClient(context.Background(), l2Cli, confirmations, contractAddr, common.Hash{}, db, nil)
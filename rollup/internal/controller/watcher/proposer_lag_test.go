@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func TestChunkProposerLag(t *testing.T) {
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{LagAlertThresholdBlocks: 5}, &params.ChainConfig{}, nil, prometheus.NewRegistry())
+
+	var latestBlockHeight, unchunkedBlockHeight uint64
+	patches := gomonkey.ApplyMethodFunc(cp.l2BlockOrm, "GetL2BlocksLatestHeight", func(ctx context.Context) (uint64, error) {
+		return latestBlockHeight, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(cp.chunkOrm, "GetUnchunkedBlockHeight", func(ctx context.Context) (uint64, error) {
+		return unchunkedBlockHeight, nil
+	})
+
+	tests := []struct {
+		latestBlockHeight    uint64
+		unchunkedBlockHeight uint64
+		expectedLag          float64
+	}{
+		{0, 1, 0},
+		{10, 1, 10},
+		{10, 8, 3},
+		{20, 8, 13},
+		{20, 21, 0},
+	}
+	for _, tt := range tests {
+		latestBlockHeight, unchunkedBlockHeight = tt.latestBlockHeight, tt.unchunkedBlockHeight
+		cp.updateProposerLag(context.Background())
+		assert.Equal(t, tt.expectedLag, testutil.ToFloat64(cp.rollupChunkProposerLagBlocks))
+	}
+}
+
+func TestBatchProposerLag(t *testing.T) {
+	bp := NewBatchProposer(context.Background(), &config.BatchProposerConfig{LagAlertThresholdChunks: 5}, &params.ChainConfig{}, nil, prometheus.NewRegistry())
+
+	var latestChunk *orm.Chunk
+	var unbatchedChunkIndex uint64
+	patches := gomonkey.ApplyMethodFunc(bp.chunkOrm, "GetLatestChunk", func(ctx context.Context) (*orm.Chunk, error) {
+		if latestChunk == nil {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return latestChunk, nil
+	})
+	defer patches.Reset()
+	patches.ApplyMethodFunc(bp.batchOrm, "GetFirstUnbatchedChunkIndex", func(ctx context.Context) (uint64, error) {
+		return unbatchedChunkIndex, nil
+	})
+
+	tests := []struct {
+		latestChunk         *orm.Chunk
+		unbatchedChunkIndex uint64
+		expectedLag         float64
+	}{
+		{nil, 0, 0},
+		{&orm.Chunk{Index: 0}, 0, 1},
+		{&orm.Chunk{Index: 9}, 0, 10},
+		{&orm.Chunk{Index: 9}, 7, 3},
+		{&orm.Chunk{Index: 9}, 10, 0},
+	}
+	for _, tt := range tests {
+		latestChunk, unbatchedChunkIndex = tt.latestChunk, tt.unbatchedChunkIndex
+		bp.updateProposerLag(context.Background())
+		assert.Equal(t, tt.expectedLag, testutil.ToFloat64(bp.rollupBatchProposerLagChunks))
+	}
+}
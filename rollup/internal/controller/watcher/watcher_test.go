@@ -103,19 +103,36 @@ func TestFunction(t *testing.T) {
 
 	// Run l1 watcher test cases.
 	t.Run("TestStartWatcher", testFetchContractEvent)
+	t.Run("TestFetchContractEventReplay", testFetchContractEventReplay)
 	t.Run("TestL1WatcherClientFetchBlockHeader", testL1WatcherClientFetchBlockHeader)
 	t.Run("TestL1WatcherClientFetchContractEvent", testL1WatcherClientFetchContractEvent)
 	t.Run("TestParseBridgeEventLogsL1QueueTransactionEventSignature", testParseBridgeEventLogsL1QueueTransactionEventSignature)
+	t.Run("TestParseBridgeEventLogsL1RevertedQueueTransactionEventSignature", testParseBridgeEventLogsL1RevertedQueueTransactionEventSignature)
 	t.Run("TestParseBridgeEventLogsL1CommitBatchEventSignature", testParseBridgeEventLogsL1CommitBatchEventSignature)
 	t.Run("TestParseBridgeEventLogsL1FinalizeBatchEventSignature", testParseBridgeEventLogsL1FinalizeBatchEventSignature)
+	t.Run("TestL1WatcherClientBackfillEvents", testL1WatcherClientBackfillEvents)
+	t.Run("TestL1WatcherClientStartBlockOverride", testL1WatcherClientStartBlockOverride)
+	t.Run("TestL1WatcherValidateBlockChain", testL1WatcherValidateBlockChain)
+	t.Run("TestL1WatcherDeepReorg", testL1WatcherDeepReorg)
+	t.Run("TestL1WatcherExpireOldMessages", testL1WatcherExpireOldMessages)
 
 	// Run l2 watcher test cases.
 	t.Run("TestFetchRunningMissingBlocks", testFetchRunningMissingBlocks)
+	t.Run("TestFetchMissingBlocks", testFetchMissingBlocks)
+	t.Run("TestL2ReorgDetector", testL2ReorgDetector)
+	t.Run("TestL2WatcherExportBlockTraces", testL2WatcherExportBlockTraces)
+	t.Run("TestL2WatcherQuarantineBlock", testL2WatcherQuarantineBlock)
 
 	// Run chunk proposer test cases.
 	t.Run("TestChunkProposerLimits", testChunkProposerLimits)
+	t.Run("TestChunkProposerCommitGasLimit", testChunkProposerCommitGasLimit)
+	t.Run("TestChunkProposerReproposeFailed", testChunkProposerReproposeFailed)
+	t.Run("TestChunkProposerChunkStats", testChunkProposerChunkStats)
+	t.Run("TestChunkProposerDumpProposalPlan", testChunkProposerDumpProposalPlan)
 
 	// Run chunk proposer test cases.
 	t.Run("TestBatchProposerLimits", testBatchProposerLimits)
 	t.Run("TestBatchCommitGasAndCalldataSizeEstimation", testBatchCommitGasAndCalldataSizeEstimation)
+	t.Run("TestBatchProposerTimeWindowStrategy", testBatchProposerTimeWindowStrategy)
+	t.Run("TestBatchProposerMaxBatchAge", testBatchProposerMaxBatchAge)
 }
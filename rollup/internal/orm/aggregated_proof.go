@@ -0,0 +1,109 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/types/message"
+)
+
+// AggregatedProof is the proof of a batch aggregated from the proofs of its chunks.
+type AggregatedProof struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	BatchIndex   uint64 `json:"batch_index" gorm:"column:batch_index;primaryKey"`
+	BatchHash    string `json:"batch_hash" gorm:"column:batch_hash"`
+	ChunkProofs  []byte `json:"chunk_proofs" gorm:"column:chunk_proofs"`
+	Proof        []byte `json:"proof" gorm:"column:proof"`
+	PublicInputs []byte `json:"public_inputs" gorm:"column:public_inputs"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewAggregatedProof creates a new AggregatedProof database instance.
+func NewAggregatedProof(db *gorm.DB) *AggregatedProof {
+	return &AggregatedProof{db: db}
+}
+
+// TableName returns the table name for the AggregatedProof model.
+func (*AggregatedProof) TableName() string {
+	return "aggregated_proof"
+}
+
+// GetAggregatedProof returns the aggregated proof of the batch of the given index, nil if there is none.
+func (o *AggregatedProof) GetAggregatedProof(ctx context.Context, batchIndex uint64) (*message.AggregatedProof, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&AggregatedProof{})
+	db = db.Where("batch_index = ?", batchIndex)
+
+	var aggregatedProof AggregatedProof
+	if err := db.First(&aggregatedProof).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("AggregatedProof.GetAggregatedProof error: %w, batch index: %v", err, batchIndex)
+	}
+
+	var chunkProofs []message.ChunkProof
+	if err := json.Unmarshal(aggregatedProof.ChunkProofs, &chunkProofs); err != nil {
+		return nil, fmt.Errorf("AggregatedProof.GetAggregatedProof error: %w, batch index: %v", err, batchIndex)
+	}
+	return &message.AggregatedProof{
+		BatchIndex:             aggregatedProof.BatchIndex,
+		ChunkProofs:            chunkProofs,
+		AggregatedProofData:    aggregatedProof.Proof,
+		AggregatedPublicInputs: aggregatedProof.PublicInputs,
+	}, nil
+}
+
+// InsertAggregatedProof stores the aggregated proof of a batch, the proof of a batch aggregated again is replaced.
+func (o *AggregatedProof) InsertAggregatedProof(ctx context.Context, batchHash string, proof *message.AggregatedProof, dbTX ...*gorm.DB) error {
+	chunkProofs, err := json.Marshal(proof.ChunkProofs)
+	if err != nil {
+		return fmt.Errorf("AggregatedProof.InsertAggregatedProof error: %w, batch index: %v", err, proof.BatchIndex)
+	}
+
+	aggregatedProof := AggregatedProof{
+		BatchIndex:   proof.BatchIndex,
+		BatchHash:    batchHash,
+		ChunkProofs:  chunkProofs,
+		Proof:        proof.AggregatedProofData,
+		PublicInputs: proof.AggregatedPublicInputs,
+	}
+
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "batch_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"batch_hash", "chunk_proofs", "proof", "public_inputs", "updated_at"}),
+	})
+	if err = db.Create(&aggregatedProof).Error; err != nil {
+		return fmt.Errorf("AggregatedProof.InsertAggregatedProof error: %w, batch index: %v", err, proof.BatchIndex)
+	}
+	return nil
+}
+
+// DeleteAggregatedProof deletes the aggregated proof of the batch of the given index.
+func (o *AggregatedProof) DeleteAggregatedProof(ctx context.Context, batchIndex uint64, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Where("batch_index = ?", batchIndex)
+	if err := db.Delete(&AggregatedProof{}).Error; err != nil {
+		return fmt.Errorf("AggregatedProof.DeleteAggregatedProof error: %w, batch index: %v", err, batchIndex)
+	}
+	return nil
+}
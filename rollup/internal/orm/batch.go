@@ -19,7 +19,8 @@ import (
 
 // Batch represents a batch of chunks.
 type Batch struct {
-	db *gorm.DB `gorm:"column:-"`
+	db      *gorm.DB    `gorm:"column:-"`
+	replica readReplica `gorm:"-"`
 
 	// batch
 	Index           uint64 `json:"index" gorm:"column:index"`
@@ -41,6 +42,9 @@ type Batch struct {
 	ProvedAt          *time.Time `json:"proved_at" gorm:"column:proved_at;default:NULL"`
 	ProofTimeSec      int32      `json:"proof_time_sec" gorm:"column:proof_time_sec;default:NULL"`
 
+	// proof aggregation
+	ProofAggregationStatus int16 `json:"proof_aggregation_status" gorm:"column:proof_aggregation_status;default:1"`
+
 	// rollup
 	RollupStatus   int16      `json:"rollup_status" gorm:"column:rollup_status;default:1"`
 	CommitTxHash   string     `json:"commit_tx_hash" gorm:"column:commit_tx_hash;default:NULL"`
@@ -48,10 +52,17 @@ type Batch struct {
 	FinalizeTxHash string     `json:"finalize_tx_hash" gorm:"column:finalize_tx_hash;default:NULL"`
 	FinalizedAt    *time.Time `json:"finalized_at" gorm:"column:finalized_at;default:NULL"`
 
+	// finalization
+	FinalizationStatus int16 `json:"finalization_status" gorm:"column:finalization_status;default:1"`
+
 	// gas oracle
 	OracleStatus int16  `json:"oracle_status" gorm:"column:oracle_status;default:1"`
 	OracleTxHash string `json:"oracle_tx_hash" gorm:"column:oracle_tx_hash;default:NULL"`
 
+	// state root oracle
+	StateRootRelayStatus int16  `json:"state_root_relay_status" gorm:"column:state_root_relay_status;default:1"`
+	StateRootRelayTxHash string `json:"state_root_relay_tx_hash" gorm:"column:state_root_relay_tx_hash;default:NULL"`
+
 	// metadata
 	TotalL1CommitGas          uint64         `json:"total_l1_commit_gas" gorm:"column:total_l1_commit_gas;default:0"`
 	TotalL1CommitCalldataSize uint64         `json:"total_l1_commit_calldata_size" gorm:"column:total_l1_commit_calldata_size;default:0"`
@@ -61,8 +72,8 @@ type Batch struct {
 }
 
 // NewBatch creates a new Batch database instance.
-func NewBatch(db *gorm.DB) *Batch {
-	return &Batch{db: db}
+func NewBatch(db *gorm.DB, opts ...Option) *Batch {
+	return &Batch{db: db, replica: newReadReplica(opts)}
 }
 
 // TableName returns the table name for the Batch model.
@@ -97,6 +108,27 @@ func (o *Batch) GetBatches(ctx context.Context, fields map[string]interface{}, o
 	return batches, nil
 }
 
+// GetBatchesByFinalizationStatus retrieves at most limit batches of the finalization status with an index greater than
+// afterIndex, in ascending order by index. The index of the last returned batch is the afterIndex of the next page.
+func (o *Batch) GetBatchesByFinalizationStatus(ctx context.Context, status types.BatchFinalizationStatus, afterIndex uint64, limit int) ([]*Batch, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("Batch.GetBatchesByFinalizationStatus error: invalid limit: %v", limit)
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("finalization_status = ?", int(status))
+	db = db.Where("index > ?", afterIndex)
+	db = db.Order("index ASC")
+	db = db.Limit(limit)
+
+	var batches []*Batch
+	if err := db.Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetBatchesByFinalizationStatus error: %w, status: %v, after index: %v, limit: %v", err, status.String(), afterIndex, limit)
+	}
+	return batches, nil
+}
+
 // GetBatchCount retrieves the total number of batches in the database.
 func (o *Batch) GetBatchCount(ctx context.Context) (uint64, error) {
 	db := o.db.WithContext(ctx)
@@ -109,6 +141,20 @@ func (o *Batch) GetBatchCount(ctx context.Context) (uint64, error) {
 	return uint64(count), nil
 }
 
+// GetBatchCountByRollupStatus retrieves the number of batches with the given rollup status.
+// It is served by the read replicas of an orm created WithReadReplica, such an orm must not count the in-flight commits.
+func (o *Batch) GetBatchCountByRollupStatus(ctx context.Context, status types.RollupStatus) (uint64, error) {
+	db := o.replica.reader(o.db).WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("rollup_status = ?", int(status))
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("Batch.GetBatchCountByRollupStatus error: %w, status: %v", err, status.String())
+	}
+	return uint64(count), nil
+}
+
 // GetVerifiedProofByHash retrieves the verified aggregate proof for a batch with the given hash.
 func (o *Batch) GetVerifiedProofByHash(ctx context.Context, hash string) (*message.BatchProof, error) {
 	db := o.db.WithContext(ctx)
@@ -144,6 +190,41 @@ func (o *Batch) GetLatestBatch(ctx context.Context) (*Batch, error) {
 	return &latestBatch, nil
 }
 
+// GetLatestFinalizedBatch retrieves the finalized batch with the highest index, nil if no batch is finalized.
+func (o *Batch) GetLatestFinalizedBatch(ctx context.Context) (*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("rollup_status = ?", int(types.RollupFinalized))
+	db = db.Order("index desc")
+
+	var latestBatch Batch
+	if err := db.First(&latestBatch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Batch.GetLatestFinalizedBatch error: %w", err)
+	}
+	return &latestBatch, nil
+}
+
+// GetLatestCommittedBatch retrieves the batch with the highest index committed on L1, whether it is finalized or not.
+// It returns nil if no batch is committed.
+func (o *Batch) GetLatestCommittedBatch(ctx context.Context) (*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("rollup_status IN ?", []int{int(types.RollupCommitted), int(types.RollupFinalizing), int(types.RollupFinalized), int(types.RollupFinalizeFailed)})
+	db = db.Order("index desc")
+
+	var latestBatch Batch
+	if err := db.First(&latestBatch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Batch.GetLatestCommittedBatch error: %w", err)
+	}
+	return &latestBatch, nil
+}
+
 // GetFirstUnbatchedChunkIndex retrieves the first unbatched chunk index.
 func (o *Batch) GetFirstUnbatchedChunkIndex(ctx context.Context) (uint64, error) {
 	// Get the latest batch
@@ -212,6 +293,28 @@ func (o *Batch) GetFailedAndPendingBatches(ctx context.Context, limit int) ([]*B
 	return batches, nil
 }
 
+// GetEarliestCommitFailedBatch retrieves the batch with the lowest index whose commit transaction failed more than olderThan ago,
+// all the commit failed batches are considered if olderThan is not positive. It returns nil if there is no such batch.
+func (o *Batch) GetEarliestCommitFailedBatch(ctx context.Context, olderThan time.Duration) (*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("rollup_status = ?", types.RollupCommitFailed)
+	if olderThan > 0 {
+		// compared in db time, updated_at is rounded to the second by the db.
+		db = db.Where("updated_at < NOW() - make_interval(secs => ?)", olderThan.Seconds())
+	}
+	db = db.Order("index ASC")
+
+	var batch Batch
+	if err := db.First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Batch.GetEarliestCommitFailedBatch error: %w, older than: %v", err, olderThan)
+	}
+	return &batch, nil
+}
+
 // GetBatchByIndex retrieves the batch by the given index.
 func (o *Batch) GetBatchByIndex(ctx context.Context, index uint64) (*Batch, error) {
 	db := o.db.WithContext(ctx)
@@ -304,6 +407,22 @@ func (o *Batch) UpdateL2GasOracleStatusAndOracleTxHash(ctx context.Context, hash
 	return nil
 }
 
+// UpdateStateRootRelayStatusAndTxHash updates the state root relay status and the relay tx hash of a batch.
+func (o *Batch) UpdateStateRootRelayStatusAndTxHash(ctx context.Context, hash string, status types.StateRootRelayStatus, txHash string) error {
+	updateFields := make(map[string]interface{})
+	updateFields["state_root_relay_status"] = int(status)
+	updateFields["state_root_relay_tx_hash"] = txHash
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash", hash)
+
+	if err := db.Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("Batch.UpdateStateRootRelayStatusAndTxHash error: %w, batch hash: %v, status: %v, txHash: %v", err, hash, status.String(), txHash)
+	}
+	return nil
+}
+
 // UpdateProvingStatus updates the proving status of a batch.
 func (o *Batch) UpdateProvingStatus(ctx context.Context, hash string, status types.ProvingStatus, dbTX ...*gorm.DB) error {
 	updateFields := make(map[string]interface{})
@@ -396,9 +515,40 @@ func (o *Batch) UpdateFinalizeTxHashAndRollupStatus(ctx context.Context, hash st
 	return nil
 }
 
+// UpdateFinalizationStatus updates the finalization status of a batch.
+func (o *Batch) UpdateFinalizationStatus(ctx context.Context, hash string, status types.BatchFinalizationStatus, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash", hash)
+
+	if err := db.Update("finalization_status", int(status)).Error; err != nil {
+		return fmt.Errorf("Batch.UpdateFinalizationStatus error: %w, batch hash: %v, status: %v", err, hash, status.String())
+	}
+	return nil
+}
+
+// UpdateProofAggregationStatus updates the proof aggregation status of a batch.
+func (o *Batch) UpdateProofAggregationStatus(ctx context.Context, hash string, status types.ProofAggregationStatus, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash", hash)
+
+	if err := db.Update("proof_aggregation_status", int(status)).Error; err != nil {
+		return fmt.Errorf("Batch.UpdateProofAggregationStatus error: %w, batch hash: %v, status: %v", err, hash, status.String())
+	}
+	return nil
+}
+
 // UpdateProofByHash updates the batch proof by hash.
-// for unit test.
-func (o *Batch) UpdateProofByHash(ctx context.Context, hash string, proof *message.BatchProof, proofTimeSec uint64) error {
+func (o *Batch) UpdateProofByHash(ctx context.Context, hash string, proof *message.BatchProof, proofTimeSec uint64, dbTX ...*gorm.DB) error {
 	proofBytes, err := json.Marshal(proof)
 	if err != nil {
 		return fmt.Errorf("Batch.UpdateProofByHash error: %w, batch hash: %v", err, hash)
@@ -408,7 +558,11 @@ func (o *Batch) UpdateProofByHash(ctx context.Context, hash string, proof *messa
 	updateFields["proof"] = proofBytes
 	updateFields["proof_time_sec"] = proofTimeSec
 
-	db := o.db.WithContext(ctx)
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
 	db = db.Model(&Batch{})
 	db = db.Where("hash", hash)
 
@@ -417,3 +571,26 @@ func (o *Batch) UpdateProofByHash(ctx context.Context, hash string, proof *messa
 	}
 	return nil
 }
+
+// DeleteBatchesAfterChunkIndex deletes the batches containing any chunk with an index greater than the given chunk index.
+// The deleted batches are returned in ascending order by their index.
+func (o *Batch) DeleteBatchesAfterChunkIndex(ctx context.Context, chunkIndex uint64, dbTX ...*gorm.DB) ([]*Batch, error) {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+
+	var batches []*Batch
+	if err := db.Model(&Batch{}).Where("end_chunk_index > ?", chunkIndex).Order("index ASC").Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("Batch.DeleteBatchesAfterChunkIndex error: %w, chunk index: %v", err, chunkIndex)
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	if err := db.Model(&Batch{}).Where("end_chunk_index > ?", chunkIndex).Delete(&Batch{}).Error; err != nil {
+		return nil, fmt.Errorf("Batch.DeleteBatchesAfterChunkIndex error: %w, chunk index: %v", err, chunkIndex)
+	}
+	return batches, nil
+}
@@ -16,7 +16,8 @@ import (
 
 // Chunk represents a chunk of blocks in the database.
 type Chunk struct {
-	db *gorm.DB `gorm:"-"`
+	db      *gorm.DB    `gorm:"-"`
+	replica readReplica `gorm:"-"`
 
 	// chunk
 	Index                        uint64 `json:"index" gorm:"column:index"`
@@ -26,6 +27,7 @@ type Chunk struct {
 	EndBlockNumber               uint64 `json:"end_block_number" gorm:"column:end_block_number"`
 	EndBlockHash                 string `json:"end_block_hash" gorm:"column:end_block_hash"`
 	StartBlockTime               uint64 `json:"start_block_time" gorm:"column:start_block_time"`
+	EndBlockTime                 uint64 `json:"end_block_time" gorm:"column:end_block_time"`
 	TotalL1MessagesPoppedBefore  uint64 `json:"total_l1_messages_popped_before" gorm:"column:total_l1_messages_popped_before"`
 	TotalL1MessagesPoppedInChunk uint64 `json:"total_l1_messages_popped_in_chunk" gorm:"column:total_l1_messages_popped_in_chunk"`
 	ParentChunkHash              string `json:"parent_chunk_hash" gorm:"column:parent_chunk_hash"`
@@ -54,8 +56,8 @@ type Chunk struct {
 }
 
 // NewChunk creates a new Chunk database instance.
-func NewChunk(db *gorm.DB) *Chunk {
-	return &Chunk{db: db}
+func NewChunk(db *gorm.DB, opts ...Option) *Chunk {
+	return &Chunk{db: db, replica: newReadReplica(opts)}
 }
 
 // TableName returns the table name for the chunk model.
@@ -63,6 +65,15 @@ func (*Chunk) TableName() string {
 	return "chunk"
 }
 
+// BlobSize is the number of data bytes of an EIP-4844 blob, 128 KiB.
+const BlobSize = 131072
+
+// EstimateBlobCount estimates the number of EIP-4844 blobs needed by the chunk data, ceil(size / BlobSize).
+// The chunk data is not compressed by the current codec, so its l1 commit calldata size is used as the data size.
+func (o *Chunk) EstimateBlobCount() int {
+	return int((o.TotalL1CommitCalldataSize + BlobSize - 1) / BlobSize)
+}
+
 // GetChunksInRange retrieves chunks within a given range (inclusive) from the database.
 // The range is closed, i.e., it includes both start and end indices.
 // The returned chunks are sorted in ascending order by their index.
@@ -136,6 +147,23 @@ func (o *Chunk) GetChunksGEIndex(ctx context.Context, index uint64, limit int) (
 	return chunks, nil
 }
 
+// GetChunksCreatedAfter retrieves the chunks created after the given time.
+// Only the block range and the l2 tx metadata columns are loaded.
+// The returned chunks are sorted in ascending order by their index.
+func (o *Chunk) GetChunksCreatedAfter(ctx context.Context, since time.Time) ([]*Chunk, error) {
+	db := o.replica.reader(o.db).WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Select("index, start_block_number, end_block_number, total_l2_tx_gas, total_l2_tx_num, created_at")
+	db = db.Where("created_at > ?", since)
+	db = db.Order("index ASC")
+
+	var chunks []*Chunk
+	if err := db.Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.GetChunksCreatedAfter error: %w, since: %v", err, since)
+	}
+	return chunks, nil
+}
+
 // InsertChunk inserts a new chunk into the database.
 func (o *Chunk) InsertChunk(ctx context.Context, chunk *encoding.Chunk, dbTX ...*gorm.DB) (*Chunk, error) {
 	if chunk == nil || len(chunk.Blocks) == 0 {
@@ -199,6 +227,7 @@ func (o *Chunk) InsertChunk(ctx context.Context, chunk *encoding.Chunk, dbTX ...
 		TotalL1CommitCalldataSize:    totalL1CommitCalldataSize,
 		TotalL1CommitGas:             totalL1CommitGas,
 		StartBlockTime:               chunk.Blocks[0].Header.Time,
+		EndBlockTime:                 chunk.Blocks[numBlocks-1].Header.Time,
 		TotalL1MessagesPoppedBefore:  totalL1MessagePoppedBefore,
 		TotalL1MessagesPoppedInChunk: chunk.NumL1Messages(totalL1MessagePoppedBefore),
 		ParentChunkHash:              parentChunkHash,
@@ -266,3 +295,26 @@ func (o *Chunk) UpdateBatchHashInRange(ctx context.Context, startIndex uint64, e
 	}
 	return nil
 }
+
+// DeleteChunksAfterBlockNumber deletes the chunks containing any block with a block number greater than the given number.
+// The deleted chunks are returned in ascending order by their index.
+func (o *Chunk) DeleteChunksAfterBlockNumber(ctx context.Context, blockNumber uint64, dbTX ...*gorm.DB) ([]*Chunk, error) {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+
+	var chunks []*Chunk
+	if err := db.Model(&Chunk{}).Where("end_block_number > ?", blockNumber).Order("index ASC").Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.DeleteChunksAfterBlockNumber error: %w, block number: %v", err, blockNumber)
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	if err := db.Model(&Chunk{}).Where("end_block_number > ?", blockNumber).Delete(&Chunk{}).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.DeleteChunksAfterBlockNumber error: %w, block number: %v", err, blockNumber)
+	}
+	return chunks, nil
+}
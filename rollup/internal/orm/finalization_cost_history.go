@@ -0,0 +1,65 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FinalizationCostHistory is the l1 base fee and the gas used of a confirmed batch finalization.
+type FinalizationCostHistory struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	BatchHash string `json:"batch_hash" gorm:"column:batch_hash;primaryKey"`
+	TxHash    string `json:"tx_hash" gorm:"column:tx_hash"`
+	L1BaseFee uint64 `json:"l1_base_fee" gorm:"column:l1_base_fee"`
+	GasUsed   uint64 `json:"gas_used" gorm:"column:gas_used"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewFinalizationCostHistory creates a new FinalizationCostHistory database instance.
+func NewFinalizationCostHistory(db *gorm.DB) *FinalizationCostHistory {
+	return &FinalizationCostHistory{db: db}
+}
+
+// TableName returns the table name for the FinalizationCostHistory model.
+func (*FinalizationCostHistory) TableName() string {
+	return "finalization_cost_history"
+}
+
+// GetLatestFinalizationCosts returns the limit latest finalization costs, the latest first.
+func (o *FinalizationCostHistory) GetLatestFinalizationCosts(ctx context.Context, limit int) ([]FinalizationCostHistory, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&FinalizationCostHistory{})
+	db = db.Order("created_at DESC")
+	db = db.Limit(limit)
+
+	var costs []FinalizationCostHistory
+	if err := db.Find(&costs).Error; err != nil {
+		return nil, fmt.Errorf("FinalizationCostHistory.GetLatestFinalizationCosts error: %w, limit: %v", err, limit)
+	}
+	return costs, nil
+}
+
+// InsertFinalizationCost stores the cost of the finalization of a batch, the cost of a batch already stored is kept.
+func (o *FinalizationCostHistory) InsertFinalizationCost(ctx context.Context, batchHash, txHash string, l1BaseFee, gasUsed uint64) error {
+	cost := FinalizationCostHistory{
+		BatchHash: batchHash,
+		TxHash:    txHash,
+		L1BaseFee: l1BaseFee,
+		GasUsed:   gasUsed,
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{DoNothing: true})
+	if err := db.Create(&cost).Error; err != nil {
+		return fmt.Errorf("FinalizationCostHistory.InsertFinalizationCost error: %w, batch hash: %v", err, batchHash)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GasOracleManualOverride is a l1 gas price set manually by an operator, bypassing the gas oracle.
+type GasOracleManualOverride struct {
+	db      *gorm.DB    `gorm:"column:-"`
+	replica readReplica `gorm:"-"`
+
+	ID         uint64 `json:"id" gorm:"column:id;primaryKey"`
+	OperatorID string `json:"operator_id" gorm:"column:operator_id"`
+	GasPrice   uint64 `json:"gas_price" gorm:"column:gas_price"`
+	TxHash     string `json:"tx_hash" gorm:"column:tx_hash"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewGasOracleManualOverride creates a new GasOracleManualOverride database instance.
+func NewGasOracleManualOverride(db *gorm.DB, opts ...Option) *GasOracleManualOverride {
+	return &GasOracleManualOverride{db: db, replica: newReadReplica(opts)}
+}
+
+// TableName returns the table name for the GasOracleManualOverride model.
+func (*GasOracleManualOverride) TableName() string {
+	return "gas_oracle_manual_overrides"
+}
+
+// GetLatestManualOverrides returns the limit latest manual overrides, the latest first.
+func (o *GasOracleManualOverride) GetLatestManualOverrides(ctx context.Context, limit int) ([]GasOracleManualOverride, error) {
+	db := o.replica.reader(o.db).WithContext(ctx)
+	db = db.Model(&GasOracleManualOverride{})
+	db = db.Order("id DESC")
+	db = db.Limit(limit)
+
+	var overrides []GasOracleManualOverride
+	if err := db.Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("GasOracleManualOverride.GetLatestManualOverrides error: %w, limit: %v", err, limit)
+	}
+	return overrides, nil
+}
+
+// InsertManualOverride records a gas price set manually by the operator in the transaction of the given hash.
+func (o *GasOracleManualOverride) InsertManualOverride(ctx context.Context, operatorID string, gasPrice uint64, txHash string) error {
+	override := GasOracleManualOverride{
+		OperatorID: operatorID,
+		GasPrice:   gasPrice,
+		TxHash:     txHash,
+	}
+
+	db := o.db.WithContext(ctx)
+	if err := db.Create(&override).Error; err != nil {
+		return fmt.Errorf("GasOracleManualOverride.InsertManualOverride error: %w, operator id: %v, gas price: %v", err, operatorID, gasPrice)
+	}
+	return nil
+}
@@ -3,25 +3,39 @@ package orm
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 
 	"scroll-tech/common/types"
+	"scroll-tech/common/utils"
+)
+
+const (
+	// l1BlockGasOracleLockNamespace is the first key of the advisory locks of the l1 block gas oracle updates,
+	// the second key is the hash of the block hash.
+	l1BlockGasOracleLockNamespace = 1
+	// advisoryLockRetryInterval is the interval between two attempts to take an advisory lock held by another session.
+	advisoryLockRetryInterval = 20 * time.Millisecond
 )
 
 // L1Block is structure of stored l1 block message
 type L1Block struct {
-	db *gorm.DB `gorm:"column:-"`
+	db          *gorm.DB            `gorm:"column:-"`
+	heightCache *l1BlockHeightCache `gorm:"-"`
+	replica     readReplica         `gorm:"-"`
 
 	// block
-	Number  uint64 `json:"number" gorm:"column:number"`
-	Hash    string `json:"hash" gorm:"column:hash"`
-	BaseFee uint64 `json:"base_fee" gorm:"column:base_fee"`
+	Number     uint64 `json:"number" gorm:"column:number"`
+	Hash       string `json:"hash" gorm:"column:hash"`
+	ParentHash string `json:"parent_hash" gorm:"column:parent_hash;default:NULL"`
+	BaseFee    uint64 `json:"base_fee" gorm:"column:base_fee"`
 
 	// oracle
-	GasOracleStatus int16  `json:"oracle_status" gorm:"column:oracle_status;default:1"`
-	OracleTxHash    string `json:"oracle_tx_hash" gorm:"column:oracle_tx_hash;default:NULL"`
+	GasOracleStatus int16      `json:"oracle_status" gorm:"column:oracle_status;default:1"`
+	OracleTxHash    string     `json:"oracle_tx_hash" gorm:"column:oracle_tx_hash;default:NULL"`
+	LastRetryAt     *time.Time `json:"last_retry_at" gorm:"column:last_retry_at;default:NULL"`
 
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
@@ -30,8 +44,8 @@ type L1Block struct {
 }
 
 // NewL1Block create an l1Block instance
-func NewL1Block(db *gorm.DB) *L1Block {
-	return &L1Block{db: db}
+func NewL1Block(db *gorm.DB, opts ...Option) *L1Block {
+	return &L1Block{db: db, heightCache: newL1BlockHeightCache(), replica: newReadReplica(opts)}
 }
 
 // TableName define the L1Block table name
@@ -39,8 +53,17 @@ func (*L1Block) TableName() string {
 	return "l1_block"
 }
 
-// GetLatestL1BlockHeight get the latest l1 block height
+// GetLatestL1BlockHeight get the latest l1 block height, cached for l1BlockHeightCacheTTL until the next InsertL1Blocks of this orm.
 func (o *L1Block) GetLatestL1BlockHeight(ctx context.Context) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("L1Block.GetLatestL1BlockHeight error: %w", err)
+	}
+
+	height, generation, ok := o.heightCache.get()
+	if ok {
+		return height, nil
+	}
+
 	db := o.db.WithContext(ctx)
 	db = db.Model(&L1Block{})
 	db = db.Select("COALESCE(MAX(number), 0)")
@@ -49,11 +72,29 @@ func (o *L1Block) GetLatestL1BlockHeight(ctx context.Context) (uint64, error) {
 	if err := db.Row().Scan(&maxNumber); err != nil {
 		return 0, fmt.Errorf("L1Block.GetLatestL1BlockHeight error: %w", err)
 	}
+	o.heightCache.set(maxNumber, generation)
 	return maxNumber, nil
 }
 
+// ReconcileBlockHeight reports the gaps of the stored l1 blocks up to chainHead.
+func (o *L1Block) ReconcileBlockHeight(ctx context.Context, chainHead uint64) (*ReconciliationReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("L1Block.ReconcileBlockHeight error: %w", err)
+	}
+
+	report, err := reconcileBlockHeight(ctx, o.replica.reader(o.db), o.TableName(), chainHead)
+	if err != nil {
+		return nil, fmt.Errorf("L1Block.ReconcileBlockHeight error: %w, chain head: %d", err, chainHead)
+	}
+	return report, nil
+}
+
 // GetL1Blocks get the l1 blocks
 func (o *L1Block) GetL1Blocks(ctx context.Context, fields map[string]interface{}) ([]L1Block, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("L1Block.GetL1Blocks error: %w", err)
+	}
+
 	db := o.db.WithContext(ctx)
 	db = db.Model(&L1Block{})
 
@@ -70,13 +111,110 @@ func (o *L1Block) GetL1Blocks(ctx context.Context, fields map[string]interface{}
 	return l1Blocks, nil
 }
 
+// GetL1BlocksInRange get a page of the l1 blocks with heights in [startHeight, endHeight] ordered by number ascending,
+// together with the total number of blocks in the range. page starts from 1.
+func (o *L1Block) GetL1BlocksInRange(ctx context.Context, startHeight, endHeight uint64, page, pageSize int) ([]*L1Block, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("L1Block.GetL1BlocksInRange error: %w", err)
+	}
+
+	if startHeight > endHeight {
+		return nil, 0, fmt.Errorf("L1Block.GetL1BlocksInRange: start height should be no greater than end height, start height: %d, end height: %d", startHeight, endHeight)
+	}
+	if page < 1 || pageSize < 1 {
+		return nil, 0, fmt.Errorf("L1Block.GetL1BlocksInRange: page and page size should be positive, page: %d, page size: %d", page, pageSize)
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L1Block{})
+	db = db.Where("number >= ? AND number <= ?", startHeight, endHeight)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("L1Block.GetL1BlocksInRange count error: %w, start height: %d, end height: %d", err, startHeight, endHeight)
+	}
+
+	db = db.Order("number ASC")
+	db = db.Offset((page - 1) * pageSize)
+	db = db.Limit(pageSize)
+
+	var l1Blocks []*L1Block
+	if err := db.Find(&l1Blocks).Error; err != nil {
+		return nil, 0, fmt.Errorf("L1Block.GetL1BlocksInRange error: %w, start height: %d, end height: %d, page: %d, page size: %d", err, startHeight, endHeight, page, pageSize)
+	}
+	return l1Blocks, total, nil
+}
+
+// ValidateParentHashChain checks that the stored l1 blocks with heights in [fromHeight, toHeight] form a chain, and
+// returns the heights of the orphan blocks in ascending order: the blocks whose previous height is not stored, or whose
+// parent hash is not the hash of the stored previous block. The first block of the range is not checked, and the
+// parent hash of the blocks stored without one is not checked.
+func (o *L1Block) ValidateParentHashChain(ctx context.Context, fromHeight, toHeight uint64) ([]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("L1Block.ValidateParentHashChain error: %w", err)
+	}
+
+	if fromHeight > toHeight {
+		return nil, fmt.Errorf("L1Block.ValidateParentHashChain: from height should be no greater than to height, from height: %d, to height: %d", fromHeight, toHeight)
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L1Block{})
+	db = db.Select("number, hash, parent_hash")
+	db = db.Where("number >= ? AND number <= ?", fromHeight, toHeight)
+	db = db.Order("number ASC")
+
+	var l1Blocks []L1Block
+	if err := db.Find(&l1Blocks).Error; err != nil {
+		return nil, fmt.Errorf("L1Block.ValidateParentHashChain error: %w, from height: %d, to height: %d", err, fromHeight, toHeight)
+	}
+
+	var orphans []uint64
+	for i := 1; i < len(l1Blocks); i++ {
+		prev, block := l1Blocks[i-1], l1Blocks[i]
+		if block.Number != prev.Number+1 || (block.ParentHash != "" && block.ParentHash != prev.Hash) {
+			orphans = append(orphans, block.Number)
+		}
+	}
+	return orphans, nil
+}
+
+// GetL1BlocksByGasOracleStatus get at most limit l1 blocks with the given gas oracle status, ordered by number ascending.
+// The most recent blocks are returned if there are more than limit ones.
+func (o *L1Block) GetL1BlocksByGasOracleStatus(ctx context.Context, status types.GasOracleStatus, limit int) ([]L1Block, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("L1Block.GetL1BlocksByGasOracleStatus error: %w", err)
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L1Block{})
+	db = db.Where("oracle_status = ?", int(status))
+	db = db.Order("number DESC")
+	db = db.Limit(limit)
+
+	var l1Blocks []L1Block
+	if err := db.Find(&l1Blocks).Error; err != nil {
+		return nil, fmt.Errorf("L1Block.GetL1BlocksByGasOracleStatus error: %w, status: %v, limit: %v", err, status.String(), limit)
+	}
+
+	for i, j := 0, len(l1Blocks)-1; i < j; i, j = i+1, j-1 {
+		l1Blocks[i], l1Blocks[j] = l1Blocks[j], l1Blocks[i]
+	}
+	return l1Blocks, nil
+}
+
 // InsertL1Blocks batch inserts l1 blocks.
 // If there's a block number conflict (e.g., due to reorg), soft deletes the existing block and inserts the new one.
 func (o *L1Block) InsertL1Blocks(ctx context.Context, blocks []L1Block) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("L1Block.InsertL1Blocks error: %w", err)
+	}
+
 	if len(blocks) == 0 {
 		return nil
 	}
 
+	defer o.heightCache.invalidate()
 	return o.db.Transaction(func(tx *gorm.DB) error {
 		minBlockNumber := blocks[0].Number
 		for _, block := range blocks[1:] {
@@ -109,17 +247,113 @@ func (o *L1Block) InsertL1Blocks(ctx context.Context, blocks []L1Block) error {
 
 // UpdateL1GasOracleStatusAndOracleTxHash update l1 gas oracle status and oracle tx hash
 func (o *L1Block) UpdateL1GasOracleStatusAndOracleTxHash(ctx context.Context, blockHash string, status types.GasOracleStatus, txHash string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("L1Block.UpdateL1GasOracleStatusAndOracleTxHash error: %w", err)
+	}
+
 	updateFields := map[string]interface{}{
 		"oracle_status":  int(status),
 		"oracle_tx_hash": txHash,
 	}
 
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockL1BlockGasOracle(ctx, tx, []string{blockHash}); err != nil {
+			return err
+		}
+		db := tx.Model(&L1Block{})
+		db = db.Where("hash", blockHash)
+		return db.Updates(updateFields).Error
+	})
+	if err != nil {
+		return fmt.Errorf("L1Block.UpdateL1GasOracleStatusAndOracleTxHash error: %w, block hash: %v, status: %v, tx hash: %v", err, blockHash, status.String(), txHash)
+	}
+	return nil
+}
+
+// UpdateL1GasOracleStatusAndOracleTxHashByHashes update l1 gas oracle status and oracle tx hash of all the given blocks in a single statement.
+func (o *L1Block) UpdateL1GasOracleStatusAndOracleTxHashByHashes(ctx context.Context, blockHashes []string, status types.GasOracleStatus, txHash string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("L1Block.UpdateL1GasOracleStatusAndOracleTxHashByHashes error: %w", err)
+	}
+
+	if len(blockHashes) == 0 {
+		return nil
+	}
+
+	updateFields := map[string]interface{}{
+		"oracle_status":  int(status),
+		"oracle_tx_hash": txHash,
+	}
+
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockL1BlockGasOracle(ctx, tx, blockHashes); err != nil {
+			return err
+		}
+		db := tx.Model(&L1Block{})
+		db = db.Where("hash IN ?", blockHashes)
+		return db.Updates(updateFields).Error
+	})
+	if err != nil {
+		return fmt.Errorf("L1Block.UpdateL1GasOracleStatusAndOracleTxHashByHashes error: %w, block hashes: %v, status: %v, tx hash: %v", err, blockHashes, status.String(), txHash)
+	}
+	return nil
+}
+
+// RetryFailedL1GasOracleBlocks resets the gas oracle status of the blocks that failed more than olderThan ago back to pending,
+// all the failed blocks are reset if olderThan is not positive. It returns the number of reset blocks.
+func (o *L1Block) RetryFailedL1GasOracleBlocks(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("L1Block.RetryFailedL1GasOracleBlocks error: %w", err)
+	}
+
+	updateFields := map[string]interface{}{
+		"oracle_status":  int(types.GasOraclePending),
+		"oracle_tx_hash": nil,
+		"last_retry_at":  utils.NowUTC(),
+	}
+
 	db := o.db.WithContext(ctx)
 	db = db.Model(&L1Block{})
-	db = db.Where("hash", blockHash)
+	db = db.Where("oracle_status = ?", int(types.GasOracleImportedFailed))
+	if olderThan > 0 {
+		// compared in db time, updated_at is rounded to the second by the db.
+		db = db.Where("updated_at < NOW() - make_interval(secs => ?)", olderThan.Seconds())
+	}
 
-	if err := db.Updates(updateFields).Error; err != nil {
-		return fmt.Errorf("L1Block.UpdateL1GasOracleStatusAndOracleTxHash error: %w, block hash: %v, status: %v, tx hash: %v", err, blockHash, status.String(), txHash)
+	result := db.Updates(updateFields)
+	if result.Error != nil {
+		return 0, fmt.Errorf("L1Block.RetryFailedL1GasOracleBlocks error: %w, older than: %v", result.Error, olderThan)
+	}
+	return result.RowsAffected, nil
+}
+
+// lockL1BlockGasOracle takes the transaction-level advisory locks of the gas oracle updates of the given blocks,
+// so that the concurrent updates of a block (e.g. by two relayers after a restart) run one at a time.
+// A lock held by another session is retried until ctx expires, the locks are released when tx ends.
+func lockL1BlockGasOracle(ctx context.Context, tx *gorm.DB, blockHashes []string) error {
+	// take the locks in the same order in all the sessions to avoid deadlocks.
+	sorted := make([]string, len(blockHashes))
+	copy(sorted, blockHashes)
+	sort.Strings(sorted)
+
+	for i, blockHash := range sorted {
+		if i > 0 && blockHash == sorted[i-1] {
+			continue
+		}
+		for {
+			var locked bool
+			if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?, hashtext(?))", l1BlockGasOracleLockNamespace, blockHash).Scan(&locked).Error; err != nil {
+				return fmt.Errorf("failed to take advisory lock, block hash: %v: %w", blockHash, err)
+			}
+			if locked {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("timed out waiting for advisory lock, block hash: %v: %w", blockHash, ctx.Err())
+			case <-time.After(advisoryLockRetryInterval):
+			}
+		}
 	}
 	return nil
 }
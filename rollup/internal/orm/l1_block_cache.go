@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// l1BlockHeightCacheTTL the time a cached latest l1 block height is served without querying the db.
+const l1BlockHeightCacheTTL = 2 * time.Second
+
+type l1BlockCacheMetrics struct {
+	cacheHits   prometheus.Counter
+	cacheMisses prometheus.Counter
+}
+
+var (
+	initL1BlockCacheMetricsOnce sync.Once
+	l1BlockCacheMetric          *l1BlockCacheMetrics
+)
+
+func initL1BlockCacheMetrics() *l1BlockCacheMetrics {
+	initL1BlockCacheMetricsOnce.Do(func() {
+		l1BlockCacheMetric = &l1BlockCacheMetrics{
+			cacheHits: promauto.NewCounter(prometheus.CounterOpts{
+				Name: "l1_block_orm_cache_hits_total",
+				Help: "The total number of latest l1 block height cache hits",
+			}),
+			cacheMisses: promauto.NewCounter(prometheus.CounterOpts{
+				Name: "l1_block_orm_cache_misses_total",
+				Help: "The total number of latest l1 block height cache misses",
+			}),
+		}
+	})
+	return l1BlockCacheMetric
+}
+
+// l1BlockHeightCache caches the latest l1 block height for l1BlockHeightCacheTTL.
+// It is held by each L1Block orm, the blocks inserted by another orm are seen once the cached height expires.
+type l1BlockHeightCache struct {
+	mu        sync.Mutex
+	height    uint64
+	expiresAt time.Time
+	// generation is increased by each invalidation, so that a height queried before an invalidation is not cached.
+	generation uint64
+
+	metrics *l1BlockCacheMetrics
+}
+
+func newL1BlockHeightCache() *l1BlockHeightCache {
+	return &l1BlockHeightCache{metrics: initL1BlockCacheMetrics()}
+}
+
+// get returns the cached height, or the generation to pass to set on a cache miss.
+func (c *l1BlockHeightCache) get() (height uint64, generation uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.expiresAt) {
+		c.metrics.cacheHits.Inc()
+		return c.height, c.generation, true
+	}
+	c.metrics.cacheMisses.Inc()
+	return 0, c.generation, false
+}
+
+// set caches height queried in generation, unless the cache has been invalidated since.
+func (c *l1BlockHeightCache) set(height uint64, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	c.height = height
+	c.expiresAt = time.Now().Add(l1BlockHeightCacheTTL)
+}
+
+func (c *l1BlockHeightCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.expiresAt = time.Time{}
+}
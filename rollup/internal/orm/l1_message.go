@@ -3,10 +3,14 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/types"
 )
 
 // L1Message is structure of stored layer1 bridge message
@@ -22,6 +26,7 @@ type L1Message struct {
 	Value      string `json:"value" gorm:"column:value"`
 	Calldata   string `json:"calldata" gorm:"column:calldata"`
 	Layer1Hash string `json:"layer1_hash" gorm:"column:layer1_hash"`
+	LogIndex   uint   `json:"log_index" gorm:"column:log_index"`
 	Layer2Hash string `json:"layer2_hash" gorm:"column:layer2_hash;default:NULL"`
 	Status     int    `json:"status" gorm:"column:status;default:1"`
 
@@ -56,21 +61,67 @@ func (m *L1Message) GetLayer1LatestWatchedHeight() (int64, error) {
 	return -1, nil
 }
 
-// SaveL1Messages batch save a list of layer1 messages
-func (m *L1Message) SaveL1Messages(ctx context.Context, messages []*L1Message) error {
+// GetL1MessagesByQueueIndices returns the layer1 messages with the given queue indices.
+func (m *L1Message) GetL1MessagesByQueueIndices(ctx context.Context, queueIndices []uint64) ([]L1Message, error) {
+	if len(queueIndices) == 0 {
+		return nil, nil
+	}
+
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index IN ?", queueIndices)
+	db = db.Order("queue_index ASC")
+
+	var messages []L1Message
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("L1Message.GetL1MessagesByQueueIndices error: %w, queue indices: %v", err, queueIndices)
+	}
+	return messages, nil
+}
+
+// SaveL1Messages batch save a list of layer1 messages, the messages already saved with the same layer1 tx hash
+// and log index are skipped. It returns the number of saved messages.
+func (m *L1Message) SaveL1Messages(ctx context.Context, messages []*L1Message) (int64, error) {
 	if len(messages) == 0 {
-		return nil
+		return 0, nil
 	}
 
-	err := m.db.WithContext(ctx).Create(&messages).Error
-	if err != nil {
+	db := m.db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "layer1_hash"}, {Name: "log_index"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	})
+	result := db.Create(&messages)
+	if result.Error != nil {
 		queueIndices := make([]uint64, 0, len(messages))
 		heights := make([]uint64, 0, len(messages))
 		for _, msg := range messages {
 			queueIndices = append(queueIndices, msg.QueueIndex)
 			heights = append(heights, msg.Height)
 		}
-		log.Error("failed to insert l1Messages", "queueIndices", queueIndices, "heights", heights, "err", err)
+		log.Error("failed to insert l1Messages", "queueIndices", queueIndices, "heights", heights, "err", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ExpireOldMessages marks the messages pending for more than ttl since they were saved as expired.
+// It returns the number of expired messages.
+func (m *L1Message) ExpireOldMessages(ctx context.Context, ttl time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("L1Message.ExpireOldMessages error: %w", err)
+	}
+
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("status = ?", int(types.MsgPending))
+	// compared in db time, created_at is set by the db.
+	db = db.Where("created_at < NOW() - make_interval(secs => ?)", ttl.Seconds())
+
+	result := db.Update("status", int(types.MsgExpired))
+	if result.Error != nil {
+		return 0, fmt.Errorf("L1Message.ExpireOldMessages error: %w, ttl: %v", result.Error, ttl)
 	}
-	return err
+	return result.RowsAffected, nil
 }
@@ -16,7 +16,8 @@ import (
 
 // L2Block represents a l2 block in the database.
 type L2Block struct {
-	db *gorm.DB `gorm:"column:-"`
+	db      *gorm.DB    `gorm:"column:-"`
+	replica readReplica `gorm:"-"`
 
 	// block
 	Number         uint64 `json:"number" gorm:"number"`
@@ -31,6 +32,10 @@ type L2Block struct {
 	BlockTimestamp uint64 `json:"block_timestamp" gorm:"block_timestamp"`
 	RowConsumption string `json:"row_consumption" gorm:"row_consumption"`
 
+	// BlockTraceBinary is the encoding.Block in its binary encoding, the transactions column is left empty when it is set.
+	// It is NULL for the legacy rows only stored as json.
+	BlockTraceBinary []byte `json:"-" gorm:"column:block_trace_binary;default:NULL"`
+
 	// chunk
 	ChunkHash string `json:"chunk_hash" gorm:"chunk_hash;default:NULL"`
 
@@ -41,8 +46,8 @@ type L2Block struct {
 }
 
 // NewL2Block creates a new L2Block instance
-func NewL2Block(db *gorm.DB) *L2Block {
-	return &L2Block{db: db}
+func NewL2Block(db *gorm.DB, opts ...Option) *L2Block {
+	return &L2Block{db: db, replica: newReadReplica(opts)}
 }
 
 // TableName returns the name of the "l2_block" table.
@@ -64,13 +69,22 @@ func (o *L2Block) GetL2BlocksLatestHeight(ctx context.Context) (uint64, error) {
 	return maxNumber, nil
 }
 
+// ReconcileBlockHeight reports the gaps of the stored l2 blocks up to chainHead.
+func (o *L2Block) ReconcileBlockHeight(ctx context.Context, chainHead uint64) (*ReconciliationReport, error) {
+	report, err := reconcileBlockHeight(ctx, o.replica.reader(o.db), o.TableName(), chainHead)
+	if err != nil {
+		return nil, fmt.Errorf("L2Block.ReconcileBlockHeight error: %w, chain head: %d", err, chainHead)
+	}
+	return report, nil
+}
+
 // GetL2BlocksGEHeight retrieves L2 blocks that have a block number greater than or equal to the given height.
 // The blocks are converted into encoding.Block format for output.
 // The returned blocks are sorted in ascending order by their block number.
 func (o *L2Block) GetL2BlocksGEHeight(ctx context.Context, height uint64, limit int) ([]*encoding.Block, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Model(&L2Block{})
-	db = db.Select("header, transactions, withdraw_root, row_consumption")
+	db = db.Select("header, transactions, withdraw_root, row_consumption, block_trace_binary")
 	db = db.Where("number >= ?", height)
 	db = db.Order("number ASC")
 
@@ -85,24 +99,11 @@ func (o *L2Block) GetL2BlocksGEHeight(ctx context.Context, height uint64, limit
 
 	var blocks []*encoding.Block
 	for _, v := range l2Blocks {
-		var block encoding.Block
-
-		if err := json.Unmarshal([]byte(v.Transactions), &block.Transactions); err != nil {
-			return nil, fmt.Errorf("L2Block.GetL2BlocksGEHeight error: %w", err)
-		}
-
-		block.Header = &gethTypes.Header{}
-		if err := json.Unmarshal([]byte(v.Header), block.Header); err != nil {
-			return nil, fmt.Errorf("L2Block.GetL2BlocksGEHeight error: %w", err)
-		}
-
-		block.WithdrawRoot = common.HexToHash(v.WithdrawRoot)
-
-		if err := json.Unmarshal([]byte(v.RowConsumption), &block.RowConsumption); err != nil {
+		block, err := v.toBlock()
+		if err != nil {
 			return nil, fmt.Errorf("L2Block.GetL2BlocksGEHeight error: %w", err)
 		}
-
-		blocks = append(blocks, &block)
+		blocks = append(blocks, block)
 	}
 
 	return blocks, nil
@@ -144,7 +145,7 @@ func (o *L2Block) GetL2BlocksInRange(ctx context.Context, startBlockNumber uint6
 
 	db := o.db.WithContext(ctx)
 	db = db.Model(&L2Block{})
-	db = db.Select("header, transactions, withdraw_root, row_consumption")
+	db = db.Select("header, transactions, withdraw_root, row_consumption, block_trace_binary")
 	db = db.Where("number >= ? AND number <= ?", startBlockNumber, endBlockNumber)
 	db = db.Order("number ASC")
 
@@ -160,27 +161,108 @@ func (o *L2Block) GetL2BlocksInRange(ctx context.Context, startBlockNumber uint6
 
 	var blocks []*encoding.Block
 	for _, v := range l2Blocks {
-		var block encoding.Block
-
-		if err := json.Unmarshal([]byte(v.Transactions), &block.Transactions); err != nil {
+		block, err := v.toBlock()
+		if err != nil {
 			return nil, fmt.Errorf("L2Block.GetL2BlocksInRange error: %w, start block: %v, end block: %v", err, startBlockNumber, endBlockNumber)
 		}
+		blocks = append(blocks, block)
+	}
 
-		block.Header = &gethTypes.Header{}
-		if err := json.Unmarshal([]byte(v.Header), block.Header); err != nil {
-			return nil, fmt.Errorf("L2Block.GetL2BlocksInRange error: %w, start block: %v, end block: %v", err, startBlockNumber, endBlockNumber)
-		}
+	return blocks, nil
+}
 
-		block.WithdrawRoot = common.HexToHash(v.WithdrawRoot)
+// GetL2BlockHashesInRange retrieves the hashes of the L2 blocks within the specified range (inclusive).
+// The returned hashes are sorted in ascending order by their block number.
+func (o *L2Block) GetL2BlockHashesInRange(ctx context.Context, startBlockNumber uint64, endBlockNumber uint64) ([]common.Hash, error) {
+	if startBlockNumber > endBlockNumber {
+		return nil, fmt.Errorf("L2Block.GetL2BlockHashesInRange: start block number should be less than or equal to end block number, start block: %v, end block: %v", startBlockNumber, endBlockNumber)
+	}
 
-		if err := json.Unmarshal([]byte(v.RowConsumption), &block.RowConsumption); err != nil {
-			return nil, fmt.Errorf("L2Block.GetL2BlocksInRange error: %w, start block: %v, end block: %v", err, startBlockNumber, endBlockNumber)
-		}
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Where("number >= ? AND number <= ?", startBlockNumber, endBlockNumber)
+	db = db.Order("number ASC")
 
-		blocks = append(blocks, &block)
+	var hashes []string
+	if err := db.Pluck("hash", &hashes).Error; err != nil {
+		return nil, fmt.Errorf("L2Block.GetL2BlockHashesInRange error: %w, start block: %v, end block: %v", err, startBlockNumber, endBlockNumber)
 	}
 
-	return blocks, nil
+	// sanity check
+	if uint64(len(hashes)) != endBlockNumber-startBlockNumber+1 {
+		return nil, fmt.Errorf("L2Block.GetL2BlockHashesInRange: unexpected number of results, expected: %v, got: %v", endBlockNumber-startBlockNumber+1, len(hashes))
+	}
+
+	blockHashes := make([]common.Hash, len(hashes))
+	for i, hash := range hashes {
+		blockHashes[i] = common.HexToHash(hash)
+	}
+	return blockHashes, nil
+}
+
+// L2BlockNumberGap is a range of block numbers missing between the stored l2 blocks, both ends inclusive.
+type L2BlockNumberGap struct {
+	Start uint64 `gorm:"column:start"`
+	End   uint64 `gorm:"column:end"`
+}
+
+// GetL2BlockNumberGaps retrieves the ranges of block numbers missing between the stored l2 blocks, in ascending order.
+// The blocks before the lowest stored block and after the highest stored block are not considered missing.
+func (o *L2Block) GetL2BlockNumberGaps(ctx context.Context) ([]L2BlockNumberGap, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Select("number, LEAD(number) OVER (ORDER BY number) AS next_number")
+
+	var gaps []L2BlockNumberGap
+	err := o.db.WithContext(ctx).
+		Table("(?) AS t", db).
+		Select("number + 1 AS start, next_number - 1 AS \"end\"").
+		Where("next_number > number + 1").
+		Order("number ASC").
+		Scan(&gaps).Error
+	if err != nil {
+		return nil, fmt.Errorf("L2Block.GetL2BlockNumberGaps error: %w", err)
+	}
+	return gaps, nil
+}
+
+// BackfillBlockTraceBinary encodes up to limit legacy l2 blocks, stored as json only, into block_trace_binary
+// and clears their transactions column. It returns the number of blocks backfilled, 0 once no legacy block is left.
+func (o *L2Block) BackfillBlockTraceBinary(ctx context.Context, limit int) (int, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Select("number, header, transactions, withdraw_root, row_consumption")
+	db = db.Where("block_trace_binary IS NULL")
+	db = db.Order("number ASC")
+	db = db.Limit(limit)
+
+	var l2Blocks []L2Block
+	if err := db.Find(&l2Blocks).Error; err != nil {
+		return 0, fmt.Errorf("L2Block.BackfillBlockTraceBinary error: %w", err)
+	}
+
+	for _, v := range l2Blocks {
+		block, err := v.toBlock()
+		if err != nil {
+			return 0, fmt.Errorf("L2Block.BackfillBlockTraceBinary error: %w, number: %v", err, v.Number)
+		}
+		blockTrace, err := block.MarshalBinary()
+		if err != nil {
+			return 0, fmt.Errorf("L2Block.BackfillBlockTraceBinary error: %w, number: %v", err, v.Number)
+		}
+
+		db = o.db.WithContext(ctx)
+		db = db.Model(&L2Block{})
+		db = db.Where("number = ? AND block_trace_binary IS NULL", v.Number)
+		updateFields := map[string]interface{}{
+			"block_trace_binary": blockTrace,
+			"transactions":       "",
+		}
+		if err := db.Updates(updateFields).Error; err != nil {
+			return 0, fmt.Errorf("L2Block.BackfillBlockTraceBinary error: %w, number: %v", err, v.Number)
+		}
+	}
+	return len(l2Blocks), nil
 }
 
 // InsertL2Blocks inserts l2 blocks into the "l2_block" table.
@@ -193,9 +275,9 @@ func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*encoding.Block)
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
 		}
 
-		txs, err := json.Marshal(block.Transactions)
+		blockTrace, err := block.MarshalBinary()
 		if err != nil {
-			log.Error("failed to marshal transactions", "hash", block.Header.Hash().String(), "err", err)
+			log.Error("failed to encode block", "hash", block.Header.Hash().String(), "err", err)
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
 		}
 
@@ -206,17 +288,17 @@ func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*encoding.Block)
 		}
 
 		l2Block := L2Block{
-			Number:         block.Header.Number.Uint64(),
-			Hash:           block.Header.Hash().String(),
-			ParentHash:     block.Header.ParentHash.String(),
-			Transactions:   string(txs),
-			WithdrawRoot:   block.WithdrawRoot.Hex(),
-			StateRoot:      block.Header.Root.Hex(),
-			TxNum:          uint32(len(block.Transactions)),
-			GasUsed:        block.Header.GasUsed,
-			BlockTimestamp: block.Header.Time,
-			RowConsumption: string(rc),
-			Header:         string(header),
+			Number:           block.Header.Number.Uint64(),
+			Hash:             block.Header.Hash().String(),
+			ParentHash:       block.Header.ParentHash.String(),
+			WithdrawRoot:     block.WithdrawRoot.Hex(),
+			StateRoot:        block.Header.Root.Hex(),
+			TxNum:            uint32(len(block.Transactions)),
+			GasUsed:          block.Header.GasUsed,
+			BlockTimestamp:   block.Header.Time,
+			RowConsumption:   string(rc),
+			Header:           string(header),
+			BlockTraceBinary: blockTrace,
 		}
 		l2Blocks = append(l2Blocks, l2Block)
 	}
@@ -230,6 +312,33 @@ func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*encoding.Block)
 	return nil
 }
 
+// toBlock converts the row into an encoding.Block, preferring the binary encoding and falling back to json for legacy rows.
+func (o *L2Block) toBlock() (*encoding.Block, error) {
+	var block encoding.Block
+	if len(o.BlockTraceBinary) > 0 {
+		if err := block.UnmarshalBinary(o.BlockTraceBinary); err != nil {
+			return nil, err
+		}
+		return &block, nil
+	}
+
+	if err := json.Unmarshal([]byte(o.Transactions), &block.Transactions); err != nil {
+		return nil, err
+	}
+
+	block.Header = &gethTypes.Header{}
+	if err := json.Unmarshal([]byte(o.Header), block.Header); err != nil {
+		return nil, err
+	}
+
+	block.WithdrawRoot = common.HexToHash(o.WithdrawRoot)
+
+	if err := json.Unmarshal([]byte(o.RowConsumption), &block.RowConsumption); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
 // UpdateChunkHashInRange updates the chunk_hash of block tx within the specified range (inclusive).
 // The range is closed, i.e., it includes both start and end indices.
 // This function ensures the number of rows updated must equal to (endIndex - startIndex + 1).
@@ -255,3 +364,20 @@ func (o *L2Block) UpdateChunkHashInRange(ctx context.Context, startIndex uint64,
 
 	return nil
 }
+
+// DeleteL2BlocksAfterNumber deletes the l2 blocks with a block number greater than the given number,
+// so that they are fetched again from l2geth.
+func (o *L2Block) DeleteL2BlocksAfterNumber(ctx context.Context, number uint64, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Where("number > ?", number)
+
+	if err := db.Delete(&L2Block{}).Error; err != nil {
+		return fmt.Errorf("L2Block.DeleteL2BlocksAfterNumber error: %w, number: %v", err, number)
+	}
+	return nil
+}
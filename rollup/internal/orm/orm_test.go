@@ -6,7 +6,9 @@ import (
 	"math/big"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/scroll-tech/go-ethereum/common"
 	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +19,7 @@ import (
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/encoding"
 	"scroll-tech/common/types/encoding/codecv0"
+	"scroll-tech/common/types/message"
 	"scroll-tech/database/migrate"
 )
 
@@ -118,6 +121,23 @@ func TestL1BlockOrm(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(3), height)
 
+	// served from the cache
+	hits := testutil.ToFloat64(l1BlockOrm.heightCache.metrics.cacheHits)
+	height, err = l1BlockOrm.GetLatestL1BlockHeight(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), height)
+	assert.Equal(t, hits+1, testutil.ToFloat64(l1BlockOrm.heightCache.metrics.cacheHits))
+
+	// the cache is invalidated by the inserts
+	block4 := L1Block{Number: 4, Hash: "hash4"}
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []L1Block{block4}))
+	height, err = l1BlockOrm.GetLatestL1BlockHeight(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), height)
+	err = db.Exec("DELETE FROM l1_block WHERE number = 4").Error
+	assert.NoError(t, err)
+	l1BlockOrm.heightCache.invalidate()
+
 	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{})
 	assert.NoError(t, err)
 	assert.Len(t, blocks, 3)
@@ -125,6 +145,28 @@ func TestL1BlockOrm(t *testing.T) {
 	assert.Equal(t, "hash2", blocks[1].Hash)
 	assert.Equal(t, "hash3", blocks[2].Hash)
 
+	rangeBlocks, total, err := l1BlockOrm.GetL1BlocksInRange(context.Background(), 2, 3, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, rangeBlocks, 1)
+	assert.Equal(t, "hash2", rangeBlocks[0].Hash)
+
+	rangeBlocks, total, err = l1BlockOrm.GetL1BlocksInRange(context.Background(), 2, 3, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, rangeBlocks, 1)
+	assert.Equal(t, "hash3", rangeBlocks[0].Hash)
+
+	rangeBlocks, total, err = l1BlockOrm.GetL1BlocksInRange(context.Background(), 2, 3, 3, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, rangeBlocks, 0)
+
+	_, _, err = l1BlockOrm.GetL1BlocksInRange(context.Background(), 3, 2, 1, 1)
+	assert.Error(t, err)
+	_, _, err = l1BlockOrm.GetL1BlocksInRange(context.Background(), 2, 3, 0, 1)
+	assert.Error(t, err)
+
 	// reorg handling: insert another block with same height and different hash
 	err = l1BlockOrm.InsertL1Blocks(context.Background(), []L1Block{block2AfterReorg})
 	assert.NoError(t, err)
@@ -143,6 +185,302 @@ func TestL1BlockOrm(t *testing.T) {
 	assert.Len(t, updatedBlocks, 2)
 	assert.Equal(t, types.GasOracleImported, types.GasOracleStatus(updatedBlocks[0].GasOracleStatus))
 	assert.Equal(t, "txhash1", updatedBlocks[0].OracleTxHash)
+
+	pendingBlocks, err := l1BlockOrm.GetL1BlocksByGasOracleStatus(context.Background(), types.GasOraclePending, 10)
+	assert.NoError(t, err)
+	assert.Len(t, pendingBlocks, 1)
+	assert.Equal(t, "hash2-reorg", pendingBlocks[0].Hash)
+
+	err = l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHashByHashes(context.Background(), []string{"hash1", "hash2-reorg"}, types.GasOracleImporting, "txhash2")
+	assert.NoError(t, err)
+
+	updatedBlocks, err = l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Len(t, updatedBlocks, 2)
+	for _, block := range updatedBlocks {
+		assert.Equal(t, types.GasOracleImporting, types.GasOracleStatus(block.GasOracleStatus))
+		assert.Equal(t, "txhash2", block.OracleTxHash)
+	}
+
+	pendingBlocks, err = l1BlockOrm.GetL1BlocksByGasOracleStatus(context.Background(), types.GasOraclePending, 10)
+	assert.NoError(t, err)
+	assert.Len(t, pendingBlocks, 0)
+
+	err = l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(context.Background(), "hash1", types.GasOracleImportedFailed, "txhash3")
+	assert.NoError(t, err)
+
+	// failed too recently
+	retried, err := l1BlockOrm.RetryFailedL1GasOracleBlocks(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), retried)
+
+	retried, err = l1BlockOrm.RetryFailedL1GasOracleBlocks(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), retried)
+
+	retriedBlocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"hash": "hash1"})
+	assert.NoError(t, err)
+	assert.Len(t, retriedBlocks, 1)
+	assert.Equal(t, types.GasOraclePending, types.GasOracleStatus(retriedBlocks[0].GasOracleStatus))
+	assert.Empty(t, retriedBlocks[0].OracleTxHash)
+	assert.NotNil(t, retriedBlocks[0].LastRetryAt)
+}
+
+func TestL1BlockOrmCancelledContext(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1BlockOrm := NewL1Block(db)
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []L1Block{{Number: 1, Hash: "hash1"}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = l1BlockOrm.GetLatestL1BlockHeight(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, _, err = l1BlockOrm.GetL1BlocksInRange(ctx, 1, 1, 1, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = l1BlockOrm.GetL1BlocksByGasOracleStatus(ctx, types.GasOraclePending, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, l1BlockOrm.InsertL1Blocks(ctx, []L1Block{{Number: 2, Hash: "hash2"}}), context.Canceled)
+	assert.ErrorIs(t, l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(ctx, "hash1", types.GasOracleImporting, "txhash1"), context.Canceled)
+	assert.ErrorIs(t, l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHashByHashes(ctx, []string{"hash1"}, types.GasOracleImporting, "txhash1"), context.Canceled)
+	_, err = l1BlockOrm.RetryFailedL1GasOracleBlocks(ctx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// none of the writes above reached the database.
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, types.GasOraclePending, types.GasOracleStatus(blocks[0].GasOracleStatus))
+	assert.Empty(t, blocks[0].OracleTxHash)
+}
+
+func TestL1BlockOrmValidateParentHashChain(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1BlockOrm := NewL1Block(db)
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []L1Block{
+		{Number: 1, Hash: "hash1"},
+		{Number: 2, Hash: "hash2", ParentHash: "hash1"},
+		// stored before the parent hashes, not checked
+		{Number: 3, Hash: "hash3"},
+		// not linked to block 3
+		{Number: 4, Hash: "hash4", ParentHash: "hash3-reorg"},
+		// block 5 is missing
+		{Number: 6, Hash: "hash6", ParentHash: "hash5"},
+		{Number: 7, Hash: "hash7", ParentHash: "hash6"},
+	}))
+
+	orphans, err := l1BlockOrm.ValidateParentHashChain(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{4, 6}, orphans)
+
+	// the first block of the range is not checked
+	orphans, err = l1BlockOrm.ValidateParentHashChain(context.Background(), 6, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	_, err = l1BlockOrm.ValidateParentHashChain(context.Background(), 2, 1)
+	assert.Error(t, err)
+}
+
+func TestL1BlockOrmGasOracleAdvisoryLock(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1BlockOrm := NewL1Block(db)
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), []L1Block{{Number: 1, Hash: "hash1"}, {Number: 2, Hash: "hash2"}}))
+
+	// the updates of a block wait for the lock held by another session until their context expires.
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- db.Transaction(func(tx *gorm.DB) error {
+			if err := lockL1BlockGasOracle(context.Background(), tx, []string{"hash1"}); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(ctx, "hash1", types.GasOracleImporting, "txhash1"), context.DeadlineExceeded)
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHashByHashes(ctx, []string{"hash2", "hash1"}, types.GasOracleImporting, "txhash1"), context.DeadlineExceeded)
+	// the other blocks are not locked.
+	assert.NoError(t, l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(context.Background(), "hash2", types.GasOracleImporting, "txhash2"))
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(context.Background(), "hash1", types.GasOracleImporting, "txhash1"))
+
+	blocks, err := l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"hash": "hash1"})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, types.GasOracleImporting, types.GasOracleStatus(blocks[0].GasOracleStatus))
+	assert.Equal(t, "txhash1", blocks[0].OracleTxHash)
+}
+
+func TestReconcileBlockHeight(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1BlockOrm := NewL1Block(db)
+
+	// nothing is reported before the first block is stored.
+	report, err := l1BlockOrm.ReconcileBlockHeight(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Gaps)
+
+	blocks := []L1Block{{Number: 10, Hash: "hash10"}, {Number: 11, Hash: "hash11"}, {Number: 15, Hash: "hash15"}, {Number: 20, Hash: "hash20"}}
+	assert.NoError(t, l1BlockOrm.InsertL1Blocks(context.Background(), blocks))
+
+	report, err = l1BlockOrm.ReconcileBlockHeight(context.Background(), 25)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), report.LatestStoredHeight)
+	assert.Equal(t, []BlockGap{{From: 12, To: 14}, {From: 16, To: 19}, {From: 21, To: 25}}, report.Gaps)
+	assert.Equal(t, uint64(5), report.LargestGap())
+
+	// a chain head behind the stored blocks adds no gap.
+	report, err = l1BlockOrm.ReconcileBlockHeight(context.Background(), 18)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockGap{{From: 12, To: 14}, {From: 16, To: 19}}, report.Gaps)
+
+	assert.NoError(t, l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2}))
+	report, err = l2BlockOrm.ReconcileBlockHeight(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), report.LatestStoredHeight)
+	assert.Empty(t, report.Gaps)
+}
+
+func TestL1MessageOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1MessageOrm := NewL1Message(db)
+	newMessage := func(queueIndex uint64, logIndex uint) *L1Message {
+		return &L1Message{
+			QueueIndex: queueIndex,
+			MsgHash:    common.BigToHash(new(big.Int).SetUint64(queueIndex)).Hex(),
+			Height:     1,
+			Sender:     "sender",
+			Target:     "target",
+			Value:      "0",
+			Layer1Hash: "0x1",
+			LogIndex:   logIndex,
+		}
+	}
+
+	saved, err := l1MessageOrm.SaveL1Messages(context.Background(), []*L1Message{newMessage(0, 0), newMessage(1, 1)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), saved)
+
+	// the refetched events are skipped
+	saved, err = l1MessageOrm.SaveL1Messages(context.Background(), []*L1Message{newMessage(1, 1), newMessage(2, 2)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), saved)
+
+	messages, err := l1MessageOrm.GetL1MessagesByQueueIndices(context.Background(), []uint64{0, 1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+	assert.Equal(t, uint(2), messages[2].LogIndex)
+}
+
+func TestL1MessageOrmExpireOldMessages(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1MessageOrm := NewL1Message(db)
+	var messages []*L1Message
+	for i := uint64(0); i < 3; i++ {
+		messages = append(messages, &L1Message{
+			QueueIndex: i,
+			MsgHash:    common.BigToHash(new(big.Int).SetUint64(i)).Hex(),
+			Height:     1,
+			Sender:     "sender",
+			Target:     "target",
+			Value:      "0",
+			Layer1Hash: "0x1",
+			LogIndex:   uint(i),
+		})
+	}
+	_, err = l1MessageOrm.SaveL1Messages(context.Background(), messages)
+	assert.NoError(t, err)
+
+	// message 0 and 1 are older than the ttl, message 1 is not pending anymore.
+	old := gorm.Expr("NOW() - INTERVAL '2 hours'")
+	assert.NoError(t, db.Model(&L1Message{}).Where("queue_index IN ?", []uint64{0, 1}).Update("created_at", old).Error)
+	assert.NoError(t, db.Model(&L1Message{}).Where("queue_index = ?", 1).Update("status", int(types.MsgConfirmed)).Error)
+
+	expired, err := l1MessageOrm.ExpireOldMessages(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	stored, err := l1MessageOrm.GetL1MessagesByQueueIndices(context.Background(), []uint64{0, 1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, stored, 3)
+	assert.Equal(t, int(types.MsgExpired), stored[0].Status)
+	assert.Equal(t, int(types.MsgConfirmed), stored[1].Status)
+	assert.Equal(t, int(types.MsgPending), stored[2].Status)
+
+	expired, err = l1MessageOrm.ExpireOldMessages(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, expired)
+}
+
+func TestL1MessageOrmSaveRoundTrips(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	var statements int
+	assert.NoError(t, db.Callback().Create().After("gorm:create").Register("test:count_statements", func(*gorm.DB) {
+		statements++
+	}))
+	defer func() {
+		assert.NoError(t, db.Callback().Create().Remove("test:count_statements"))
+	}()
+
+	messages := make([]*L1Message, 1000)
+	for i := range messages {
+		messages[i] = &L1Message{
+			QueueIndex: uint64(i),
+			MsgHash:    common.BigToHash(big.NewInt(int64(i))).Hex(),
+			Height:     uint64(i / 10),
+			Sender:     "sender",
+			Target:     "target",
+			Value:      "0",
+			Layer1Hash: common.BigToHash(big.NewInt(int64(i / 10))).Hex(),
+			LogIndex:   uint(i % 10),
+		}
+	}
+
+	// the messages are saved in a single INSERT ... ON CONFLICT DO NOTHING statement rather than one per message.
+	saved, err := NewL1Message(db).SaveL1Messages(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(messages)), saved)
+	assert.Equal(t, 1, statements)
+
+	saved, err = NewL1Message(db).SaveL1Messages(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), saved)
+	assert.Equal(t, 2, statements)
 }
 
 func TestL2BlockOrm(t *testing.T) {
@@ -177,6 +515,50 @@ func TestL2BlockOrm(t *testing.T) {
 	assert.Len(t, chunkHashes, 2)
 	assert.Equal(t, "test hash", chunkHashes[0])
 	assert.Equal(t, "", chunkHashes[1])
+
+	hashes, err := l2BlockOrm.GetL2BlockHashesInRange(context.Background(), 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Hash{block1.Header.Hash(), block2.Header.Hash()}, hashes)
+
+	err = l2BlockOrm.DeleteL2BlocksAfterNumber(context.Background(), 2)
+	assert.NoError(t, err)
+	height, err = l2BlockOrm.GetL2BlocksLatestHeight(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), height)
+	_, err = l2BlockOrm.GetL2BlockHashesInRange(context.Background(), 2, 3)
+	assert.Error(t, err)
+
+	gaps, err := l2BlockOrm.GetL2BlockNumberGaps(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	header := *block2.Header
+	header.Number = big.NewInt(6)
+	block6 := &encoding.Block{Header: &header, Transactions: block2.Transactions, WithdrawRoot: block2.WithdrawRoot, RowConsumption: block2.RowConsumption}
+	err = l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block6})
+	assert.NoError(t, err)
+	gaps, err = l2BlockOrm.GetL2BlockNumberGaps(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []L2BlockNumberGap{{Start: 3, End: 5}}, gaps)
+
+	// a legacy row stored as json only is read through the json fallback, then backfilled.
+	txs, err := json.Marshal(block2.Transactions)
+	assert.NoError(t, err)
+	err = db.Model(&L2Block{}).Where("number = ?", 6).Updates(map[string]interface{}{"block_trace_binary": nil, "transactions": string(txs)}).Error
+	assert.NoError(t, err)
+	blocks, err = l2BlockOrm.GetL2BlocksInRange(context.Background(), 6, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, block6, blocks[0])
+
+	count, err := l2BlockOrm.BackfillBlockTraceBinary(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = l2BlockOrm.BackfillBlockTraceBinary(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	blocks, err = l2BlockOrm.GetL2BlocksGEHeight(context.Background(), 6, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []*encoding.Block{block6}, blocks)
 }
 
 func TestChunkOrm(t *testing.T) {
@@ -200,6 +582,14 @@ func TestChunkOrm(t *testing.T) {
 	assert.Equal(t, "", chunks[0].BatchHash)
 	assert.Equal(t, "", chunks[1].BatchHash)
 
+	chunks, err = chunkOrm.GetChunksCreatedAfter(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, chunk2.Blocks[0].Header.Number.Uint64(), chunks[1].StartBlockNumber)
+	chunks, err = chunkOrm.GetChunksCreatedAfter(context.Background(), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 0)
+
 	err = chunkOrm.UpdateProvingStatus(context.Background(), chunkHash1.Hex(), types.ProvingTaskVerified)
 	assert.NoError(t, err)
 	err = chunkOrm.UpdateProvingStatus(context.Background(), chunkHash2.Hex(), types.ProvingTaskAssigned)
@@ -222,6 +612,38 @@ func TestChunkOrm(t *testing.T) {
 	assert.Equal(t, chunkHash2.Hex(), chunks[1].Hash)
 	assert.Equal(t, "test hash", chunks[0].BatchHash)
 	assert.Equal(t, "", chunks[1].BatchHash)
+
+	deletedChunks, err := chunkOrm.DeleteChunksAfterBlockNumber(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Len(t, deletedChunks, 0)
+
+	deletedChunks, err = chunkOrm.DeleteChunksAfterBlockNumber(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, deletedChunks, 1)
+	assert.Equal(t, chunkHash2.Hex(), deletedChunks[0].Hash)
+
+	latestChunk, err := chunkOrm.GetLatestChunk(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, chunkHash1.Hex(), latestChunk.Hash)
+}
+
+func TestChunkEstimateBlobCount(t *testing.T) {
+	tests := []struct {
+		size     uint64
+		expected int
+	}{
+		{0, 0},
+		{1, 1},
+		{BlobSize - 1, 1},
+		{BlobSize, 1},
+		{BlobSize + 1, 2},
+		{2 * BlobSize, 2},
+		{2*BlobSize + 1, 3},
+	}
+	for _, tt := range tests {
+		chunk := &Chunk{TotalL1CommitCalldataSize: tt.size}
+		assert.Equal(t, tt.expected, chunk.EstimateBlobCount(), "size: %d", tt.size)
+	}
 }
 
 func TestBatchOrm(t *testing.T) {
@@ -282,6 +704,22 @@ func TestBatchOrm(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(pendingBatches))
 
+	failedCount, err := batchOrm.GetBatchCountByRollupStatus(context.Background(), types.RollupCommitFailed)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), failedCount)
+
+	failedBatch, err := batchOrm.GetEarliestCommitFailedBatch(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, failedBatch)
+	failedBatch, err = batchOrm.GetEarliestCommitFailedBatch(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.NotNil(t, failedBatch)
+	assert.Equal(t, batchHash1, failedBatch.Hash)
+
+	committedBatch, err := batchOrm.GetLatestCommittedBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, committedBatch)
+
 	rollupStatus, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{batchHash1, batchHash2})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(rollupStatus))
@@ -318,6 +756,11 @@ func TestBatchOrm(t *testing.T) {
 	assert.Equal(t, "commitTxHash", updatedBatch.CommitTxHash)
 	assert.Equal(t, types.RollupCommitted, types.RollupStatus(updatedBatch.RollupStatus))
 
+	committedBatch, err = batchOrm.GetLatestCommittedBatch(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, committedBatch)
+	assert.Equal(t, batchHash2, committedBatch.Hash)
+
 	err = batchOrm.UpdateFinalizeTxHashAndRollupStatus(context.Background(), batchHash2, "finalizeTxHash", types.RollupFinalizeFailed)
 	assert.NoError(t, err)
 
@@ -326,6 +769,62 @@ func TestBatchOrm(t *testing.T) {
 	assert.NotNil(t, updatedBatch)
 	assert.Equal(t, "finalizeTxHash", updatedBatch.FinalizeTxHash)
 	assert.Equal(t, types.RollupFinalizeFailed, types.RollupStatus(updatedBatch.RollupStatus))
+
+	deletedBatches, err := batchOrm.DeleteBatchesAfterChunkIndex(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Len(t, deletedBatches, 1)
+	assert.Equal(t, batchHash2, deletedBatches[0].Hash)
+
+	count, err = batchOrm.GetBatchCount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestBatchOrmGetBatchesByFinalizationStatus(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	var hashes []string
+	for i := uint64(0); i < 5; i++ {
+		batch := &encoding.Batch{
+			Index:           i,
+			Chunks:          []*encoding.Chunk{chunk1},
+			StartChunkIndex: i,
+			StartChunkHash:  chunkHash1,
+			EndChunkIndex:   i,
+			EndChunkHash:    chunkHash1,
+		}
+		dbBatch, insertErr := batchOrm.InsertBatch(context.Background(), batch)
+		assert.NoError(t, insertErr)
+		hashes = append(hashes, dbBatch.Hash)
+	}
+	assert.NoError(t, batchOrm.UpdateFinalizationStatus(context.Background(), hashes[2], types.FinalizationSubmitted))
+
+	// page through the pending batches two at a time.
+	var indices []uint64
+	var afterIndex uint64
+	for {
+		batches, getErr := batchOrm.GetBatchesByFinalizationStatus(context.Background(), types.FinalizationPending, afterIndex, 2)
+		assert.NoError(t, getErr)
+		if len(batches) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(batches), 2)
+		for _, batch := range batches {
+			indices = append(indices, batch.Index)
+		}
+		afterIndex = batches[len(batches)-1].Index
+	}
+	assert.Equal(t, []uint64{1, 3, 4}, indices)
+
+	batches, err := batchOrm.GetBatchesByFinalizationStatus(context.Background(), types.FinalizationSubmitted, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, hashes[2], batches[0].Hash)
+
+	_, err = batchOrm.GetBatchesByFinalizationStatus(context.Background(), types.FinalizationPending, 0, 0)
+	assert.Error(t, err)
 }
 
 func TestTransactionOrm(t *testing.T) {
@@ -377,6 +876,19 @@ func TestTransactionOrm(t *testing.T) {
 	err = pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(context.Background(), tx0.Hash(), types.TxStatusReplaced)
 	assert.NoError(t, err)
 
+	pendingCount, err := pendingTransactionOrm.GetPendingTransactionCountBySenderTypeAndAddress(context.Background(), senderMeta.Type, senderMeta.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pendingCount)
+
+	// only the pending transaction is returned, the replaced one is not stuck.
+	stuckTxs, err := pendingTransactionOrm.GetPendingTransactionsCreatedBefore(context.Background(), time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Len(t, stuckTxs, 1)
+	assert.Equal(t, tx1.Hash().String(), stuckTxs[0].Hash)
+	stuckTxs, err = pendingTransactionOrm.GetPendingTransactionsCreatedBefore(context.Background(), time.Now().Add(-time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, stuckTxs)
+
 	txs, err := pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), senderMeta.Type, 2)
 	assert.NoError(t, err)
 	assert.Len(t, txs, 2)
@@ -390,6 +902,16 @@ func TestTransactionOrm(t *testing.T) {
 	assert.Equal(t, senderMeta.Service, txs[1].SenderService)
 	assert.Equal(t, senderMeta.Address.String(), txs[1].SenderAddress)
 	assert.Equal(t, senderMeta.Type, txs[1].SenderType)
+	assert.Empty(t, txs[0].ReplacedBy)
+
+	err = pendingTransactionOrm.UpdateTransactionAsReplaced(context.Background(), tx0.Hash(), tx1.Hash())
+	assert.NoError(t, err)
+
+	txs, err = pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), senderMeta.Type, 2)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.Equal(t, types.TxStatusReplaced, txs[0].Status)
+	assert.Equal(t, tx1.Hash().String(), txs[0].ReplacedBy)
 
 	err = pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(context.Background(), tx1.Hash(), types.TxStatusConfirmed)
 	assert.NoError(t, err)
@@ -398,7 +920,15 @@ func TestTransactionOrm(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, txs, 1)
 
-	err = pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(context.Background(), senderMeta.Address.String(), tx1.Nonce(), tx1.Hash())
+	txs, err = pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(context.Background(), senderMeta.Type, senderMeta.Address, 2)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+
+	txs, err = pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(context.Background(), senderMeta.Type, common.HexToAddress("0x1"), 2)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 0)
+
+	err = pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(context.Background(), senderMeta.Type, senderMeta.Address.String(), tx1.Nonce(), tx1.Hash())
 	assert.NoError(t, err)
 
 	txs, err = pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), senderMeta.Type, 2)
@@ -408,4 +938,315 @@ func TestTransactionOrm(t *testing.T) {
 	status, err := pendingTransactionOrm.GetTxStatusByTxHash(context.Background(), tx0.Hash())
 	assert.NoError(t, err)
 	assert.Equal(t, types.TxStatusConfirmedFailed, status)
+
+	// the replacements are followed back from the confirmed transaction.
+	assert.NoError(t, pendingTransactionOrm.UpdateTransactionAsReplaced(context.Background(), tx0.Hash(), tx1.Hash()))
+	replaced, err := pendingTransactionOrm.GetReplacedTransactionHashes(context.Background(), tx1.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Hash{tx0.Hash()}, replaced)
+	replaced, err = pendingTransactionOrm.GetReplacedTransactionHashes(context.Background(), tx0.Hash())
+	assert.NoError(t, err)
+	assert.Empty(t, replaced)
+}
+
+func TestTransactionOrmSenderTypes(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	// the gas oracle senders of the primary and shadow l2 use the same key, so their nonces overlap.
+	newTx := func(gasTipCap int64) *gethTypes.Transaction {
+		return gethTypes.NewTx(&gethTypes.DynamicFeeTx{
+			Nonce:      0,
+			To:         &common.Address{},
+			Data:       []byte{},
+			Gas:        42000,
+			AccessList: gethTypes.AccessList{},
+			Value:      big.NewInt(0),
+			ChainID:    big.NewInt(1),
+			GasTipCap:  big.NewInt(gasTipCap),
+			GasFeeCap:  big.NewInt(gasTipCap + 1),
+			V:          big.NewInt(0),
+			R:          big.NewInt(0),
+			S:          big.NewInt(0),
+		})
+	}
+	primaryMeta := &SenderMeta{
+		Name:    "gas_oracle_sender",
+		Service: "l1_relayer",
+		Address: common.HexToAddress("0x1"),
+		Type:    types.SenderTypeL1GasOracle,
+	}
+	shadowMeta := &SenderMeta{
+		Name:    "gas_oracle_shadow_sender",
+		Service: "l1_relayer_shadow",
+		Address: primaryMeta.Address,
+		Type:    types.SenderTypeL1GasOracleShadow,
+	}
+	primaryTx := newTx(1)
+	shadowTx := newTx(2)
+
+	pendingTransactionOrm := NewPendingTransaction(db)
+	assert.NoError(t, pendingTransactionOrm.InsertPendingTransaction(context.Background(), "primary", primaryMeta, primaryTx, 0))
+	assert.NoError(t, pendingTransactionOrm.InsertPendingTransaction(context.Background(), "shadow", shadowMeta, shadowTx, 0))
+
+	for _, meta := range []*SenderMeta{primaryMeta, shadowMeta} {
+		pendingCount, err := pendingTransactionOrm.GetPendingTransactionCountBySenderTypeAndAddress(context.Background(), meta.Type, meta.Address)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), pendingCount)
+	}
+	txs, err := pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(context.Background(), primaryMeta.Type, primaryMeta.Address, 10)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, primaryTx.Hash().String(), txs[0].Hash)
+	txs, err = pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderTypeAndAddress(context.Background(), shadowMeta.Type, shadowMeta.Address, 10)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, shadowTx.Hash().String(), txs[0].Hash)
+
+	// the confirmation of the primary transaction does not fail the shadow one of the same nonce.
+	err = pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(context.Background(), primaryMeta.Type, primaryMeta.Address.String(), primaryTx.Nonce(), primaryTx.Hash())
+	assert.NoError(t, err)
+	status, err := pendingTransactionOrm.GetTxStatusByTxHash(context.Background(), shadowTx.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, types.TxStatusPending, status)
+}
+
+func TestProofJobOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	proofJobOrm := NewProofJob(db)
+
+	inserted, err := proofJobOrm.InsertProofJob(context.Background(), 2, "hash2")
+	assert.NoError(t, err)
+	assert.True(t, inserted)
+	inserted, err = proofJobOrm.InsertProofJob(context.Background(), 1, "hash1")
+	assert.NoError(t, err)
+	assert.True(t, inserted)
+	inserted, err = proofJobOrm.InsertProofJob(context.Background(), 1, "hash1")
+	assert.NoError(t, err)
+	assert.False(t, inserted)
+
+	// the jobs are claimed in ascending order by batch index, once.
+	job, err := proofJobOrm.ClaimPendingProofJob(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), job.BatchIndex)
+	assert.Equal(t, int16(types.ProofJobRunning), job.Status)
+	assert.NotNil(t, job.StartedAt)
+	job, err = proofJobOrm.ClaimPendingProofJob(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), job.BatchIndex)
+	job, err = proofJobOrm.ClaimPendingProofJob(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+
+	assert.NoError(t, proofJobOrm.UpdateProofJobStatus(context.Background(), 1, types.ProofJobCompleted))
+	reset, err := proofJobOrm.ResetRunningProofJobs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), reset)
+
+	job, err = proofJobOrm.ClaimPendingProofJob(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), job.BatchIndex)
+}
+
+func TestWatcherStateOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	watcherStateOrm := NewWatcherState(db)
+
+	block, err := watcherStateOrm.GetStartBlockOverride(context.Background(), "l1_watcher")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), block)
+
+	assert.NoError(t, watcherStateOrm.SetStartBlockOverride(context.Background(), "l1_watcher", 100))
+	assert.NoError(t, watcherStateOrm.SetStartBlockOverride(context.Background(), "l1_watcher", 200))
+	block, err = watcherStateOrm.GetStartBlockOverride(context.Background(), "l1_watcher")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(200), block)
+
+	assert.NoError(t, watcherStateOrm.SetStartBlockOverride(context.Background(), "l1_watcher", 0))
+	block, err = watcherStateOrm.GetStartBlockOverride(context.Background(), "l1_watcher")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), block)
+}
+
+func TestQuarantinedBlockOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	quarantinedBlockOrm := NewQuarantinedBlock(db)
+
+	block, err := quarantinedBlockOrm.GetQuarantinedBlock(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+
+	assert.NoError(t, quarantinedBlockOrm.InsertQuarantinedBlock(context.Background(), 5, []byte(`{"number":`), "unexpected end of JSON input"))
+	assert.NoError(t, quarantinedBlockOrm.InsertQuarantinedBlock(context.Background(), 8, nil, "missing required field 'parentHash' for Header"))
+	// a block quarantined again keeps a single row with the last error.
+	assert.NoError(t, quarantinedBlockOrm.InsertQuarantinedBlock(context.Background(), 5, []byte(`{"number":"0x5"`), "unexpected end of JSON input"))
+
+	block, err = quarantinedBlockOrm.GetQuarantinedBlock(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"number":"0x5"`), block.RawBlock)
+	assert.Equal(t, "unexpected end of JSON input", block.Error)
+	assert.Nil(t, block.DiscardedAt)
+
+	numbers, err := quarantinedBlockOrm.GetQuarantinedBlockNumbers(context.Background(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{5, 8}, numbers)
+	numbers, err = quarantinedBlockOrm.GetQuarantinedBlockNumbers(context.Background(), 6, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{8}, numbers)
+
+	discarded, err := quarantinedBlockOrm.DiscardQuarantinedBlock(context.Background(), 8)
+	assert.NoError(t, err)
+	assert.True(t, discarded)
+	discarded, err = quarantinedBlockOrm.DiscardQuarantinedBlock(context.Background(), 9)
+	assert.NoError(t, err)
+	assert.False(t, discarded)
+	block, err = quarantinedBlockOrm.GetQuarantinedBlock(context.Background(), 8)
+	assert.NoError(t, err)
+	assert.NotNil(t, block.DiscardedAt)
+
+	assert.NoError(t, quarantinedBlockOrm.DeleteQuarantinedBlock(context.Background(), 5))
+	numbers, err = quarantinedBlockOrm.GetQuarantinedBlockNumbers(context.Background(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{8}, numbers)
+}
+
+func TestAggregatedProofOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	aggregatedProofOrm := NewAggregatedProof(db)
+
+	proof, err := aggregatedProofOrm.GetAggregatedProof(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Nil(t, proof)
+
+	aggregatedProof := &message.AggregatedProof{
+		BatchIndex:             1,
+		ChunkProofs:            []message.ChunkProof{{Proof: []byte{1}}, {Proof: []byte{2}}},
+		AggregatedProofData:    []byte{3},
+		AggregatedPublicInputs: []byte{4},
+	}
+	assert.NoError(t, aggregatedProofOrm.InsertAggregatedProof(context.Background(), "test hash", aggregatedProof))
+	// a batch aggregated again keeps a single row with the last proof.
+	aggregatedProof.AggregatedProofData = []byte{5}
+	assert.NoError(t, aggregatedProofOrm.InsertAggregatedProof(context.Background(), "test hash", aggregatedProof))
+
+	proof, err = aggregatedProofOrm.GetAggregatedProof(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, aggregatedProof, proof)
+
+	assert.NoError(t, aggregatedProofOrm.DeleteAggregatedProof(context.Background(), 1))
+	proof, err = aggregatedProofOrm.GetAggregatedProof(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Nil(t, proof)
+}
+
+func TestFinalizationCostHistoryOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	costHistoryOrm := NewFinalizationCostHistory(db)
+
+	costs, err := costHistoryOrm.GetLatestFinalizationCosts(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, costs)
+
+	assert.NoError(t, costHistoryOrm.InsertFinalizationCost(context.Background(), "batch1", "tx1", 10, 100))
+	assert.NoError(t, costHistoryOrm.InsertFinalizationCost(context.Background(), "batch2", "tx2", 20, 200))
+	// the cost of a batch is only stored once.
+	assert.NoError(t, costHistoryOrm.InsertFinalizationCost(context.Background(), "batch1", "tx3", 30, 300))
+
+	costs, err = costHistoryOrm.GetLatestFinalizationCosts(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, costs, 2)
+	byBatch := make(map[string]FinalizationCostHistory)
+	for _, cost := range costs {
+		byBatch[cost.BatchHash] = cost
+	}
+	assert.Equal(t, "tx1", byBatch["batch1"].TxHash)
+	assert.Equal(t, uint64(10), byBatch["batch1"].L1BaseFee)
+	assert.Equal(t, uint64(100), byBatch["batch1"].GasUsed)
+
+	costs, err = costHistoryOrm.GetLatestFinalizationCosts(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, costs, 1)
+}
+
+func TestGasOracleManualOverrideOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	overrideOrm := NewGasOracleManualOverride(db)
+
+	overrides, err := overrideOrm.GetLatestManualOverrides(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	assert.NoError(t, overrideOrm.InsertManualOverride(context.Background(), "alice", 100, "tx1"))
+	assert.NoError(t, overrideOrm.InsertManualOverride(context.Background(), "bob", 200, "tx2"))
+
+	overrides, err = overrideOrm.GetLatestManualOverrides(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, overrides, 2)
+	assert.Equal(t, "bob", overrides[0].OperatorID)
+	assert.Equal(t, uint64(200), overrides[0].GasPrice)
+	assert.Equal(t, "tx2", overrides[0].TxHash)
+	assert.Equal(t, "alice", overrides[1].OperatorID)
+
+	overrides, err = overrideOrm.GetLatestManualOverrides(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, overrides, 1)
+	assert.Equal(t, "bob", overrides[0].OperatorID)
+}
+
+func TestSchemaVersion(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	version, err := GetSchemaVersion(db)
+	assert.NoError(t, err)
+	current, err := migrate.Current(sqlDB)
+	assert.NoError(t, err)
+	assert.Equal(t, current, version)
+	assert.Equal(t, int64(ExpectedSchemaVersion), version)
+
+	assert.NoError(t, CheckSchemaVersion(db, ExpectedSchemaVersion))
+	assert.Error(t, CheckSchemaVersion(db, ExpectedSchemaVersion-1))
+
+	// a rolled back version is no longer applied
+	assert.NoError(t, migrate.Rollback(sqlDB, nil))
+	version, err = GetSchemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(ExpectedSchemaVersion-1), version)
+	assert.NoError(t, migrate.Migrate(sqlDB))
+}
+
+func TestMigrateSchema(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.Rollback(sqlDB, new(int64)))
+	assert.False(t, db.Migrator().HasTable("l1_block"))
+
+	assert.NoError(t, MigrateSchema(db))
+	version, err := GetSchemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(ExpectedSchemaVersion), version)
+	assert.True(t, db.Migrator().HasTable("l1_block"))
+
+	// the migrations are applied once.
+	assert.NoError(t, MigrateSchema(db))
 }
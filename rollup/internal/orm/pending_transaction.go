@@ -23,7 +23,8 @@ type SenderMeta struct {
 
 // PendingTransaction represents the structure of a transaction in the database.
 type PendingTransaction struct {
-	db *gorm.DB `gorm:"column:-"`
+	db      *gorm.DB    `gorm:"column:-"`
+	replica readReplica `gorm:"-"`
 
 	ID                uint             `json:"id" gorm:"id;primaryKey"`
 	ContextID         string           `json:"context_id" gorm:"context_id"`
@@ -36,6 +37,7 @@ type PendingTransaction struct {
 	Nonce             uint64           `json:"nonce" gorm:"nonce"`
 	SubmitBlockNumber uint64           `json:"submit_block_number" gorm:"submit_block_number"`
 	Status            types.TxStatus   `json:"status" gorm:"status"`
+	ReplacedBy        string           `json:"replaced_by" gorm:"column:replaced_by;default:NULL"`
 	RLPEncoding       []byte           `json:"rlp_encoding" gorm:"rlp_encoding"`
 	SenderName        string           `json:"sender_name" gorm:"sender_name"`
 	SenderService     string           `json:"sender_service" gorm:"sender_service"`
@@ -52,8 +54,8 @@ func (*PendingTransaction) TableName() string {
 }
 
 // NewPendingTransaction returns a new instance of PendingTransaction.
-func NewPendingTransaction(db *gorm.DB) *PendingTransaction {
-	return &PendingTransaction{db: db}
+func NewPendingTransaction(db *gorm.DB, opts ...Option) *PendingTransaction {
+	return &PendingTransaction{db: db, replica: newReadReplica(opts)}
 }
 
 // GetTxStatusByTxHash retrieves the status of a transaction by its hash.
@@ -85,6 +87,55 @@ func (o *PendingTransaction) GetPendingOrReplacedTransactionsBySenderType(ctx co
 	return transactions, nil
 }
 
+// GetPendingOrReplacedTransactionsBySenderTypeAndAddress retrieves pending or replaced transactions filtered by sender type and sender address,
+// ordered by nonce, then gas_fee_cap (gas_price in legacy tx), and limited to a specified count.
+func (o *PendingTransaction) GetPendingOrReplacedTransactionsBySenderTypeAndAddress(ctx context.Context, senderType types.SenderType, senderAddress common.Address, limit int) ([]PendingTransaction, error) {
+	var transactions []PendingTransaction
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("sender_type = ?", senderType)
+	db = db.Where("sender_address = ?", senderAddress.String())
+	db = db.Where("status = ? OR status = ?", types.TxStatusPending, types.TxStatusReplaced)
+	db = db.Order("nonce asc")
+	db = db.Order("gas_fee_cap asc")
+	db = db.Limit(limit)
+	if err := db.Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending or replaced transactions by sender type and address, error: %w", err)
+	}
+	return transactions, nil
+}
+
+// GetPendingTransactionCountBySenderTypeAndAddress counts the pending transactions filtered by sender type and sender address.
+// Replaced transactions are not counted, as each of them shares its nonce with a pending transaction.
+func (o *PendingTransaction) GetPendingTransactionCountBySenderTypeAndAddress(ctx context.Context, senderType types.SenderType, senderAddress common.Address) (int64, error) {
+	var count int64
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("sender_type = ?", senderType)
+	db = db.Where("sender_address = ?", senderAddress.String())
+	db = db.Where("status = ?", types.TxStatusPending)
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count pending transactions by sender type and address, error: %w", err)
+	}
+	return count, nil
+}
+
+// GetPendingTransactionsCreatedBefore retrieves at most limit pending transactions created before the given time,
+// i.e. still unconfirmed and not replaced since then, ordered by creation time.
+func (o *PendingTransaction) GetPendingTransactionsCreatedBefore(ctx context.Context, before time.Time, limit int) ([]PendingTransaction, error) {
+	var transactions []PendingTransaction
+	db := o.replica.reader(o.db).WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("status = ?", types.TxStatusPending)
+	db = db.Where("created_at < ?", before)
+	db = db.Order("created_at asc")
+	db = db.Limit(limit)
+	if err := db.Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending transactions created before %v, error: %w", before, err)
+	}
+	return transactions, nil
+}
+
 // InsertPendingTransaction creates a new pending transaction record and stores it in the database.
 func (o *PendingTransaction) InsertPendingTransaction(ctx context.Context, contextID string, senderMeta *SenderMeta, tx *gethTypes.Transaction, submitBlockNumber uint64, dbTX ...*gorm.DB) error {
 	rlp := new(bytes.Buffer)
@@ -137,19 +188,60 @@ func (o *PendingTransaction) UpdatePendingTransactionStatusByTxHash(ctx context.
 	return nil
 }
 
-// UpdateOtherTransactionsAsFailedByNonce updates the status of all transactions to TxStatusConfirmedFailed for a specific nonce and sender address, excluding a specified transaction hash.
-func (o *PendingTransaction) UpdateOtherTransactionsAsFailedByNonce(ctx context.Context, senderAddress string, nonce uint64, hash common.Hash, dbTX ...*gorm.DB) error {
+// UpdateTransactionAsReplaced marks a transaction as replaced and records the hash of the transaction replacing it.
+func (o *PendingTransaction) UpdateTransactionAsReplaced(ctx context.Context, hash common.Hash, replacedBy common.Hash, dbTX ...*gorm.DB) error {
 	db := o.db
 	if len(dbTX) > 0 && dbTX[0] != nil {
 		db = dbTX[0]
 	}
 	db = db.WithContext(ctx)
 	db = db.Model(&PendingTransaction{})
+	db = db.Where("hash = ?", hash.String())
+	updateFields := map[string]interface{}{
+		"status":      types.TxStatusReplaced,
+		"replaced_by": replacedBy.String(),
+	}
+	if err := db.Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("failed to UpdateTransactionAsReplaced, txHash: %s, replacedBy: %s, error: %w", hash, replacedBy, err)
+	}
+	return nil
+}
+
+// GetReplacedTransactionHashes retrieves the hashes of the transactions replaced by the transaction of hash,
+// directly or through a chain of replacements.
+func (o *PendingTransaction) GetReplacedTransactionHashes(ctx context.Context, hash common.Hash) ([]common.Hash, error) {
+	db := o.db.WithContext(ctx)
+	query := `WITH RECURSIVE replaced AS (
+		SELECT hash FROM pending_transaction WHERE replaced_by = ? AND deleted_at IS NULL
+		UNION
+		SELECT t.hash FROM pending_transaction t JOIN replaced r ON t.replaced_by = r.hash WHERE t.deleted_at IS NULL
+	) SELECT hash FROM replaced`
+
+	var hashes []string
+	if err := db.Raw(query, hash.String()).Scan(&hashes).Error; err != nil {
+		return nil, fmt.Errorf("failed to GetReplacedTransactionHashes, txHash: %s, error: %w", hash, err)
+	}
+	replaced := make([]common.Hash, 0, len(hashes))
+	for _, h := range hashes {
+		replaced = append(replaced, common.HexToHash(h))
+	}
+	return replaced, nil
+}
+
+// UpdateOtherTransactionsAsFailedByNonce updates the status of all transactions to TxStatusConfirmedFailed for a specific nonce, sender type and sender address, excluding a specified transaction hash.
+func (o *PendingTransaction) UpdateOtherTransactionsAsFailedByNonce(ctx context.Context, senderType types.SenderType, senderAddress string, nonce uint64, hash common.Hash, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("sender_type = ?", senderType)
 	db = db.Where("sender_address = ?", senderAddress)
 	db = db.Where("nonce = ?", nonce)
 	db = db.Where("hash != ?", hash.String())
 	if err := db.Update("status", types.TxStatusConfirmedFailed).Error; err != nil {
-		return fmt.Errorf("failed to update other transactions as failed by nonce, senderAddress: %s, nonce: %d, txHash: %s, error: %w", senderAddress, nonce, hash, err)
+		return fmt.Errorf("failed to update other transactions as failed by nonce, senderType: %s, senderAddress: %s, nonce: %d, txHash: %s, error: %w", senderType, senderAddress, nonce, hash, err)
 	}
 	return nil
 }
@@ -0,0 +1,140 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/utils"
+)
+
+// ProofJob is a job of the rollup relayer proof queue, generating the proof of a batch through the proof service.
+type ProofJob struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID          uint64     `json:"id" gorm:"column:id;primaryKey"`
+	BatchIndex  uint64     `json:"batch_index" gorm:"column:batch_index"`
+	BatchHash   string     `json:"batch_hash" gorm:"column:batch_hash"`
+	Status      int16      `json:"status" gorm:"column:status;default:1"`
+	StartedAt   *time.Time `json:"started_at" gorm:"column:started_at;default:NULL"`
+	CompletedAt *time.Time `json:"completed_at" gorm:"column:completed_at;default:NULL"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewProofJob creates a new ProofJob database instance.
+func NewProofJob(db *gorm.DB) *ProofJob {
+	return &ProofJob{db: db}
+}
+
+// TableName returns the table name for the ProofJob model.
+func (*ProofJob) TableName() string {
+	return "proof_jobs"
+}
+
+// InsertProofJob inserts a pending proof job for a batch, it does nothing if the batch already has one.
+// It returns true if the job is inserted.
+func (o *ProofJob) InsertProofJob(ctx context.Context, batchIndex uint64, batchHash string) (bool, error) {
+	job := ProofJob{
+		BatchIndex: batchIndex,
+		BatchHash:  batchHash,
+		Status:     int16(types.ProofJobPending),
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "batch_index"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	})
+	result := db.Create(&job)
+	if result.Error != nil {
+		return false, fmt.Errorf("ProofJob.InsertProofJob error: %w, batch index: %v, batch hash: %v", result.Error, batchIndex, batchHash)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimPendingProofJob marks the pending proof job of the lowest batch index as running and returns it, nil if there is none.
+// The concurrent claims skip the jobs being claimed, so each job is claimed once.
+func (o *ProofJob) ClaimPendingProofJob(ctx context.Context) (*ProofJob, error) {
+	var job *ProofJob
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&ProofJob{})
+		db = db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		db = db.Where("status = ?", int(types.ProofJobPending))
+		db = db.Order("batch_index ASC")
+
+		var jobs []*ProofJob
+		if err := db.Limit(1).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		now := utils.NowUTC()
+		updateFields := map[string]interface{}{
+			"status":     int(types.ProofJobRunning),
+			"started_at": now,
+		}
+		if err := tx.Model(&ProofJob{}).Where("id = ?", jobs[0].ID).Updates(updateFields).Error; err != nil {
+			return err
+		}
+		job = jobs[0]
+		job.Status = int16(types.ProofJobRunning)
+		job.StartedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ProofJob.ClaimPendingProofJob error: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateProofJobStatus updates the status of the proof job of a batch, the completion time is recorded for the final statuses.
+func (o *ProofJob) UpdateProofJobStatus(ctx context.Context, batchIndex uint64, status types.ProofJobStatus, dbTX ...*gorm.DB) error {
+	updateFields := make(map[string]interface{})
+	updateFields["status"] = int(status)
+	if status == types.ProofJobCompleted || status == types.ProofJobFailed {
+		updateFields["completed_at"] = utils.NowUTC()
+	}
+
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&ProofJob{})
+	db = db.Where("batch_index = ?", batchIndex)
+
+	if err := db.Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("ProofJob.UpdateProofJobStatus error: %w, batch index: %v, status: %v", err, batchIndex, status.String())
+	}
+	return nil
+}
+
+// ResetRunningProofJobs moves the running proof jobs back to pending, it is meant to be called at startup
+// to resume the jobs interrupted by a restart. It returns the number of reset jobs.
+func (o *ProofJob) ResetRunningProofJobs(ctx context.Context) (int64, error) {
+	updateFields := map[string]interface{}{
+		"status":     int(types.ProofJobPending),
+		"started_at": nil,
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProofJob{})
+	db = db.Where("status = ?", int(types.ProofJobRunning))
+
+	result := db.Updates(updateFields)
+	if result.Error != nil {
+		return 0, fmt.Errorf("ProofJob.ResetRunningProofJobs error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
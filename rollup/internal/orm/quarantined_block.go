@@ -0,0 +1,109 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuarantinedBlock is a l2 block whose trace could not be decoded, it is skipped by the l2 watcher
+// until an operator reprocesses or discards it.
+type QuarantinedBlock struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	Number      uint64     `json:"number" gorm:"column:number;primaryKey"`
+	RawBlock    []byte     `json:"raw_block" gorm:"column:raw_block"`
+	Error       string     `json:"error" gorm:"column:error"`
+	DiscardedAt *time.Time `json:"discarded_at" gorm:"column:discarded_at"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewQuarantinedBlock creates a new QuarantinedBlock database instance.
+func NewQuarantinedBlock(db *gorm.DB) *QuarantinedBlock {
+	return &QuarantinedBlock{db: db}
+}
+
+// TableName returns the table name for the QuarantinedBlock model.
+func (*QuarantinedBlock) TableName() string {
+	return "quarantined_block"
+}
+
+// GetQuarantinedBlock returns the quarantined block of the given number, nil if it is not quarantined.
+func (o *QuarantinedBlock) GetQuarantinedBlock(ctx context.Context, number uint64) (*QuarantinedBlock, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&QuarantinedBlock{})
+	db = db.Where("number = ?", number)
+
+	var block QuarantinedBlock
+	if err := db.First(&block).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("QuarantinedBlock.GetQuarantinedBlock error: %w, number: %v", err, number)
+	}
+	return &block, nil
+}
+
+// GetQuarantinedBlockNumbers returns the numbers of the quarantined blocks in [from, to], discarded or not,
+// in ascending order.
+func (o *QuarantinedBlock) GetQuarantinedBlockNumbers(ctx context.Context, from, to uint64) ([]uint64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&QuarantinedBlock{})
+	db = db.Where("number >= ? AND number <= ?", from, to)
+	db = db.Order("number ASC")
+
+	var numbers []uint64
+	if err := db.Pluck("number", &numbers).Error; err != nil {
+		return nil, fmt.Errorf("QuarantinedBlock.GetQuarantinedBlockNumbers error: %w, from: %v, to: %v", err, from, to)
+	}
+	return numbers, nil
+}
+
+// InsertQuarantinedBlock quarantines a block, the raw block and the error of a block quarantined again are replaced.
+func (o *QuarantinedBlock) InsertQuarantinedBlock(ctx context.Context, number uint64, rawBlock []byte, decodeErr string) error {
+	block := QuarantinedBlock{
+		Number:   number,
+		RawBlock: rawBlock,
+		Error:    decodeErr,
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"raw_block", "error", "updated_at"}),
+	})
+	if err := db.Create(&block).Error; err != nil {
+		return fmt.Errorf("QuarantinedBlock.InsertQuarantinedBlock error: %w, number: %v", err, number)
+	}
+	return nil
+}
+
+// DiscardQuarantinedBlock marks a quarantined block as discarded, it returns false if the block is not quarantined.
+func (o *QuarantinedBlock) DiscardQuarantinedBlock(ctx context.Context, number uint64) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&QuarantinedBlock{})
+	db = db.Where("number = ?", number)
+
+	result := db.Updates(map[string]interface{}{"discarded_at": time.Now()})
+	if result.Error != nil {
+		return false, fmt.Errorf("QuarantinedBlock.DiscardQuarantinedBlock error: %w, number: %v", result.Error, number)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteQuarantinedBlock releases a block from the quarantine once it is reprocessed.
+func (o *QuarantinedBlock) DeleteQuarantinedBlock(ctx context.Context, number uint64) error {
+	db := o.db.WithContext(ctx)
+	db = db.Where("number = ?", number)
+	if err := db.Delete(&QuarantinedBlock{}).Error; err != nil {
+		return fmt.Errorf("QuarantinedBlock.DeleteQuarantinedBlock error: %w, number: %v", err, number)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package orm
+
+import (
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+)
+
+// Option configures the orm created by a constructor.
+type Option func(*readReplica)
+
+// WithReadReplica routes the status and reporting reads of the orm, e.g. ReconcileBlockHeight, to the read replicas
+// of db in round-robin. The replicas may lag behind the primary, so the reads a write depends on, e.g. the latest
+// chunk a proposer builds on, always go to the primary.
+// A nil db leaves the orm on the primary.
+func WithReadReplica(db *database.DB) Option {
+	return func(r *readReplica) {
+		if db != nil {
+			r.readDB = db.ReadDB
+		}
+	}
+}
+
+// readReplica picks the db handler of the select queries of an orm.
+type readReplica struct {
+	readDB func() *gorm.DB
+}
+
+func newReadReplica(opts []Option) readReplica {
+	var r readReplica
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
+
+// reader returns a read replica handler if configured, otherwise primary.
+func (r readReplica) reader(primary *gorm.DB) *gorm.DB {
+	if r.readDB == nil {
+		return primary
+	}
+	return r.readDB()
+}
@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// BlockGap is an inclusive range of block numbers missing in the db.
+type BlockGap struct {
+	From uint64 `gorm:"column:gap_from"`
+	To   uint64 `gorm:"column:gap_to"`
+}
+
+// Size returns the number of blocks in the gap.
+func (g BlockGap) Size() uint64 {
+	return g.To - g.From + 1
+}
+
+// ReconciliationReport is the result of comparing the blocks stored in the db against the chain head.
+type ReconciliationReport struct {
+	ChainHead uint64
+	// LatestStoredHeight is the highest stored block number, 0 if no block is stored.
+	LatestStoredHeight uint64
+	// Gaps lists the ranges missing between the lowest stored block and the chain head in ascending order.
+	Gaps []BlockGap
+}
+
+// LargestGap returns the size of the largest gap, 0 if there is none.
+func (r *ReconciliationReport) LargestGap() uint64 {
+	var largest uint64
+	for _, gap := range r.Gaps {
+		if gap.Size() > largest {
+			largest = gap.Size()
+		}
+	}
+	return largest
+}
+
+// reconcileBlockHeight reports the gaps of the block numbers stored in table up to chainHead.
+// Nothing is reported for an empty table, as the watchers start from their configured heights.
+// The whole table is scanned, it is meant to be called once at startup.
+func reconcileBlockHeight(ctx context.Context, db *gorm.DB, table string, chainHead uint64) (*ReconciliationReport, error) {
+	db = db.WithContext(ctx)
+
+	var stored struct {
+		MaxNumber uint64 `gorm:"column:max_number"`
+		Count     uint64 `gorm:"column:count"`
+	}
+	query := fmt.Sprintf("SELECT COALESCE(MAX(number), 0) AS max_number, COUNT(*) AS count FROM %s WHERE deleted_at IS NULL", table)
+	if err := db.Raw(query).Scan(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{ChainHead: chainHead, LatestStoredHeight: stored.MaxNumber}
+	if stored.Count == 0 {
+		return report, nil
+	}
+
+	query = fmt.Sprintf(`SELECT number + 1 AS gap_from, next_number - 1 AS gap_to FROM (
+		SELECT number, LEAD(number) OVER (ORDER BY number) AS next_number FROM %s WHERE deleted_at IS NULL
+	) AS t WHERE next_number > number + 1 ORDER BY number`, table)
+	if err := db.Raw(query).Scan(&report.Gaps).Error; err != nil {
+		return nil, err
+	}
+
+	if chainHead > stored.MaxNumber {
+		report.Gaps = append(report.Gaps, BlockGap{From: stored.MaxNumber + 1, To: chainHead})
+	}
+	return report, nil
+}
@@ -0,0 +1,68 @@
+package orm
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/database/migrate"
+)
+
+// ExpectedSchemaVersion is the latest migration version of database/migrate this binary is built for,
+// it must be bumped together with every new migration.
+const ExpectedSchemaVersion = 32
+
+// schemaMigrationsTable is the version table of the goose migrations in database/migrate.
+const schemaMigrationsTable = "scroll_migrations"
+
+// schemaMigration is a row of the goose version table.
+type schemaMigration struct {
+	VersionID int64 `gorm:"column:version_id"`
+	IsApplied bool  `gorm:"column:is_applied"`
+}
+
+// GetSchemaVersion returns the current migration version of the database, following the goose semantics
+// that a version rolled back by a later row is no longer applied.
+func GetSchemaVersion(db *gorm.DB) (int64, error) {
+	var migrations []schemaMigration
+	if err := db.Table(schemaMigrationsTable).Select("version_id, is_applied").Order("id DESC").Find(&migrations).Error; err != nil {
+		return 0, fmt.Errorf("GetSchemaVersion error: %w", err)
+	}
+
+	rolledBack := make(map[int64]struct{})
+	for _, migration := range migrations {
+		if _, ok := rolledBack[migration.VersionID]; ok {
+			continue
+		}
+		if migration.IsApplied {
+			return migration.VersionID, nil
+		}
+		rolledBack[migration.VersionID] = struct{}{}
+	}
+	return 0, nil
+}
+
+// CheckSchemaVersion returns an error if the database schema is ahead of expectedVersion,
+// i.e. the binary is older than the migrations applied to the database.
+func CheckSchemaVersion(db *gorm.DB, expectedVersion int) error {
+	version, err := GetSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > int64(expectedVersion) {
+		return fmt.Errorf("database schema version %d is ahead of the expected version %d, upgrade the binary", version, expectedVersion)
+	}
+	return nil
+}
+
+// MigrateSchema applies the pending migrations of database/migrate to db.
+func MigrateSchema(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("MigrateSchema error: %w", err)
+	}
+	if err = migrate.Migrate(sqlDB); err != nil {
+		return fmt.Errorf("MigrateSchema error: %w", err)
+	}
+	return nil
+}
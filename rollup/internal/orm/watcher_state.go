@@ -0,0 +1,67 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WatcherState is the state of a watcher set by the operators, it is kept across restarts.
+type WatcherState struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	Name               string `json:"name" gorm:"column:name;primaryKey"`
+	StartBlockOverride uint64 `json:"start_block_override" gorm:"column:start_block_override"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewWatcherState creates a new WatcherState database instance.
+func NewWatcherState(db *gorm.DB) *WatcherState {
+	return &WatcherState{db: db}
+}
+
+// TableName returns the table name for the WatcherState model.
+func (*WatcherState) TableName() string {
+	return "watcher_state"
+}
+
+// GetStartBlockOverride returns the start block override of the watcher, 0 if it is not set.
+func (o *WatcherState) GetStartBlockOverride(ctx context.Context, name string) (uint64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&WatcherState{})
+	db = db.Where("name = ?", name)
+
+	var state WatcherState
+	if err := db.First(&state).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("WatcherState.GetStartBlockOverride error: %w, name: %v", err, name)
+	}
+	return state.StartBlockOverride, nil
+}
+
+// SetStartBlockOverride sets the start block override of the watcher, 0 clears it.
+func (o *WatcherState) SetStartBlockOverride(ctx context.Context, name string, block uint64) error {
+	state := WatcherState{
+		Name:               name,
+		StartBlockOverride: block,
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start_block_override", "updated_at"}),
+	})
+	if err := db.Create(&state).Error; err != nil {
+		return fmt.Errorf("WatcherState.SetStartBlockOverride error: %w, name: %v, block: %v", err, name, block)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+type traceIDKey struct{}
+
+// WithTrace returns a copy of ctx carrying traceID, which is emitted by the loggers returned by Logger.
+func WithTrace(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID returns the trace id carried by ctx, or an empty string if there is none.
+func TraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// NewTraceID returns a random trace id identifying a single run of an operation.
+func NewTraceID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Logger returns a logger emitting the trace id carried by ctx, if any, followed by the given key-value pairs,
+// e.g. Logger(ctx, "batchID", batch.Hash).
+func Logger(ctx context.Context, ctxKVs ...interface{}) log.Logger {
+	if traceID := TraceID(ctx); traceID != "" {
+		ctxKVs = append([]interface{}{"traceID", traceID}, ctxKVs...)
+	}
+	return log.New(ctxKVs...)
+}
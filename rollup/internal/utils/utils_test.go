@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,3 +49,31 @@ func TestBufferToUint256Le(t *testing.T) {
 	result := BufferToUint256Le(input)
 	assert.Equal(t, expectedOutput, result)
 }
+
+func TestTrace(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, TraceID(ctx))
+
+	traceID := NewTraceID()
+	assert.Len(t, traceID, 16)
+	assert.NotEqual(t, traceID, NewTraceID())
+
+	ctx = WithTrace(ctx, traceID)
+	assert.Equal(t, traceID, TraceID(ctx))
+
+	var records []*log.Record
+	capture := log.FuncHandler(func(r *log.Record) error {
+		records = append(records, r)
+		return nil
+	})
+	logger := Logger(ctx, "batchID", "0x01")
+	logger.SetHandler(capture)
+	logger.Info("traced")
+	logger = Logger(context.Background(), "chunkID", "0x02")
+	logger.SetHandler(capture)
+	logger.Info("untraced")
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, []interface{}{"traceID", traceID, "batchID", "0x01"}, records[0].Ctx)
+	assert.Equal(t, []interface{}{"chunkID", "0x02"}, records[1].Ctx)
+}
@@ -42,11 +42,15 @@ contract MockBridgeL2 {
   /// @notice Message nonce, used to avoid relay attack.
   uint256 public messageNonce;
 
+  /// @notice The latest L1 base fee set by the gas oracle relayer.
+  uint256 public l1BaseFee;
+
   /***********************************
    * Functions from L1GasPriceOracle *
    ***********************************/
 
-  function setL1BaseFee(uint256) external {
+  function setL1BaseFee(uint256 _l1BaseFee) external {
+    l1BaseFee = _l1BaseFee;
   }
 
   /************************************
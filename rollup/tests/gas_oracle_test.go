@@ -55,7 +55,7 @@ func testImportL1GasPrice(t *testing.T) {
 	assert.Equal(t, types.GasOracleStatus(blocks[0].GasOracleStatus), types.GasOraclePending)
 
 	// relay gas price
-	l1Relayer.ProcessGasPriceOracle()
+	assert.NoError(t, l1Relayer.ProcessGasPriceOracle())
 	blocks, err = l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"number": latestBlockHeight})
 	assert.NoError(t, err)
 	assert.Equal(t, len(blocks), 1)
@@ -116,7 +116,7 @@ func testImportL2GasPrice(t *testing.T) {
 	assert.Equal(t, types.GasOracleStatus(dbBatch.OracleStatus), types.GasOraclePending)
 
 	// relay gas price
-	l2Relayer.ProcessGasPriceOracle()
+	assert.NoError(t, l2Relayer.ProcessGasPriceOracle())
 	dbBatch, err = batchOrm.GetLatestBatch(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, batch)